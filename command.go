@@ -3,7 +3,6 @@ package commandkit
 
 import (
 	"fmt"
-	"os"
 )
 
 // Command represents a CLI command with its configuration
@@ -17,6 +16,9 @@ type Command struct {
 	Definitions map[string]*Definition
 	SubCommands map[string]*Command
 	Middleware  []CommandMiddleware
+	traced      []string          // Names of the Middleware entries, for MiddlewareChain
+	Metadata    map[string]string // Free-form annotations for middleware and doc generators
+	RequiredEnv []string          // Environment variables the command needs besides its definitions
 }
 
 // clone creates a deep copy of the command
@@ -41,6 +43,15 @@ func (cmd *Command) clone() *Command {
 	middleware := make([]CommandMiddleware, len(cmd.Middleware))
 	copy(middleware, cmd.Middleware)
 
+	// Copy metadata map
+	var metadata map[string]string
+	if cmd.Metadata != nil {
+		metadata = make(map[string]string, len(cmd.Metadata))
+		for k, v := range cmd.Metadata {
+			metadata[k] = v
+		}
+	}
+
 	return &Command{
 		Name:        cmd.Name,
 		Func:        cmd.Func,
@@ -51,6 +62,9 @@ func (cmd *Command) clone() *Command {
 		Definitions: definitions,
 		SubCommands: subCommands,
 		Middleware:  middleware,
+		traced:      append([]string(nil), cmd.traced...),
+		Metadata:    metadata,
+		RequiredEnv: append([]string(nil), cmd.RequiredEnv...),
 	}
 }
 
@@ -97,7 +111,7 @@ func validateRequiredFlags(cmd *Command, ctx *CommandContext) {
 
 			// Check environment variable
 			if !hasValue && def.envVar != "" {
-				if envVal := os.Getenv(def.envVar); envVal != "" {
+				if _, exists, _ := ctx.GlobalConfig.lookupEnv(def); exists {
 					hasValue = true
 				}
 			}
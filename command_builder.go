@@ -1,6 +1,8 @@
 // commandkit/command_builder.go
 package commandkit
 
+import "slices"
+
 // CommandBuilder provides a fluent API for building commands
 type CommandBuilder struct {
 	cmd    *Command
@@ -50,6 +52,29 @@ func (b *CommandBuilder) Aliases(aliases ...string) *CommandBuilder {
 	return b
 }
 
+// RequiresEnv declares environment variables the command needs that are not managed as
+// definitions, such as KUBECONFIG for a tool it shells out to. The command does not run
+// while one is unset or empty, and its help lists them. Requirements of a command also
+// apply to its subcommands.
+func (b *CommandBuilder) RequiresEnv(names ...string) *CommandBuilder {
+	for _, name := range names {
+		if !slices.Contains(b.cmd.RequiredEnv, name) {
+			b.cmd.RequiredEnv = append(b.cmd.RequiredEnv, name)
+		}
+	}
+	return b
+}
+
+// Annotation attaches a metadata entry to the command, such as "requires-auth" or
+// "stability", readable by middleware through CommandContext.Annotation
+func (b *CommandBuilder) Annotation(key, value string) *CommandBuilder {
+	if b.cmd.Metadata == nil {
+		b.cmd.Metadata = make(map[string]string)
+	}
+	b.cmd.Metadata[key] = value
+	return b
+}
+
 // Config defines command-specific configuration
 func (b *CommandBuilder) Config(fn func(*CommandConfig)) *CommandBuilder {
 	cmdConfig := b.createCommandConfig()
@@ -113,7 +138,8 @@ func (b *CommandBuilder) SubCommand(name string) *CommandBuilder {
 
 // Middleware adds middleware to this command
 func (b *CommandBuilder) Middleware(middleware CommandMiddleware) *CommandBuilder {
-	b.cmd.Middleware = append(b.cmd.Middleware, middleware)
+	b.cmd.Middleware = append(b.cmd.Middleware, traceMiddleware(middleware))
+	b.cmd.traced = append(b.cmd.traced, middlewareName(middleware))
 	return b
 }
 
@@ -0,0 +1,119 @@
+// commandkit/command_check.go
+package commandkit
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// CheckCommands validates the command tree and returns every problem found, joined:
+// aliases colliding with sibling command names or aliases, subcommand flags shadowing
+// a different key of the parent command, and commands with neither a Func nor
+// subcommands. Execute runs it before routing.
+func (c *Config) CheckCommands() error {
+	return errors.Join(checkCommandTree("", nil, c.commands)...)
+}
+
+// checkCommandTree checks sibling commands under parent, then their subcommands
+func checkCommandTree(path string, parent *Command, commands map[string]*Command) []error {
+	var problems []error
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Every name and alias must lead to a single command
+	owners := make(map[string]string, len(commands))
+	for _, name := range names {
+		owners[name] = name
+	}
+	for _, name := range names {
+		for _, alias := range commands[name].Aliases {
+			if owner, taken := owners[alias]; taken && owner != name {
+				problems = append(problems, fmt.Errorf("command '%s': alias '%s' collides with command '%s'", qualify(path, name), alias, qualify(path, owner)))
+				continue
+			}
+			owners[alias] = name
+		}
+	}
+
+	for _, name := range names {
+		cmd := commands[name]
+		if cmd.Func == nil && len(cmd.SubCommands) == 0 {
+			problems = append(problems, fmt.Errorf("command '%s' has no implementation", qualify(path, name)))
+		}
+		if parent != nil {
+			problems = append(problems, shadowedFlags(qualify(path, name), parent, cmd)...)
+		}
+		problems = append(problems, checkCommandTree(qualify(path, name), cmd, cmd.SubCommands)...)
+	}
+	return problems
+}
+
+// shadowedFlags reports subcommand flags that the parent command uses for another key
+func shadowedFlags(path string, parent, cmd *Command) []error {
+	parentFlags := make(map[string]string)
+	for key, def := range parent.Definitions {
+		if def.flag != "" {
+			parentFlags[def.flag] = key
+		}
+	}
+
+	keys := make([]string, 0, len(cmd.Definitions))
+	for key := range cmd.Definitions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var problems []error
+	for _, key := range keys {
+		def := cmd.Definitions[key]
+		if owner, taken := parentFlags[def.flag]; taken && owner != key {
+			problems = append(problems, fmt.Errorf("command '%s': flag --%s of %s shadows %s of the parent command", path, def.flag, key, owner))
+		}
+	}
+	return problems
+}
+
+// qualify joins a command path and name
+func qualify(path, name string) string {
+	return strings.TrimSpace(path + " " + name)
+}
+
+// requiredEnv returns the environment variables cmd requires, those of its parent
+// command first
+func requiredEnv(parent, cmd *Command) []string {
+	var names []string
+	if parent != nil && parent != cmd {
+		names = append(names, parent.RequiredEnv...)
+	}
+	for _, name := range cmd.RequiredEnv {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// checkRequiredEnv reports the environment variables required by the routed command
+// that are unset or empty
+func (c *Config) checkRequiredEnv(ctx *CommandContext, cmd *Command) []ConfigError {
+	var errs []ConfigError
+	for _, name := range requiredEnv(c.commands[ctx.Command], cmd) {
+		if c.getenv(name) != "" {
+			continue
+		}
+		errs = append(errs, ConfigError{
+			Key:              name,
+			Source:           "none",
+			Display:          name + " (environment, required)",
+			ErrorDescription: fmt.Sprintf("required by command '%s' but not set", strings.Join(commandPath(ctx), " ")),
+		})
+	}
+	return errs
+}
@@ -0,0 +1,93 @@
+package commandkit
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheckCommands(t *testing.T) {
+	cfg := New()
+	cfg.Command("start").Func(testCommand).Aliases("run", "s")
+	cfg.Command("stop").Func(testCommand).Aliases("s", "start")
+	cfg.Command("broken").ShortHelp("Not implemented yet")
+
+	db := cfg.Command("db").Config(func(cc *CommandConfig) {
+		cc.Define("DSN").String().Flag("dsn")
+	})
+	db.SubCommand("migrate").Func(testCommand).Config(func(cc *CommandConfig) {
+		cc.Define("TARGET").String().Flag("dsn")
+	})
+	db.SubCommand("seed").Func(testCommand).Aliases("load")
+	db.SubCommand("load").Func(testCommand)
+
+	err := cfg.CheckCommands()
+	if err == nil {
+		t.Fatal("expected problems")
+	}
+	want := []string{
+		"command 'broken' has no implementation",
+		"command 'stop': alias 's' collides with command 'start'",
+		"command 'stop': alias 'start' collides with command 'start'",
+		"command 'db migrate': flag --dsn of TARGET shadows DSN of the parent command",
+		"command 'db seed': alias 'load' collides with command 'db load'",
+	}
+	problems := strings.Split(err.Error(), "\n")
+	for _, w := range want {
+		found := false
+		for _, problem := range problems {
+			found = found || problem == w
+		}
+		if !found {
+			t.Errorf("missing problem %q in:\n%s", w, err)
+		}
+	}
+	if len(problems) != len(want) {
+		t.Errorf("expected %d problems, got:\n%s", len(want), err)
+	}
+
+	// Execute refuses to route through a broken tree
+	if err := cfg.Execute([]string{"app", "start"}); err == nil {
+		t.Error("expected Execute to report the command tree problems")
+	}
+
+	valid := New()
+	valid.Command("start").Func(testCommand).Aliases("run")
+	valid.Command("db").SubCommand("migrate").Func(testCommand)
+	if err := valid.CheckCommands(); err != nil {
+		t.Errorf("unexpected problems: %v", err)
+	}
+}
+
+func TestRequiresEnv(t *testing.T) {
+	newConfig := func(env ...string) *Config {
+		cfg := New().WithEnviron(env)
+		kube := cfg.Command("kube").ShortHelp("Cluster tasks").RequiresEnv("KUBECONFIG")
+		kube.SubCommand("apply").Func(testCommand).RequiresEnv("KUBE_CONTEXT", "KUBECONFIG")
+		return cfg
+	}
+
+	err := newConfig("KUBECONFIG=/etc/kube/config").ExecuteReport([]string{"app", "kube", "apply"}).Err
+	var configErrs ConfigErrors
+	if !errors.As(err, &configErrs) || len(configErrs) != 1 || configErrs[0].Key != "KUBE_CONTEXT" {
+		t.Fatalf("expected only KUBE_CONTEXT to be reported, got %v", err)
+	}
+	if !strings.Contains(configErrs[0].ErrorDescription, "command 'kube apply'") {
+		t.Errorf("error = %q, want it to name the command", configErrs[0].ErrorDescription)
+	}
+
+	// Empty counts as unset
+	if err := newConfig("KUBECONFIG=", "KUBE_CONTEXT=prod").ExecuteReport([]string{"app", "kube", "apply"}).Err; err == nil {
+		t.Error("expected an error for an empty KUBECONFIG")
+	}
+	if err := newConfig("KUBECONFIG=/etc/kube/config", "KUBE_CONTEXT=prod").ExecuteReport([]string{"app", "kube", "apply"}).Err; err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	output := captureStdout(t, func() {
+		newConfig().Execute([]string{"app", "kube", "apply", "--help"})
+	})
+	if !strings.Contains(output, "Required Environment:\n  KUBECONFIG\n  KUBE_CONTEXT\n") {
+		t.Errorf("help does not list the requirements:\n%s", output)
+	}
+}
@@ -5,12 +5,17 @@ package commandkit
 type CommandContext struct {
 	Args          []string
 	GlobalConfig  *Config // Immutable global config
-	CommandConfig *Config // Immutable command-specific config (nil if no command defs)
+	CommandConfig *Config // Command definitions layered over GlobalConfig (nil if no command defs)
 	Command       string
 	SubCommand    string
 	Flags         map[string]string
 	data          map[string]any    // For middleware data sharing
 	execution     *ExecutionContext // Thread-safe error collection
+	cmd           *Command          // Command being executed, once routed
+	result        any               // Value set with Result, rendered after execution
+	hasResult     bool
+	report        *RunReport // Report filled by ExecuteReport, nil otherwise
+	lastRun       *RunRecord // Previous run of the command, nil if none was recorded
 }
 
 // NewCommandContext creates a new command context
@@ -49,3 +54,12 @@ func (ctx *CommandContext) GetData(key string) (any, bool) {
 func (ctx *CommandContext) IsHelpRequested() bool {
 	return argsContainHelpFlag(ctx.Args)
 }
+
+// Annotation returns a metadata entry of the command being executed
+func (ctx *CommandContext) Annotation(key string) (string, bool) {
+	if ctx.cmd == nil {
+		return "", false
+	}
+	value, exists := ctx.cmd.Metadata[key]
+	return value, exists
+}
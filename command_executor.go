@@ -35,6 +35,7 @@ func (ce *commandExecutor) Execute(cmd *Command, ctx *CommandContext, services *
 	if ctx.execution == nil {
 		ctx.execution = NewExecutionContext(ctx.Command)
 	}
+	ctx.cmd = cmd
 
 	// 1. Validate command state
 	if err := ce.validateCommand(cmd, ctx); err != nil {
@@ -56,6 +57,9 @@ func (ce *commandExecutor) validateCommand(cmd *Command, ctx *CommandContext) *C
 	if cmd.Func == nil && len(cmd.SubCommands) > 0 {
 		// Use the new help system to show subcommand help
 		helpService := newHelpService()
+		if ctx.GlobalConfig != nil {
+			helpService.SetGlobalDefinitions(ctx.GlobalConfig.definitions)
+		}
 
 		// Get commands from the context's global config
 		var commands map[string]*Command
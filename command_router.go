@@ -37,6 +37,7 @@ func (cr *commandRouter) RouteCommand(args []string, config *Config) (*Command,
 	// Check for help requests first (last arg must be a help flag)
 	helpArgs := args[1:] // Skip program name (safe: len >= 0 slice)
 	helpService := config.getHelpService()
+	helpService.SetTags(helpTagsFromArgs(helpArgs))
 	if lastArgIsHelpFlag(helpArgs) {
 		err := helpService.ShowHelp(helpArgs, config.commands)
 		return nil, nil, err // Help shown, no command to execute
@@ -125,6 +126,7 @@ func (cr *commandRouter) RouteWithHelpHandling(args []string, config *Config) (*
 	if config == nil {
 		return nil, nil, fmt.Errorf("config cannot be nil")
 	}
+	config.getHelpService().SetTags(helpTagsFromArgs(args))
 
 	// Handle no command case - check for empty string default command
 	if len(args) < 2 {
@@ -1,7 +1,11 @@
 package commandkit
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -328,6 +332,39 @@ func TestCommandSuggestions(t *testing.T) {
 	}
 }
 
+func TestSuggestCommandsRanking(t *testing.T) {
+	cfg := New()
+	for _, name := range []string{"status", "start", "stop", "stage", "restart"} {
+		cfg.Command(name).Func(func(*CommandContext) error { return nil })
+	}
+
+	// Prefix matches first, then shorter distances, capped at three
+	if got := cfg.SuggestCommands("sta"); !reflect.DeepEqual(got, []string{"stage", "start", "status"}) {
+		t.Errorf("SuggestCommands(sta) = %v", got)
+	}
+	if got := cfg.SuggestCommands("stopp"); !reflect.DeepEqual(got, []string{"stop", "stage", "start"}) {
+		t.Errorf("SuggestCommands(stopp) = %v", got)
+	}
+	if got := cfg.SuggestCommands("xyz"); len(got) != 0 {
+		t.Errorf("SuggestCommands(xyz) = %v, want none", got)
+	}
+
+	// Frequently used commands win among equally close candidates
+	history := filepath.Join(t.TempDir(), "usage.json")
+	cfg.SuggestionHistory(history)
+	for range 2 {
+		if err := cfg.Execute([]string{"app", "start"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if got := cfg.SuggestCommands("sta"); !reflect.DeepEqual(got, []string{"start", "stage", "status"}) {
+		t.Errorf("SuggestCommands(sta) with history = %v", got)
+	}
+	if data, err := os.ReadFile(history); err != nil || string(data) != `{"start":2}` {
+		t.Errorf("history = %s (%v)", data, err)
+	}
+}
+
 func TestCommandMiddleware(t *testing.T) {
 	cfg := New()
 
@@ -451,6 +488,47 @@ func TestGlobalMiddleware(t *testing.T) {
 	}
 }
 
+func TestCommandAnnotations(t *testing.T) {
+	cfg := New()
+
+	var seen []string
+	cfg.UseMiddleware(func(next CommandFunc) CommandFunc {
+		return func(ctx *CommandContext) error {
+			if value, ok := ctx.Annotation("requires-auth"); ok && value == "true" {
+				seen = append(seen, ctx.Command+":auth")
+			}
+			return next(ctx)
+		}
+	})
+
+	builder := cfg.Command("deploy").
+		Func(func(ctx *CommandContext) error { return nil }).
+		Annotation("requires-auth", "true").
+		Annotation("stability", "beta")
+	cfg.Command("status").Func(func(ctx *CommandContext) error { return nil })
+
+	if err := cfg.Execute([]string{"app", "deploy"}); err != nil {
+		t.Fatalf("Command execution failed: %v", err)
+	}
+	if err := cfg.Execute([]string{"app", "status"}); err != nil {
+		t.Fatalf("Command execution failed: %v", err)
+	}
+	if len(seen) != 1 || seen[0] != "deploy:auth" {
+		t.Errorf("Expected middleware to see only the deploy annotation, got %v", seen)
+	}
+
+	deploy := cfg.commands["deploy"]
+	if deploy.Metadata["stability"] != "beta" {
+		t.Errorf("Expected stability metadata, got %v", deploy.Metadata)
+	}
+
+	clone := builder.Clone()
+	clone.Annotation("stability", "stable")
+	if deploy.Metadata["stability"] != "beta" {
+		t.Error("Expected cloned command metadata to be independent")
+	}
+}
+
 func TestUseMiddlewareForCommands(t *testing.T) {
 	cfg := New()
 
@@ -691,3 +769,112 @@ func TestGetSubcommandHelp(t *testing.T) {
 	// Print the actual help output to verify format
 	t.Logf("Actual help output:\n%s", help)
 }
+
+func TestPersistentFlags(t *testing.T) {
+	cfg := New()
+	cfg.PersistentFlag("OUTPUT", "output").String().Default("text").OneOf("text", "json")
+	cfg.Define("VERBOSE").CountFlag("v").Persistent()
+
+	var middlewareOutput string
+	cfg.UseMiddleware(func(next CommandFunc) CommandFunc {
+		return func(ctx *CommandContext) error {
+			middlewareOutput, _ = Get[string](ctx, "OUTPUT")
+			return next(ctx)
+		}
+	})
+
+	var output string
+	var verbose int64
+	var replicas int64
+	capture := func(ctx *CommandContext) error {
+		output, _ = Get[string](ctx, "OUTPUT")
+		verbose, _ = Get[int64](ctx, "VERBOSE")
+		replicas, _ = Get[int64](ctx, "REPLICAS")
+		return nil
+	}
+
+	cfg.Command("deploy").
+		Func(capture).
+		Config(func(cc *CommandConfig) {
+			cc.Define("REPLICAS").Int64().Flag("replicas").Default(int64(1))
+		}).
+		SubCommand("rollback").Func(func(ctx *CommandContext) error {
+		output, _ = Get[string](ctx, "OUTPUT")
+		return nil
+	})
+
+	if err := cfg.Execute([]string{"app", "--output", "json", "deploy", "--replicas", "3", "-v", "-v"}); err != nil {
+		t.Fatalf("Command execution failed: %v", err)
+	}
+	if output != "json" || middlewareOutput != "json" || verbose != 2 || replicas != 3 {
+		t.Errorf("got output=%q middleware=%q verbose=%d replicas=%d", output, middlewareOutput, verbose, replicas)
+	}
+
+	if err := cfg.Execute([]string{"app", "deploy", "rollback", "--output=text"}); err != nil {
+		t.Fatalf("Subcommand execution failed: %v", err)
+	}
+	if output != "text" {
+		t.Errorf("Expected the persistent flag to reach the subcommand, got %q", output)
+	}
+
+	err := cfg.Execute([]string{"app", "deploy", "--output", "yaml"})
+	var configErrs ConfigErrors
+	if !errors.As(err, &configErrs) || configErrs[0].Key != "OUTPUT" {
+		t.Errorf("Expected an OUTPUT validation error, got %v", err)
+	}
+}
+
+func TestCommandInheritsGlobalConfig(t *testing.T) {
+	os.Setenv("INHERIT_TEST_TOKEN", "s3cr3t")
+	defer os.Unsetenv("INHERIT_TEST_TOKEN")
+
+	cfg := New()
+	cfg.Define("REGION").String().Flag("region").Default("eu-west-1")
+	cfg.Define("TOKEN").String().Env("INHERIT_TEST_TOKEN").Secret()
+
+	var middlewareRegion string
+	cfg.UseMiddleware(func(next CommandFunc) CommandFunc {
+		return func(ctx *CommandContext) error {
+			middlewareRegion, _ = Get[string](ctx, "REGION")
+			return next(ctx)
+		}
+	})
+
+	var region, level, token, globalToken string
+	capture := func(ctx *CommandContext) error {
+		region, _ = Get[string](ctx, "REGION")
+		level, _ = Get[string](ctx, "LEVEL")
+		token = getConfig(ctx).GetSecret("TOKEN").String()
+		globalToken = ctx.GlobalConfig.GetSecret("TOKEN").String()
+		return nil
+	}
+
+	// A command with its own configuration layers over the globals
+	cfg.Command("deploy").Func(capture).Config(func(cc *CommandConfig) {
+		cc.Define("LEVEL").String().Flag("level").Default("info")
+	})
+	// A command without configuration, and a global defined after the commands
+	cfg.Command("status").Func(capture)
+	cfg.Define("LEVEL").String().Flag("level").Default("warn")
+
+	if err := cfg.Execute([]string{"app", "deploy", "--region", "us-east-1", "--level", "debug"}); err != nil {
+		t.Fatalf("Command execution failed: %v", err)
+	}
+	if region != "us-east-1" || middlewareRegion != "us-east-1" || level != "debug" {
+		t.Errorf("deploy: region=%q middleware=%q level=%q", region, middlewareRegion, level)
+	}
+	if token != "s3cr3t" || globalToken != "s3cr3t" {
+		t.Errorf("deploy: expected the global secret to be shared, got %q and %q", token, globalToken)
+	}
+	if value, _ := cfg.lookupValue("LEVEL"); value == "debug" {
+		t.Errorf("the command's LEVEL must not leak into the global config, got %v", value)
+	}
+
+	token = ""
+	if err := cfg.Execute([]string{"app", "status", "--region=ap-south-1"}); err != nil {
+		t.Fatalf("Command execution failed: %v", err)
+	}
+	if region != "ap-south-1" || level != "warn" || token != "s3cr3t" {
+		t.Errorf("status: region=%q level=%q token=%q", region, level, token)
+	}
+}
@@ -0,0 +1,197 @@
+// commandkit/completion.go
+package commandkit
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// completeCommand is the hidden command shells invoke to ask for completion candidates
+const completeCommand = "__complete"
+
+// Completions returns the completion candidates for the last word in args, where args are
+// the words typed after the program name (the last one possibly empty). Commands,
+// subcommands and flags are offered by name; flag values come from OneOf validations
+// and from the bool type.
+func (c *Config) Completions(args []string) []string {
+	if len(args) == 0 {
+		args = []string{""}
+	}
+	current := args[len(args)-1]
+	previous := args[:len(args)-1]
+
+	cmd, sub, known := c.completionTarget(previous)
+	if !known {
+		return nil
+	}
+	defs := c.definitions
+	if sub != nil {
+		defs = sub.Definitions
+	} else if cmd != nil {
+		defs = cmd.Definitions
+	}
+
+	// --flag=value
+	if name, value, found := strings.Cut(strings.TrimLeft(current, "-"), "="); found && strings.HasPrefix(current, "-") {
+		prefix := current[:len(current)-len(value)]
+		var candidates []string
+		if def := findFlagDefinition(defs, name); def != nil {
+			for _, v := range def.completionValues() {
+				candidates = append(candidates, prefix+v)
+			}
+		}
+		return filterByPrefix(candidates, current)
+	}
+
+	// --flag value
+	if len(previous) > 0 {
+		if def := findFlagDefinition(defs, flagName(previous[len(previous)-1])); def != nil && def.takesValue() {
+			return filterByPrefix(def.completionValues(), current)
+		}
+	}
+
+	if strings.HasPrefix(current, "-") {
+		var candidates []string
+		for _, def := range defs {
+			if def.flag != "" {
+				candidates = append(candidates, "--"+def.flag)
+			}
+		}
+		sort.Strings(candidates)
+		return filterByPrefix(candidates, current)
+	}
+
+	var candidates []string
+	switch {
+	case cmd == nil:
+		for name := range c.commands {
+			if name != "" {
+				candidates = append(candidates, name)
+			}
+		}
+	case sub == nil:
+		for name := range cmd.SubCommands {
+			candidates = append(candidates, name)
+		}
+	}
+	sort.Strings(candidates)
+	return filterByPrefix(candidates, current)
+}
+
+// completionTarget finds the command and subcommand selected by the words already typed.
+// known is false when the words name a command that does not exist.
+func (c *Config) completionTarget(words []string) (cmd, sub *Command, known bool) {
+	for i, word := range words {
+		if strings.HasPrefix(word, "-") || (i > 0 && isFlagValue(c, cmd, words[i-1])) {
+			continue
+		}
+		if cmd == nil {
+			found, exists := c.commands[word]
+			if !exists {
+				return nil, nil, len(c.commands) == 0
+			}
+			cmd = found
+			continue
+		}
+		return cmd, cmd.FindSubCommand(word), true
+	}
+	return cmd, nil, true
+}
+
+// isFlagValue reports whether a word following previous is the value of a flag
+func isFlagValue(c *Config, cmd *Command, previous string) bool {
+	if !strings.HasPrefix(previous, "-") || strings.Contains(previous, "=") {
+		return false
+	}
+	defs := c.definitions
+	if cmd != nil {
+		defs = cmd.Definitions
+	}
+	def := findFlagDefinition(defs, flagName(previous))
+	return def != nil && def.takesValue()
+}
+
+// completionValues returns the values a definition accepts, when they can be enumerated
+func (d *Definition) completionValues() []string {
+	for _, validation := range d.validations {
+		if values := validation.allowedValues(); len(values) > 0 {
+			return values
+		}
+	}
+	if d.valueType == TypeBool {
+		return []string{"true", "false"}
+	}
+	return nil
+}
+
+// takesValue reports whether the definition's flag consumes the following word
+func (d *Definition) takesValue() bool {
+	return !d.counter
+}
+
+// findFlagDefinition returns the definition registered for a flag name
+func findFlagDefinition(defs map[string]*Definition, name string) *Definition {
+	if name == "" {
+		return nil
+	}
+	for _, def := range defs {
+		if def.flag == name {
+			return def
+		}
+	}
+	return nil
+}
+
+// flagName strips leading dashes from a command-line word
+func flagName(word string) string {
+	if !strings.HasPrefix(word, "-") {
+		return ""
+	}
+	return strings.TrimLeft(word, "-")
+}
+
+// filterByPrefix keeps the candidates starting with prefix
+func filterByPrefix(candidates []string, prefix string) []string {
+	result := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, prefix) {
+			result = append(result, candidate)
+		}
+	}
+	return result
+}
+
+// CompletionScript returns a shell completion script for the executable.
+// Supported shells are bash, zsh and fish; the script calls back into the
+// program, which answers through Execute.
+func (c *Config) CompletionScript(shell string) (string, error) {
+	executable := getExecutableName()
+	function := "_" + strings.NewReplacer("-", "_", ".", "_").Replace(executable) + "_complete"
+
+	bash := fmt.Sprintf(`%[1]s() {
+    local IFS=$'\n'
+    COMPREPLY=($("${COMP_WORDS[0]}" %[3]s "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null))
+}
+complete -o default -F %[1]s %[2]s
+`, function, executable, completeCommand)
+
+	switch shell {
+	case "bash":
+		return bash, nil
+	case "zsh":
+		return "autoload -U +X bashcompinit && bashcompinit\n" + bash, nil
+	case "fish":
+		return fmt.Sprintf("complete -c %[1]s -f -a '(%[1]s %[2]s (commandline -opc)[2..-1] (commandline -ct))'\n",
+			executable, completeCommand), nil
+	default:
+		return "", fmt.Errorf("unsupported shell %q (supported: bash, zsh, fish)", shell)
+	}
+}
+
+// printCompletions answers a completion request made by a completion script
+func (c *Config) printCompletions(args []string) {
+	for _, candidate := range c.Completions(args) {
+		fmt.Println(candidate)
+	}
+}
@@ -0,0 +1,97 @@
+// commandkit/completion_test.go
+package commandkit
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newCompletionConfig() *Config {
+	cfg := New()
+	cfg.Define("LOG_LEVEL").String().Flag("log-level").OneOf("debug", "info", "warn", "error")
+
+	deploy := cfg.Command("deploy").Func(func(ctx *CommandContext) error { return nil })
+	deploy.Config(func(cc *CommandConfig) {
+		cc.Define("ENV").String().Flag("env").OneOf("dev", "staging", "prod")
+		cc.Define("DRY_RUN").Bool().Flag("dry-run")
+		cc.Define("VERBOSITY").CountFlag("v")
+	})
+	deploy.SubCommand("rollback").Func(func(ctx *CommandContext) error { return nil })
+	cfg.Command("status").Func(func(ctx *CommandContext) error { return nil })
+	return cfg
+}
+
+func TestCompletions(t *testing.T) {
+	cfg := newCompletionConfig()
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+	}{
+		{name: "commands", args: []string{""}, expected: []string{"deploy", "status"}},
+		{name: "command prefix", args: []string{"de"}, expected: []string{"deploy"}},
+		{name: "subcommands", args: []string{"deploy", ""}, expected: []string{"rollback"}},
+		{name: "flags", args: []string{"deploy", "--e"}, expected: []string{"--env"}},
+		{name: "flag values", args: []string{"deploy", "--env", ""}, expected: []string{"dev", "staging", "prod"}},
+		{name: "flag value prefix", args: []string{"deploy", "--env", "st"}, expected: []string{"staging"}},
+		{name: "inline flag value", args: []string{"deploy", "--env=p"}, expected: []string{"--env=prod"}},
+		{name: "bool values", args: []string{"deploy", "--dry-run", ""}, expected: []string{"true", "false"}},
+		{name: "count flag takes no value", args: []string{"deploy", "-v", ""}, expected: []string{"rollback"}},
+		{name: "flag value skipped when routing", args: []string{"deploy", "--env", "dev", "ro"}, expected: []string{"rollback"}},
+		{name: "unknown command", args: []string{"nope", ""}, expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := cfg.Completions(tt.args)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Completions(%q) = %q, expected %q", tt.args, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCompletionsWithoutCommands(t *testing.T) {
+	cfg := New()
+	cfg.Define("LOG_LEVEL").String().Flag("log-level").OneOf("debug", "info")
+
+	if got := cfg.Completions([]string{"--log-level", ""}); !reflect.DeepEqual(got, []string{"debug", "info"}) {
+		t.Errorf("Expected OneOf values, got %q", got)
+	}
+	if got := cfg.Completions([]string{"-"}); !reflect.DeepEqual(got, []string{"--log-level"}) {
+		t.Errorf("Expected flag names, got %q", got)
+	}
+}
+
+func TestExecuteAnswersCompletionRequests(t *testing.T) {
+	cfg := newCompletionConfig()
+
+	output := captureStdout(t, func() {
+		if err := cfg.Execute([]string{"app", completeCommand, "deploy", "--env", ""}); err != nil {
+			t.Errorf("Execute returned error: %v", err)
+		}
+	})
+	if output != "dev\nstaging\nprod\n" {
+		t.Errorf("Expected completion candidates on stdout, got %q", output)
+	}
+}
+
+func TestCompletionScript(t *testing.T) {
+	cfg := New()
+
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		script, err := cfg.CompletionScript(shell)
+		if err != nil {
+			t.Fatalf("CompletionScript(%q) failed: %v", shell, err)
+		}
+		if !strings.Contains(script, completeCommand) {
+			t.Errorf("Expected %s script to call back with %s, got:\n%s", shell, completeCommand, script)
+		}
+	}
+
+	if _, err := cfg.CompletionScript("powershell"); err == nil {
+		t.Error("Expected error for unsupported shell")
+	}
+}
@@ -0,0 +1,63 @@
+// commandkit/complex.go
+package commandkit
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Complex makes the definition hold a structured value that cannot be flattened into
+// flags or environment variables, such as a list of upstream objects. Complex values
+// are only read from files (or the default) and are retrieved with UnmarshalKey.
+func (b *DefinitionBuilder) Complex() *DefinitionBuilder {
+	b.def.valueType = TypeComplex
+	b.def.priority = SourcePriority{SourceFile, SourceDefault}
+	return b
+}
+
+// Schema validates the value with fn, which receives it as decoded from the file:
+// map[string]any for tables, []any for lists and plain values otherwise
+func (b *DefinitionBuilder) Schema(fn func(value any) error) *DefinitionBuilder {
+	b.def.validations = append(b.def.validations, Validation{Name: "schema", Check: fn})
+	return b
+}
+
+// UnmarshalKey decodes the value of key into out, typically a pointer to a struct or a
+// slice of structs. Fields are matched the way encoding/json matches them, honoring
+// json tags.
+func (ctx *CommandContext) UnmarshalKey(key string, out any) error {
+	c := getConfig(ctx).layerFor(key)
+	if _, defined := c.definitions[key]; !defined {
+		c = ctx.GlobalConfig
+	}
+	if def, defined := c.definitions[key]; defined && def.secret {
+		return fmt.Errorf("configuration '%s' is secret, use GetSecret() instead", key)
+	}
+	value, exists := c.lookupValue(key)
+	if !exists || value == nil {
+		return fmt.Errorf("configuration '%s' not found", key)
+	}
+	return unmarshalValue(key, value, out)
+}
+
+// UnmarshalKey decodes the value of key in the snapshot into out, like
+// CommandContext.UnmarshalKey
+func (s Snapshot) UnmarshalKey(key string, out any) error {
+	value, exists := s.values[key]
+	if !exists || value == nil {
+		return fmt.Errorf("configuration '%s' not found", key)
+	}
+	return unmarshalValue(key, value, out)
+}
+
+// unmarshalValue decodes value into out through its JSON form
+func unmarshalValue(key string, value, out any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("configuration '%s' cannot be decoded: %w", key, err)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("configuration '%s' does not match %T: %w", key, out, err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package commandkit
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestComplexValues(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfigFile(t, path, `upstreams:
+  - host: a.internal
+    weight: 3
+  - host: b.internal
+    weight: 1
+`)
+
+	type upstream struct {
+		Host   string `json:"host"`
+		Weight int    `json:"weight"`
+	}
+	validUpstreams := func(value any) error {
+		items, ok := value.([]any)
+		if !ok || len(items) == 0 {
+			return errors.New("expected a non-empty list of upstreams")
+		}
+		for _, item := range items {
+			if table, ok := item.(map[string]any); !ok || table["host"] == nil {
+				return errors.New("every upstream needs a host")
+			}
+		}
+		return nil
+	}
+
+	cfg := New()
+	cfg.Define("UPSTREAMS").Complex().File("upstreams").Schema(validUpstreams)
+	if err := cfg.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx := NewCommandContext(nil, cfg, "", "")
+	var upstreams []upstream
+	if err := ctx.UnmarshalKey("UPSTREAMS", &upstreams); err != nil {
+		t.Fatalf("UnmarshalKey failed: %v", err)
+	}
+	if len(upstreams) != 2 || upstreams[0] != (upstream{"a.internal", 3}) || upstreams[1] != (upstream{"b.internal", 1}) {
+		t.Errorf("upstreams = %+v", upstreams)
+	}
+	var wrong map[string]string
+	if err := ctx.UnmarshalKey("UPSTREAMS", &wrong); err == nil {
+		t.Error("expected an error decoding a list into a map")
+	}
+
+	writeConfigFile(t, path, "upstreams:\n  - weight: 3\n")
+	cfg = New()
+	cfg.Define("UPSTREAMS").Complex().File("upstreams").Schema(validUpstreams)
+	if err := cfg.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if err := cfg.Process([]string{"app"}); err == nil || !strings.Contains(err.Error(), "every upstream needs a host") {
+		t.Errorf("expected the schema to reject the value, got %v", err)
+	}
+
+	cfg = New().WithEnviron([]string{"UPSTREAMS=a.internal"})
+	cfg.Define("UPSTREAMS").Complex().Env("UPSTREAMS").Priority(PriorityEnvFlagDefault)
+	if err := cfg.Process([]string{"app"}); err == nil || !strings.Contains(err.Error(), "complex values can only be read from files") {
+		t.Errorf("expected environment values to be rejected, got %v", err)
+	}
+}
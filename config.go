@@ -2,11 +2,19 @@
 package commandkit
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/awnumar/memguard"
+	"github.com/fsnotify/fsnotify"
 )
 
 // Config holds configuration definitions and values
@@ -14,15 +22,53 @@ type Config struct {
 	definitions      map[string]*Definition
 	values           map[string]any
 	secrets          *SecretStore
+	valueSources     map[string]SourceType // Source each resolved value came from
 	flagSet          *flag.FlagSet
 	flagValues       map[string]*string
 	fileConfig       *FileConfig
 	commands         map[string]*Command
 	globalMiddleware []CommandMiddleware
+	middlewareSteps  []middlewareStep // Describes globalMiddleware, for MiddlewareChain
 	overrideWarnings *OverrideWarnings
 	processed        bool
 	helpService      *helpService
 	defaultPriority  SourcePriority // Fallback priority for definitions without explicit priority
+	mu               sync.RWMutex   // Guards values and secrets while a reload swaps them
+	runMu            sync.Mutex     // Serializes the runs started with ExecuteReport
+	version          int            // Version of the last committed snapshot
+	history          []Snapshot     // Last committed versions, oldest first
+	historyLimit     int            // Versions kept in history, 0 for the default
+	loadedAt         time.Time      // When the active values were committed
+	reloadInterval   time.Duration  // How long RunWithReload lets file events settle
+	reloadHandoffs   []func(previous, current Snapshot)
+	changeHandlers   map[string][]func(old, new any)
+	reloadFailed     []func([]ConfigError)
+	reloadApplied    []func()
+	sourcePolicies   map[string]SourceFailurePolicy
+	sourceFailed     []func(source, key string, err error)
+	staging          bool
+	liveValues       map[string]any
+	errorFormat      ErrorFormat              // How configuration errors are printed
+	watchers         []*fsnotify.Watcher      // File watchers started by WatchFile
+	refresher        *refreshLoop             // Polling loop started by StartRefresh
+	subscriptions    []subscriber             // Channels returned by Watch, closed by Destroy
+	sources          []registeredSource       // External sources, highest priority first
+	sourceCache      *sourceCache             // Last known source values, for SourceFailureCached
+	parent           *Config                  // Global config a command config layers over
+	configFlag       *configFlag              // Built-in flag naming configuration files
+	decryptors       map[string]Decryptor     // Decryptors for ENC[scheme,...] file values
+	fileDecryptors   map[string]FileDecryptor // Decryptors for whole files, by name suffix
+	mergeStrategy    MergeStrategy            // How files loaded later combine with earlier ones
+	sliceMerge       SliceMerge               // How lists in files loaded later combine, unless set per key
+	usageHistory     string                   // File counting command runs to rank suggestions
+	stateDir         string                   // Directory recording command runs, "" for none
+	environ          map[string]string        // Injected environment snapshot, nil for the process environment
+	environment      string                   // Environment selected with SetEnvironment
+	secretFileCheck  bool                     // Reject secrets from files with loose permissions
+	limits           Limits                   // Input size limits, zero for none
+	timeLocation     *time.Location           // Zone of Time values given without one, nil for UTC
+	schedules        []scheduledCommand       // Commands run by RunScheduler
+	scheduleHook     func(*RunReport)         // Receives the report of every scheduled run
 }
 
 // New creates a new Config instance
@@ -39,6 +85,31 @@ func New() *Config {
 		overrideWarnings: NewOverrideWarnings(),
 		processed:        false,
 		defaultPriority:  PriorityFlagEnvDefault, // Flag > Env > Default to match test expectations
+		reloadInterval:   defaultReloadInterval,
+	}
+}
+
+// deriveChild returns a Config sharing the settings c resolves values with, such as
+// sources, decryptors, limits and the environment snapshot. Callers set what differs:
+// definitions, values, secrets, flags and files. Settings added to Config that affect
+// resolution belong here, so command, inherited and reload configs never drop them.
+func (c *Config) deriveChild() *Config {
+	return &Config{
+		commands:         c.commands,
+		defaultPriority:  c.defaultPriority,
+		sources:          c.sources,
+		sourceCache:      c.sourceCache,
+		sourcePolicies:   c.sourcePolicies,
+		sourceFailed:     c.sourceFailed,
+		decryptors:       c.decryptors,
+		fileDecryptors:   c.fileDecryptors,
+		mergeStrategy:    c.mergeStrategy,
+		sliceMerge:       c.sliceMerge,
+		environ:          c.environ,
+		secretFileCheck:  c.secretFileCheck,
+		limits:           c.limits,
+		timeLocation:     c.timeLocation,
+		overrideWarnings: NewOverrideWarnings(),
 	}
 }
 
@@ -49,6 +120,15 @@ func (c *Config) Define(key string) *DefinitionBuilder {
 	return builder
 }
 
+// MustDefine starts a definition like Define, but panics as soon as its flag or
+// environment variable is already used by another key. Define reports such conflicts
+// when processing or executing instead.
+func (c *Config) MustDefine(key string) *DefinitionBuilder {
+	builder := c.Define(key)
+	builder.strict = true
+	return builder
+}
+
 // Command starts a new command definition
 func (c *Config) Command(name string) *CommandBuilder {
 	builder := newCommandBuilder(c, name)
@@ -56,9 +136,28 @@ func (c *Config) Command(name string) *CommandBuilder {
 	return builder
 }
 
+// FindCommand returns the command at path, such as ("db", "migrate"), following
+// aliases, or nil if there is none
+func (c *Config) FindCommand(path ...string) *Command {
+	if len(path) == 0 {
+		return nil
+	}
+	cmd, exists := c.commands[path[0]]
+	if !exists {
+		return nil
+	}
+	for _, name := range path[1:] {
+		if cmd = cmd.FindSubCommand(name); cmd == nil {
+			return nil
+		}
+	}
+	return cmd
+}
+
 // UseMiddleware adds global middleware that applies to all commands
 func (c *Config) UseMiddleware(middleware CommandMiddleware) {
-	c.globalMiddleware = append(c.globalMiddleware, middleware)
+	c.globalMiddleware = append(c.globalMiddleware, traceMiddleware(middleware))
+	c.middlewareSteps = append(c.middlewareSteps, middlewareStep{name: middlewareName(middleware)})
 }
 
 // UseMiddlewareForCommands adds middleware only for specific commands
@@ -68,13 +167,18 @@ func (c *Config) UseMiddlewareForCommands(commandNames []string, middleware Comm
 		return func(ctx *CommandContext) error {
 			for _, name := range commandNames {
 				if ctx.Command == name {
-					return middleware(next)(ctx)
+					return traceMiddleware(middleware)(next)(ctx)
 				}
 			}
 			return next(ctx)
 		}
 	}
 	c.globalMiddleware = append(c.globalMiddleware, wrapper)
+	c.middlewareSteps = append(c.middlewareSteps, middlewareStep{
+		name:     middlewareName(middleware),
+		scope:    "commands: " + strings.Join(commandNames, ", "),
+		commands: commandNames,
+	})
 }
 
 // UseMiddlewareForSubcommands adds middleware only for specific subcommands of a command
@@ -85,7 +189,7 @@ func (c *Config) UseMiddlewareForSubcommands(commandName string, subcommandNames
 			if ctx.Command == commandName {
 				for _, name := range subcommandNames {
 					if ctx.SubCommand == name {
-						return middleware(next)(ctx)
+						return traceMiddleware(middleware)(next)(ctx)
 					}
 				}
 			}
@@ -93,6 +197,12 @@ func (c *Config) UseMiddlewareForSubcommands(commandName string, subcommandNames
 		}
 	}
 	c.globalMiddleware = append(c.globalMiddleware, wrapper)
+	c.middlewareSteps = append(c.middlewareSteps, middlewareStep{
+		name:        middlewareName(middleware),
+		scope:       commandName + " subcommands: " + strings.Join(subcommandNames, ", "),
+		commands:    []string{commandName},
+		subcommands: subcommandNames,
+	})
 }
 
 // SetDefaultPriority sets the default priority order for all definitions
@@ -102,11 +212,40 @@ func (c *Config) SetDefaultPriority(priority SourcePriority) *Config {
 	return c
 }
 
+// implicitOrder is the conventional precedence used to place a source that is
+// enabled implicitly (by UseSource or ConfigFlag) into the default priority
+var implicitOrder = SourcePriority{SourceFlag, SourceEnv, SourceFile, SourceRemote, SourceDefault}
+
+// ensureSource adds source to the default priority, if missing, below the sources
+// that conventionally override it
+func (c *Config) ensureSource(source SourceType) {
+	if slices.Contains(c.defaultPriority, source) {
+		return
+	}
+	rank := slices.Index(implicitOrder, source)
+	priority := slices.Clone(c.defaultPriority)
+	i := slices.IndexFunc(priority, func(s SourceType) bool {
+		return slices.Index(implicitOrder, s) > rank
+	})
+	if i < 0 {
+		i = len(priority)
+	}
+	c.defaultPriority = slices.Insert(priority, i, source)
+}
+
 // GetDefaultPriority returns the current default priority order
 func (c *Config) GetDefaultPriority() SourcePriority {
 	return append(SourcePriority(nil), c.defaultPriority...)
 }
 
+// SetTimeLocation sets the zone Time values given without one are read in, such as
+// "2030-01-01 02:00" from a flag, environment variable, file text or default. Without
+// it they are read in UTC. Values with an explicit offset keep it.
+func (c *Config) SetTimeLocation(loc *time.Location) *Config {
+	c.timeLocation = loc
+	return c
+}
+
 // processDefinitions resolves and validates all definitions, storing values into the Config.
 // It assumes c.flagValues is already populated. Returns any ConfigErrors found.
 func (c *Config) processDefinitions() []ConfigError {
@@ -117,12 +256,31 @@ func (c *Config) processDefinitions() []ConfigError {
 // When help is requested, validation is skipped to allow help display.
 func (c *Config) processDefinitionsWithContext(ctx *CommandContext) []ConfigError {
 	var errs []ConfigError
+	sources := make(map[string]SourceType, len(c.definitions))
 
 	for key, def := range c.definitions {
 		var value any
 		var source SourceType
 		var err error
 
+		// Ask on the terminal for opted-in secrets no source provides
+		if def.promptIfMissing && (ctx == nil || !ctx.IsHelpRequested()) && !c.hasSourceValue(key, def) {
+			if c.secrets.Has(key) {
+				continue // Typed at an earlier prompt
+			}
+			if prompted, promptErr := c.promptSecret(key, def); prompted {
+				if promptErr != nil {
+					errs = append(errs, ConfigError{
+						Key:              key,
+						Source:           "prompt",
+						Display:          buildErrorDisplay(def),
+						ErrorDescription: promptErr.Error(),
+					})
+				}
+				continue
+			}
+		}
+
 		// Use context-aware resolution if context is provided
 		if ctx != nil {
 			value, source, err = c.resolveValueWithPriorityContext(key, def, ctx)
@@ -147,33 +305,39 @@ func (c *Config) processDefinitionsWithContext(ctx *CommandContext) []ConfigErro
 			continue
 		}
 
-		if def.secret && value != nil {
+		sources[key] = source
+		if buf, ok := value.(*memguard.LockedBuffer); ok {
+			c.secrets.storeBuffer(key, buf)
+		} else if def.secret && value != nil {
 			strValue := fmt.Sprintf("%v", value)
 			c.secrets.Store(key, strValue)
+			if source == SourceFile {
+				c.sealFileSecret(key, def)
+			}
 		} else {
 			c.values[key] = value
 		}
 	}
 
+	// Relational rules need every value resolved first
+	if len(errs) == 0 && (ctx == nil || !ctx.IsHelpRequested()) {
+		errs = append(errs, c.checkKeyRelations(sources)...)
+		errs = append(errs, c.checkTLSBundles(sources)...)
+	}
+
 	overrideWarnings := c.checkSourceOverrides()
 	if overrideWarnings.HasWarnings() {
 		c.overrideWarnings = overrideWarnings
 		// Automatic logging removed - overrides work silently as expected
 	}
 
+	c.valueSources = sources
 	return errs
 }
 
 // processConfigWithContext parses flags from the provided args, validates all definitions,
 // and populates the Config's values and secrets maps with context awareness.
 func (c *Config) processConfigWithContext(args []string, ctx *CommandContext) []ConfigError {
-	if c.processed {
-		c.values = make(map[string]any)
-		c.secrets.DestroyAll()
-		c.secrets = newSecretStore()
-	}
-	c.processed = true
-
 	services := c.createServices()
 	flagParser := services.FlagParser
 	parsedFlags, err := flagParser.ParseGlobal(args, c.definitions)
@@ -186,24 +350,67 @@ func (c *Config) processConfigWithContext(args []string, ctx *CommandContext) []
 		}}
 	}
 
+	// Processing again resolves into a staging area so a failure keeps the current
+	// values and secrets
+	if c.processed {
+		return c.reprocessDefinitions(parsedFlags.Values, ctx)
+	}
+	c.processed = true
 	c.flagValues = parsedFlags.Values
 
 	// Use context-aware processing if context is provided
+	var errs []ConfigError
 	if ctx != nil {
-		return c.processDefinitionsWithContext(ctx)
+		errs = c.processDefinitionsWithContext(ctx)
+	} else {
+		errs = c.processDefinitions()
+	}
+
+	if len(errs) == 0 {
+		c.mu.Lock()
+		c.recordVersion(maps.Clone(c.values), c.valueSources)
+		c.mu.Unlock()
+		c.commitSourceCache()
+	}
+	return errs
+}
+
+// reprocessDefinitions resolves every definition into a staging Config and swaps the
+// values and secrets in only when there are no errors
+func (c *Config) reprocessDefinitions(flagValues map[string]*string, ctx *CommandContext) []ConfigError {
+	staged := c.stagingConfig(c.fileConfig, flagValues)
+	errs := staged.processDefinitionsWithContext(ctx)
+	if len(errs) == 0 {
+		errs = c.immutableChanges(staged)
+	}
+	if len(errs) > 0 {
+		staged.secrets.DestroyAll()
+		return errs
 	}
 
-	return c.processDefinitions()
+	c.flagValues = flagValues
+	c.overrideWarnings = staged.overrideWarnings
+	c.commitSnapshot(&Snapshot{
+		values:     staged.values,
+		secrets:    staged.secrets,
+		sources:    staged.valueSources,
+		fileConfig: c.fileConfig,
+	}).DestroyAll()
+	return nil
 }
 
-// Destroy cleans up all secrets from memory
+// Destroy stops watchers and the refresher, closes Watch channels and cleans up all
+// secrets from memory
 func (c *Config) Destroy() {
+	c.StopWatching()
+	c.StopRefresh()
+	c.closeSubscriptions()
 	c.secrets.DestroyAll()
 }
 
 // IsSecret checks if a configuration key is defined as a secret
 func (c *Config) IsSecret(key string) bool {
-	if def, exists := c.definitions[key]; exists {
+	if def, exists := c.layerFor(key).definitions[key]; exists {
 		return def.secret
 	}
 	return false
@@ -219,17 +426,18 @@ func (c *Config) HasOverrideWarnings() bool {
 	return c.overrideWarnings.HasWarnings()
 }
 
-// PrintOverrideWarnings prints override warnings to stderr
+// PrintOverrideWarnings prints override warnings to stderr, unless quiet mode is selected
 func (c *Config) PrintOverrideWarnings() {
-	if c.overrideWarnings.HasWarnings() {
+	if c.overrideWarnings.HasWarnings() && c.verbosity() > VerbosityQuiet {
 		fmt.Fprint(os.Stderr, c.overrideWarnings.FormatWarnings())
 	}
 }
 
-// Dump returns a map of all configuration values (secrets masked)
-func (c *Config) Dump() map[string]string {
+// Dump returns a map of all configuration values (secrets, and values duplicating
+// a secret, masked). Given tags, only the keys having one of them are included.
+func (c *Config) Dump(tags ...string) map[string]string {
 	result := make(map[string]string)
-	for key, def := range c.definitions {
+	for key, def := range taggedDefinitions(c.definitions, tags) {
 		if def.secret {
 			if c.secrets.Get(key).IsSet() {
 				result[key] = "[SECRET:" + fmt.Sprintf("%d", c.secrets.Get(key).Size()) + " bytes]"
@@ -237,7 +445,7 @@ func (c *Config) Dump() map[string]string {
 				result[key] = "[SECRET:not set]"
 			}
 		} else if val, ok := c.values[key]; ok && val != nil {
-			result[key] = fmt.Sprintf("%v", val)
+			result[key] = c.maskSecretCopy(key, fmt.Sprintf("%v", val))
 		} else {
 			result[key] = "[not set]"
 		}
@@ -256,6 +464,7 @@ func (c *Config) getHelpService() *helpService {
 	if c.helpService == nil {
 		c.helpService = newHelpService()
 	}
+	c.helpService.SetGlobalDefinitions(c.definitions)
 	return c.helpService
 }
 
@@ -269,39 +478,76 @@ func (c *Config) createServices() *CommandServices {
 	return newCommandServices()
 }
 
+// Execute routes args (laid out like os.Args) to a command and runs it, or processes
+// the configuration when no commands are defined
 func (c *Config) Execute(args []string) error {
+	return c.execute(args, nil)
+}
+
+// execute runs Execute, filling report (if not nil) with what ran
+func (c *Config) execute(args []string, report *RunReport) error {
+	// Answer shell completion requests before anything else
+	if len(args) > 1 && args[1] == completeCommand {
+		c.printCompletions(args[2:])
+		return nil
+	}
+
 	// Check if this is a no-command application
 	if len(c.commands) == 0 {
-		// Create a temporary context to check for help request
-		tempCtx := NewCommandContext(args[1:], c, "", "")
-
-		errs := c.processConfigWithContext(args[1:], tempCtx)
-		if len(errs) > 0 {
-			// If help is requested, don't show configuration errors
-			if tempCtx.IsHelpRequested() {
-				// Show help instead
-				return c.ShowGlobalHelp()
-			}
+		err := c.Process(args)
+		if errors.Is(err, ErrHelpRequested) {
+			// Help was shown instead of configuration errors
+			return nil
+		}
 
-			executable := filepath.Base(args[0])
-			execCtx := NewExecutionContext(executable)
-			for _, configErr := range errs {
-				execCtx.CollectConfigError(c, configErr)
-			}
-			helpText, err := execCtx.renderErrorsWithCommand(nil, c.getHelpService())
-			if err != nil {
-				return err
+		var configErrs ConfigErrors
+		if errors.As(err, &configErrs) {
+			if printErr := c.printErrors(os.Stderr, filepath.Base(args[0]), configErrs); printErr != nil {
+				return printErr
 			}
-			fmt.Fprintln(os.Stderr, helpText)
-			return fmt.Errorf("configuration errors")
 		}
-		return nil
+		return err
+	}
+
+	// Definitions sharing a flag or an environment variable would silently override
+	// each other
+	if errs := c.definitionConflicts(); len(errs) > 0 {
+		err := ConfigErrors(errs)
+		if printErr := c.printErrors(os.Stderr, filepath.Base(args[0]), err); printErr != nil {
+			return printErr
+		}
+		return err
+	}
+
+	// A broken command tree would otherwise fail mysteriously while routing
+	if err := c.CheckCommands(); err != nil {
+		return err
 	}
 
 	// Create services for routing
 	services := c.createServices()
 	router := services.CommandRouter
 
+	// Load the files named by the config flag before routing sees the arguments, then
+	// check the selected environment against everything loaded
+	args, errs := c.applyConfigFlag(args)
+	errs = append(errs, c.checkEnvironment(c.fileConfig)...)
+	if len(errs) > 0 {
+		err := ConfigErrors(errs)
+		if printErr := c.printErrors(os.Stderr, filepath.Base(args[0]), err); printErr != nil {
+			return printErr
+		}
+		return err
+	}
+
+	// Persistent flags may appear anywhere, even before the command name
+	var persistentArgs []string
+	if len(args) > 1 {
+		var rest []string
+		persistentArgs, rest = c.splitPersistentArgs(args[1:])
+		args = append([]string{args[0]}, rest...)
+	}
+
 	// Route command with integrated help handling
 	cmd, ctx, err := router.RouteWithHelpHandling(args, c)
 	if err != nil {
@@ -312,9 +558,31 @@ func (c *Config) Execute(args []string) error {
 	if cmd == nil {
 		return nil
 	}
+	c.recordCommandUse(ctx.Command)
+
+	if report != nil {
+		ctx.report = report
+		report.record(ctx)
+	}
 
-	// Execute command with global middleware
-	return c.executeWithGlobalMiddleware(cmd, ctx)
+	// Resolve the global configuration the command inherits, and check the environment
+	// it requires
+	errs = c.processInherited(cmd, persistentArgs, ctx)
+	errs = append(errs, c.checkRequiredEnv(ctx, cmd)...)
+	if len(errs) > 0 {
+		err := ConfigErrors(errs)
+		if printErr := c.printErrors(os.Stderr, filepath.Base(args[0]), err); printErr != nil {
+			return printErr
+		}
+		return err
+	}
+
+	// Execute command with global middleware, recording the run in the state directory
+	c.loadLastRun(ctx)
+	start := time.Now()
+	err = c.executeWithGlobalMiddleware(cmd, ctx)
+	c.recordRun(ctx, start, err)
+	return err
 }
 
 // executeWithGlobalMiddleware wraps command execution with global middleware
@@ -323,26 +591,34 @@ func (c *Config) executeWithGlobalMiddleware(cmd *Command, ctx *CommandContext)
 	services := c.createServices()
 	middlewareChain := services.MiddlewareChain
 
+	// Expose the routed command (and its annotations) to global middleware
+	ctx.cmd = cmd
+
 	// Create the final execution function that runs the command
 	execFunc := func(ctx *CommandContext) error {
 		result := cmd.Execute(ctx)
 		if result.Error != nil {
 			// Check if execution context has errors and display them
 			if ctx.execution != nil && ctx.execution.HasErrors() {
+				// Reported runs never exit, the caller receives the errors instead
+				if ctx.report != nil {
+					return ctx.execution.configErrors()
+				}
 				helpText, err := ctx.execution.renderErrorsWithCommand(cmd, c.getHelpService())
 				if err != nil {
 					return err
 				}
-				fmt.Fprintln(os.Stderr, helpText)
-				os.Exit(1)
+				fmt.Fprintln(os.Stderr, redactContext(ctx, helpText))
+				// SafeExit wipes every memguard buffer before the process terminates
+				memguard.SafeExit(1)
 			}
 
 			// Always display the message if it exists
 			if result.Message != "" {
-				fmt.Fprintln(os.Stderr, result.Message)
+				fmt.Fprintln(os.Stderr, redactContext(ctx, result.Message))
 			}
 
-			if result.ShouldExit {
+			if result.ShouldExit && ctx.report == nil {
 				result.Handle()
 			}
 		}
@@ -352,7 +628,18 @@ func (c *Config) executeWithGlobalMiddleware(cmd *Command, ctx *CommandContext)
 	// Apply global middleware using MiddlewareChain service
 	finalFunc := middlewareChain.ApplyGlobalOnly(c.globalMiddleware, execFunc)
 
-	return finalFunc(ctx)
+	if err := finalFunc(ctx); err != nil {
+		return err
+	}
+
+	// Reported runs hand the result to the caller instead of rendering it
+	if ctx.report != nil {
+		ctx.report.Result = ctx.result
+		return nil
+	}
+
+	// Render the command result once the command and its middleware succeeded
+	return ctx.writeResult(os.Stdout)
 }
 
 // ShowGlobalHelp displays help for all commands using the new template-based help system
@@ -365,18 +652,9 @@ func (c *Config) ShowCommandHelp(commandName string) error {
 	return c.getHelpService().ShowHelp([]string{"app", commandName, "--help"}, c.commands)
 }
 
-// findSuggestions finds similar command names for suggestions
+// findSuggestions formats the commands suggested for input, best first
 func (c *Config) findSuggestions(input string) string {
-	var suggestions []string
-	minDistance := 3
-
-	for name := range c.commands {
-		distance := levenshteinDistance(input, name)
-		if distance <= minDistance {
-			suggestions = append(suggestions, name)
-		}
-	}
-
+	suggestions := c.SuggestCommands(input)
 	if len(suggestions) == 0 {
 		return "no similar commands found"
 	}
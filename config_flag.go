@@ -0,0 +1,99 @@
+// commandkit/config_flag.go
+package commandkit
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// configFlag describes the built-in flag pointing at configuration files
+type configFlag struct {
+	flag   string
+	envVar string
+}
+
+// ConfigFlag enables a built-in flag, such as --config, naming configuration files to
+// load before the configuration is processed. The flag is repeatable (later files
+// override earlier ones) and accepted anywhere on the command line. When it is not
+// given, envVar (if not empty) may hold the files instead, separated by the OS path
+// list separator. Files are loaded with LoadFiles, and file values are added to the
+// default priority below flags and environment variables if it has no file source.
+func (c *Config) ConfigFlag(flag, envVar string) *Config {
+	c.configFlag = &configFlag{flag: flag, envVar: envVar}
+	c.ensureSource(SourceFile)
+	return c
+}
+
+// applyConfigFlag removes the config flag from args (laid out like os.Args) and loads
+// the files it names, or those in the environment variable when the flag is absent
+func (c *Config) applyConfigFlag(args []string) ([]string, []ConfigError) {
+	if c.configFlag == nil || len(args) == 0 {
+		return args, nil
+	}
+
+	files, rest, err := c.configFlag.split(args[1:])
+	if err != nil {
+		return args, []ConfigError{c.configFlag.error("flag", "", err)}
+	}
+	args = append([]string{args[0]}, rest...)
+
+	source := "flag"
+	if len(files) == 0 && c.configFlag.envVar != "" {
+		source = "environment"
+		files = filepath.SplitList(c.getenv(c.configFlag.envVar))
+	}
+
+	var errs []ConfigError
+	for _, filename := range files {
+		if filename == "" || c.fileConfig != nil && slices.Contains(c.fileConfig.files, filename) {
+			continue
+		}
+		if err := c.LoadFile(filename); err != nil {
+			errs = append(errs, c.configFlag.error(source, filename, err))
+		}
+	}
+	return args, errs
+}
+
+// split separates the config flag's values from the other arguments
+func (cf *configFlag) split(args []string) (files, rest []string, err error) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+
+		name, value, hasValue := strings.Cut(flagName(arg), "=")
+		if name != cf.flag {
+			rest = append(rest, arg)
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return nil, nil, fmt.Errorf("flag needs an argument: -%s", cf.flag)
+			}
+			i++
+			value = args[i]
+		}
+		files = append(files, value)
+	}
+	return files, rest, nil
+}
+
+// error reports a config file that could not be used
+func (cf *configFlag) error(source, filename string, err error) ConfigError {
+	display := "--" + cf.flag
+	if source == "environment" {
+		display = cf.envVar
+	}
+	return ConfigError{
+		Key:              display,
+		Source:           source,
+		Value:            filename,
+		Display:          display,
+		ErrorDescription: err.Error(),
+	}
+}
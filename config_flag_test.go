@@ -0,0 +1,70 @@
+package commandkit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfigFlagLoadsFiles(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.json")
+	local := filepath.Join(dir, "local.yaml")
+	writeConfigFile(t, base, `{"port": 8080, "host": "example.com"}`)
+	writeConfigFile(t, local, "port: 9090\n")
+
+	cfg := New()
+	cfg.Define("PORT").Int64().File("port").Default(int64(80))
+	cfg.Define("HOST").String().File("host").Flag("host")
+	cfg.ConfigFlag("config", "")
+
+	if err := cfg.Process([]string{"app", "--config", base, "--host", "flag.example.com", "--config=" + local}); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if value, _ := cfg.lookupValue("PORT"); value != int64(9090) {
+		t.Errorf("later files should override earlier ones, got PORT=%v", value)
+	}
+	if value, _ := cfg.lookupValue("HOST"); value != "flag.example.com" {
+		t.Errorf("flags should override files, got HOST=%v", value)
+	}
+}
+
+func TestConfigFlagFromEnvironment(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeConfigFile(t, path, `{"port": 7070}`)
+	os.Setenv("CONFIG_FLAG_TEST", path)
+	defer os.Unsetenv("CONFIG_FLAG_TEST")
+
+	var port int64
+	cfg := New()
+	cfg.Define("PORT").Int64().File("port")
+	cfg.ConfigFlag("config", "CONFIG_FLAG_TEST")
+	cfg.Command("serve").Func(func(ctx *CommandContext) error {
+		port, _ = Get[int64](ctx, "PORT")
+		return nil
+	})
+
+	if err := cfg.Execute([]string{"app", "serve"}); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if port != 7070 {
+		t.Errorf("expected the file named by the environment variable to be loaded, got PORT=%d", port)
+	}
+}
+
+func TestConfigFlagErrors(t *testing.T) {
+	cfg := New()
+	cfg.Define("PORT").Int64().File("port")
+	cfg.ConfigFlag("config", "")
+
+	err := cfg.Process([]string{"app", "--config", filepath.Join(t.TempDir(), "missing.json")})
+	if err == nil || !strings.Contains(err.Error(), "--config") {
+		t.Errorf("expected a --config error, got %v", err)
+	}
+
+	err = cfg.Process([]string{"app", "--config"})
+	if err == nil || !strings.Contains(err.Error(), "needs an argument") {
+		t.Errorf("expected a missing argument error, got %v", err)
+	}
+}
@@ -3,7 +3,6 @@ package commandkit
 
 import (
 	"fmt"
-	"os"
 )
 
 // ConfigProcessor processes command-specific configuration
@@ -41,20 +40,20 @@ func (cp *configProcessor) ProcessCommandConfig(cmd *Command, ctx *CommandContex
 	// Parse command-specific flags to detect flag errors with rich reporting
 	services := newCommandServices()
 	flagParser := services.FlagParser
-	parsedFlags, err := flagParser.ParseCommand(ctx.Args, cmd.Definitions)
-
-	// Create temp config with command definitions and inherited global settings
-	tempConfig := &Config{
-		definitions:      cmd.Definitions,
-		values:           make(map[string]any),
-		secrets:          newSecretStore(),
-		flagSet:          parsedFlags.FlagSet,
-		flagValues:       parsedFlags.Values,
-		fileConfig:       ctx.GlobalConfig.fileConfig,
-		commands:         ctx.GlobalConfig.commands,
-		defaultPriority:  ctx.GlobalConfig.defaultPriority,
-		overrideWarnings: NewOverrideWarnings(),
-	}
+	// Global flags are accepted too, but inherited definitions are resolved on the global config
+	globals := ctx.GlobalConfig.definitions
+	flagDefs := flagDefinitions(cmd, globals)
+	parsedFlags, err := flagParser.ParseCommand(ctx.Args, flagDefs)
+
+	// Create the command layer over the global config with the command's own definitions
+	tempConfig := ctx.GlobalConfig.deriveChild()
+	tempConfig.definitions = ownDefinitions(cmd, globals)
+	tempConfig.values = make(map[string]any)
+	tempConfig.secrets = newSecretStore()
+	tempConfig.flagSet = parsedFlags.FlagSet
+	tempConfig.flagValues = parsedFlags.Values
+	tempConfig.fileConfig = ctx.GlobalConfig.fileConfig
+	tempConfig.parent = ctx.GlobalConfig
 
 	// Handle flag parsing errors with rich per-flag error info
 	if err != nil || len(parsedFlags.Errors) > 0 {
@@ -64,7 +63,7 @@ func (cp *configProcessor) ProcessCommandConfig(cmd *Command, ctx *CommandContex
 		}
 		allErrors = append(allErrors, parsedFlags.Errors...)
 
-		flagConfigErrs := flagParser.ConvertFlagErrorsToConfigErrors(allErrors, cmd.Definitions)
+		flagConfigErrs := flagParser.ConvertFlagErrorsToConfigErrors(allErrors, flagDefs)
 
 		if ctx.execution != nil {
 			ctx.execution.Clear()
@@ -111,7 +110,7 @@ func (cp *configProcessor) ValidateRequiredFlags(cmd *Command, ctx *CommandConte
 		return errorResult(fmt.Errorf("context cannot be nil"))
 	}
 
-	for key, def := range cmd.Definitions {
+	for key, def := range ownDefinitions(cmd, ctx.GlobalConfig.definitions) {
 		if def.required {
 			// Check if value is provided in any source (flag, env, or default)
 			hasValue := false
@@ -132,7 +131,7 @@ func (cp *configProcessor) ValidateRequiredFlags(cmd *Command, ctx *CommandConte
 
 			// Check environment variable
 			if !hasValue && def.envVar != "" {
-				if envVal := os.Getenv(def.envVar); envVal != "" {
+				if _, exists, _ := ctx.GlobalConfig.lookupEnv(def); exists {
 					hasValue = true
 				}
 			}
@@ -277,3 +277,19 @@ func TestSecretHandling(t *testing.T) {
 		t.Errorf("Expected message 'use GetSecret() instead', got '%s'", collected[0].Message)
 	}
 }
+
+func TestDeriveChildKeepsSettings(t *testing.T) {
+	loc := time.FixedZone("CET", 3600)
+	cfg := New()
+	cfg.WithEnviron([]string{"A=1"}).SetTimeLocation(loc).SetSliceMerge(SliceAppend).StrictSecretFiles()
+	cfg.Limits(Limits{MaxItems: 3})
+	cfg.UseFileDecryptor(".enc", FileDecryptorFunc(func(data []byte) ([]byte, error) { return data, nil }))
+	cfg.SetSourceFailurePolicy("map", SourceFailureDefault).UseSource(&mapSource{})
+
+	child := cfg.deriveChild()
+	if !reflect.DeepEqual(child.environ, cfg.environ) || child.timeLocation != loc || child.sliceMerge != SliceAppend ||
+		!child.secretFileCheck || child.limits != cfg.limits || len(child.fileDecryptors) != 1 ||
+		len(child.sources) != 1 || child.sources[0].failure != SourceFailureDefault {
+		t.Errorf("child config lost settings: %+v", child)
+	}
+}
@@ -0,0 +1,70 @@
+// commandkit/custom_type.go
+package commandkit
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ParseFunc parses the text of a value given by a flag, environment variable, file,
+// remote source or string default into a custom type
+type ParseFunc func(raw string) (any, error)
+
+// customTypes holds the types registered with RegisterType, by name
+var customTypes = struct {
+	sync.RWMutex
+	parsers map[string]ParseFunc
+}{parsers: make(map[string]ParseFunc)}
+
+// RegisterType registers a named custom type, such as money, geo coordinates or
+// Kubernetes quantities, for DefinitionBuilder.Type and DefinitionSpec. Registering a
+// name again replaces its parser; built-in type names cannot be registered.
+func RegisterType(name string, parse ParseFunc) {
+	if _, builtin := valueTypeNamed(name); builtin || name == "" {
+		panic(fmt.Sprintf("commandkit: cannot register type %q: the name is built in", name))
+	}
+	customTypes.Lock()
+	defer customTypes.Unlock()
+	customTypes.parsers[name] = parse
+}
+
+// registeredType returns the parser registered for name
+func registeredType(name string) (ParseFunc, bool) {
+	customTypes.RLock()
+	defer customTypes.RUnlock()
+	parse, found := customTypes.parsers[name]
+	return parse, found
+}
+
+// ParseWith makes the key a custom type parsed by parse. The parsed values go through
+// validations and are read with Get of the type parse returns.
+func (b *DefinitionBuilder) ParseWith(parse ParseFunc) *DefinitionBuilder {
+	b.def.valueType = TypeCustom
+	b.def.customType = ""
+	b.def.parse = parse
+	return b
+}
+
+// Type makes the key the custom type registered under name with RegisterType. The
+// name is resolved when values are parsed, so the type may be registered later; an
+// unknown name is reported as a configuration error.
+func (b *DefinitionBuilder) Type(name string) *DefinitionBuilder {
+	b.def.valueType = TypeCustom
+	b.def.customType = name
+	b.def.parse = func(raw string) (any, error) {
+		parse, found := registeredType(name)
+		if !found {
+			return nil, fmt.Errorf("type %q is not registered", name)
+		}
+		return parse(raw)
+	}
+	return b
+}
+
+// typeName returns the name of the custom type, or of the value type
+func (d *Definition) typeName() string {
+	if d.customType != "" {
+		return d.customType
+	}
+	return d.valueType.String()
+}
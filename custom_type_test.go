@@ -0,0 +1,107 @@
+package commandkit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// money is a custom type used by the tests, in cents
+type money int64
+
+func (m money) String() string {
+	return fmt.Sprintf("%d.%02d", m/100, m%100)
+}
+
+func parseMoney(raw string) (any, error) {
+	units, cents, _ := strings.Cut(strings.TrimSpace(raw), ".")
+	u, err := strconv.ParseInt(units, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid amount: %s", raw)
+	}
+	c := int64(0)
+	if cents != "" {
+		if c, err = strconv.ParseInt(cents, 10, 64); err != nil || len(cents) != 2 {
+			return nil, fmt.Errorf("invalid amount: %s", raw)
+		}
+	}
+	return money(u*100 + c), nil
+}
+
+func TestParseWith(t *testing.T) {
+	cfg := New()
+	cfg.WithEnviron([]string{"BUDGET=12.50"})
+	cfg.Define("BUDGET").ParseWith(parseMoney).Env("BUDGET").Custom("positive", func(value any) error {
+		if value.(money) <= 0 {
+			return fmt.Errorf("budget must be positive")
+		}
+		return nil
+	})
+	cfg.Define("FEE").ParseWith(parseMoney).Default("0.99")
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := NewCommandContext([]string{}, cfg, "", "")
+	if budget := MustGet[money](ctx, "BUDGET"); budget != 1250 {
+		t.Errorf("BUDGET = %v", budget)
+	}
+	if fee := MustGet[money](ctx, "FEE"); fee != 99 {
+		t.Errorf("FEE = %v", fee)
+	}
+	if dump := cfg.Dump(); dump["BUDGET"] != "12.50" {
+		t.Errorf("Dump() BUDGET = %q", dump["BUDGET"])
+	}
+
+	for _, value := range []string{"12.5", "0"} {
+		cfg := New()
+		cfg.WithEnviron([]string{"BUDGET=" + value})
+		cfg.Define("BUDGET").ParseWith(parseMoney).Env("BUDGET").Custom("positive", func(value any) error {
+			if value.(money) <= 0 {
+				return fmt.Errorf("budget must be positive")
+			}
+			return nil
+		})
+		if err := cfg.Process([]string{"app"}); err == nil {
+			t.Errorf("expected %q to be rejected", value)
+		}
+	}
+}
+
+func TestRegisterType(t *testing.T) {
+	RegisterType("money", parseMoney)
+
+	cfg := New()
+	cfg.WithEnviron([]string{"BUDGET=3.00", "LIMIT=7.25"})
+	cfg.Define("BUDGET").Type("money").Env("BUDGET")
+	if err := cfg.DefineFromSpec([]DefinitionSpec{{Key: "LIMIT", Type: "money", Env: "LIMIT"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := NewCommandContext([]string{}, cfg, "", "")
+	if budget := MustGet[money](ctx, "BUDGET"); budget != 300 {
+		t.Errorf("BUDGET = %v", budget)
+	}
+	if limit := MustGet[money](ctx, "LIMIT"); limit != 725 {
+		t.Errorf("LIMIT = %v", limit)
+	}
+	if def, _ := cfg.Definition("BUDGET"); def.TypeName() != "money" {
+		t.Errorf("TypeName() = %q", def.TypeName())
+	}
+
+	unknown := New()
+	unknown.WithEnviron([]string{"BUDGET=3.00"})
+	unknown.Define("BUDGET").Type("doubloons").Env("BUDGET")
+	if err := unknown.Process([]string{"app"}); err == nil || !strings.Contains(err.Error(), "not registered") {
+		t.Errorf("expected unregistered type error, got %v", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected registering a built-in name to panic")
+		}
+	}()
+	RegisterType("int64", parseMoney)
+}
@@ -4,43 +4,78 @@ package commandkit
 import (
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 	"time"
 )
 
 // Definition represents a configuration definition with fluent builder
 type Definition struct {
-	key          string
-	valueType    ValueType
-	envVar       string
-	flag         string
-	fileKey      string // Key name to look for in loaded files
-	defaultValue any
-	required     bool
-	secret       bool
-	delimiter    string
-	validations  []Validation
-	description  string
-	sources      []SourceType   // Available sources for this definition
-	priority     SourcePriority // Custom priority order (nil = use config default)
+	key             string
+	valueType       ValueType
+	envVar          string
+	flag            string
+	counter         bool   // Flag counts its occurrences instead of taking a value
+	fileKey         string // Key name to look for in loaded files
+	defaultValue    any
+	fallbackKeys    []string // Names tried in order when a source has no value under the main one
+	required        bool
+	secret          bool
+	promptIfMissing bool // Ask on the terminal when no source provides a value
+	persistent      bool // Flag is accepted anywhere on the command line of every command
+	immutable       bool // Value cannot change across reloads
+	strictBool      bool // Only accept the bool forms of strconv.ParseBool
+	strictInt       bool // Only accept plain decimal integers
+	delimiter       string
+	parse           ParseFunc  // Parser of a TypeCustom value
+	customType      string     // Name of a registered custom type, "" for ParseWith
+	tlsBundle       string     // Key given to DefineTLS, set on the bundle's certificate key
+	timeLayouts     []string   // Layouts accepted for Time() values, nil for the defaults
+	sliceMerge      SliceMerge // How lists of this key combine across files
+	validations     []Validation
+	description     string
+	example         string         // Sample value shown in help and example config files
+	tags            []string       // Labels used to filter Dump, help and docs
+	ttl             time.Duration  // Age after which the value is reported by Stale
+	sources         []SourceType   // Available sources for this definition
+	onlyFrom        []SourceType   // Sources a value may come from, nil for any
+	neverFrom       []SourceType   // Sources a value must not come from
+	priority        SourcePriority // Custom priority order (nil = use config default)
 }
 
 // clone creates a deep copy of the definition
 func (d *Definition) clone() *Definition {
 	return &Definition{
-		key:          d.key,
-		valueType:    d.valueType,
-		envVar:       d.envVar,
-		flag:         d.flag,
-		fileKey:      d.fileKey,
-		defaultValue: d.defaultValue,
-		required:     d.required,
-		secret:       d.secret,
-		delimiter:    d.delimiter,
-		validations:  append([]Validation(nil), d.validations...),
-		description:  d.description,
-		sources:      append([]SourceType(nil), d.sources...),
-		priority:     append(SourcePriority(nil), d.priority...),
+		key:             d.key,
+		valueType:       d.valueType,
+		envVar:          d.envVar,
+		flag:            d.flag,
+		counter:         d.counter,
+		fileKey:         d.fileKey,
+		fallbackKeys:    append([]string(nil), d.fallbackKeys...),
+		defaultValue:    d.defaultValue,
+		required:        d.required,
+		secret:          d.secret,
+		promptIfMissing: d.promptIfMissing,
+		persistent:      d.persistent,
+		immutable:       d.immutable,
+		strictBool:      d.strictBool,
+		strictInt:       d.strictInt,
+		delimiter:       d.delimiter,
+		parse:           d.parse,
+		customType:      d.customType,
+		tlsBundle:       d.tlsBundle,
+		timeLayouts:     append([]string(nil), d.timeLayouts...),
+		sliceMerge:      d.sliceMerge,
+		validations:     append([]Validation(nil), d.validations...),
+		description:     d.description,
+		example:         d.example,
+		tags:            append([]string(nil), d.tags...),
+		ttl:             d.ttl,
+		sources:         append([]SourceType(nil), d.sources...),
+		onlyFrom:        append([]SourceType(nil), d.onlyFrom...),
+		neverFrom:       append([]SourceType(nil), d.neverFrom...),
+		priority:        append(SourcePriority(nil), d.priority...),
 	}
 }
 
@@ -48,6 +83,7 @@ func (d *Definition) clone() *Definition {
 type DefinitionBuilder struct {
 	def    *Definition
 	config *Config
+	strict bool // Panic when the flag or environment variable is already taken
 }
 
 // formatValidation formats validation rules for help display
@@ -65,9 +101,7 @@ func formatValidation(validations []Validation) []string {
 		case strings.HasPrefix(validation.Name, "max("):
 			maxVal = extractValue(validation.Name, "max(")
 		case strings.HasPrefix(validation.Name, "oneOf("):
-			// Extract values from oneOf(format)
-			values := extractOneOfValues(validation.Name)
-			result = append(result, fmt.Sprintf("oneOf: %s", values))
+			result = append(result, fmt.Sprintf("one of: %s", strings.Join(validation.allowedValues(), ", ")))
 		case strings.HasPrefix(validation.Name, "minLength("):
 			min := extractValue(validation.Name, "minLength(")
 			result = append(result, fmt.Sprintf("minLength: %s", min))
@@ -77,6 +111,12 @@ func formatValidation(validations []Validation) []string {
 		case strings.HasPrefix(validation.Name, "regexp("):
 			pattern := extractValue(validation.Name, "regexp(")
 			result = append(result, fmt.Sprintf("pattern: %s", pattern))
+		case strings.HasPrefix(validation.Name, "lessThanKey("):
+			other := extractValue(validation.Name, "lessThanKey(")
+			result = append(result, fmt.Sprintf("< %s", other))
+		case strings.HasPrefix(validation.Name, "greaterThanKey("):
+			other := extractValue(validation.Name, "greaterThanKey(")
+			result = append(result, fmt.Sprintf("> %s", other))
 		default:
 			// For other validations, use the name as-is
 			result = append(result, validation.Name)
@@ -109,53 +149,31 @@ func extractValue(name, prefix string) string {
 	return name[start : start+end]
 }
 
-// extractOneOfValues extracts values from oneOf(['a', 'b', 'c']) format
+// extractOneOfValues formats the values of a oneOf validation name for display
 func extractOneOfValues(name string) string {
+	return strings.Join(oneOfValues(name), ", ")
+}
+
+// oneOfValues extracts the values from oneOf([a b c]), oneOf(['a','b']) or oneOf(a,b,c) names
+func oneOfValues(name string) []string {
 	start := strings.Index(name, "oneOf(")
 	if start == -1 {
-		return ""
+		return nil
 	}
 	start += len("oneOf(")
-	end := strings.Index(name[start:], ")")
-	if end == -1 {
-		return name[start:]
-	}
-	values := name[start : start+end]
-
-	// Handle array format oneOf([debug info warn error])
-	if strings.HasPrefix(values, "[") && strings.HasSuffix(values, "]") {
-		// Remove brackets and clean up
-		content := values[1 : len(values)-1]
-		// Split by space and filter empty strings
-		parts := strings.Fields(content)
-		var quotedParts []string
-		for _, part := range parts {
-			if part != "" {
-				quotedParts = append(quotedParts, fmt.Sprintf("'%s'", part))
-			}
-		}
-		var sb strings.Builder
-		sb.Grow(len(quotedParts)*8 + 2) // Pre-allocate estimated capacity
-		sb.WriteString("[")
-		sb.WriteString(strings.Join(quotedParts, ", "))
-		sb.WriteString("]")
-		return sb.String()
+	values := name[start:]
+	if end := strings.LastIndex(values, ")"); end != -1 {
+		values = values[:end]
 	}
+	values = strings.TrimSuffix(strings.TrimPrefix(values, "["), "]")
 
-	// Handle simple format oneOf(a,b,c)
-	parts := strings.Split(values, ",")
-	var quotedParts []string
-	for _, part := range parts {
-		if strings.TrimSpace(part) != "" {
-			quotedParts = append(quotedParts, fmt.Sprintf("'%s'", strings.TrimSpace(part)))
+	var result []string
+	for _, part := range strings.FieldsFunc(values, func(r rune) bool { return r == ',' || r == ' ' }) {
+		if part = strings.Trim(part, `'"`); part != "" {
+			result = append(result, part)
 		}
 	}
-	var sb strings.Builder
-	sb.Grow(len(quotedParts)*8 + 2) // Pre-allocate estimated capacity
-	sb.WriteString("[")
-	sb.WriteString(strings.Join(quotedParts, ", "))
-	sb.WriteString("]")
-	return sb.String()
+	return result
 }
 
 // formatFlagHelp generates enhanced help text with required/default indicators and validations
@@ -212,6 +230,19 @@ func newDefinitionBuilder(cfg *Config, key string) *DefinitionBuilder {
 	}
 }
 
+// mustBeUnique panics, for MustDefine builders, when another key already uses name as
+// its flag or environment variable
+func (b *DefinitionBuilder) mustBeUnique(kind, name string, get func(*Definition) string) {
+	if !b.strict || name == "" {
+		return
+	}
+	for key, def := range b.config.definitions {
+		if key != b.def.key && get(def) == name {
+			panic(fmt.Sprintf("commandkit: %s %s of %s is already used by %s", kind, name, b.def.key, key))
+		}
+	}
+}
+
 // Type setters
 
 func (b *DefinitionBuilder) String() *DefinitionBuilder {
@@ -229,6 +260,11 @@ func (b *DefinitionBuilder) Int() *DefinitionBuilder {
 	return b
 }
 
+func (b *DefinitionBuilder) Int32() *DefinitionBuilder {
+	b.def.valueType = TypeInt32
+	return b
+}
+
 func (b *DefinitionBuilder) Float64() *DefinitionBuilder {
 	b.def.valueType = TypeFloat64
 	return b
@@ -244,11 +280,25 @@ func (b *DefinitionBuilder) Duration() *DefinitionBuilder {
 	return b
 }
 
+// URL makes the key a URL with a scheme and a host, read with Get[string] or GetURL
 func (b *DefinitionBuilder) URL() *DefinitionBuilder {
 	b.def.valueType = TypeURL
 	return b
 }
 
+// Schemes requires a URL, or every item of a URLSlice, to use one of the schemes,
+// compared case-insensitively
+func (b *DefinitionBuilder) Schemes(schemes ...string) *DefinitionBuilder {
+	b.def.validations = append(b.def.validations, validateURLSchemes(schemes))
+	return b
+}
+
+// RequirePort requires a URL, or every item of a URLSlice, to give an explicit port
+func (b *DefinitionBuilder) RequirePort() *DefinitionBuilder {
+	b.def.validations = append(b.def.validations, validateURLPort())
+	return b
+}
+
 func (b *DefinitionBuilder) StringSlice() *DefinitionBuilder {
 	b.def.valueType = TypeStringSlice
 	return b
@@ -303,11 +353,121 @@ func (b *DefinitionBuilder) Time() *DefinitionBuilder {
 	return b
 }
 
+// TimeLayout makes the key a time value accepted only in the given layouts (in time
+// package notation, e.g. "2006-01-02"), tried in order. Without it, Time() values
+// accept RFC3339 and a few common layouts.
+func (b *DefinitionBuilder) TimeLayout(layouts ...string) *DefinitionBuilder {
+	b.def.valueType = TypeTime
+	b.def.timeLayouts = layouts
+	return b
+}
+
+// StringMap makes the key a map[string]string, given as key=value entries separated
+// by the delimiter in flags and environment variables, or as a map in files
+func (b *DefinitionBuilder) StringMap() *DefinitionBuilder {
+	b.def.valueType = TypeStringMap
+	return b
+}
+
+// StrictBool makes a Bool or BoolSlice key accept only true/false, 1/0 and the other
+// forms of strconv.ParseBool, rejecting yes/no, on/off and enabled/disabled
+func (b *DefinitionBuilder) StrictBool() *DefinitionBuilder {
+	b.def.strictBool = true
+	return b
+}
+
+// StrictInt makes an integer key accept only plain decimal digits, rejecting the
+// 1_000_000, 0x1F and 1e6 forms
+func (b *DefinitionBuilder) StrictInt() *DefinitionBuilder {
+	b.def.strictInt = true
+	return b
+}
+
 func (b *DefinitionBuilder) Float64Slice() *DefinitionBuilder {
 	b.def.valueType = TypeFloat64Slice
 	return b
 }
 
+// Port makes the key a TCP or UDP port number from 1 to 65535, read with Get[int]
+func (b *DefinitionBuilder) Port() *DefinitionBuilder {
+	b.def.valueType = TypePort
+	return b
+}
+
+// MustBeFree checks during processing that nothing listens on the TCP port yet, by
+// binding it briefly on all interfaces. A reload keeping the port the process itself
+// now listens on is not reported.
+func (b *DefinitionBuilder) MustBeFree() *DefinitionBuilder {
+	b.def.validations = append(b.def.validations, validatePortFree())
+	return b
+}
+
+// TimeZone makes the key an IANA time zone name such as "Europe/Madrid", read with
+// Get[*time.Location]
+func (b *DefinitionBuilder) TimeZone() *DefinitionBuilder {
+	b.def.valueType = TypeTimeZone
+	return b
+}
+
+// HostPort makes the key a network address such as "db.internal:5432" or ":8080",
+// read with Get[HostPort] or Get[string]
+func (b *DefinitionBuilder) HostPort() *DefinitionBuilder {
+	b.def.valueType = TypeHostPort
+	return b
+}
+
+// MustResolve checks during processing that the host of a HostPort value resolves in
+// DNS; IP addresses and empty hosts are accepted as they are
+func (b *DefinitionBuilder) MustResolve() *DefinitionBuilder {
+	b.def.validations = append(b.def.validations, validateHostResolves())
+	return b
+}
+
+// Locale makes the key a BCP 47 language tag such as "en-US" or "pt-BR", read with
+// Get[language.Tag] or with Get[string] in canonical form
+func (b *DefinitionBuilder) Locale() *DefinitionBuilder {
+	b.def.valueType = TypeLocale
+	return b
+}
+
+// Pattern makes the key a regular expression in RE2 syntax, compiled when the
+// configuration is processed and read with GetRegexp. Regexp, by contrast, validates
+// a String value against a fixed expression.
+func (b *DefinitionBuilder) Pattern() *DefinitionBuilder {
+	b.def.valueType = TypeRegexp
+	return b
+}
+
+// Email makes the key an RFC 5322 email address such as "ops@example.com" or
+// "Ops <ops@example.com>", read with Get[string] or Get[*mail.Address]
+func (b *DefinitionBuilder) Email() *DefinitionBuilder {
+	b.def.valueType = TypeEmail
+	return b
+}
+
+// EmailDomains requires an Email value to belong to one of the domains, compared
+// case-insensitively; subdomains must be listed on their own
+func (b *DefinitionBuilder) EmailDomains(domains ...string) *DefinitionBuilder {
+	b.def.validations = append(b.def.validations, validateEmailDomains(domains))
+	return b
+}
+
+// URLSlice makes the key a list of URLs, e.g. broker addresses, each requiring a
+// scheme and a host. Values are read with Get[[]string] or Get[[]*url.URL].
+func (b *DefinitionBuilder) URLSlice() *DefinitionBuilder {
+	b.def.valueType = TypeURLSlice
+	return b
+}
+
+// DurationSlice makes the key a []time.Duration, e.g. a retry backoff schedule given
+// as "1s,5s,30s"
+func (b *DefinitionBuilder) DurationSlice() *DefinitionBuilder {
+	b.def.valueType = TypeDurationSlice
+	return b
+}
+
+// BoolSlice makes the key a []bool, e.g. feature toggles per shard given as
+// "true,false,true"; items accept the same forms as Bool()
 func (b *DefinitionBuilder) BoolSlice() *DefinitionBuilder {
 	b.def.valueType = TypeBoolSlice
 	return b
@@ -323,11 +483,19 @@ func (b *DefinitionBuilder) IP() *DefinitionBuilder {
 	return b
 }
 
+// UUID makes the key a UUID in canonical form, in any case, read with Get[uuid.UUID] or
+// with Get[string] as the normalized lowercase string
 func (b *DefinitionBuilder) UUID() *DefinitionBuilder {
 	b.def.valueType = TypeUUID
 	return b
 }
 
+// UUIDVersion requires a UUID value to be one of the given versions, such as 4 or 7
+func (b *DefinitionBuilder) UUIDVersion(versions ...int) *DefinitionBuilder {
+	b.def.validations = append(b.def.validations, validateUUIDVersion(versions))
+	return b
+}
+
 func (b *DefinitionBuilder) Path() *DefinitionBuilder {
 	b.def.valueType = TypePath
 	return b
@@ -336,12 +504,24 @@ func (b *DefinitionBuilder) Path() *DefinitionBuilder {
 // Source setters
 
 func (b *DefinitionBuilder) Env(envVar string) *DefinitionBuilder {
+	b.mustBeUnique("environment variable", envVar, func(def *Definition) string { return def.envVar })
 	b.def.envVar = envVar
 	return b
 }
 
 func (b *DefinitionBuilder) Flag(flag string) *DefinitionBuilder {
+	b.mustBeUnique("flag", flag, func(def *Definition) string { return def.flag })
+	b.def.flag = flag
+	return b
+}
+
+// CountFlag sets a repeatable flag whose value is the number of times it was given,
+// so "-v -v -v" (or "-v=3") resolves to 3. The definition becomes an Int64.
+func (b *DefinitionBuilder) CountFlag(flag string) *DefinitionBuilder {
+	b.mustBeUnique("flag", flag, func(def *Definition) string { return def.flag })
 	b.def.flag = flag
+	b.def.counter = true
+	b.def.valueType = TypeInt64
 	return b
 }
 
@@ -350,12 +530,36 @@ func (b *DefinitionBuilder) File(fileKey string) *DefinitionBuilder {
 	return b
 }
 
+// FallbackKeys sets names a value is also looked up under, in order, when a source
+// has none under the definition's own name: environment variables for the
+// environment (only with Env()), keys for files and remote sources. It eases renames, e.g.
+// Env("DB_URL").FallbackKeys("DATABASE_URL") during a transition period.
+func (b *DefinitionBuilder) FallbackKeys(names ...string) *DefinitionBuilder {
+	b.def.fallbackKeys = append(b.def.fallbackKeys, names...)
+	return b
+}
+
 // Sources sets the available sources for this definition
 func (b *DefinitionBuilder) Sources(sources ...SourceType) *DefinitionBuilder {
 	b.def.sources = append([]SourceType(nil), sources...)
 	return b
 }
 
+// OnlyFrom restricts the sources a value may come from, e.g. OnlyFrom(SourceEnv) for a
+// secret that must not be passed as a flag. A value found in any other source is an
+// error instead of being used; defaults are always accepted.
+func (b *DefinitionBuilder) OnlyFrom(sources ...SourceType) *DefinitionBuilder {
+	b.def.onlyFrom = append([]SourceType(nil), sources...)
+	return b
+}
+
+// NeverFrom rejects values found in sources, e.g. NeverFrom(SourceFlag) because flags
+// leak through process listings. A value found there is an error instead of being used.
+func (b *DefinitionBuilder) NeverFrom(sources ...SourceType) *DefinitionBuilder {
+	b.def.neverFrom = append(b.def.neverFrom, sources...)
+	return b
+}
+
 // Priority sets the custom priority order for this definition
 func (b *DefinitionBuilder) Priority(priority SourcePriority) *DefinitionBuilder {
 	// Validate the priority before setting
@@ -400,6 +604,37 @@ func (b *DefinitionBuilder) Secret() *DefinitionBuilder {
 	return b
 }
 
+// Immutable rejects reloads that would change the value, for keys such as PORT or
+// DATA_DIR that a running server cannot pick up without a restart
+func (b *DefinitionBuilder) Immutable() *DefinitionBuilder {
+	b.def.immutable = true
+	return b
+}
+
+// TTL sets how long a value stays fresh. Older values are still returned by Get, but
+// Stale lists them and StartRefresh reloads early so they don't wait a full interval.
+func (b *DefinitionBuilder) TTL(ttl time.Duration) *DefinitionBuilder {
+	b.def.ttl = ttl
+	return b
+}
+
+// PromptIfMissing marks the definition as secret and, when no source provides a value
+// and stdin is a terminal, asks for it with echo disabled. The input goes straight into
+// protected memory, so developer CLIs don't need secrets in environment variables.
+func (b *DefinitionBuilder) PromptIfMissing() *DefinitionBuilder {
+	b.def.secret = true
+	b.def.promptIfMissing = true
+	return b
+}
+
+// Persistent makes a global definition's flag valid for every command and subcommand,
+// before or after the command name. Its value is resolved on the global config, so
+// middleware and commands read it through ctx.GlobalConfig (or Get) wherever they run.
+func (b *DefinitionBuilder) Persistent() *DefinitionBuilder {
+	b.def.persistent = true
+	return b
+}
+
 func (b *DefinitionBuilder) Default(value any) *DefinitionBuilder {
 	// If we know the target type, try to convert immediately for better error detection
 	if b.def.valueType != TypeString && b.def.valueType != 0 {
@@ -430,6 +665,24 @@ func (b *DefinitionBuilder) Description(desc string) *DefinitionBuilder {
 	return b
 }
 
+// Example sets a sample value shown in help and in example config files, e.g. the
+// expected shape of a connection string
+func (b *DefinitionBuilder) Example(value string) *DefinitionBuilder {
+	b.def.example = value
+	return b
+}
+
+// Tags labels the key, e.g. "network" or "tuning", so Dump, help and the docs site
+// can show only the keys of one area of a large service
+func (b *DefinitionBuilder) Tags(tags ...string) *DefinitionBuilder {
+	for _, tag := range tags {
+		if !slices.Contains(b.def.tags, tag) {
+			b.def.tags = append(b.def.tags, tag)
+		}
+	}
+	return b
+}
+
 // Validation setters
 
 func (b *DefinitionBuilder) Min(min float64) *DefinitionBuilder {
@@ -521,12 +774,32 @@ func (b *DefinitionBuilder) MaxItems(max int) *DefinitionBuilder {
 	return b
 }
 
+// RequiredKeys requires a StringMap value to have every given key
+func (b *DefinitionBuilder) RequiredKeys(keys ...string) *DefinitionBuilder {
+	b.def.validations = append(b.def.validations, validateRequiredKeys(keys))
+	return b
+}
+
 func (b *DefinitionBuilder) ItemsRange(min, max int) *DefinitionBuilder {
 	b.def.validations = append(b.def.validations, validateMinItems(min))
 	b.def.validations = append(b.def.validations, validateMaxItems(max))
 	return b
 }
 
+// LessThanKey requires the value to be strictly less than the value of another key
+// Works for numeric, duration and time definitions; checked after all keys resolve
+func (b *DefinitionBuilder) LessThanKey(otherKey string) *DefinitionBuilder {
+	b.def.validations = append(b.def.validations, validateLessThanKey(otherKey))
+	return b
+}
+
+// GreaterThanKey requires the value to be strictly greater than the value of another key
+// Works for numeric, duration and time definitions; checked after all keys resolve
+func (b *DefinitionBuilder) GreaterThanKey(otherKey string) *DefinitionBuilder {
+	b.def.validations = append(b.def.validations, validateGreaterThanKey(otherKey))
+	return b
+}
+
 // FileMode validation methods
 func (b *DefinitionBuilder) ValidFilePermission() *DefinitionBuilder {
 	b.def.validations = append(b.def.validations, validateValidFilePermission())
@@ -559,6 +832,9 @@ func (b *DefinitionBuilder) PathReadable() *DefinitionBuilder {
 	return b
 }
 
+// PathWritable requires the path to be writable: an existing file that can be opened
+// for writing, a directory new files can be created in, or a missing file whose
+// directory can hold it. Directories and missing files used to be rejected.
 func (b *DefinitionBuilder) PathWritable() *DefinitionBuilder {
 	b.def.validations = append(b.def.validations, validatePathWritable())
 	return b
@@ -627,6 +903,21 @@ func (d *Definition) getEffectivePriority(configDefault SourcePriority) SourcePr
 	return configDefault
 }
 
+// checkSource returns an error when a value must not come from source
+func (d *Definition) checkSource(source SourceType) error {
+	if slices.Contains(d.neverFrom, source) {
+		return fmt.Errorf("value from %s is not allowed", source)
+	}
+	if d.onlyFrom != nil && source != SourceDefault && !slices.Contains(d.onlyFrom, source) {
+		names := make([]string, len(d.onlyFrom))
+		for i, allowed := range d.onlyFrom {
+			names[i] = allowed.String()
+		}
+		return fmt.Errorf("value from %s is not allowed, only from %s", source, strings.Join(names, ", "))
+	}
+	return nil
+}
+
 // inferAvailableSources determines which sources are available for this definition
 // based on the configured fields (envVar, flag, defaultValue, etc.)
 func (d *Definition) inferAvailableSources() []SourceType {
@@ -660,6 +951,8 @@ func (d *Definition) validatePriority(priority SourcePriority) error {
 	for _, source := range availableSources {
 		availableSet[source] = true
 	}
+	// Registered sources can provide any key
+	availableSet[SourceRemote] = true
 
 	// Check that all sources in priority are available
 	for _, source := range priority {
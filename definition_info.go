@@ -0,0 +1,107 @@
+// commandkit/definition_info.go
+package commandkit
+
+import "slices"
+
+// Definition returns a copy of the definition of key, for tooling such as
+// documentation sites, UIs or schema exporters. Changing the configuration after
+// the call does not affect the copy.
+func (c *Config) Definition(key string) (*Definition, bool) {
+	def, exists := c.layerFor(key).definitions[key]
+	if !exists {
+		return nil, false
+	}
+	return def.clone(), true
+}
+
+// Key returns the configuration key
+func (d *Definition) Key() string {
+	return d.key
+}
+
+// Type returns the value type
+func (d *Definition) Type() ValueType {
+	return d.valueType
+}
+
+// TypeName returns the name of the type as shown in help, e.g. "int64", or the name
+// of a custom type registered with RegisterType
+func (d *Definition) TypeName() string {
+	return d.typeName()
+}
+
+// EnvVar returns the environment variable, "" if the key has none
+func (d *Definition) EnvVar() string {
+	return d.envVar
+}
+
+// Flag returns the flag name without dashes, "" if the key has none
+func (d *Definition) Flag() string {
+	return d.flag
+}
+
+// FileKey returns the key looked up in configuration files
+func (d *Definition) FileKey() string {
+	if d.fileKey != "" {
+		return d.fileKey
+	}
+	return d.key
+}
+
+// Description returns the help description
+func (d *Definition) Description() string {
+	return d.description
+}
+
+// Required reports whether a value must be given
+func (d *Definition) Required() bool {
+	return d.required
+}
+
+// Secret reports whether the value is stored as a secret
+func (d *Definition) Secret() bool {
+	return d.secret
+}
+
+// Default returns the default value, nil when there is none or the key is secret
+func (d *Definition) Default() any {
+	if d.secret {
+		return nil
+	}
+	return d.defaultValue
+}
+
+// Tags returns the labels set with Tags
+func (d *Definition) Tags() []string {
+	return slices.Clone(d.tags)
+}
+
+// Validations returns the names of the validations, such as "min(1)" or "oneOf(a, b)"
+func (d *Definition) Validations() []string {
+	names := make([]string, 0, len(d.validations))
+	for _, v := range d.validations {
+		names = append(names, v.Name)
+	}
+	return names
+}
+
+// tagged reports whether the definition has one of tags; any definition matches no tags
+func (d *Definition) tagged(tags []string) bool {
+	return len(tags) == 0 || slices.ContainsFunc(d.tags, func(tag string) bool {
+		return slices.Contains(tags, tag)
+	})
+}
+
+// taggedDefinitions returns the definitions having one of tags, defs itself for no tags
+func taggedDefinitions(defs map[string]*Definition, tags []string) map[string]*Definition {
+	if len(tags) == 0 {
+		return defs
+	}
+	result := make(map[string]*Definition)
+	for key, def := range defs {
+		if def.tagged(tags) {
+			result[key] = def
+		}
+	}
+	return result
+}
@@ -3,6 +3,7 @@ package commandkit
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -286,7 +287,7 @@ func TestFormatValidation(t *testing.T) {
 	if !strings.Contains(joined, "valid: 1-65535") {
 		t.Fatalf("expected range formatting, got: %v", formatted)
 	}
-	if !strings.Contains(joined, "oneOf: ['debug', 'info', 'warn', 'error']") {
+	if !strings.Contains(joined, "one of: debug, info, warn, error") {
 		t.Fatalf("expected oneOf formatting, got: %v", formatted)
 	}
 	if !strings.Contains(joined, "minLength: 3") {
@@ -316,10 +317,10 @@ func TestExtractValue(t *testing.T) {
 }
 
 func TestExtractOneOfValues(t *testing.T) {
-	if got := extractOneOfValues("oneOf(debug,info,warn,error)"); got != "['debug', 'info', 'warn', 'error']" {
+	if got := extractOneOfValues("oneOf(debug,info,warn,error)"); got != "debug, info, warn, error" {
 		t.Fatalf("unexpected oneOf comma formatting: %q", got)
 	}
-	if got := extractOneOfValues("oneOf([debug info warn error])"); got != "['debug', 'info', 'warn', 'error']" {
+	if got := extractOneOfValues("oneOf([debug info warn error])"); got != "debug, info, warn, error" {
 		t.Fatalf("unexpected oneOf array formatting: %q", got)
 	}
 	if got := extractOneOfValues("missing"); got != "" {
@@ -347,7 +348,7 @@ func TestFormatFlagHelp(t *testing.T) {
 		"env: LOG_LEVEL",
 		"required",
 		"default: 'info'",
-		"oneOf: ['debug', 'info', 'warn', 'error']",
+		"one of: debug, info, warn, error",
 	}
 
 	for _, part := range expectedParts {
@@ -391,3 +392,89 @@ func TestDefinitionBuilderBuild(t *testing.T) {
 		t.Error("build() should return definition with correct default")
 	}
 }
+
+func TestConfigDefinition(t *testing.T) {
+	cfg := New()
+	cfg.Define("PORT").Int64().Env("PORT").Flag("port").Default(int64(8080)).Range(1, 65535).Description("HTTP port")
+	cfg.Define("API_KEY").String().Env("API_KEY").Secret().Required().Default("dev-key")
+
+	def, ok := cfg.Definition("PORT")
+	if !ok {
+		t.Fatal("expected PORT to be defined")
+	}
+	if def.Key() != "PORT" || def.Type() != TypeInt64 || def.EnvVar() != "PORT" || def.Flag() != "port" || def.FileKey() != "PORT" {
+		t.Errorf("unexpected PORT definition: %s %v %s %s %s", def.Key(), def.Type(), def.EnvVar(), def.Flag(), def.FileKey())
+	}
+	if def.Description() != "HTTP port" || def.Required() || def.Secret() || def.Default() != int64(8080) {
+		t.Errorf("unexpected PORT details: %q %v %v %v", def.Description(), def.Required(), def.Secret(), def.Default())
+	}
+	if names := def.Validations(); len(names) != 2 || names[0] != "min(1)" || names[1] != "max(65535)" {
+		t.Errorf("PORT validations = %v", names)
+	}
+
+	secret, _ := cfg.Definition("API_KEY")
+	if !secret.Secret() || !secret.Required() || secret.Default() != nil {
+		t.Errorf("unexpected API_KEY definition: secret %v, required %v, default %v", secret.Secret(), secret.Required(), secret.Default())
+	}
+
+	// The definition is a copy
+	cfg.Define("PORT").Int64().Env("HTTP_PORT")
+	if def.EnvVar() != "PORT" {
+		t.Errorf("copy changed to %s", def.EnvVar())
+	}
+	if _, ok := cfg.Definition("MISSING"); ok {
+		t.Error("expected MISSING to be undefined")
+	}
+}
+
+func TestTags(t *testing.T) {
+	cfg := New()
+	cfg.Define("PORT").Int64().Flag("port").Default(8080).Tags("network")
+	cfg.Define("TIMEOUT").Duration().Env("TIMEOUT").Default(5*time.Second).Tags("network", "tuning")
+	cfg.Define("WORKERS").Int64().Flag("workers").Default(4).Tags("tuning", "tuning")
+	cfg.Define("NAME").String().Env("NAME").Default("app")
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if def, _ := cfg.Definition("WORKERS"); strings.Join(def.Tags(), ",") != "tuning" {
+		t.Errorf("WORKERS tags = %v", def.Tags())
+	}
+	if dump := cfg.Dump("network"); len(dump) != 2 || dump["PORT"] == "" || dump["TIMEOUT"] == "" {
+		t.Errorf("Dump(network) = %v", dump)
+	}
+	if dump := cfg.Dump(); len(dump) != 4 {
+		t.Errorf("Dump() = %v", dump)
+	}
+
+	cfg.Command("serve").Func(testCommand).Config(func(cc *CommandConfig) {
+		cc.Define("LISTEN").String().Flag("listen").Tags("network")
+		cc.Define("CACHE").Int64().Flag("cache").Tags("tuning")
+	})
+	output := captureStdout(t, func() {
+		if err := cfg.Execute([]string{"app", "serve", "--help=network"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+	if !strings.Contains(output, "--listen") || strings.Contains(output, "--cache") {
+		t.Errorf("expected only network options, got: %s", output)
+	}
+	if !strings.Contains(output, "--port") || strings.Contains(output, "--workers") {
+		t.Errorf("expected only network globals, got: %s", output)
+	}
+
+	dir := t.TempDir()
+	if err := cfg.WriteDocsSite(dir, "Acme", "tuning"); err != nil {
+		t.Fatal(err)
+	}
+	index, err := os.ReadFile(filepath.Join(dir, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(index), `<tr id="WORKERS">`) || strings.Contains(string(index), `<tr id="PORT">`) {
+		t.Errorf("expected only tuning keys in docs, got: %s", index)
+	}
+	if !strings.Contains(string(index), `<span class="tag">tuning</span>`) {
+		t.Error("expected tags in docs")
+	}
+}
@@ -0,0 +1,242 @@
+// commandkit/docs_site.go
+package commandkit
+
+import (
+	"bytes"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// docsKey documents one configuration key
+type docsKey struct {
+	Key         string
+	Type        string
+	Description string
+	Flag        string
+	EnvVar      string
+	FileKey     string
+	Default     string
+	Example     string
+	Validations []string
+	Tags        []string
+	Required    bool
+	Secret      bool
+}
+
+// docsCommand documents one command of the tree
+type docsCommand struct {
+	Name        string
+	Path        string // Full invocation, e.g. "db migrate"
+	Page        string // Page file name under commands/
+	Description string
+	Aliases     []string
+	Keys        []docsKey
+	Subcommands []*docsCommand
+}
+
+// docsPage is the data rendered into each page
+type docsPage struct {
+	Title    string
+	Root     string // Relative path back to the site root
+	Keys     []docsKey
+	Commands []*docsCommand
+	Command  *docsCommand
+}
+
+// docsTree is a command list rendered as links from a page under Root
+type docsTree struct {
+	Root     string
+	Commands []*docsCommand
+}
+
+// Tree links commands from the page
+func (p docsPage) Tree(commands []*docsCommand) docsTree {
+	return docsTree{Root: p.Root, Commands: commands}
+}
+
+// Sub links nested commands from the same page
+func (t docsTree) Sub(commands []*docsCommand) docsTree {
+	return docsTree{Root: t.Root, Commands: commands}
+}
+
+// WriteDocsSite writes a static HTML site documenting the configuration to dir:
+// index.html lists the global keys and the command tree, and commands/<path>.html
+// documents each command with its own keys. Keys show their type, flag, environment
+// variable, file key, default, example, validations and tags; secret defaults are
+// hidden. Given tags, only the keys having one of them are documented.
+func (c *Config) WriteDocsSite(dir, title string, tags ...string) error {
+	commands := c.docsCommands(c.commands, nil, tags)
+
+	pages := map[string]docsPage{
+		"index.html": {Title: title, Keys: docsKeys(taggedDefinitions(c.definitions, tags)), Commands: commands},
+	}
+	var addPages func(commands []*docsCommand)
+	addPages = func(commands []*docsCommand) {
+		for _, cmd := range commands {
+			pages[filepath.Join("commands", cmd.Page)] = docsPage{Title: title, Root: "../", Command: cmd}
+			addPages(cmd.Subcommands)
+		}
+	}
+	addPages(commands)
+
+	if err := os.MkdirAll(filepath.Join(dir, "commands"), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "style.css"), []byte(docsStyle), 0o644); err != nil {
+		return err
+	}
+	for name, page := range pages {
+		var buf bytes.Buffer
+		if err := docsTemplate.Execute(&buf, page); err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), buf.Bytes(), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// docsCommands documents commands and their subcommands, sorted by name, with the
+// keys having one of tags
+func (c *Config) docsCommands(commands map[string]*Command, parent []string, tags []string) []*docsCommand {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	docs := make([]*docsCommand, 0, len(names))
+	for _, name := range names {
+		cmd := commands[name]
+		path := append(append([]string(nil), parent...), name)
+		description := cmd.LongHelp
+		if description == "" {
+			description = cmd.ShortHelp
+		}
+		docs = append(docs, &docsCommand{
+			Name:        name,
+			Path:        strings.Join(path, " "),
+			Page:        strings.Join(path, "-") + ".html",
+			Description: description,
+			Aliases:     cmd.Aliases,
+			Keys:        docsKeys(taggedDefinitions(ownDefinitions(cmd, c.definitions), tags)),
+			Subcommands: c.docsCommands(cmd.SubCommands, path, tags),
+		})
+	}
+	return docs
+}
+
+// docsKeys documents definitions, sorted by key
+func docsKeys(defs map[string]*Definition) []docsKey {
+	converter := NewTypeConverter()
+	keys := make([]docsKey, 0, len(defs))
+	for key, def := range defs {
+		doc := docsKey{
+			Key:         key,
+			Type:        def.typeName(),
+			Description: def.description,
+			EnvVar:      def.envVar,
+			FileKey:     key,
+			Example:     def.example,
+			Validations: formatValidation(def.validations),
+			Tags:        def.tags,
+			Required:    def.required,
+			Secret:      def.secret,
+		}
+		if def.flag != "" {
+			doc.Flag = "--" + def.flag
+		}
+		if def.fileKey != "" {
+			doc.FileKey = def.fileKey
+		}
+		if shouldDisplayDefault(def) && !def.secret {
+			doc.Default, _ = converter.ConvertToString(def.defaultValue, def.delimiter)
+		}
+		keys = append(keys, doc)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Key < keys[j].Key })
+	return keys
+}
+
+// docsTemplate renders every page of the documentation site
+var docsTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{if .Command}}{{.Command.Path}} - {{end}}{{.Title}}</title>
+<link rel="stylesheet" href="{{.Root}}style.css">
+</head>
+<body>
+<header><a href="{{.Root}}index.html">{{.Title}}</a></header>
+<main>
+{{- with .Command}}
+<h1>{{.Path}}</h1>
+{{- if .Description}}
+<p>{{.Description}}</p>
+{{- end}}
+{{- if .Aliases}}
+<p>Aliases: {{range $i, $alias := .Aliases}}{{if $i}}, {{end}}<code>{{$alias}}</code>{{end}}</p>
+{{- end}}
+{{- if .Keys}}
+<h2>Configuration</h2>
+{{template "keys" .Keys}}
+{{- end}}
+{{- if .Subcommands}}
+<h2>Subcommands</h2>
+{{template "tree" ($.Tree .Subcommands)}}
+{{- end}}
+{{- else}}
+<h1>{{.Title}}</h1>
+{{- if .Keys}}
+<h2>Configuration</h2>
+{{template "keys" .Keys}}
+{{- end}}
+{{- if .Commands}}
+<h2>Commands</h2>
+{{template "tree" ($.Tree .Commands)}}
+{{- end}}
+{{- end}}
+</main>
+</body>
+</html>
+{{define "keys"}}<table>
+<thead><tr><th>Key</th><th>Type</th><th>Sources</th><th>Default</th><th>Details</th></tr></thead>
+<tbody>
+{{- range .}}
+<tr id="{{.Key}}">
+<td><code>{{.Key}}</code>{{if .Required}} <span class="tag">required</span>{{end}}{{if .Secret}} <span class="tag">secret</span>{{end}}{{range .Tags}} <span class="tag">{{.}}</span>{{end}}</td>
+<td>{{.Type}}</td>
+<td>{{if .Flag}}<div>flag <code>{{.Flag}}</code></div>{{end}}{{if .EnvVar}}<div>env <code>{{.EnvVar}}</code></div>{{end}}<div>file <code>{{.FileKey}}</code></div></td>
+<td>{{if .Default}}<code>{{.Default}}</code>{{end}}</td>
+<td>{{if .Description}}<div>{{.Description}}</div>{{end}}{{if .Example}}<div>Example: <code>{{.Example}}</code></div>{{end}}{{range .Validations}}<div class="rule">{{.}}</div>{{end}}</td>
+</tr>
+{{- end}}
+</tbody>
+</table>{{end}}
+{{define "tree"}}<ul class="tree">
+{{- range .Commands}}
+<li><a href="{{$.Root}}commands/{{.Page}}">{{.Path}}</a>{{if .Description}} - {{.Description}}{{end}}
+{{- if .Subcommands}}
+{{template "tree" ($.Sub .Subcommands)}}
+{{- end}}</li>
+{{- end}}
+</ul>{{end}}`))
+
+// docsStyle is the stylesheet of the documentation site
+const docsStyle = `body { font-family: system-ui, sans-serif; margin: 0; color: #1f2328; }
+header { padding: 0.75rem 2rem; background: #24292f; }
+header a { color: #fff; text-decoration: none; font-weight: 600; }
+main { max-width: 72rem; margin: 0 auto; padding: 1rem 2rem; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border-bottom: 1px solid #d0d7de; padding: 0.5rem; text-align: left; vertical-align: top; }
+code { background: #f6f8fa; padding: 0 0.2rem; border-radius: 3px; }
+.tag { font-size: 0.75rem; background: #ddf4ff; border-radius: 3px; padding: 0 0.3rem; }
+.rule { color: #57606a; font-size: 0.875rem; }
+.tree li { margin: 0.25rem 0; }
+`
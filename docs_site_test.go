@@ -0,0 +1,70 @@
+package commandkit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteDocsSite(t *testing.T) {
+	cfg := New()
+	cfg.Define("PORT").Int64().Flag("port").Env("PORT").Default(8080).Range(1, 65535).Description("HTTP <port>")
+	cfg.Define("API_KEY").String().Env("API_KEY").Secret().Default("hunter2")
+	db := cfg.Command("db").ShortHelp("Database tasks")
+	db.SubCommand("migrate").Func(testCommand).ShortHelp("Apply migrations").Config(func(cc *CommandConfig) {
+		cc.Define("DSN").String().Env("DSN").Example("postgres://localhost/app").Required()
+	})
+
+	dir := t.TempDir()
+	if err := cfg.WriteDocsSite(dir, "Acme CLI"); err != nil {
+		t.Fatalf("WriteDocsSite failed: %v", err)
+	}
+
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	index := read("index.html")
+	for _, want := range []string{
+		"<h1>Acme CLI</h1>",
+		`<tr id="PORT">`,
+		"flag <code>--port</code>",
+		"<code>8080</code>",
+		"HTTP &lt;port&gt;",
+		`<a href="commands/db.html">db</a> - Database tasks`,
+		`<a href="commands/db-migrate.html">db migrate</a>`,
+		`<link rel="stylesheet" href="style.css">`,
+	} {
+		if !strings.Contains(index, want) {
+			t.Errorf("index.html is missing %q", want)
+		}
+	}
+	if strings.Contains(index, "hunter2") {
+		t.Error("index.html shows a secret default")
+	}
+
+	migrate := read(filepath.Join("commands", "db-migrate.html"))
+	for _, want := range []string{
+		"<title>db migrate - Acme CLI</title>",
+		`<tr id="DSN">`,
+		`<span class="tag">required</span>`,
+		"Example: <code>postgres://localhost/app</code>",
+		`<a href="../index.html">Acme CLI</a>`,
+	} {
+		if !strings.Contains(migrate, want) {
+			t.Errorf("db-migrate.html is missing %q", want)
+		}
+	}
+	if strings.Contains(migrate, `id="PORT"`) {
+		t.Error("command pages should only list their own keys")
+	}
+	if db := read(filepath.Join("commands", "db.html")); !strings.Contains(db, `<a href="../commands/db-migrate.html">db migrate</a>`) {
+		t.Errorf("db.html should link its subcommands:\n%s", db)
+	}
+	read("style.css")
+}
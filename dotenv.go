@@ -0,0 +1,114 @@
+// commandkit/dotenv.go
+package commandkit
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// dotenvFiles are the files LoadDotenv looks for, in load order
+var dotenvFiles = []string{".env", ".env.local"}
+
+// LoadDotenv loads .env and then .env.local from the working directory, skipping those
+// that do not exist. Values are matched to definitions by key or environment variable
+// name. If the default priority has no file source, file values are added below flags
+// and environment variables, so the real environment keeps overriding dotenv files.
+func (c *Config) LoadDotenv() error {
+	c.ensureSource(SourceFile)
+	for _, filename := range dotenvFiles {
+		if err := c.LoadFile(filename); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// isDotenvFile reports whether filename is a dotenv file: .env, .env.* or *.env, in
+// any case
+func isDotenvFile(filename string) bool {
+	base := strings.ToLower(filepath.Base(filename))
+	return base == ".env" || strings.HasPrefix(base, ".env.") || strings.HasSuffix(base, ".env")
+}
+
+// parseDotenv decodes KEY=VALUE lines. Blank lines and # comments are skipped and an
+// "export " prefix is allowed. Unquoted values end at an inline " #" comment, single
+// quoted values are literal, and double quoted values support \n, \t, \" and \\
+// escapes and may span several lines.
+func parseDotenv(data []byte) (map[string]any, error) {
+	config := make(map[string]any)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, found := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", lineNumber)
+		}
+		value = strings.TrimSpace(value)
+
+		switch {
+		case strings.HasPrefix(value, `"`):
+			// Keep reading lines until the closing quote
+			start := lineNumber
+			end := closingQuote(value)
+			for end < 0 {
+				if !scanner.Scan() {
+					return nil, fmt.Errorf("line %d: unterminated double quote", start)
+				}
+				lineNumber++
+				value += "\n" + scanner.Text()
+				end = closingQuote(value)
+			}
+			value = unescapeDotenv(value[1:end])
+		case strings.HasPrefix(value, "'"):
+			end := strings.IndexByte(value[1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated single quote", lineNumber)
+			}
+			value = value[1 : end+1]
+		default:
+			if i := strings.Index(value, " #"); i >= 0 {
+				value = strings.TrimSpace(value[:i])
+			}
+		}
+		config[key] = value
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// closingQuote returns the index of the unescaped quote closing a value that starts
+// with a double quote, or -1
+func closingQuote(value string) int {
+	for i := 1; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+// unescapeDotenv resolves the escapes allowed in double quoted values
+func unescapeDotenv(value string) string {
+	return strings.NewReplacer(`\n`, "\n", `\t`, "\t", `\"`, `"`, `\\`, `\`).Replace(value)
+}
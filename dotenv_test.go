@@ -0,0 +1,95 @@
+package commandkit
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseDotenv(t *testing.T) {
+	data := `
+# Database settings
+DATABASE_URL=postgres://localhost/app
+export PORT = 8080
+NAME=my app # trailing comment
+HASH=abc#123
+SINGLE='literal \n $HOME' # comment with a ' quote
+DOUBLE="line1\nline2 \"quoted\""
+MULTI="first
+second"
+EMPTY=
+`
+	got, err := parseDotenv([]byte(data))
+	if err != nil {
+		t.Fatalf("parseDotenv failed: %v", err)
+	}
+
+	want := map[string]any{
+		"DATABASE_URL": "postgres://localhost/app",
+		"PORT":         "8080",
+		"NAME":         "my app",
+		"HASH":         "abc#123",
+		"SINGLE":       `literal \n $HOME`,
+		"DOUBLE":       "line1\nline2 \"quoted\"",
+		"MULTI":        "first\nsecond",
+		"EMPTY":        "",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseDotenv =\n%v\nwant\n%v", got, want)
+	}
+
+	for _, invalid := range []string{"NO_EQUALS", "BAD KEY=1", `OPEN="never closed`, "OPEN='never closed"} {
+		if _, err := parseDotenv([]byte(invalid)); err == nil {
+			t.Errorf("expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestLoadDotenv(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFile(t, filepath.Join(dir, ".env"), "APP_PORT=8080\nLOG_LEVEL=info\nAPI_KEY=from-dotenv\n")
+	writeConfigFile(t, filepath.Join(dir, ".env.local"), "LOG_LEVEL=debug\n")
+
+	wd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	cfg := New()
+	cfg.Define("PORT").Int64().Env("APP_PORT").Default(int64(80))
+	cfg.Define("LOG_LEVEL").String().Env("LOG_LEVEL").Default("warn")
+	cfg.Define("API_KEY").String().Env("API_KEY")
+	cfg.WithEnviron([]string{"API_KEY=from-env"})
+
+	if err := cfg.LoadDotenv(); err != nil {
+		t.Fatalf("LoadDotenv failed: %v", err)
+	}
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx := NewCommandContext(nil, cfg, "", "")
+	if port, _ := Get[int64](ctx, "PORT"); port != 8080 {
+		t.Errorf("PORT = %d, want 8080 from APP_PORT in .env", port)
+	}
+	if level, _ := Get[string](ctx, "LOG_LEVEL"); level != "debug" {
+		t.Errorf("LOG_LEVEL = %q, .env.local should override .env", level)
+	}
+	if key, _ := Get[string](ctx, "API_KEY"); key != "from-env" {
+		t.Errorf("API_KEY = %q, the environment should override dotenv files", key)
+	}
+}
+
+func TestLoadDotenvWithoutFiles(t *testing.T) {
+	wd, _ := os.Getwd()
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	if err := New().LoadDotenv(); err != nil {
+		t.Errorf("missing dotenv files should be skipped, got %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+// commandkit/duration.go
+package commandkit
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// durationUnits are the units ParseDuration adds to those of time.ParseDuration
+var durationUnits = map[string]time.Duration{
+	"w": 7 * 24 * time.Hour,
+	"d": 24 * time.Hour,
+}
+
+// ParseDuration parses a duration like time.ParseDuration, also accepting days ("d")
+// and weeks ("w"), alone or ahead of the other units, e.g. "7d", "2w" or "1d12h".
+// Months and years are not accepted as their length varies.
+func ParseDuration(raw string) (time.Duration, error) {
+	return parseDuration(raw)
+}
+
+// parseDuration parses a Go duration, also accepting days and weeks such as "7d",
+// and rejects durations that do not fit in a time.Duration
+func parseDuration(raw string) (time.Duration, error) {
+	text := strings.TrimSpace(raw)
+	sign := time.Duration(1)
+	if rest, negative := strings.CutPrefix(text, "-"); negative {
+		sign, text = -1, rest
+	} else {
+		text = strings.TrimPrefix(text, "+")
+	}
+	if text == "" {
+		return 0, fmt.Errorf("invalid duration: %s (use format like 15m, 1h, 7d, 2w)", raw)
+	}
+
+	var total time.Duration
+	for text != "" {
+		number := strings.IndexFunc(text, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+		if number <= 0 {
+			break
+		}
+		unit := durationUnits[text[number:number+1]]
+		if unit == 0 {
+			break // Standard units from here on
+		}
+		value, err := strconv.ParseFloat(text[:number], 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration: %s (use format like 15m, 1h, 7d, 2w)", raw)
+		}
+		part := value * float64(unit)
+		if part >= math.MaxInt64 || time.Duration(part) > math.MaxInt64-total {
+			return 0, fmt.Errorf("invalid duration: %s (out of range)", raw)
+		}
+		total += time.Duration(part)
+		text = text[number+1:]
+	}
+
+	if text != "" {
+		rest, err := time.ParseDuration(text)
+		if err != nil || rest < 0 {
+			return 0, fmt.Errorf("invalid duration: %s (use format like 15m, 1h, 7d, 2w)", raw)
+		}
+		if rest > math.MaxInt64-total {
+			return 0, fmt.Errorf("invalid duration: %s (out of range)", raw)
+		}
+		total += rest
+	}
+	return sign * total, nil
+}
+
+// FormatDuration formats a duration the way ParseDuration reads it, using weeks and
+// days for long durations and dropping zero units: "2w", "1d12h", "1h30m". The output
+// does not depend on the locale.
+func FormatDuration(d time.Duration) string {
+	if d == 0 {
+		return "0s"
+	}
+	var b strings.Builder
+	if d < 0 {
+		b.WriteByte('-')
+		d = -d
+	}
+	for _, unit := range []string{"w", "d"} {
+		if n := d / durationUnits[unit]; n > 0 {
+			b.WriteString(strconv.FormatInt(int64(n), 10) + unit)
+			d -= n * durationUnits[unit]
+		}
+	}
+	if d > 0 {
+		rest := d.String()
+		if strings.HasSuffix(rest, "m0s") {
+			rest = strings.TrimSuffix(rest, "0s")
+		}
+		if strings.HasSuffix(rest, "h0m") {
+			rest = strings.TrimSuffix(rest, "0m")
+		}
+		b.WriteString(rest)
+	}
+	return b.String()
+}
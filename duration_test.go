@@ -0,0 +1,65 @@
+// commandkit/duration_test.go
+package commandkit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	day, week := 24*time.Hour, 7*24*time.Hour
+	valid := map[string]time.Duration{
+		"15m":     15 * time.Minute,
+		"7d":      7 * day,
+		"1.5d":    36 * time.Hour,
+		"2w":      2 * week,
+		"1w2d":    week + 2*day,
+		"1d12h":   day + 12*time.Hour,
+		"-1d":     -day,
+		"1h30m":   90 * time.Minute,
+		" 2w1h ":  2*week + time.Hour,
+		"0":       0,
+		"300ms":   300 * time.Millisecond,
+		"1w1d1ns": week + day + 1,
+	}
+	for text, want := range valid {
+		if got, err := ParseDuration(text); err != nil || got != want {
+			t.Errorf("ParseDuration(%q) = %v, %v; want %v", text, got, err, want)
+		}
+	}
+
+	for _, text := range []string{"", "-", "d", "12h1d", "1mo", "1w-2d", "soon", "100000000w", "15000w2562047h"} {
+		if _, err := ParseDuration(text); err == nil {
+			t.Errorf("ParseDuration(%q) should fail", text)
+		}
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := map[time.Duration]string{
+		0:                            "0s",
+		90 * time.Minute:             "1h30m",
+		2 * time.Hour:                "2h",
+		36 * time.Hour:               "1d12h",
+		14 * 24 * time.Hour:          "2w",
+		8*24*time.Hour + time.Second: "1w1d1s",
+		-48 * time.Hour:              "-2d",
+		1500 * time.Millisecond:      "1.5s",
+	}
+	for d, want := range tests {
+		if got := FormatDuration(d); got != want {
+			t.Errorf("FormatDuration(%v) = %q, want %q", d, got, want)
+		}
+		if parsed, err := ParseDuration(want); err != nil || parsed != d {
+			t.Errorf("ParseDuration(%q) = %v, %v; want %v", want, parsed, err, d)
+		}
+	}
+
+	cfg := New()
+	cfg.WithEnviron([]string{"RETENTION=3d"})
+	cfg.Define("RETENTION").Duration().Env("RETENTION").MinDuration(7 * 24 * time.Hour)
+	err := cfg.Process([]string{"app"})
+	if err == nil || !contains(err.Error(), "duration 3d is less than minimum 1w") {
+		t.Errorf("expected the validation message to use days and weeks, got %v", err)
+	}
+}
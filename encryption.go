@@ -0,0 +1,173 @@
+// commandkit/encryption.go
+package commandkit
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/awnumar/memguard"
+)
+
+// SchemeAES256 is the scheme of values encrypted with EncryptValue
+const SchemeAES256 = "AES256"
+
+// encryptedValuePattern matches file values written as ENC[scheme,payload]
+var encryptedValuePattern = regexp.MustCompile(`^ENC\[([A-Za-z0-9_-]+),(.+)\]$`)
+
+// Decryptor decrypts the payload of file values written as ENC[scheme,payload]
+type Decryptor interface {
+	Decrypt(payload string) ([]byte, error)
+}
+
+// UseDecryptor registers the decryptor for an encryption scheme. Encrypted file values
+// are decrypted while processing, straight into the secret store; they are only
+// accepted by Secret() definitions.
+func (c *Config) UseDecryptor(scheme string, decryptor Decryptor) *Config {
+	if c.decryptors == nil {
+		c.decryptors = make(map[string]Decryptor)
+	}
+	c.decryptors[scheme] = decryptor
+	return c
+}
+
+// parseEncryptedValue splits an ENC[scheme,payload] marker
+func parseEncryptedValue(value any) (scheme, payload string, ok bool) {
+	text, isString := value.(string)
+	if !isString {
+		return "", "", false
+	}
+	match := encryptedValuePattern.FindStringSubmatch(strings.TrimSpace(text))
+	if match == nil {
+		return "", "", false
+	}
+	return match[1], match[2], true
+}
+
+// decryptValue decrypts an encrypted file value into a LockedBuffer and runs the
+// definition's validations on it
+func (c *Config) decryptValue(def *Definition, scheme, payload string) (*memguard.LockedBuffer, error) {
+	if !def.secret {
+		return nil, fmt.Errorf("encrypted value requires a Secret() definition")
+	}
+	decryptor, exists := c.decryptors[scheme]
+	if !exists {
+		return nil, fmt.Errorf("no decryptor registered for scheme %s", scheme)
+	}
+
+	plaintext, err := decryptor.Decrypt(payload)
+	if err != nil {
+		memguard.WipeBytes(plaintext)
+		return nil, fmt.Errorf("failed to decrypt %s value: %w", scheme, err)
+	}
+	if len(plaintext) == 0 {
+		return nil, fmt.Errorf("decrypted %s value is empty", scheme)
+	}
+
+	// The buffer takes ownership of plaintext and wipes it
+	buf := memguard.NewBufferFromBytes(plaintext)
+	for _, validation := range def.validations {
+		if validation.Name == "required" {
+			continue
+		}
+		if err := validation.Check(buf.String()); err != nil {
+			buf.Destroy()
+			return nil, err
+		}
+	}
+	return buf, nil
+}
+
+// keyFileDecryptor decrypts AES-256-GCM values with a key kept encrypted in memory
+type keyFileDecryptor struct {
+	key *memguard.Enclave
+}
+
+// NewKeyFileDecryptor reads a 32-byte AES-256 key from path, stored raw, hex or
+// base64 encoded, and returns a decryptor for values made with EncryptValue
+func NewKeyFileDecryptor(path string) (Decryptor, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+	defer memguard.WipeBytes(data)
+
+	key, err := decodeKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key file %s: %w", path, err)
+	}
+	return &keyFileDecryptor{key: memguard.NewEnclave(key)}, nil
+}
+
+// decodeKey accepts a 32-byte key as raw bytes, hex or base64
+func decodeKey(data []byte) ([]byte, error) {
+	if len(data) == 32 {
+		return append([]byte(nil), data...), nil
+	}
+
+	text := strings.TrimSpace(string(data))
+	if key, err := hex.DecodeString(text); err == nil && len(key) == 32 {
+		return key, nil
+	}
+	if key, err := base64.StdEncoding.DecodeString(text); err == nil && len(key) == 32 {
+		return key, nil
+	}
+	return nil, fmt.Errorf("expected a 32-byte key (raw, hex or base64)")
+}
+
+// Decrypt decrypts a base64 payload holding the GCM nonce followed by the ciphertext
+func (d *keyFileDecryptor) Decrypt(payload string) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, fmt.Errorf("invalid payload: %w", err)
+	}
+
+	key, err := d.key.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer key.Destroy()
+
+	gcm, err := newGCM(key.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("payload too short")
+	}
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+// EncryptValue encrypts plaintext with a 32-byte key into an ENC[AES256,...] value
+// that a key file decryptor holding the same key can read
+func EncryptValue(key, plaintext []byte) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, plaintext, nil)
+	return fmt.Sprintf("ENC[%s,%s]", SchemeAES256, base64.StdEncoding.EncodeToString(sealed)), nil
+}
+
+// newGCM creates an AES-256-GCM cipher
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("AES-256 requires a 32-byte key, got %d bytes", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,109 @@
+package commandkit
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestKeyFile(t *testing.T) ([]byte, string) {
+	t.Helper()
+	key := bytes.Repeat([]byte{0x42}, 32)
+	path := filepath.Join(t.TempDir(), "config.key")
+	if err := os.WriteFile(path, []byte(hex.EncodeToString(key)+"\n"), 0o600); err != nil {
+		t.Fatalf("failed to write key file: %v", err)
+	}
+	return key, path
+}
+
+func TestEncryptedFileValues(t *testing.T) {
+	key, keyPath := newTestKeyFile(t)
+	encrypted, err := EncryptValue(key, []byte("hunter2"))
+	if err != nil {
+		t.Fatalf("EncryptValue failed: %v", err)
+	}
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfigFile(t, configPath, "user: app\npassword: "+encrypted+"\n")
+
+	decryptor, err := NewKeyFileDecryptor(keyPath)
+	if err != nil {
+		t.Fatalf("NewKeyFileDecryptor failed: %v", err)
+	}
+
+	cfg := New()
+	cfg.SetDefaultPriority(PriorityFileEnvFlagDefault).UseDecryptor(SchemeAES256, decryptor)
+	cfg.Define("USER").String().File("user")
+	cfg.Define("PASSWORD").String().File("password").Secret().MinLength(4)
+	if err := cfg.LoadFile(configPath); err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+
+	if errs := cfg.processConfigWithContext(nil, nil); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if got := cfg.GetSecret("PASSWORD").String(); got != "hunter2" {
+		t.Errorf("PASSWORD = %q, want hunter2", got)
+	}
+	if _, exists := cfg.lookupValue("PASSWORD"); exists {
+		t.Error("the decrypted value must only be stored as a secret")
+	}
+	if user, _ := cfg.lookupValue("USER"); user != "app" {
+		t.Errorf("plain values should be unaffected, got USER=%v", user)
+	}
+}
+
+func TestEncryptedFileValueErrors(t *testing.T) {
+	key, keyPath := newTestKeyFile(t)
+	encrypted, _ := EncryptValue(key, []byte("abc"))
+	decryptor, _ := NewKeyFileDecryptor(keyPath)
+
+	tests := []struct {
+		name      string
+		secret    bool
+		decryptor Decryptor
+		value     string
+		want      string
+	}{
+		{"not a secret", false, decryptor, encrypted, "Secret()"},
+		{"no decryptor", true, nil, encrypted, "no decryptor registered"},
+		{"tampered", true, decryptor, strings.Replace(encrypted, encrypted[12:16], "AAAA", 1), "failed to decrypt"},
+		{"validation", true, decryptor, encrypted, "length"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.json")
+			writeConfigFile(t, path, `{"password": "`+tt.value+`"}`)
+
+			cfg := New()
+			cfg.SetDefaultPriority(PriorityFileEnvFlagDefault)
+			if tt.decryptor != nil {
+				cfg.UseDecryptor(SchemeAES256, tt.decryptor)
+			}
+			def := cfg.Define("PASSWORD").String().File("password").MinLength(4)
+			if tt.secret {
+				def.Secret()
+			}
+			if err := cfg.LoadFile(path); err != nil {
+				t.Fatalf("LoadFile failed: %v", err)
+			}
+
+			errs := cfg.processConfigWithContext(nil, nil)
+			if len(errs) != 1 || !strings.Contains(errs[0].ErrorDescription, tt.want) {
+				t.Fatalf("expected an error containing %q, got %v", tt.want, errs)
+			}
+		})
+	}
+}
+
+func TestNewKeyFileDecryptorRejectsBadKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "short.key")
+	os.WriteFile(path, []byte("too short"), 0o600)
+	if _, err := NewKeyFileDecryptor(path); err == nil {
+		t.Error("expected an error for a key that is not 32 bytes")
+	}
+}
@@ -0,0 +1,133 @@
+// commandkit/environ.go
+package commandkit
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// WithEnviron makes the configuration read environment variables from env, a list of
+// "KEY=value" entries as returned by os.Environ, instead of the process environment.
+// It allows evaluating the configuration under another environment and hermetic tests.
+// Passing nil restores the process environment.
+func (c *Config) WithEnviron(env []string) *Config {
+	if env == nil {
+		c.environ = nil
+		return c
+	}
+
+	c.environ = make(map[string]string, len(env))
+	for _, entry := range env {
+		name, value, _ := strings.Cut(entry, "=")
+		c.environ[name] = value
+	}
+	return c
+}
+
+// getenv returns an environment variable from the injected snapshot, if any, or from
+// the process environment
+func (c *Config) getenv(name string) string {
+	if c.environ != nil {
+		return c.environ[name]
+	}
+	return os.Getenv(name)
+}
+
+// envFileSuffix names the variable holding a path to read a value from, following the
+// FOO_FILE convention used by container images for secrets
+const envFileSuffix = "_FILE"
+
+// lookupEnv returns the value of the definition's environment variable or, when it is
+// unset, the content of the file named by <VAR>_FILE without its trailing newline. Slice
+// definitions also accept indexed variables (<VAR>_0, <VAR>_1, ...), returned as indexedEnv.
+// The fallback keys are tried the same way, in order, when the variable is not set;
+// a definition without Env() reads no variable at all.
+func (c *Config) lookupEnv(def *Definition) (any, bool, error) {
+	if def.envVar == "" {
+		return "", false, nil
+	}
+	for _, name := range append([]string{def.envVar}, def.fallbackKeys...) {
+		if value, found, err := c.lookupEnvVar(def, name); found || err != nil {
+			return value, found, err
+		}
+	}
+	return "", false, nil
+}
+
+// lookupEnvVar looks the value of def up under the environment variable name
+func (c *Config) lookupEnvVar(def *Definition, name string) (any, bool, error) {
+	if value := c.getenv(name); value != "" {
+		return value, true, nil
+	}
+
+	path := c.getenv(name + envFileSuffix)
+	if path == "" {
+		if _, isSlice := indexedElementTypes[def.valueType]; isSlice {
+			if items := c.lookupIndexedEnv(name); items != nil {
+				return items, true, nil
+			}
+		}
+		return "", false, nil
+	}
+	if err := c.checkSecretFile(def, path); err != nil {
+		return "", false, err
+	}
+	data, err := c.readValueFile(path)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read %s%s: %w", name, envFileSuffix, err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), true, nil
+}
+
+// indexedEnv holds the items of a slice given as indexed environment variables, which
+// are parsed one by one so that delimiters inside items are kept
+type indexedEnv []string
+
+// indexedElementTypes maps the slice types accepting indexed variables to their items
+var indexedElementTypes = map[ValueType]ValueType{
+	TypeStringSlice:   TypeString,
+	TypeInt64Slice:    TypeInt64,
+	TypeIntSlice:      TypeInt,
+	TypeFloat64Slice:  TypeFloat64,
+	TypeBoolSlice:     TypeBool,
+	TypeDurationSlice: TypeDuration,
+	TypeURLSlice:      TypeURL,
+}
+
+// lookupIndexedEnv collects <name>_0, <name>_1, ... up to the first unset index, nil
+// when <name>_0 is unset
+func (c *Config) lookupIndexedEnv(name string) indexedEnv {
+	var items indexedEnv
+	for i := 0; ; i++ {
+		value := c.getenv(name + "_" + strconv.Itoa(i))
+		if value == "" {
+			return items
+		}
+		items = append(items, value)
+	}
+}
+
+// parse parses each item as the element type of the slice type valueType
+func (items indexedEnv) parse(valueType ValueType) (any, error) {
+	result := reflect.ValueOf(nil)
+	for _, item := range items {
+		value, err := parseValue(strings.TrimSpace(item), indexedElementTypes[valueType], "")
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			continue
+		}
+		if !result.IsValid() {
+			result = reflect.MakeSlice(reflect.SliceOf(reflect.TypeOf(value)), 0, len(items))
+		}
+		result = reflect.Append(result, reflect.ValueOf(value))
+	}
+	if !result.IsValid() {
+		return nil, nil
+	}
+	return result.Interface(), nil
+}
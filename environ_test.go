@@ -0,0 +1,183 @@
+package commandkit
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithEnviron(t *testing.T) {
+	t.Setenv("PORT", "8080")
+	t.Setenv("HOST", "process.local")
+
+	cfg := New()
+	cfg.Define("PORT").Int64().Env("PORT").Default(int64(80))
+	cfg.Define("HOST").String().Env("HOST").Default("localhost")
+	cfg.WithEnviron([]string{"PORT=9090", "IGNORED", "EMPTY="})
+
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx := NewCommandContext(nil, cfg, "", "")
+	if port, _ := Get[int64](ctx, "PORT"); port != 9090 {
+		t.Errorf("PORT = %d, want the injected 9090", port)
+	}
+	if host, _ := Get[string](ctx, "HOST"); host != "localhost" {
+		t.Errorf("HOST = %q, the process environment must not leak into the snapshot", host)
+	}
+
+	cfg.WithEnviron(nil)
+	if got := cfg.getenv("HOST"); got != "process.local" {
+		t.Errorf("WithEnviron(nil) should restore the process environment, got %q", got)
+	}
+}
+
+func TestWithEnvironConfigFlag(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfigFile(t, path, "level: debug\n")
+
+	cfg := New()
+	cfg.ConfigFlag("config", "APP_CONFIG")
+	cfg.Define("LEVEL").String().File("level").Default("info")
+	cfg.WithEnviron([]string{"APP_CONFIG=" + path})
+
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx := NewCommandContext(nil, cfg, "", "")
+	if level, _ := Get[string](ctx, "LEVEL"); level != "debug" {
+		t.Errorf("LEVEL = %q, want the file named by the injected environment", level)
+	}
+}
+
+func TestWithEnvironCommands(t *testing.T) {
+	var region string
+	cfg := New()
+	cfg.WithEnviron([]string{"REGION=eu-west-1"})
+	cfg.Command("deploy").
+		Config(func(cc *CommandConfig) {
+			cc.Define("REGION").String().Env("REGION").Required()
+		}).
+		Func(func(ctx *CommandContext) error {
+			region, _ = Get[string](ctx, "REGION")
+			return nil
+		})
+
+	if err := cfg.Execute([]string{"app", "deploy"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if region != "eu-west-1" {
+		t.Errorf("REGION = %q, want the injected value", region)
+	}
+}
+
+func TestEnvFileConvention(t *testing.T) {
+	dir := t.TempDir()
+	passwordFile := filepath.Join(dir, "db_password")
+	writeConfigFile(t, passwordFile, "hunter2\n")
+
+	cfg := New()
+	cfg.Define("DB_PASSWORD").String().Env("DB_PASSWORD").Secret().Required()
+	cfg.Define("DB_USER").String().Env("DB_USER").Required()
+	cfg.WithEnviron([]string{
+		"DB_PASSWORD_FILE=" + passwordFile,
+		"DB_USER=app",
+		"DB_USER_FILE=" + filepath.Join(dir, "ignored"),
+	})
+
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := cfg.GetSecret("DB_PASSWORD").String(); got != "hunter2" {
+		t.Errorf("DB_PASSWORD = %q, want the file content without the newline", got)
+	}
+	ctx := NewCommandContext(nil, cfg, "", "")
+	if user, _ := Get[string](ctx, "DB_USER"); user != "app" {
+		t.Errorf("DB_USER = %q, the variable itself must win over _FILE", user)
+	}
+
+	cfg = New()
+	cfg.Define("DB_PASSWORD").String().Env("DB_PASSWORD").Secret()
+	cfg.WithEnviron([]string{"DB_PASSWORD_FILE=" + filepath.Join(dir, "missing")})
+	err := cfg.Process([]string{"app"})
+	if err == nil || !strings.Contains(err.Error(), "DB_PASSWORD_FILE") {
+		t.Errorf("expected an error naming DB_PASSWORD_FILE, got %v", err)
+	}
+}
+
+func TestIndexedEnv(t *testing.T) {
+	cfg := New().WithEnviron([]string{
+		"CORS_ORIGINS_0=https://a.example",
+		"CORS_ORIGINS_1=https://b.example,https://c.example",
+		"CORS_ORIGINS_3=skipped after the gap",
+		"PORTS_0=80",
+		"PORTS_1=443",
+		"HOSTS=db1,db2",
+		"HOSTS_0=ignored when HOSTS is set",
+		"BAD_0=x",
+	})
+	cfg.Define("CORS_ORIGINS").StringSlice().Env("CORS_ORIGINS")
+	cfg.Define("PORTS").Int64Slice().Env("PORTS")
+	cfg.Define("HOSTS").StringSlice().Env("HOSTS")
+	cfg.Define("NAME").String().Env("BAD").Default("plain")
+
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx := NewCommandContext(nil, cfg, "", "")
+	if origins, _ := Get[[]string](ctx, "CORS_ORIGINS"); !reflect.DeepEqual(origins, []string{"https://a.example", "https://b.example,https://c.example"}) {
+		t.Errorf("CORS_ORIGINS = %q", origins)
+	}
+	if ports, _ := Get[[]int64](ctx, "PORTS"); !reflect.DeepEqual(ports, []int64{80, 443}) {
+		t.Errorf("PORTS = %v", ports)
+	}
+	if hosts, _ := Get[[]string](ctx, "HOSTS"); !reflect.DeepEqual(hosts, []string{"db1", "db2"}) {
+		t.Errorf("HOSTS = %v", hosts)
+	}
+	if name, _ := Get[string](ctx, "NAME"); name != "plain" {
+		t.Errorf("NAME = %q, indexed variables only apply to slices", name)
+	}
+
+	cfg = New().WithEnviron([]string{"PORTS_0=80", "PORTS_1=http"})
+	cfg.Define("PORTS").Int64Slice().Env("PORTS")
+	if err := cfg.Process([]string{"app"}); err == nil || !strings.Contains(err.Error(), "invalid int64: http") {
+		t.Errorf("expected an invalid item error, got %v", err)
+	}
+}
+
+func TestFallbackKeys(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFile(t, filepath.Join(dir, "config.yaml"), "legacy_region: us\n")
+
+	cfg := New()
+	cfg.SetDefaultPriority(PriorityFileEnvFlagDefault)
+	cfg.WithEnviron([]string{"OLD_DB_URL=postgres://old", "OLDER_DB_URL=postgres://older", "NEW_PORT=9090", "OLD_PORT=1", "legacy.timeout=1m"})
+	cfg.UseSource(&mapSource{values: map[string]string{"legacy.timeout": "30s"}})
+	cfg.Define("DB_URL").String().Env("DB_URL").FallbackKeys("OLD_DB_URL", "OLDER_DB_URL")
+	cfg.Define("PORT").Int64().Env("NEW_PORT").FallbackKeys("OLD_PORT")
+	cfg.Define("REGION").String().File("region").FallbackKeys("legacy_region")
+	cfg.Define("TIMEOUT").Duration().FallbackKeys("legacy.timeout").Default(time.Second)
+	if err := cfg.LoadFile(filepath.Join(dir, "config.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]any{
+		"DB_URL":  "postgres://old", // first fallback set
+		"PORT":    int64(9090),      // the main name wins
+		"REGION":  "us",
+		"TIMEOUT": 30 * time.Second, // no Env(), so the environment is not consulted
+	}
+	for key, expected := range want {
+		if value, _ := cfg.lookupValue(key); value != expected {
+			t.Errorf("%s = %v, want %v", key, value, expected)
+		}
+	}
+}
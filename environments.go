@@ -0,0 +1,173 @@
+// commandkit/environments.go
+package commandkit
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// environmentsKey is the top-level file key holding per-environment overrides:
+//
+//	port: 8080
+//	database:
+//	  host: localhost
+//	environments:
+//	  production:
+//	    port: 443
+//	    database:
+//	      host: db.internal
+//
+// The schema is the same for JSON, YAML and TOML files.
+const environmentsKey = "environments"
+
+// SetEnvironment selects the section of the environments map whose values override
+// the top-level file values. It may be called before or after loading files; the
+// environment is checked against the loaded files when the configuration is processed.
+func (c *Config) SetEnvironment(name string) error {
+	c.environment = name
+	if c.fileConfig != nil {
+		c.fileConfig.environment = name
+	}
+	return nil
+}
+
+// Environment returns the selected environment, or "" when none is selected
+func (c *Config) Environment() string {
+	return c.environment
+}
+
+// Environments returns the names of the environments defined by the loaded files in
+// sorted order, e.g. to validate an APP_ENV value or list the choices in an error
+func (c *Config) Environments() []string {
+	if c.fileConfig == nil {
+		return nil
+	}
+	return c.fileConfig.environmentNames()
+}
+
+// checkEnvironment reports a selected environment that the files loaded into fc
+// (nil when there are none) do not define
+func (c *Config) checkEnvironment(fc *FileConfig) []ConfigError {
+	if c.environment == "" {
+		return nil
+	}
+
+	description := fmt.Sprintf("environment %q is selected but no configuration file is loaded", c.environment)
+	if fc != nil {
+		if _, exists := fc.environments()[c.environment]; exists {
+			return nil
+		}
+		available := "none"
+		if names := fc.environmentNames(); len(names) > 0 {
+			available = strings.Join(names, ", ")
+		}
+		description = fmt.Sprintf("environment %q is not defined in the loaded files (available: %s)", c.environment, available)
+	}
+	return []ConfigError{{
+		Key:              "environment",
+		Source:           SourceFile.String(),
+		ErrorDescription: description,
+	}}
+}
+
+// environments returns the environments map of the loaded files
+func (fc *FileConfig) environments() map[string]any {
+	environments, _ := fc.data[environmentsKey].(map[string]any)
+	return environments
+}
+
+// environmentNames returns the environments defined by the loaded files in sorted order
+func (fc *FileConfig) environmentNames() []string {
+	names := make([]string, 0, len(fc.environments()))
+	for name := range fc.environments() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// environmentData returns the values of the selected environment
+func (fc *FileConfig) environmentData() map[string]any {
+	if fc.environment == "" {
+		return nil
+	}
+	data, _ := fc.environments()[fc.environment].(map[string]any)
+	return data
+}
+
+// mergeEnvironments merges the environments of a newly loaded file into existing ones,
+// so a later file overriding one environment keeps the others
+func mergeEnvironments(existing, incoming any) any {
+	current, ok := existing.(map[string]any)
+	additions, ok2 := incoming.(map[string]any)
+	if !ok || !ok2 {
+		return incoming
+	}
+
+	merged := make(map[string]any, len(current)+len(additions))
+	for name, values := range current {
+		merged[name] = values
+	}
+	for name, values := range additions {
+		base, ok := merged[name].(map[string]any)
+		overrides, ok2 := values.(map[string]any)
+		if !ok || !ok2 {
+			merged[name] = values
+			continue
+		}
+		section := make(map[string]any, len(base)+len(overrides))
+		for key, value := range base {
+			section[key] = value
+		}
+		for key, value := range overrides {
+			section[key] = value
+		}
+		merged[name] = section
+	}
+	return merged
+}
+
+// lookupFileKey finds key in file data: as written, in lowercase, or as a dotted path
+// into nested tables ("database.host")
+func lookupFileKey(data map[string]any, key string) (any, bool) {
+	table, name, exists := fileKeySlot(data, key)
+	if !exists {
+		return nil, false
+	}
+	return table[name], true
+}
+
+// fileKeySlot returns the table holding the value lookupFileKey finds for key, and its
+// name in that table
+func fileKeySlot(data map[string]any, key string) (map[string]any, string, bool) {
+	for _, name := range []string{key, strings.ToLower(key)} {
+		if _, exists := data[name]; exists {
+			return data, name, true
+		}
+	}
+
+	if !strings.Contains(key, ".") {
+		return nil, "", false
+	}
+	table := data
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		name := part
+		if _, exists := table[name]; !exists {
+			name = strings.ToLower(part)
+			if _, exists := table[name]; !exists {
+				return nil, "", false
+			}
+		}
+		if i == len(parts)-1 {
+			return table, name, true
+		}
+		next, ok := table[name].(map[string]any)
+		if !ok {
+			return nil, "", false
+		}
+		table = next
+	}
+	return nil, "", false
+}
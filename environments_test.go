@@ -0,0 +1,200 @@
+package commandkit
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// environmentFiles holds the same configuration in every supported format
+var environmentFiles = map[string]string{
+	"config.json": `{
+		"port": 8080,
+		"database": {"host": "localhost", "pool": 5},
+		"environments": {
+			"production": {"port": 443, "database": {"host": "db.internal", "pool": 20}},
+			"staging": {"database.host": "db.staging"}
+		}
+	}`,
+	"config.yaml": `
+defaults: &defaults
+  host: localhost
+  pool: 5
+port: 8080
+database: *defaults
+environments:
+  production:
+    port: 443
+    database:
+      <<: *defaults
+      host: db.internal
+      pool: 20
+  staging:
+    database.host: db.staging
+`,
+	"config.toml": `
+port = 8080
+
+[database]
+host = "localhost"
+pool = 5
+
+[environments.production]
+port = 443
+
+[environments.production.database]
+host = "db.internal"
+pool = 20
+
+[environments.staging]
+"database.host" = "db.staging"
+`,
+}
+
+func newEnvironmentConfig(t *testing.T, filename string) *Config {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), filename)
+	writeConfigFile(t, path, environmentFiles[filename])
+
+	cfg := New()
+	cfg.SetDefaultPriority(PriorityFileEnvFlagDefault)
+	cfg.Define("PORT").Int64().File("port")
+	cfg.Define("DB_HOST").String().File("database.host")
+	cfg.Define("DB_POOL").Int64().File("database.pool")
+	if err := cfg.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	return cfg
+}
+
+func TestEnvironmentsAcrossFormats(t *testing.T) {
+	tests := []struct {
+		environment string
+		port        int64
+		host        string
+		pool        int64
+	}{
+		{"", 8080, "localhost", 5},
+		{"production", 443, "db.internal", 20},
+		{"staging", 8080, "db.staging", 5},
+	}
+
+	for filename := range environmentFiles {
+		for _, tt := range tests {
+			cfg := newEnvironmentConfig(t, filename)
+			if tt.environment != "" {
+				if err := cfg.SetEnvironment(tt.environment); err != nil {
+					t.Fatalf("%s: SetEnvironment failed: %v", filename, err)
+				}
+			}
+			if errs := cfg.processConfigWithContext(nil, nil); len(errs) > 0 {
+				t.Fatalf("%s/%s: unexpected errors: %v", filename, tt.environment, errs)
+			}
+
+			port, _ := cfg.lookupValue("PORT")
+			host, _ := cfg.lookupValue("DB_HOST")
+			pool, _ := cfg.lookupValue("DB_POOL")
+			if port != tt.port || host != tt.host || pool != tt.pool {
+				t.Errorf("%s/%q: got port=%v host=%v pool=%v, want %d %s %d",
+					filename, tt.environment, port, host, pool, tt.port, tt.host, tt.pool)
+			}
+		}
+	}
+}
+
+func TestEnvironmentsMergeAcrossFiles(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.yaml")
+	override := filepath.Join(dir, "override.json")
+	writeConfigFile(t, base, "environments:\n  production:\n    port: 443\n  staging:\n    port: 8443\n")
+	writeConfigFile(t, override, `{"environments": {"production": {"workers": 8}}}`)
+
+	cfg := New()
+	cfg.SetDefaultPriority(PriorityFileEnvFlagDefault)
+	cfg.Define("PORT").Int64().File("port")
+	cfg.Define("WORKERS").Int64().File("workers")
+	if err := cfg.LoadFiles(base, override); err != nil {
+		t.Fatalf("LoadFiles failed: %v", err)
+	}
+	if err := cfg.SetEnvironment("production"); err != nil {
+		t.Fatalf("SetEnvironment failed: %v", err)
+	}
+	if errs := cfg.processConfigWithContext(nil, nil); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	if port, _ := cfg.lookupValue("PORT"); port != int64(443) {
+		t.Errorf("a later file should keep earlier values of the same environment, got PORT=%v", port)
+	}
+	if workers, _ := cfg.lookupValue("WORKERS"); workers != int64(8) {
+		t.Errorf("expected WORKERS from the later file, got %v", workers)
+	}
+	if names := cfg.fileConfig.environmentNames(); strings.Join(names, ",") != "production,staging" {
+		t.Errorf("expected both environments to survive the merge, got %v", names)
+	}
+}
+
+func TestSetEnvironmentErrors(t *testing.T) {
+	cfg := New()
+	if err := cfg.SetEnvironment("production"); err != nil {
+		t.Fatalf("SetEnvironment before loading files failed: %v", err)
+	}
+	err := cfg.Process([]string{"app"})
+	if err == nil || !strings.Contains(err.Error(), "no configuration file is loaded") {
+		t.Errorf("expected an error for an environment without files, got %v", err)
+	}
+
+	cfg = newEnvironmentConfig(t, "config.json")
+	if err := cfg.SetEnvironment("qa"); err != nil {
+		t.Fatalf("SetEnvironment failed: %v", err)
+	}
+	err = cfg.Process([]string{"app"})
+	if err == nil || !strings.Contains(err.Error(), `environment "qa" is not defined in the loaded files (available: production, staging)`) {
+		t.Errorf("expected an error listing the available environments, got %v", err)
+	}
+}
+
+func TestSetEnvironmentBeforeLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfigFile(t, path, environmentFiles["config.yaml"])
+
+	cfg := New()
+	cfg.SetDefaultPriority(PriorityFileEnvFlagDefault)
+	cfg.Define("PORT").Int64().File("port")
+	if err := cfg.SetEnvironment("production"); err != nil {
+		t.Fatalf("SetEnvironment failed: %v", err)
+	}
+	if cfg.Environment() != "production" {
+		t.Errorf("Environment() = %q before loading files", cfg.Environment())
+	}
+	if err := cfg.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if port, _ := cfg.lookupValue("PORT"); port != int64(443) {
+		t.Errorf("expected the production port, got %v", port)
+	}
+}
+
+func TestEnvironmentsIntrospection(t *testing.T) {
+	cfg := New()
+	if names := cfg.Environments(); names != nil {
+		t.Errorf("expected no environments without files, got %v", names)
+	}
+
+	cfg = newEnvironmentConfig(t, "config.yaml")
+	if names := cfg.Environments(); strings.Join(names, ",") != "production,staging" {
+		t.Errorf("Environments() = %v", names)
+	}
+	if current := cfg.Environment(); current != "" {
+		t.Errorf("Environment() = %q before selecting one", current)
+	}
+	if err := cfg.SetEnvironment("staging"); err != nil {
+		t.Fatalf("SetEnvironment failed: %v", err)
+	}
+	if current := cfg.Environment(); current != "staging" {
+		t.Errorf("Environment() = %q, want staging", current)
+	}
+}
@@ -44,8 +44,19 @@ func (e *ConfigError) Error() string {
 	return e.ErrorDescription
 }
 
+// ConfigErrors aggregates every error found while processing a configuration
+type ConfigErrors []ConfigError
+
+func (e ConfigErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, fmt.Sprintf("%s: %s", err.Key, err.ErrorDescription))
+	}
+	return fmt.Sprintf("configuration errors: %s", strings.Join(messages, "; "))
+}
+
 func buildErrorDisplay(def *Definition) string {
-	valueType := def.valueType.String()
+	valueType := def.typeName()
 	var indicators []string
 
 	if def.envVar != "" {
@@ -80,7 +91,7 @@ func buildErrorDisplay(def *Definition) string {
 
 // buildDefinitionDisplay creates unified display for both flags and environment variables
 func buildDefinitionDisplay(def *Definition) string {
-	valueType := def.valueType.String()
+	valueType := def.typeName()
 	var indicators []string
 
 	// Collect all indicators
@@ -120,6 +131,10 @@ func buildDefinitionDisplay(def *Definition) string {
 	if def.flag != "" {
 		// This is a flag display
 		base = fmt.Sprintf("--%s %s", def.flag, valueType)
+		if def.counter {
+			base = fmt.Sprintf("--%s", def.flag)
+			indicators = append(indicators, "repeatable")
+		}
 
 		// Add env indicator if it also has an environment variable
 		if def.envVar != "" {
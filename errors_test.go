@@ -130,7 +130,7 @@ func TestBuildDefinitionDisplay(t *testing.T) {
 	got := buildDefinitionDisplay(def)
 	expectedParts := []string{
 		"--log-level string",
-		"(default: info, oneOf: ['debug', 'info', 'warn', 'error'], env: LOG_LEVEL)",
+		"(default: info, one of: debug, info, warn, error, env: LOG_LEVEL)",
 	}
 
 	for _, part := range expectedParts {
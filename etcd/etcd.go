@@ -0,0 +1,201 @@
+// Package etcd provides a commandkit source backed by etcd v3.
+//
+//	client, _ := clientv3.New(clientv3.Config{Endpoints: []string{"localhost:2379"}})
+//	source := etcd.New(client, "/myapp/prod/")
+//	cfg.UseSource(source)
+//	cfg.WatchSources(ctx, nil) // optional: reload when keys change
+//
+// Every key below the prefix is loaded once, on first use, and matched to definition
+// keys by name: "/myapp/prod/database/host" and "/myapp/prod/database-host" both
+// provide DATABASE_HOST. Watching resumes from the revision last seen, so no change is
+// missed, and reloads every key when that revision was compacted.
+package etcd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// loadTimeout bounds reading the keys below the prefix
+const loadTimeout = 10 * time.Second
+
+// retryDelay is how long Watch waits before watching again after the watch ended
+var retryDelay = time.Second
+
+// Client is the subset of the etcd API used by Source; *clientv3.Client implements it
+type Client interface {
+	clientv3.KV
+	clientv3.Watcher
+}
+
+// Source resolves configuration keys from the etcd keys below a prefix
+type Source struct {
+	client Client
+	prefix string
+
+	onError func(error)
+
+	mu       sync.RWMutex
+	loaded   bool
+	values   map[string]string
+	revision int64 // Revision of the values, watched from the next one
+}
+
+// New creates a source for the keys below prefix
+func New(client Client, prefix string) *Source {
+	return &Source{
+		client: client,
+		prefix: prefix,
+		onError: func(err error) {
+			log.Printf("etcd: %v", err)
+		},
+	}
+}
+
+// OnWatchError sets the function receiving the errors met while watching, such as a
+// lost connection; they are logged by default. Watching carries on after an error.
+func (s *Source) OnWatchError(fn func(error)) *Source {
+	if fn != nil {
+		s.onError = fn
+	}
+	return s
+}
+
+// Name identifies the source in error messages
+func (s *Source) Name() string {
+	return "etcd:" + s.prefix
+}
+
+// Lookup returns the value of the etcd key matching key
+func (s *Source) Lookup(key string) (string, bool, error) {
+	if err := s.load(context.Background()); err != nil {
+		return "", false, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	value, found := s.values[normalize(key)]
+	return value, found, nil
+}
+
+// Watch follows changes below the prefix, updating the cached values and calling
+// changed after each batch of events, until ctx is done
+func (s *Source) Watch(ctx context.Context, changed func()) error {
+	if err := s.load(ctx); err != nil {
+		return err
+	}
+	go s.follow(ctx, changed)
+	return nil
+}
+
+// follow watches from the revision after the cached values, watching again whenever
+// the watch ends before ctx is done
+func (s *Source) follow(ctx context.Context, changed func()) {
+	for {
+		s.mu.RLock()
+		revision := s.revision
+		s.mu.RUnlock()
+
+		events := s.client.Watch(ctx, s.prefix, clientv3.WithPrefix(), clientv3.WithRev(revision+1))
+		for response := range events {
+			switch {
+			case response.CompactRevision != 0:
+				// The revisions in between are gone: read every key again
+				if err := s.reload(ctx); err != nil {
+					s.onError(err)
+				} else {
+					changed()
+				}
+			case response.Err() != nil:
+				s.onError(fmt.Errorf("watch failed: %w", response.Err()))
+			case len(response.Events) > 0:
+				s.apply(revisionOf(response.Header), response.Events)
+				changed()
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retryDelay):
+		}
+	}
+}
+
+// load reads every key below the prefix the first time it is called
+func (s *Source) load(ctx context.Context) error {
+	s.mu.RLock()
+	loaded := s.loaded
+	s.mu.RUnlock()
+	if loaded {
+		return nil
+	}
+	return s.reload(ctx)
+}
+
+// reload reads every key below the prefix, replacing the cached values
+func (s *Source) reload(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, loadTimeout)
+	defer cancel()
+
+	response, err := s.client.Get(ctx, s.prefix, clientv3.WithPrefix())
+	if err != nil {
+		return fmt.Errorf("failed to read keys: %w", err)
+	}
+
+	values := make(map[string]string, len(response.Kvs))
+	for _, kv := range response.Kvs {
+		values[s.name(kv.Key)] = string(kv.Value)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values = values
+	s.revision = revisionOf(response.Header)
+	s.loaded = true
+	return nil
+}
+
+// apply updates the cached values from watch events received at revision
+func (s *Source) apply(revision int64, events []*clientv3.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.revision = max(s.revision, revision)
+	for _, event := range events {
+		s.revision = max(s.revision, event.Kv.ModRevision)
+		switch event.Type {
+		case mvccpb.PUT:
+			s.values[s.name(event.Kv.Key)] = string(event.Kv.Value)
+		case mvccpb.DELETE:
+			delete(s.values, s.name(event.Kv.Key))
+		}
+	}
+}
+
+// revisionOf returns the store revision of a response header
+func revisionOf(header *etcdserverpb.ResponseHeader) int64 {
+	if header == nil {
+		return 0
+	}
+	return header.Revision
+}
+
+// name maps an etcd key to its normalized name relative to the prefix
+func (s *Source) name(key []byte) string {
+	return normalize(strings.TrimPrefix(string(key), s.prefix))
+}
+
+// normalize maps etcd key names and definition keys to a common form
+func normalize(name string) string {
+	name = strings.Trim(name, "/")
+	return strings.ToUpper(strings.NewReplacer("/", "_", "-", "_", ".", "_").Replace(name))
+}
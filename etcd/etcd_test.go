@@ -0,0 +1,195 @@
+package etcd
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fernandezvara/commandkit"
+	"go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// fakeClient serves a fixed set of keys and lets tests push watch events
+type fakeClient struct {
+	clientv3.KV
+	clientv3.Watcher
+
+	kvs      []*mvccpb.KeyValue
+	revision int64
+	err      error
+	gets     int
+	events   chan clientv3.WatchResponse
+	streams  chan chan clientv3.WatchResponse // Served to watches before events
+	watches  chan int64                       // Revision each watch starts from
+}
+
+func (f *fakeClient) Get(_ context.Context, key string, _ ...clientv3.OpOption) (*clientv3.GetResponse, error) {
+	f.gets++
+	if f.err != nil {
+		return nil, f.err
+	}
+	var kvs []*mvccpb.KeyValue
+	for _, kv := range f.kvs {
+		if strings.HasPrefix(string(kv.Key), key) {
+			kvs = append(kvs, kv)
+		}
+	}
+	return &clientv3.GetResponse{Header: &etcdserverpb.ResponseHeader{Revision: f.revision}, Kvs: kvs}, nil
+}
+
+func (f *fakeClient) Watch(_ context.Context, key string, opts ...clientv3.OpOption) clientv3.WatchChan {
+	select {
+	case f.watches <- clientv3.OpGet(key, opts...).Rev():
+	default:
+	}
+	select {
+	case stream := <-f.streams:
+		return stream
+	default:
+		return f.events
+	}
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{
+		kvs: []*mvccpb.KeyValue{
+			{Key: []byte("/myapp/prod/database/host"), Value: []byte("db.internal")},
+			{Key: []byte("/myapp/prod/log-level"), Value: []byte("info")},
+			{Key: []byte("/other/log-level"), Value: []byte("debug")},
+		},
+		revision: 7,
+		events:   make(chan clientv3.WatchResponse, 1),
+		streams:  make(chan chan clientv3.WatchResponse, 2),
+		watches:  make(chan int64, 4),
+	}
+}
+
+func TestSourceLookup(t *testing.T) {
+	client := newFakeClient()
+	source := New(client, "/myapp/prod/")
+
+	value, found, err := source.Lookup("DATABASE_HOST")
+	if err != nil || !found || value != "db.internal" {
+		t.Fatalf("Lookup(DATABASE_HOST) = %q, %v, %v", value, found, err)
+	}
+	if value, _, _ := source.Lookup("LOG_LEVEL"); value != "info" {
+		t.Errorf("keys outside the prefix must be ignored, got LOG_LEVEL=%q", value)
+	}
+	if _, found, _ := source.Lookup("MISSING"); found {
+		t.Error("Lookup(MISSING) should not find a value")
+	}
+	if client.gets != 1 {
+		t.Errorf("expected the prefix to be read once, got %d reads", client.gets)
+	}
+
+	client.err = errors.New("connection refused")
+	if _, _, err := New(client, "/myapp/prod/").Lookup("LOG_LEVEL"); err == nil {
+		t.Error("expected the client error to be returned")
+	}
+}
+
+func TestSourceWatchReloadsConfig(t *testing.T) {
+	client := newFakeClient()
+
+	cfg := commandkit.New()
+	cfg.Define("LOG_LEVEL").String().Default("warn")
+	cfg.UseSource(New(client, "/myapp/prod/"))
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	watchCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reloaded := make(chan error, 1)
+	if err := cfg.WatchSources(watchCtx, func(err error) { reloaded <- err }); err != nil {
+		t.Fatalf("WatchSources failed: %v", err)
+	}
+
+	client.events <- clientv3.WatchResponse{Events: []*clientv3.Event{{
+		Type: mvccpb.PUT,
+		Kv:   &mvccpb.KeyValue{Key: []byte("/myapp/prod/log-level"), Value: []byte("debug")},
+	}}}
+
+	select {
+	case err := <-reloaded:
+		if err != nil {
+			t.Fatalf("reload failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the reload")
+	}
+
+	ctx := commandkit.NewCommandContext(nil, cfg, "", "")
+	if level, _ := commandkit.Get[string](ctx, "LOG_LEVEL"); level != "debug" {
+		t.Errorf("LOG_LEVEL = %q after the watch event, want debug", level)
+	}
+
+	client.events <- clientv3.WatchResponse{Events: []*clientv3.Event{{
+		Type: mvccpb.DELETE,
+		Kv:   &mvccpb.KeyValue{Key: []byte("/myapp/prod/log-level")},
+	}}}
+	<-reloaded
+	if level, _ := commandkit.Get[string](ctx, "LOG_LEVEL"); level != "warn" {
+		t.Errorf("LOG_LEVEL = %q after the key was deleted, want the default", level)
+	}
+}
+
+func TestSourceWatchResumes(t *testing.T) {
+	defer func(original time.Duration) { retryDelay = original }(retryDelay)
+	retryDelay = time.Millisecond
+
+	client := newFakeClient()
+	first, second := make(chan clientv3.WatchResponse), make(chan clientv3.WatchResponse)
+	client.streams <- first
+	client.streams <- second
+
+	errs := make(chan error, 1)
+	source := New(client, "/myapp/prod/").OnWatchError(func(err error) { errs <- err })
+	changed := make(chan struct{}, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := source.Watch(ctx, func() { changed <- struct{}{} }); err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+
+	if revision := <-client.watches; revision != 8 {
+		t.Errorf("watch started at revision %d, want the one after the load (8)", revision)
+	}
+	first <- clientv3.WatchResponse{Header: &etcdserverpb.ResponseHeader{Revision: 9}, Events: []*clientv3.Event{{
+		Type: mvccpb.PUT,
+		Kv:   &mvccpb.KeyValue{Key: []byte("/myapp/prod/log-level"), Value: []byte("debug"), ModRevision: 9},
+	}}}
+	<-changed
+
+	// A watch ending early resumes after the last revision seen
+	close(first)
+	if revision := <-client.watches; revision != 10 {
+		t.Errorf("watch resumed at revision %d, want 10", revision)
+	}
+
+	// Compacted revisions: every key is read again
+	client.kvs = append(client.kvs, &mvccpb.KeyValue{Key: []byte("/myapp/prod/workers"), Value: []byte("4")})
+	second <- clientv3.WatchResponse{CompactRevision: 9}
+	<-changed
+	if value, found, _ := source.Lookup("WORKERS"); !found || value != "4" {
+		t.Errorf("Lookup(WORKERS) = %q, %v after a compaction; want the reloaded value", value, found)
+	}
+	if client.gets != 2 {
+		t.Errorf("expected the prefix to be read again after a compaction, got %d reads", client.gets)
+	}
+
+	second <- clientv3.WatchResponse{Canceled: true}
+	select {
+	case err := <-errs:
+		if err == nil {
+			t.Error("expected a watch error")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the watch error")
+	}
+}
@@ -0,0 +1,102 @@
+// commandkit/example_config.go
+package commandkit
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// exampleEntry is one key of an example config file
+type exampleEntry struct {
+	key         string
+	value       string
+	description string
+}
+
+// WriteExampleConfig writes a commented example config file in format (yaml, toml or
+// env) holding every global and command definition, with its Example value or else its
+// default. Secrets are left empty unless they have an example.
+func (c *Config) WriteExampleConfig(w io.Writer, format string) error {
+	format = strings.ToLower(strings.TrimPrefix(format, "."))
+	switch format {
+	case "yaml", "yml", "toml", "env", "dotenv":
+	default:
+		return fmt.Errorf("unsupported example config format: %s", format)
+	}
+	entries := c.exampleEntries(format == "env" || format == "dotenv")
+
+	bw := bufio.NewWriter(w)
+	for i, entry := range entries {
+		if i > 0 {
+			fmt.Fprintln(bw)
+		}
+		if entry.description != "" {
+			fmt.Fprintf(bw, "# %s\n", entry.description)
+		}
+
+		switch format {
+		case "yaml", "yml":
+			value, err := yaml.Marshal(entry.value)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(bw, "%s: %s", entry.key, value)
+		case "toml":
+			fmt.Fprintf(bw, "%q = %s\n", entry.key, strconv.Quote(entry.value))
+		default:
+			fmt.Fprintf(bw, "%s=%s\n", entry.key, strconv.Quote(entry.value))
+		}
+	}
+	return bw.Flush()
+}
+
+// exampleEntries collects the definitions read from files, sorted by key. Env files
+// are keyed by environment variable, other formats by file key.
+func (c *Config) exampleEntries(envFormat bool) []exampleEntry {
+	defs := make(map[string]*Definition)
+	for key, def := range c.definitions {
+		defs[key] = def
+	}
+	var addCommands func(commands map[string]*Command)
+	addCommands = func(commands map[string]*Command) {
+		for _, cmd := range commands {
+			for key, def := range ownDefinitions(cmd, c.definitions) {
+				if _, exists := defs[key]; !exists {
+					defs[key] = def
+				}
+			}
+			addCommands(cmd.SubCommands)
+		}
+	}
+	addCommands(c.commands)
+
+	converter := NewTypeConverter()
+	entries := make([]exampleEntry, 0, len(defs))
+	for key, def := range defs {
+		entry := exampleEntry{key: key, description: def.description}
+		switch {
+		case envFormat && def.envVar != "":
+			entry.key = def.envVar
+		case !envFormat && def.fileKey != "":
+			entry.key = def.fileKey
+		}
+
+		switch {
+		case def.example != "":
+			entry.value = def.example
+		case def.defaultValue != nil && !def.secret:
+			entry.value, _ = converter.ConvertToString(def.defaultValue, def.delimiter)
+		}
+		entry.value = c.maskSecretCopy(key, entry.value)
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].key < entries[j].key })
+	return entries
+}
@@ -210,6 +210,20 @@ func (ctx *ExecutionContext) DisplayAndExit() {
 	os.Exit(1)
 }
 
+// configErrors returns the collected errors as ConfigErrors
+func (ctx *ExecutionContext) configErrors() ConfigErrors {
+	var errs ConfigErrors
+	for _, err := range ctx.GetErrors() {
+		errs = append(errs, ConfigError{
+			Key:              err.Key,
+			Source:           "none",
+			Display:          err.Display,
+			ErrorDescription: err.ErrorDescription,
+		})
+	}
+	return errs
+}
+
 // Clear removes all collected errors (useful for testing)
 func (ctx *ExecutionContext) Clear() {
 	ctx.mu.Lock()
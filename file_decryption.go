@@ -0,0 +1,107 @@
+// commandkit/file_decryption.go
+package commandkit
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// FileDecryptor decrypts whole configuration files, such as age or SOPS encrypted ones
+type FileDecryptor interface {
+	DecryptFile(data []byte) ([]byte, error)
+}
+
+// FileDecryptorFunc adapts a function to the FileDecryptor interface
+type FileDecryptorFunc func(data []byte) ([]byte, error)
+
+// DecryptFile calls f
+func (f FileDecryptorFunc) DecryptFile(data []byte) ([]byte, error) {
+	return f(data)
+}
+
+// UseFileDecryptor registers the decryptor for files whose name ends in suffix. The
+// file is decrypted when loaded and reloaded, then parsed by the extension left once
+// the suffix is removed (config.yaml.age is YAML), or by the suffix's own extension
+// (".sops.yaml"). The decrypted bytes are wiped once parsed, and processing moves the
+// values of Secret() definitions to the secret store, sealing them in the file data;
+// other values stay in ordinary memory like those of any file.
+func (c *Config) UseFileDecryptor(suffix string, decryptor FileDecryptor) *Config {
+	if c.fileDecryptors == nil {
+		c.fileDecryptors = make(map[string]FileDecryptor)
+	}
+	c.fileDecryptors[suffix] = decryptor
+	return c
+}
+
+// fileDecryptorFor returns the decryptor registered for the longest suffix of filename,
+// compared case-insensitively, and the suffix as written in filename
+func (c *Config) fileDecryptorFor(filename string) (string, FileDecryptor) {
+	var match string
+	for suffix := range c.fileDecryptors {
+		if len(suffix) <= len(filename) && len(suffix) > len(match) &&
+			strings.EqualFold(filename[len(filename)-len(suffix):], suffix) {
+			match = suffix
+		}
+	}
+	if match == "" {
+		return "", nil
+	}
+	return filename[len(filename)-len(match):], c.fileDecryptors[match]
+}
+
+// CommandDecryptor returns a decryptor running an external tool with the encrypted
+// file on stdin and reading the plaintext from its stdout, e.g.
+//
+//	CommandDecryptor("age", "--decrypt", "--identity", "key.txt")
+func CommandDecryptor(name string, args ...string) FileDecryptor {
+	return FileDecryptorFunc(func(data []byte) ([]byte, error) {
+		var stdout, stderr bytes.Buffer
+		cmd := exec.Command(name, args...)
+		cmd.Stdin = bytes.NewReader(data)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			if message := strings.TrimSpace(stderr.String()); message != "" {
+				return nil, fmt.Errorf("%s: %w: %s", name, err, message)
+			}
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		return stdout.Bytes(), nil
+	})
+}
+
+// AgeDecryptor decrypts age files with the age tool and an identity file
+//
+//	cfg.UseFileDecryptor(".age", commandkit.AgeDecryptor("/etc/myapp/key.txt"))
+//	cfg.LoadFile("config.yaml.age")
+func AgeDecryptor(identityFile string) FileDecryptor {
+	return CommandDecryptor("age", "--decrypt", "--identity", identityFile)
+}
+
+// SOPSDecryptor decrypts SOPS files in format (yaml, json, ini or dotenv) with the sops
+// tool, which finds its keys as usual (SOPS_AGE_KEY_FILE, cloud KMS credentials, ...)
+//
+//	cfg.UseFileDecryptor(".sops.yaml", commandkit.SOPSDecryptor("yaml"))
+//	cfg.LoadFile("secrets.sops.yaml")
+func SOPSDecryptor(format string) FileDecryptor {
+	// sops reads a file, and /dev/stdin does not exist everywhere (Windows); the
+	// encrypted data goes to a temporary file instead
+	return FileDecryptorFunc(func(data []byte) ([]byte, error) {
+		file, err := os.CreateTemp("", "commandkit-*.sops")
+		if err != nil {
+			return nil, fmt.Errorf("sops: %w", err)
+		}
+		defer os.Remove(file.Name())
+		_, err = file.Write(data)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, fmt.Errorf("sops: %w", err)
+		}
+		return CommandDecryptor("sops", "--decrypt", "--input-type", format, "--output-type", format, file.Name()).DecryptFile(nil)
+	})
+}
@@ -0,0 +1,113 @@
+package commandkit
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// rot13 stands in for an encryption tool
+func rot13(text string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return 'a' + (r-'a'+13)%26
+		case r >= 'A' && r <= 'Z':
+			return 'A' + (r-'A'+13)%26
+		}
+		return r
+	}, text)
+}
+
+func TestEncryptedConfigFiles(t *testing.T) {
+	if _, err := exec.LookPath("tr"); err != nil {
+		t.Skip("tr is not available")
+	}
+	rot := CommandDecryptor("tr", "A-Za-z", "N-ZA-Mn-za-m")
+
+	dir := t.TempDir()
+	yamlFile := filepath.Join(dir, "config.yaml.rot")
+	writeConfigFile(t, yamlFile, rot13("API_KEY: s3cret\nHOST: db.internal\n"))
+	sopsFile := filepath.Join(dir, "secrets.sops.env")
+	writeConfigFile(t, sopsFile, rot13("PORT=8443\n"))
+
+	cfg := New()
+	cfg.SetDefaultPriority(PriorityFileEnvFlagDefault)
+	cfg.UseFileDecryptor(".rot", rot)
+	cfg.UseFileDecryptor(".sops.env", rot)
+	cfg.Define("API_KEY").String().Secret()
+	cfg.Define("HOST").String()
+	cfg.Define("PORT").Int64()
+
+	if err := cfg.LoadFiles(yamlFile, sopsFile); err != nil {
+		t.Fatalf("LoadFiles failed: %v", err)
+	}
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := cfg.GetSecret("API_KEY").String(); got != "s3cret" {
+		t.Errorf("API_KEY = %q, want the decrypted value", got)
+	}
+	ctx := NewCommandContext(nil, cfg, "", "")
+	if host, _ := Get[string](ctx, "HOST"); host != "db.internal" {
+		t.Errorf("HOST = %q", host)
+	}
+	if port, _ := Get[int64](ctx, "PORT"); port != 8443 {
+		t.Errorf("PORT = %d, want 8443", port)
+	}
+
+	// The decrypted secret is sealed in the file data, and found again when processing
+	if _, sealed := cfg.fileConfig.data["API_KEY"].(*Secret); !sealed {
+		t.Errorf("API_KEY is kept in the file data as %T, want a sealed *Secret", cfg.fileConfig.data["API_KEY"])
+	}
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error processing again: %v", err)
+	}
+	if got := cfg.GetSecret("API_KEY").String(); got != "s3cret" {
+		t.Errorf("API_KEY = %q after processing again, want the sealed value", got)
+	}
+
+	// Reloads decrypt again
+	writeConfigFile(t, yamlFile, rot13("API_KEY: rotated\n"))
+	fresh, err := cfg.reloadFiles()
+	if err != nil {
+		t.Fatalf("reloadFiles failed: %v", err)
+	}
+	if fresh.data["API_KEY"] != "rotated" {
+		t.Errorf("reloaded data = %v", fresh.data)
+	}
+
+	failing := New().UseFileDecryptor(".rot", CommandDecryptor("sh", "-c", "echo bad key >&2; exit 1"))
+	err = failing.LoadFile(yamlFile)
+	if err == nil || !strings.Contains(err.Error(), "failed to decrypt config file") || !strings.Contains(err.Error(), "bad key") {
+		t.Errorf("expected the decryptor error, got %v", err)
+	}
+}
+
+func TestSOPSDecryptorReadsAFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake sops is a shell script")
+	}
+	if _, err := exec.LookPath("tr"); err != nil {
+		t.Skip("tr is not available")
+	}
+
+	// The fake sops decrypts the file named by its last argument
+	bin := t.TempDir()
+	script := "#!/bin/sh\nfor last; do :; done\ntr A-Za-z N-ZA-Mn-za-m < \"$last\"\n"
+	if err := os.WriteFile(filepath.Join(bin, "sops"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	plaintext, err := SOPSDecryptor("yaml").DecryptFile([]byte(rot13("API_KEY: s3cret\n")))
+	if err != nil {
+		t.Fatalf("DecryptFile failed: %v", err)
+	}
+	if string(plaintext) != "API_KEY: s3cret\n" {
+		t.Errorf("plaintext = %q", plaintext)
+	}
+}
@@ -4,58 +4,276 @@ package commandkit
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/BurntSushi/toml"
+	"github.com/awnumar/memguard"
 	"gopkg.in/yaml.v3"
 )
 
 // FileConfig represents configuration loaded from files
 type FileConfig struct {
-	data      map[string]any
-	envPrefix string
+	data        map[string]any
+	envPrefix   string
+	files       []string          // Loaded file paths in load order, used for reloading
+	readers     []readerData      // Data loaded with LoadReader, replayed on reloads
+	origins     map[string]string // File providing each key path (a, a.b), "" for readers
+	included    []string          // Files pulled in with include keys, watched for changes
+	strategy    MergeStrategy     // How files loaded later combine with earlier ones
+	environment string            // Selected section of the environments map
 }
 
-// LoadFile loads configuration from a single file
-func (c *Config) LoadFile(filename string) error {
-	data, err := os.ReadFile(filename)
+// readerData is configuration loaded from a reader, which cannot be read again
+type readerData struct {
+	after int // Number of files loaded before it
+	data  map[string]any
+}
+
+// merge merges new config data read from filename into the file data. Later data
+// wins: with MergeDeep, nested tables are merged key by key; with MergeReplace, each
+// top-level key is replaced as a whole (environments are still merged per section).
+func (fc *FileConfig) merge(newData map[string]any, filename string) {
+	if fc.origins == nil {
+		fc.origins = make(map[string]string)
+	}
+	for key, value := range newData {
+		switch {
+		case fc.strategy == MergeDeep:
+			value = combine(fc.data[key], value)
+		case key == environmentsKey:
+			value = mergeEnvironments(fc.data[key], value)
+		default:
+			value = stackSlices(fc.data[key], value)
+		}
+		if _, isTable := value.(map[string]any); !isTable || fc.strategy == MergeReplace {
+			fc.forget(key)
+		}
+		fc.track(key, newData[key], filename)
+		fc.data[key] = value
+	}
+}
+
+// track records filename as the origin of path and of every nested key under it
+func (fc *FileConfig) track(path string, value any, filename string) {
+	fc.origins[path] = filename
+	if table, ok := value.(map[string]any); ok {
+		for key, nested := range table {
+			fc.track(path+"."+key, nested, filename)
+		}
+	}
+}
+
+// forget drops the origins of the keys nested under path, once it is replaced
+func (fc *FileConfig) forget(path string) {
+	for tracked := range fc.origins {
+		if strings.HasPrefix(tracked, path+".") {
+			delete(fc.origins, tracked)
+		}
+	}
+}
+
+// readConfigFile reads and decodes a single configuration file based on its extension,
+// decrypting it first when a file decryptor handles its suffix
+func (c *Config) readConfigFile(filename string) (map[string]any, error) {
+	data, err := c.readFileLimited(filename)
 	if err != nil {
-		return fmt.Errorf("failed to read config file %s: %w", filename, err)
+		return nil, fmt.Errorf("failed to read config file %s: %w", filename, err)
+	}
+
+	name := filename
+	if suffix, decryptor := c.fileDecryptorFor(filename); decryptor != nil {
+		plaintext, err := decryptor.DecryptFile(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt config file %s: %w", filename, err)
+		}
+		// Parsing copies the values out, so the decrypted bytes are wiped afterwards;
+		// processing seals the values of Secret() definitions (see sealFileSecret)
+		defer memguard.WipeBytes(plaintext)
+		data = plaintext
+		name = strings.TrimSuffix(filename, suffix)
+		if filepath.Ext(name) == "" {
+			name += filepath.Ext(suffix) // e.g. secrets.sops.yaml handled as ".sops.yaml"
+		}
 	}
 
+	format := filepath.Ext(name)
+	if isDotenvFile(name) {
+		format = "env"
+	}
+	config, err := parseConfig(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("%w (%s)", err, filename)
+	}
+	return config, nil
+}
+
+// parseConfig decodes data in format, a file extension with or without the dot
+func parseConfig(data []byte, format string) (map[string]any, error) {
 	var config map[string]any
-	ext := strings.ToLower(filepath.Ext(filename))
+	var err error
 
-	switch ext {
-	case ".json":
+	format = strings.ToLower(strings.TrimPrefix(format, "."))
+	switch format {
+	case "json":
 		err = json.Unmarshal(data, &config)
-	case ".yaml", ".yml":
+	case "yaml", "yml":
 		err = yaml.Unmarshal(data, &config)
-	case ".toml":
+	case "toml":
 		err = toml.Unmarshal(data, &config)
+	case "ini", "cfg":
+		config, err = parseINI(data)
+	case "properties":
+		config, err = parseProperties(data)
+	case "env", "dotenv":
+		config, err = parseDotenv(data)
 	default:
-		return fmt.Errorf("unsupported config file format: %s", ext)
+		return nil, fmt.Errorf("unsupported config file format: %s", format)
 	}
 
 	if err != nil {
-		return fmt.Errorf("failed to parse %s file: %w", ext, err)
+		return nil, fmt.Errorf("failed to parse %s file: %w", format, err)
+	}
+	return config, nil
+}
+
+// LoadFile loads configuration from a single file, after the files it includes
+func (c *Config) LoadFile(filename string) error {
+	layers, err := c.readConfigLayers(filename, nil)
+	if err != nil {
+		return err
 	}
 
 	// Store file data for resolution
 	if c.fileConfig == nil {
 		c.fileConfig = &FileConfig{
-			data: make(map[string]any),
+			data:        make(map[string]any),
+			environment: c.environment,
 		}
 	}
 
-	// Merge with existing file data
-	c.mergeFileData(config)
+	// Merge with existing file data, included files first
+	for _, layer := range layers {
+		c.mergeFileData(layer.data, layer.filename)
+		if layer.filename != filename {
+			c.fileConfig.included = append(c.fileConfig.included, layer.filename)
+		}
+	}
+	c.fileConfig.files = append(c.fileConfig.files, filename)
 
 	return nil
 }
 
+// includeKeys are the file keys naming other files to load first
+var includeKeys = []string{"include", "includes"}
+
+// fileLayer is the data read from a single file
+type fileLayer struct {
+	filename string
+	data     map[string]any
+}
+
+// readConfigLayers reads filename and, before it, the files it includes (recursively),
+// in merge order. including holds the absolute paths of the files including this one.
+func (c *Config) readConfigLayers(filename string, including []string) ([]fileLayer, error) {
+	path, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(including, path) {
+		return nil, fmt.Errorf("include cycle: %s -> %s", strings.Join(including, " -> "), path)
+	}
+
+	data, err := c.readConfigFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	includes, err := includedFiles(data, filepath.Dir(filename))
+	if err != nil {
+		return nil, fmt.Errorf("invalid include in %s: %w", filename, err)
+	}
+
+	var layers []fileLayer
+	for _, include := range includes {
+		included, err := c.readConfigLayers(include, append(including, path))
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, included...)
+	}
+	return append(layers, fileLayer{filename: filename, data: data}), nil
+}
+
+// includedFiles removes the include keys from data and returns the files they name,
+// relative to dir; a pattern such as "conf.d/*.yaml" includes its matches in lexical
+// order
+func includedFiles(data map[string]any, dir string) ([]string, error) {
+	var files []string
+	for _, key := range includeKeys {
+		value, exists := data[key]
+		if !exists {
+			continue
+		}
+		delete(data, key)
+
+		var names []string
+		switch v := value.(type) {
+		case string:
+			names = []string{v}
+		case []any:
+			for _, item := range v {
+				name, ok := item.(string)
+				if !ok {
+					return nil, fmt.Errorf("%s must list file names, got %v", key, item)
+				}
+				names = append(names, name)
+			}
+		default:
+			return nil, fmt.Errorf("%s must be a file name or a list of them, got %v", key, value)
+		}
+
+		for _, name := range names {
+			if !filepath.IsAbs(name) {
+				name = filepath.Join(dir, name)
+			}
+			if !strings.ContainsAny(name, "*?[") {
+				files = append(files, name)
+				continue
+			}
+			matches, err := filepath.Glob(name)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %w", name, err)
+			}
+			files = append(files, matches...)
+		}
+	}
+	return files, nil
+}
+
+// LoadReader loads configuration from r in format (json, yaml, toml, ini, properties or
+// env), e.g. piped on stdin or embedded with go:embed. It is merged like a file loaded
+// at this point, and kept for reloads since a reader cannot be read again.
+func (c *Config) LoadReader(r io.Reader, format string) error {
+	data, err := c.readLimited(r, "config")
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+	config, err := parseConfig(data, format)
+	if err != nil {
+		return err
+	}
+
+	c.mergeFileData(config, "")
+	c.fileConfig.readers = append(c.fileConfig.readers, readerData{after: len(c.fileConfig.files), data: config})
+	return nil
+}
+
 // LoadFiles loads configuration from multiple files (later files override earlier ones)
 func (c *Config) LoadFiles(filenames ...string) error {
 	for _, filename := range filenames {
@@ -66,36 +284,236 @@ func (c *Config) LoadFiles(filenames ...string) error {
 	return nil
 }
 
-// LoadFromEnv loads configuration file path from environment variable
+// LoadDir loads the files of dir matching pattern (e.g. "*.yaml") in lexical order, so
+// later files override earlier ones, as with conf.d drop-in directories. Files added
+// to dir afterwards are not picked up by reloads.
+func (c *Config) LoadDir(dir, pattern string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read config directory: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+
+	matches, err := filepath.Glob(filepath.Join(dir, pattern))
+	if err != nil {
+		return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	for _, filename := range matches {
+		if info, err := os.Stat(filename); err != nil || info.IsDir() {
+			continue
+		}
+		if err := c.LoadFile(filename); err != nil {
+			return fmt.Errorf("error loading %s: %w", filename, err)
+		}
+	}
+	return nil
+}
+
+// standardConfigNames are the file names LoadStandardPaths looks for in each directory
+var standardConfigNames = []string{"config.yaml", "config.yml", "config.json", "config.toml"}
+
+// systemConfigDir holds system-wide configuration; replaced in tests
+var systemConfigDir = "/etc"
+
+// configOS is the operating system whose path conventions apply; replaced in tests
+var configOS = runtime.GOOS
+
+// LoadStandardPaths loads the first config.{yaml,yml,json,toml} found in the current
+// directory, then $XDG_CONFIG_HOME/app (~/.config/app by default), then /etc/app. On
+// Windows the user and system directories are %APPDATA%\app and %ProgramData%\app.
+// It is not an error when there is none.
+func (c *Config) LoadStandardPaths(app string) error {
+	if files := c.standardConfigFiles(app); len(files) > 0 {
+		return c.LoadFile(files[0])
+	}
+	return nil
+}
+
+// LoadAllStandardPaths loads every config file LoadStandardPaths looks for, from
+// /etc/app to the current directory, so more specific files override system ones
+func (c *Config) LoadAllStandardPaths(app string) error {
+	files := c.standardConfigFiles(app)
+	slices.Reverse(files)
+	return c.LoadFiles(files...)
+}
+
+// standardConfigFiles returns the existing standard config files for app, most
+// specific first; only the first matching name of each directory is used
+func (c *Config) standardConfigFiles(app string) []string {
+	dirs := []string{"."}
+	if configOS == "windows" {
+		if appData := c.getenv("APPDATA"); appData != "" {
+			dirs = append(dirs, filepath.Join(appData, app))
+		}
+		programData := c.getenv("ProgramData")
+		if programData == "" {
+			programData = `C:\ProgramData`
+		}
+		dirs = append(dirs, filepath.Join(programData, app))
+	} else {
+		configHome := c.getenv("XDG_CONFIG_HOME")
+		if configHome == "" {
+			if home := c.getenv("HOME"); home != "" {
+				configHome = filepath.Join(home, ".config")
+			}
+		}
+		if configHome != "" {
+			dirs = append(dirs, filepath.Join(configHome, app))
+		}
+		dirs = append(dirs, filepath.Join(systemConfigDir, app))
+	}
+
+	var files []string
+	for _, dir := range dirs {
+		for _, name := range standardConfigNames {
+			filename := filepath.Join(dir, name)
+			if info, err := os.Stat(filename); err == nil && !info.IsDir() {
+				files = append(files, filename)
+				break
+			}
+		}
+	}
+	return files
+}
+
+// LoadFromEnv loads configuration file path from environment variable. A leading ~
+// and $VAR references in the path are expanded, as are %VAR% references on Windows.
 func (c *Config) LoadFromEnv(envVar string) error {
-	filename := os.Getenv(envVar)
+	filename := c.getenv(envVar)
 	if filename == "" {
 		return nil // No environment variable set
 	}
-	return c.LoadFile(filename)
+	return c.LoadFile(c.expandConfigPath(filename))
 }
 
 // LoadFileFromEnv loads configuration file from environment variable containing the file path
 func (c *Config) LoadFileFromEnv(envVar string) error {
-	filename := os.Getenv(envVar)
-	if filename == "" {
-		return nil // No environment variable set
+	return c.LoadFromEnv(envVar)
+}
+
+// expandConfigPath expands a leading ~ to the home directory (%USERPROFILE% on
+// Windows), $VAR and ${VAR} references and, on Windows, %VAR% references. Undefined
+// %VAR% references are kept as they are.
+func (c *Config) expandConfigPath(path string) string {
+	if configOS == "windows" {
+		var b strings.Builder
+		for {
+			start := strings.IndexByte(path, '%')
+			end := strings.IndexByte(path[start+1:], '%')
+			if start < 0 || end < 0 {
+				break
+			}
+			end += start + 1
+			if value := c.getenv(path[start+1 : end]); value != "" {
+				b.WriteString(path[:start] + value)
+				path = path[end+1:]
+				continue
+			}
+			b.WriteString(path[:end])
+			path = path[end:]
+		}
+		path = b.String() + path
+	}
+
+	path = os.Expand(path, c.getenv)
+
+	homeVar := "HOME"
+	if configOS == "windows" {
+		homeVar = "USERPROFILE"
+	}
+	if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, `~\`) {
+		if home := c.getenv(homeVar); home != "" {
+			path = filepath.Join(home, path[1:])
+		}
 	}
-	return c.LoadFile(filename)
+	return path
 }
 
 // mergeFileData merges new config data with existing file data
-func (c *Config) mergeFileData(newData map[string]any) {
+func (c *Config) mergeFileData(newData map[string]any, filename string) {
 	if c.fileConfig == nil {
 		c.fileConfig = &FileConfig{
-			data: make(map[string]any),
+			data:        make(map[string]any),
+			environment: c.environment,
 		}
 	}
 
-	// Simple merge - new data overrides old data
-	for key, value := range newData {
-		c.fileConfig.data[key] = value
+	c.fileConfig.strategy = c.mergeStrategy
+	c.fileConfig.merge(newData, filename)
+}
+
+// reloadFiles re-reads every loaded file, in load order, into a fresh FileConfig
+// The current file data is left untouched so a failed reload can be discarded
+func (c *Config) reloadFiles() (*FileConfig, error) {
+	if c.fileConfig == nil {
+		return nil, nil
+	}
+
+	fresh := &FileConfig{
+		data:        make(map[string]any),
+		envPrefix:   c.fileConfig.envPrefix,
+		files:       append([]string(nil), c.fileConfig.files...),
+		readers:     c.fileConfig.readers,
+		environment: c.fileConfig.environment,
+		strategy:    c.mergeStrategy,
+	}
+	readers := fresh.readers
+	for i, filename := range fresh.files {
+		for len(readers) > 0 && readers[0].after <= i {
+			fresh.merge(readers[0].data, "")
+			readers = readers[1:]
+		}
+		layers, err := c.readConfigLayers(filename, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, layer := range layers {
+			fresh.merge(layer.data, layer.filename)
+			if layer.filename != filename {
+				fresh.included = append(fresh.included, layer.filename)
+			}
+		}
+	}
+	for _, reader := range readers {
+		fresh.merge(reader.data, "")
+	}
+	return fresh, nil
+}
+
+// fileKeys returns the keys a definition's value is looked up under in files: its
+// fileKey, or the definition key, then its fallback keys
+func fileKeys(key string, def *Definition) []string {
+	if def == nil {
+		return []string{key}
+	}
+	if def.fileKey != "" {
+		key = def.fileKey
+	}
+	return append([]string{key}, def.fallbackKeys...)
+}
+
+// parseFileList converts the items of a file array to the slice type valueType. Items
+// must be scalars; whole numbers decoded as float64 (as JSON does) are accepted by
+// integer slices.
+func parseFileList(items []any, valueType ValueType) (any, error) {
+	texts := make(indexedEnv, 0, len(items))
+	for _, item := range items {
+		switch v := item.(type) {
+		case nil:
+			continue
+		case []any, map[string]any:
+			return nil, fmt.Errorf("invalid %s item: nested values are not supported", valueType)
+		case float64:
+			texts = append(texts, strconv.FormatFloat(v, 'f', -1, 64))
+		case string:
+			texts = append(texts, v)
+		default:
+			texts = append(texts, fmt.Sprint(v))
+		}
 	}
+	return texts.parse(valueType)
 }
 
 // getFileValue gets a value from file configuration using fileKey or fallback to definition key
@@ -104,23 +522,123 @@ func (c *Config) getFileValue(key string, def *Definition) (any, bool) {
 		return nil, false
 	}
 
-	// Use fileKey if specified, otherwise use the definition key
-	searchKey := key
-	if def != nil && def.fileKey != "" {
-		searchKey = def.fileKey
+	for _, searchKey := range fileKeys(key, def) {
+		// The selected environment overrides the top-level values
+		if envData := c.fileConfig.environmentData(); envData != nil {
+			if value, exists := lookupFileKey(envData, searchKey); exists {
+				return c.combineFileSlices(searchKey, value), true
+			}
+		}
+
+		if value, exists := lookupFileKey(c.fileConfig.data, searchKey); exists {
+			return c.combineFileSlices(searchKey, value), true
+		}
 	}
 
-	// Check for regular value (case-insensitive)
-	if value, exists := c.fileConfig.data[searchKey]; exists {
-		return value, true
+	// Dotenv files name values after their environment variables
+	if def != nil && def.envVar != "" {
+		if value, exists := c.fileConfig.data[def.envVar]; exists {
+			return c.combineFileSlices(def.envVar, value), true
+		}
 	}
+	return nil, false
+}
 
-	// Check lowercase version
-	if value, exists := c.fileConfig.data[strings.ToLower(searchKey)]; exists {
-		return value, true
+// sealFileSecret replaces the plaintext value of the secret definition key by a Secret
+// when it was read from a decrypted file, once processing copied it to the secret
+// store, so the decrypted secret does not stay in ordinary memory. Later processing
+// finds the sealed value and copies it to the store again.
+func (c *Config) sealFileSecret(key string, def *Definition) {
+	if c.fileConfig == nil {
+		return
+	}
+	if _, decryptor := c.fileDecryptorFor(c.fileOrigin(key, def)); decryptor == nil {
+		return
 	}
 
-	return nil, false
+	// Same search order as getFileValue
+	for _, searchKey := range fileKeys(key, def) {
+		for _, data := range []map[string]any{c.fileConfig.environmentData(), c.fileConfig.data} {
+			if table, name, exists := fileKeySlot(data, searchKey); exists {
+				table[name] = sealFileValue(table[name])
+				return
+			}
+		}
+	}
+	if def.envVar != "" {
+		if value, exists := c.fileConfig.data[def.envVar]; exists {
+			c.fileConfig.data[def.envVar] = sealFileValue(value)
+		}
+	}
+}
+
+// sealFileValue moves a scalar file value into a Secret; tables, lists and empty values
+// are returned unchanged
+func sealFileValue(value any) any {
+	switch v := value.(type) {
+	case nil, *Secret, map[string]any, []any, fileSlices:
+		return value
+	case string:
+		if v == "" {
+			return value
+		}
+		return newSecret(v)
+	default:
+		return newSecret(fmt.Sprint(v))
+	}
+}
+
+// openSealedValue copies a value sealed by sealFileSecret into a new LockedBuffer and
+// runs the definition's validations on it
+func openSealedValue(def *Definition, sealed *Secret) (*memguard.LockedBuffer, error) {
+	buf := memguard.NewBuffer(sealed.Size())
+	buf.Copy(sealed.Bytes())
+	for _, validation := range def.validations {
+		if validation.Name == "required" {
+			continue
+		}
+		if err := validation.Check(buf.String()); err != nil {
+			buf.Destroy()
+			return nil, err
+		}
+	}
+	return buf, nil
+}
+
+// fileOrigin returns the file providing the value getFileValue finds for def, or ""
+// when it was loaded from a reader
+func (c *Config) fileOrigin(key string, def *Definition) string {
+	keys := fileKeys(key, def)
+	searchKey := keys[0]
+	for _, candidate := range keys {
+		if _, exists := c.getFileValue(candidate, nil); exists {
+			searchKey = candidate
+			break
+		}
+	}
+
+	if envData := c.fileConfig.environmentData(); envData != nil {
+		if _, exists := lookupFileKey(envData, searchKey); exists {
+			if origin, tracked := c.fileConfig.origins[environmentsKey+"."+c.fileConfig.environment+"."+searchKey]; tracked {
+				return origin
+			}
+			return c.fileConfig.origins[environmentsKey]
+		}
+	}
+	for _, candidate := range []string{searchKey, strings.ToLower(searchKey)} {
+		if origin, tracked := c.fileConfig.origins[candidate]; tracked {
+			return origin
+		}
+	}
+	// Dotted keys are provided by the file holding their top-level table
+	if top, _, dotted := strings.Cut(searchKey, "."); dotted {
+		for _, candidate := range []string{top, strings.ToLower(top)} {
+			if _, exists := c.fileConfig.data[candidate]; exists {
+				return c.fileConfig.origins[candidate]
+			}
+		}
+	}
+	return c.fileConfig.origins[def.envVar]
 }
 
 // getValueFromSource gets value from a specific source type
@@ -133,10 +651,8 @@ func (c *Config) getValueFromSource(key string, def *Definition, sourceType Sour
 		return nil, false
 
 	case SourceEnv:
-		if def.envVar != "" {
-			if envVal := os.Getenv(def.envVar); envVal != "" {
-				return envVal, true
-			}
+		if envVal, exists, err := c.lookupEnv(def); exists && err == nil {
+			return envVal, true
 		}
 		return nil, false
 
@@ -154,6 +670,10 @@ func (c *Config) getValueFromSource(key string, def *Definition, sourceType Sour
 		}
 		return nil, false
 
+	case SourceRemote:
+		value, exists, err := c.getRemoteValue(key, def)
+		return value, exists && err == nil
+
 	default:
 		return nil, false
 	}
@@ -171,10 +691,80 @@ func (c *Config) resolveValueWithPriorityContext(key string, def *Definition, ct
 
 	// Check sources in priority order
 	for _, sourceType := range priority {
-		if value, exists := c.getValueFromSource(key, def, sourceType); exists {
+		var value any
+		var exists bool
+		if sourceType == SourceRemote {
+			// Remote sources can fail, which must not be mistaken for a missing value
+			var err error
+			if value, exists, err = c.getRemoteValue(key, def); err != nil {
+				return nil, sourceType, err
+			}
+		} else if sourceType == SourceEnv {
+			// A <VAR>_FILE naming an unreadable file is an error, not a missing value
+			var err error
+			if value, exists, err = c.lookupEnv(def); err != nil {
+				return nil, sourceType, err
+			}
+		} else {
+			value, exists = c.getValueFromSource(key, def, sourceType)
+			if exists && sourceType == SourceFile {
+				if err := c.checkSecretFile(def, c.fileOrigin(key, def)); err != nil {
+					return nil, sourceType, err
+				}
+			}
+		}
+
+		// Pinned definitions reject values from the other sources
+		if exists {
+			if err := def.checkSource(sourceType); err != nil {
+				return nil, sourceType, err
+			}
+		}
+
+		// Encrypted file values are decrypted straight into protected memory
+		if scheme, payload, encrypted := parseEncryptedValue(value); exists && sourceType == SourceFile && encrypted {
+			buf, err := c.decryptValue(def, scheme, payload)
+			if err != nil {
+				return nil, sourceType, err
+			}
+			return buf, sourceType, nil
+		}
+
+		// Secrets sealed out of decrypted files go back to protected memory as they are
+		if sealed, isSealed := value.(*Secret); exists && sourceType == SourceFile && isSealed {
+			buf, err := openSealedValue(def, sealed)
+			if err != nil {
+				return nil, sourceType, err
+			}
+			return buf, sourceType, nil
+		}
+
+		if exists {
 			// Handle special case for Default source - use type conversion
 			if sourceType == SourceDefault {
 				// Convert default value to target type
+				if text, isText := value.(string); isText && def.valueType == TypePath {
+					// Default paths are expanded like the other sources
+					parsed, err := parseValue(text, TypePath, "")
+					if err != nil {
+						return value, sourceType, err
+					}
+					value = parsed
+				}
+				if text, isText := value.(string); isText && def.valueType == TypeTime {
+					parsed, err := parseTime(text, def.timeLayouts, c.timeLocation)
+					if err != nil {
+						return value, sourceType, err
+					}
+					value = parsed
+				}
+				if text, isText := value.(string); isText && def.parse != nil {
+					parsed, err := def.parse(text)
+					if err != nil {
+						return value, sourceType, err
+					}
+					value = parsed
+				}
 				converter := NewTypeConverter()
 				convertedValue, err := converter.ConvertDefaultValue(value, def.valueType)
 				if err != nil {
@@ -191,6 +781,9 @@ func (c *Config) resolveValueWithPriorityContext(key string, def *Definition, ct
 					if v.Name == "required" {
 						continue // Skip required check for defaults
 					}
+					if c.keepsLivePort(key, v, convertedValue) {
+						continue
+					}
 					if err := v.Check(convertedValue); err != nil {
 						return convertedValue, sourceType, err
 					}
@@ -199,16 +792,85 @@ func (c *Config) resolveValueWithPriorityContext(key string, def *Definition, ct
 			}
 
 			// For non-default sources, convert to string and parse using TypeConverter
-			converter := NewTypeConverter()
-			rawValue, err := converter.ConvertToString(value, def.delimiter)
-			if err != nil {
-				return value, sourceType, err
+			var parsedValue any
+			if err := c.checkLimits(def, value); err != nil {
+				return nil, sourceType, err
+			}
+			if def.strictBool {
+				if err := checkStrictBool(value, def.delimiter); err != nil {
+					return value, sourceType, err
+				}
+			}
+			if def.strictInt {
+				if err := checkStrictInt(value, def.delimiter); err != nil {
+					return value, sourceType, err
+				}
 			}
+			_, isSliceType := indexedElementTypes[def.valueType]
+			if items, indexed := value.(indexedEnv); indexed {
+				// Indexed variables are parsed item by item
+				parsed, err := items.parse(def.valueType)
+				if err != nil {
+					return value, sourceType, err
+				}
+				parsedValue = parsed
+			} else if _, isText := value.(string); def.valueType == TypeTime && (len(def.timeLayouts) > 0 || isText && c.timeLocation != nil) {
+				// Times decoded by the file parser are kept; text must match a layout
+				// and is read in the time location
+				parsedValue = value
+				if text, isText := value.(string); isText {
+					parsed, err := parseTime(text, def.timeLayouts, c.timeLocation)
+					if err != nil {
+						return value, sourceType, err
+					}
+					parsedValue = parsed
+				} else if _, isTime := value.(time.Time); !isTime {
+					return value, sourceType, fmt.Errorf("invalid time: %v", value)
+				}
+			} else if items, isList := value.([]any); isList && isSliceType {
+				// File arrays are converted item by item, never through the delimiter
+				parsed, err := parseFileList(items, def.valueType)
+				if err != nil {
+					return value, sourceType, err
+				}
+				parsedValue = parsed
+			} else if _, isText := value.(string); !isText && def.valueType == TypeStringMap {
+				// Maps decoded by the file parser are converted entry by entry
+				parsed, err := stringMapFromAny(value)
+				if err != nil {
+					return value, sourceType, err
+				}
+				parsedValue = parsed
+			} else if def.parse != nil {
+				// Custom types parse the text form of the value
+				rawValue, err := NewTypeConverter().ConvertToString(value, def.delimiter)
+				if err != nil {
+					return value, sourceType, err
+				}
+				parsed, err := def.parse(rawValue)
+				if err != nil {
+					return rawValue, sourceType, err
+				}
+				parsedValue = parsed
+			} else if def.valueType == TypeComplex {
+				// Structured values are kept as decoded from the file
+				if sourceType != SourceFile {
+					return nil, sourceType, fmt.Errorf("complex values can only be read from files, not from %s", sourceType)
+				}
+				parsedValue = value
+			} else {
+				converter := NewTypeConverter()
+				rawValue, err := converter.ConvertToString(value, def.delimiter)
+				if err != nil {
+					return value, sourceType, err
+				}
 
-			// Parse the raw string value into the expected type
-			parsedValue, err := parseValue(rawValue, def.valueType, def.delimiter)
-			if err != nil {
-				return rawValue, sourceType, err
+				// Parse the raw string value into the expected type
+				parsed, err := parseValue(rawValue, def.valueType, def.delimiter)
+				if err != nil {
+					return rawValue, sourceType, err
+				}
+				parsedValue = parsed
 			}
 
 			// Skip validation if help is requested
@@ -218,6 +880,9 @@ func (c *Config) resolveValueWithPriorityContext(key string, def *Definition, ct
 
 			// Run validations
 			for _, validation := range def.validations {
+				if c.keepsLivePort(key, validation, parsedValue) {
+					continue
+				}
 				if err := validation.Check(parsedValue); err != nil {
 					return parsedValue, sourceType, err
 				}
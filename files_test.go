@@ -0,0 +1,454 @@
+package commandkit
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadReader(t *testing.T) {
+	cfg := New()
+	cfg.SetDefaultPriority(PriorityFileEnvFlagDefault)
+	cfg.Define("HOST").String()
+	cfg.Define("PORT").Int64()
+
+	path := filepath.Join(t.TempDir(), "base.json")
+	writeConfigFile(t, path, `{"HOST": "file", "PORT": 80}`)
+	if err := cfg.LoadReader(strings.NewReader("HOST: piped\nPORT: 8080\n"), "yaml"); err != nil {
+		t.Fatalf("LoadReader failed: %v", err)
+	}
+	if err := cfg.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if err := cfg.LoadReader(strings.NewReader("PORT=9090"), ".env"); err != nil {
+		t.Fatalf("LoadReader failed: %v", err)
+	}
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx := NewCommandContext(nil, cfg, "", "")
+	if host, _ := Get[string](ctx, "HOST"); host != "file" {
+		t.Errorf("HOST = %q, want the file loaded after the reader", host)
+	}
+	if port, _ := Get[int64](ctx, "PORT"); port != 9090 {
+		t.Errorf("PORT = %d, want 9090 from the last reader", port)
+	}
+
+	// Reloads replay reader data in load order
+	fresh, err := cfg.reloadFiles()
+	if err != nil {
+		t.Fatalf("reloadFiles failed: %v", err)
+	}
+	if fresh.data["HOST"] != "file" || fresh.data["PORT"] != "9090" {
+		t.Errorf("reloaded data = %v", fresh.data)
+	}
+
+	if err := cfg.LoadReader(strings.NewReader("{"), "json"); err == nil {
+		t.Error("expected a parse error")
+	}
+	if err := cfg.LoadReader(strings.NewReader(""), "xml"); err == nil || !strings.Contains(err.Error(), "unsupported config file format: xml") {
+		t.Errorf("expected an unsupported format error, got %v", err)
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFile(t, filepath.Join(dir, "10-base.yaml"), "HOST: base\nPORT: 80\n")
+	writeConfigFile(t, filepath.Join(dir, "20-override.yaml"), "PORT: 8080\n")
+	writeConfigFile(t, filepath.Join(dir, "99-ignored.json"), `{"PORT": 1}`)
+	if err := os.Mkdir(filepath.Join(dir, "30-dir.yaml"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := New()
+	cfg.SetDefaultPriority(PriorityFileEnvFlagDefault)
+	cfg.Define("HOST").String()
+	cfg.Define("PORT").Int64()
+	if err := cfg.LoadDir(dir, "*.yaml"); err != nil {
+		t.Fatalf("LoadDir failed: %v", err)
+	}
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx := NewCommandContext(nil, cfg, "", "")
+	if host, _ := Get[string](ctx, "HOST"); host != "base" {
+		t.Errorf("HOST = %q, want base", host)
+	}
+	if port, _ := Get[int64](ctx, "PORT"); port != 8080 {
+		t.Errorf("PORT = %d, want 8080 from the later file", port)
+	}
+	if len(cfg.fileConfig.files) != 2 {
+		t.Errorf("expected 2 loaded files, got %v", cfg.fileConfig.files)
+	}
+
+	if err := cfg.LoadDir(filepath.Join(dir, "missing"), "*.yaml"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected a not exist error, got %v", err)
+	}
+	if err := cfg.LoadDir(dir, "["); err == nil || !strings.Contains(err.Error(), "invalid pattern") {
+		t.Errorf("expected an invalid pattern error, got %v", err)
+	}
+}
+
+func TestLoadStandardPaths(t *testing.T) {
+	root := t.TempDir()
+	defer func(original string) { systemConfigDir = original }(systemConfigDir)
+	systemConfigDir = filepath.Join(root, "etc")
+
+	for _, dir := range []string{"etc/myapp", "home/.config/myapp", "work"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeConfigFile(t, filepath.Join(root, "etc", "myapp", "config.yaml"), "HOST: system\nPORT: 1\nTLS: true\n")
+	writeConfigFile(t, filepath.Join(root, "home", ".config", "myapp", "config.json"), `{"HOST": "user", "PORT": 2}`)
+	writeConfigFile(t, filepath.Join(root, "home", ".config", "myapp", "config.toml"), `HOST = "ignored"`)
+	work := filepath.Join(root, "work")
+	writeConfigFile(t, filepath.Join(work, "config.toml"), "PORT = 3\n")
+	t.Chdir(work)
+
+	newConfig := func(environ ...string) *Config {
+		cfg := New()
+		cfg.SetDefaultPriority(PriorityFileEnvFlagDefault)
+		cfg.WithEnviron(environ)
+		cfg.Define("HOST").String().Default("none")
+		cfg.Define("PORT").Int64()
+		cfg.Define("TLS").Bool().Default(false)
+		return cfg
+	}
+	values := func(cfg *Config) string {
+		if err := cfg.Process([]string{"app"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		ctx := NewCommandContext(nil, cfg, "", "")
+		host, _ := Get[string](ctx, "HOST")
+		port, _ := Get[int64](ctx, "PORT")
+		tls, _ := Get[bool](ctx, "TLS")
+		return fmt.Sprintf("%s %d %t", host, port, tls)
+	}
+
+	home := "HOME=" + filepath.Join(root, "home")
+	cfg := newConfig(home)
+	if err := cfg.LoadStandardPaths("myapp"); err != nil {
+		t.Fatalf("LoadStandardPaths failed: %v", err)
+	}
+	if got := values(cfg); got != "none 3 false" {
+		t.Errorf("first found = %q, want the working directory file only", got)
+	}
+
+	cfg = newConfig(home)
+	if err := cfg.LoadAllStandardPaths("myapp"); err != nil {
+		t.Fatalf("LoadAllStandardPaths failed: %v", err)
+	}
+	if got := values(cfg); got != "user 3 true" {
+		t.Errorf("all found = %q, want specific files overriding system ones", got)
+	}
+
+	// XDG_CONFIG_HOME replaces ~/.config
+	cfg = newConfig(home, "XDG_CONFIG_HOME="+filepath.Join(root, "xdg"))
+	if err := cfg.LoadAllStandardPaths("myapp"); err != nil {
+		t.Fatalf("LoadAllStandardPaths failed: %v", err)
+	}
+	if got := values(cfg); got != "system 3 true" {
+		t.Errorf("with XDG_CONFIG_HOME = %q", got)
+	}
+
+	if err := newConfig().LoadStandardPaths("other"); err != nil {
+		t.Errorf("expected no error without config files, got %v", err)
+	}
+}
+
+func TestWindowsConfigPaths(t *testing.T) {
+	root := t.TempDir()
+	defer func(original string) { configOS = original }(configOS)
+	configOS = "windows"
+
+	for _, dir := range []string{"ProgramData/myapp", "AppData/myapp", "work"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeConfigFile(t, filepath.Join(root, "ProgramData", "myapp", "config.yaml"), "HOST: system\nPORT: 1\n")
+	writeConfigFile(t, filepath.Join(root, "AppData", "myapp", "config.json"), `{"PORT": 2}`)
+	writeConfigFile(t, filepath.Join(root, "AppData", "myapp", "Service.YAML"), "HOST: service\n")
+	t.Chdir(filepath.Join(root, "work"))
+
+	newConfig := func() *Config {
+		cfg := New()
+		cfg.SetDefaultPriority(PriorityFileEnvFlagDefault)
+		cfg.WithEnviron([]string{
+			"APPDATA=" + filepath.Join(root, "AppData"),
+			"ProgramData=" + filepath.Join(root, "ProgramData"),
+			"MYAPP_CONFIG=%APPDATA%/myapp/Service.YAML",
+		})
+		cfg.Define("HOST").String()
+		cfg.Define("PORT").Int64()
+		return cfg
+	}
+
+	cfg := newConfig()
+	if err := cfg.LoadAllStandardPaths("myapp"); err != nil {
+		t.Fatalf("LoadAllStandardPaths failed: %v", err)
+	}
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if host, _ := cfg.lookupValue("HOST"); host != "system" {
+		t.Errorf("HOST = %v, want the ProgramData value", host)
+	}
+	if port, _ := cfg.lookupValue("PORT"); port != int64(2) {
+		t.Errorf("PORT = %v, want the APPDATA value", port)
+	}
+
+	// %VAR% references are expanded and extensions match in any case
+	cfg = newConfig()
+	if err := cfg.LoadFromEnv("MYAPP_CONFIG"); err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if host, _ := cfg.lookupValue("HOST"); host != "service" {
+		t.Errorf("HOST = %v, want the file named by MYAPP_CONFIG", host)
+	}
+
+	if got := cfg.expandConfigPath("%UNDEFINED%/50%/%APPDATA%"); got != "%UNDEFINED%/50%/"+filepath.Join(root, "AppData") {
+		t.Errorf("expandConfigPath = %q", got)
+	}
+}
+
+func TestFileListConversion(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFile(t, filepath.Join(dir, "config.json"), `{
+		"LIMITS": [1000000, 2, 3],
+		"RATIOS": [1, 0.25],
+		"NAMES": ["Smith, John", "Doe, Jane", 7, true],
+		"FLAGS": [true, "false"],
+		"NESTED": [[1, 2]],
+		"WORDS": [["a", "b"]]
+	}`)
+
+	cfg := New()
+	cfg.SetDefaultPriority(PriorityFileEnvFlagDefault)
+	cfg.Define("LIMITS").Int64Slice()
+	cfg.Define("RATIOS").Float64Slice()
+	cfg.Define("NAMES").StringSlice()
+	cfg.Define("FLAGS").BoolSlice()
+	if err := cfg.LoadFile(filepath.Join(dir, "config.json")); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]any{
+		"LIMITS": []int64{1000000, 2, 3},
+		"RATIOS": []float64{1, 0.25},
+		"NAMES":  []string{"Smith, John", "Doe, Jane", "7", "true"},
+		"FLAGS":  []bool{true, false},
+	}
+	for key, expected := range want {
+		if value, _ := cfg.lookupValue(key); !reflect.DeepEqual(value, expected) {
+			t.Errorf("%s = %#v, want %#v", key, value, expected)
+		}
+	}
+
+	nested := New()
+	nested.SetDefaultPriority(PriorityFileEnvFlagDefault)
+	nested.Define("NESTED").IntSlice()
+	nested.LoadFile(filepath.Join(dir, "config.json"))
+	if err := nested.Process([]string{"app"}); err == nil {
+		t.Error("expected nested arrays to be rejected")
+	}
+
+	// String slices are converted item by item too
+	words := New()
+	words.SetDefaultPriority(PriorityFileEnvFlagDefault)
+	words.Define("WORDS").StringSlice()
+	words.LoadFile(filepath.Join(dir, "config.json"))
+	if err := words.Process([]string{"app"}); err == nil {
+		t.Error("expected nested arrays to be rejected for string slices")
+	}
+}
+
+func TestIncludeDirective(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "conf.d"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeConfigFile(t, filepath.Join(dir, "config.yaml"), "include:\n  - db.yaml\n  - conf.d/*.json\nHOST: main\n")
+	writeConfigFile(t, filepath.Join(dir, "db.yaml"), "includes: auth.toml\nHOST: db\nDATABASE_URL: postgres://db/app\n")
+	writeConfigFile(t, filepath.Join(dir, "auth.toml"), "TOKEN_TTL = \"1h\"\n")
+	writeConfigFile(t, filepath.Join(dir, "conf.d", "10-port.json"), `{"PORT": 8080}`)
+
+	cfg := New()
+	cfg.SetDefaultPriority(PriorityFileEnvFlagDefault)
+	cfg.Define("HOST").String()
+	cfg.Define("DATABASE_URL").String()
+	cfg.Define("TOKEN_TTL").Duration()
+	cfg.Define("PORT").Int64()
+	if err := cfg.LoadFile(filepath.Join(dir, "config.yaml")); err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx := NewCommandContext(nil, cfg, "", "")
+	if host, _ := Get[string](ctx, "HOST"); host != "main" {
+		t.Errorf("HOST = %q, the including file must override its includes", host)
+	}
+	if url, _ := Get[string](ctx, "DATABASE_URL"); url != "postgres://db/app" {
+		t.Errorf("DATABASE_URL = %q", url)
+	}
+	if ttl, _ := Get[time.Duration](ctx, "TOKEN_TTL"); ttl != time.Hour {
+		t.Errorf("TOKEN_TTL = %v, want the nested include", ttl)
+	}
+	if port, _ := Get[int64](ctx, "PORT"); port != 8080 {
+		t.Errorf("PORT = %d, want the glob include", port)
+	}
+	if _, exists := cfg.fileConfig.data["include"]; exists {
+		t.Error("include keys must not be kept as values")
+	}
+	if len(cfg.fileConfig.included) != 3 {
+		t.Errorf("included = %v, want the three included files", cfg.fileConfig.included)
+	}
+
+	// Reloads follow includes again
+	writeConfigFile(t, filepath.Join(dir, "db.yaml"), "DATABASE_URL: postgres://replica/app\n")
+	fresh, err := cfg.reloadFiles()
+	if err != nil {
+		t.Fatalf("reloadFiles failed: %v", err)
+	}
+	if fresh.data["DATABASE_URL"] != "postgres://replica/app" || fresh.data["TOKEN_TTL"] != nil {
+		t.Errorf("reloaded data = %v", fresh.data)
+	}
+
+	writeConfigFile(t, filepath.Join(dir, "a.yaml"), "include: b.yaml\n")
+	writeConfigFile(t, filepath.Join(dir, "b.yaml"), "include: [a.yaml]\n")
+	if err := New().LoadFile(filepath.Join(dir, "a.yaml")); err == nil || !strings.Contains(err.Error(), "include cycle") {
+		t.Errorf("expected an include cycle error, got %v", err)
+	}
+	writeConfigFile(t, filepath.Join(dir, "bad.yaml"), "include: 3\n")
+	if err := New().LoadFile(filepath.Join(dir, "bad.yaml")); err == nil || !strings.Contains(err.Error(), "invalid include") {
+		t.Errorf("expected an invalid include error, got %v", err)
+	}
+}
+
+func TestMergeStrategy(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.yaml")
+	local := filepath.Join(dir, "local.yaml")
+	writeConfigFile(t, base, "database:\n  host: db.internal\n  pool:\n    min: 2\n    max: 10\n")
+	writeConfigFile(t, local, "database:\n  pool:\n    max: 50\n")
+
+	load := func(strategy MergeStrategy) *Config {
+		cfg := New()
+		cfg.SetDefaultPriority(PriorityFileEnvFlagDefault)
+		cfg.SetMergeStrategy(strategy)
+		cfg.Define("database.host").String().Default("localhost")
+		cfg.Define("database.pool.min").Int64().Default(1)
+		cfg.Define("database.pool.max").Int64()
+		if err := cfg.LoadFiles(base, local); err != nil {
+			t.Fatalf("LoadFiles failed: %v", err)
+		}
+		if err := cfg.Process([]string{"app"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return cfg
+	}
+
+	cfg := load(MergeDeep)
+	ctx := NewCommandContext(nil, cfg, "", "")
+	if host, _ := Get[string](ctx, "database.host"); host != "db.internal" {
+		t.Errorf("database.host = %q, want the sibling kept from base.yaml", host)
+	}
+	if poolMin, _ := Get[int64](ctx, "database.pool.min"); poolMin != 2 {
+		t.Errorf("database.pool.min = %d, want 2", poolMin)
+	}
+	if poolMax, _ := Get[int64](ctx, "database.pool.max"); poolMax != 50 {
+		t.Errorf("database.pool.max = %d, want 50 from local.yaml", poolMax)
+	}
+	if origin := cfg.fileOrigin("database.pool.max", cfg.definitions["database.pool.max"]); origin != local {
+		t.Errorf("origin of database.pool.max = %q, want %q", origin, local)
+	}
+	if origin := cfg.fileOrigin("database.host", cfg.definitions["database.host"]); origin != base {
+		t.Errorf("origin of database.host = %q, want %q", origin, base)
+	}
+
+	cfg = load(MergeReplace)
+	ctx = NewCommandContext(nil, cfg, "", "")
+	if host, _ := Get[string](ctx, "database.host"); host != "localhost" {
+		t.Errorf("database.host = %q, want the default after the table was replaced", host)
+	}
+	if poolMax, _ := Get[int64](ctx, "database.pool.max"); poolMax != 50 {
+		t.Errorf("database.pool.max = %d, want 50", poolMax)
+	}
+}
+
+func TestSliceMerge(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.yaml")
+	local := filepath.Join(dir, "local.yaml")
+	writeConfigFile(t, base, "cors_origins: [a.example, b.example]\nhosts: [db1]\nhttp:\n  methods: [GET]\n")
+	writeConfigFile(t, local, "cors_origins: [b.example, c.example]\nhosts: [db2]\nhttp:\n  methods: [GET, POST]\n")
+
+	load := func(configure func(*Config)) *CommandContext {
+		cfg := New()
+		cfg.SetDefaultPriority(PriorityFileEnvFlagDefault)
+		cfg.Define("CORS_ORIGINS").StringSlice().File("cors_origins").MergeSlices(SliceUnion)
+		cfg.Define("HOSTS").StringSlice().File("hosts")
+		cfg.Define("http.methods").StringSlice()
+		configure(cfg)
+		if err := cfg.LoadFiles(base, local); err != nil {
+			t.Fatalf("LoadFiles failed: %v", err)
+		}
+		if err := cfg.Process([]string{"app"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return NewCommandContext(nil, cfg, "", "")
+	}
+	check := func(ctx *CommandContext, key string, want []string) {
+		t.Helper()
+		if got, _ := Get[[]string](ctx, key); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s = %v, want %v", key, got, want)
+		}
+	}
+
+	ctx := load(func(*Config) {})
+	check(ctx, "CORS_ORIGINS", []string{"a.example", "b.example", "c.example"})
+	check(ctx, "HOSTS", []string{"db2"})
+	check(ctx, "http.methods", []string{"GET", "POST"})
+
+	ctx = load(func(cfg *Config) { cfg.SetSliceMerge(SliceAppend) })
+	check(ctx, "CORS_ORIGINS", []string{"a.example", "b.example", "c.example"})
+	check(ctx, "HOSTS", []string{"db1", "db2"})
+	check(ctx, "http.methods", []string{"GET", "GET", "POST"})
+
+	ctx = load(func(cfg *Config) { cfg.SetSliceMerge(SliceAppend).SetMergeStrategy(MergeReplace) })
+	check(ctx, "HOSTS", []string{"db1", "db2"})
+	check(ctx, "http.methods", []string{"GET", "POST"})
+
+	// The merge is resolved when values are looked up, not when files are loaded
+	cfg := New()
+	cfg.SetDefaultPriority(PriorityFileEnvFlagDefault)
+	if err := cfg.LoadFiles(base, local); err != nil {
+		t.Fatalf("LoadFiles failed: %v", err)
+	}
+	cfg.SetSliceMerge(SliceAppend)
+	cfg.Define("CORS_ORIGINS").StringSlice().File("cors_origins").MergeSlices(SliceUnion)
+	cfg.Define("HOSTS").StringSlice().File("hosts")
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx = NewCommandContext(nil, cfg, "", "")
+	check(ctx, "CORS_ORIGINS", []string{"a.example", "b.example", "c.example"})
+	check(ctx, "HOSTS", []string{"db1", "db2"})
+}
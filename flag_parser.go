@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strconv"
 	"strings"
 )
 
@@ -75,7 +76,11 @@ func (fp *flagParser) parseFlags(args []string, defs map[string]*Definition, fla
 	// Create values map and register flags with correct types
 	values := make(map[string]*string)
 	for key, def := range defs {
-		if def.flag != "" {
+		if def.counter {
+			value := ""
+			flagSet.Var(&countValue{target: &value}, def.flag, def.description)
+			values[key] = &value
+		} else if def.flag != "" {
 			// Use string values for all flags to maintain consistency
 			// The type conversion will happen during config processing
 			values[key] = flagSet.String(def.flag, "", def.description)
@@ -100,6 +105,44 @@ func (fp *flagParser) parseFlags(args []string, defs map[string]*Definition, fla
 	return result, nil
 }
 
+// countValue is a boolean-style flag.Value that counts how many times it was set.
+// The running total is written to target as a string so it flows through the
+// same conversion path as every other flag value.
+type countValue struct {
+	count  int64
+	target *string
+}
+
+func (cv *countValue) String() string {
+	if cv == nil || cv.target == nil {
+		return ""
+	}
+	return *cv.target
+}
+
+// Set increments the count for "-v", and accepts "-v=false" or "-v=N" to reset or set it
+func (cv *countValue) Set(value string) error {
+	switch value {
+	case "true":
+		cv.count++
+	case "false":
+		cv.count = 0
+	default:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || n < 0 {
+			return fmt.Errorf("expected a non-negative count, got %q", value)
+		}
+		cv.count = n
+	}
+	*cv.target = strconv.FormatInt(cv.count, 10)
+	return nil
+}
+
+// IsBoolFlag lets the flag be given without a value
+func (cv *countValue) IsBoolFlag() bool {
+	return true
+}
+
 // GenerateHelp generates consistent help text for flags
 func (fp *flagParser) GenerateHelp(defs map[string]*Definition) string {
 	var sb strings.Builder
@@ -112,7 +155,9 @@ func (fp *flagParser) GenerateHelp(defs map[string]*Definition) string {
 
 	// Register flags with enhanced descriptions
 	for _, def := range defs {
-		if def.flag != "" {
+		if def.counter {
+			flagSet.Var(&countValue{target: new(string)}, def.flag, fp.generateEnhancedDescription(def))
+		} else if def.flag != "" {
 			enhancedDescription := fp.generateEnhancedDescription(def)
 			flagSet.String(def.flag, "", enhancedDescription)
 		}
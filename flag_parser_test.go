@@ -317,3 +317,75 @@ func TestParsedFlags_Structure(t *testing.T) {
 		t.Error("ParsedFlags.Args should not be nil")
 	}
 }
+
+func TestFlagParser_CountFlag(t *testing.T) {
+	flagParser := newFlagParser()
+
+	defs := map[string]*Definition{
+		"verbosity": {
+			key:       "verbosity",
+			valueType: TypeInt64,
+			flag:      "v",
+			counter:   true,
+		},
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+		wantErr  bool
+	}{
+		{name: "not given", args: []string{}, expected: ""},
+		{name: "single", args: []string{"-v"}, expected: "1"},
+		{name: "repeated", args: []string{"-v", "-v", "-v"}, expected: "3"},
+		{name: "explicit count", args: []string{"-v=4"}, expected: "4"},
+		{name: "explicit then repeated", args: []string{"-v=2", "-v"}, expected: "3"},
+		{name: "reset", args: []string{"-v", "-v=false"}, expected: "0"},
+		{name: "keeps positional args", args: []string{"-v", "file.txt"}, expected: "1"},
+		{name: "invalid count", args: []string{"-v=lots"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsedFlags, err := flagParser.ParseCommand(tt.args, defs)
+			if err != nil {
+				t.Fatalf("ParseCommand() error = %v", err)
+			}
+			if tt.wantErr {
+				if len(parsedFlags.Errors) == 0 {
+					t.Error("Expected parse error for invalid count")
+				}
+				return
+			}
+			if len(parsedFlags.Errors) > 0 {
+				t.Fatalf("Unexpected parse errors: %v", parsedFlags.Errors)
+			}
+			if got := *parsedFlags.Values["verbosity"]; got != tt.expected {
+				t.Errorf("Expected count %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCountFlagResolvesToInt64(t *testing.T) {
+	cfg := New()
+	cfg.Define("VERBOSITY").CountFlag("v").Default(int64(0)).Max(2)
+
+	if errs := cfg.processConfigWithContext([]string{"-v", "-v"}, nil); len(errs) > 0 {
+		t.Fatalf("Unexpected errors: %v", errs)
+	}
+	if got, _ := cfg.lookupValue("VERBOSITY"); got != int64(2) {
+		t.Errorf("Expected verbosity 2, got %v", got)
+	}
+
+	cfg = New()
+	cfg.Define("VERBOSITY").CountFlag("v").Default(int64(0)).Max(2)
+	if errs := cfg.processConfigWithContext([]string{"-v", "-v", "-v"}, nil); len(errs) == 0 {
+		t.Error("Expected max validation to apply to the counted value")
+	}
+
+	if display := buildDefinitionDisplay(cfg.definitions["VERBOSITY"]); !strings.Contains(display, "--v") || !strings.Contains(display, "repeatable") {
+		t.Errorf("Expected count flag display to mark the flag repeatable, got %q", display)
+	}
+}
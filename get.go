@@ -4,15 +4,21 @@ package commandkit
 import (
 	"fmt"
 	"log"
+	"maps"
+	"math"
 	"net"
+	"net/mail"
+	"net/url"
 	"os"
 	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"golang.org/x/text/language"
 )
 
 // typeCache provides performance optimization for type descriptions
@@ -81,6 +87,8 @@ func getTypeDescription(v any) string {
 		return "[]float64"
 	case []bool:
 		return "[]bool"
+	case []time.Duration:
+		return "[]time.Duration"
 	case os.FileMode:
 		return "os.FileMode"
 	case net.IP:
@@ -115,28 +123,42 @@ func convertValue(value any, targetType reflect.Type) (any, error) {
 		return os.FileMode(sourceValue.Int()), nil
 	}
 
-	// Handle int to unsigned integer conversions (but not for specific types like FileMode)
-	switch targetType.Kind() {
-	case reflect.Uint:
-		if sourceType.Kind() == reflect.Int {
-			return uint(sourceValue.Int()), nil
-		}
-	case reflect.Uint8:
-		if sourceType.Kind() == reflect.Int {
-			return uint8(sourceValue.Int()), nil
-		}
-	case reflect.Uint16:
-		if sourceType.Kind() == reflect.Int {
-			return uint16(sourceValue.Int()), nil
+	// Convert between the built-in integer types (e.g. int64 values read with Get[int]),
+	// rejecting values the target cannot hold
+	if isIntegerKind(sourceType.Kind()) && isIntegerKind(targetType.Kind()) &&
+		sourceType.PkgPath() == "" && targetType.PkgPath() == "" {
+		return convertInteger(sourceValue, targetType)
+	}
+
+	// Handle string to *mail.Address conversion
+	if text, ok := value.(string); ok && targetType == reflect.TypeOf((*mail.Address)(nil)) {
+		address, err := mail.ParseAddress(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid email address: %s", text)
 		}
-	case reflect.Uint32:
-		if sourceType.Kind() == reflect.Int {
-			return uint32(sourceValue.Int()), nil
+		return address, nil
+	}
+
+	// Handle string to *url.URL conversion, parsed afresh so callers may modify it
+	if raw, ok := value.(string); ok && targetType == reflect.TypeOf((*url.URL)(nil)) {
+		parsed, err := url.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid URL: %s", redactRawURL(raw))
 		}
-	case reflect.Uint64:
-		if sourceType.Kind() == reflect.Int {
-			return uint64(sourceValue.Int()), nil
+		return parsed, nil
+	}
+
+	// Handle URL list to []*url.URL conversion
+	if urls, ok := value.([]string); ok && targetType == reflect.TypeOf([]*url.URL(nil)) {
+		result := make([]*url.URL, len(urls))
+		for i, raw := range urls {
+			parsed, err := url.Parse(raw)
+			if err != nil {
+				return nil, fmt.Errorf("invalid URL: %s", redactRawURL(raw))
+			}
+			result[i] = parsed
 		}
+		return result, nil
 	}
 
 	// FileMode is now stored directly as os.FileMode, no conversion needed
@@ -168,6 +190,31 @@ func convertValue(value any, targetType reflect.Type) (any, error) {
 		return parsed, nil
 	}
 
+	// Handle *time.Location to string conversion, giving the zone name
+	if loc, ok := value.(*time.Location); ok && targetType == reflect.TypeOf("") {
+		return loc.String(), nil
+	}
+
+	// Handle HostPort to string conversion, in host:port form
+	if hp, ok := value.(HostPort); ok && targetType == reflect.TypeOf("") {
+		return hp.String(), nil
+	}
+
+	// Handle language.Tag to string conversion, in canonical form
+	if tag, ok := value.(language.Tag); ok && targetType == reflect.TypeOf("") {
+		return tag.String(), nil
+	}
+
+	// Handle *regexp.Regexp to string conversion, giving the pattern
+	if re, ok := value.(*regexp.Regexp); ok && targetType == reflect.TypeOf("") {
+		return re.String(), nil
+	}
+
+	// Handle uuid.UUID to string conversion, in normalized lowercase form
+	if id, ok := value.(uuid.UUID); ok && targetType == reflect.TypeOf("") {
+		return id.String(), nil
+	}
+
 	// Handle path expansion for string defaults
 	if sourceType == reflect.TypeOf("") && sourceValue.String() != "" {
 		pathStr := sourceValue.String()
@@ -184,6 +231,51 @@ func convertValue(value any, targetType reflect.Type) (any, error) {
 	return value, nil
 }
 
+// isIntegerKind reports whether kind is a signed or unsigned integer kind
+func isIntegerKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	default:
+		return false
+	}
+}
+
+// convertInteger converts an integer to another integer type, failing on overflow
+func convertInteger(value reflect.Value, targetType reflect.Type) (any, error) {
+	result := reflect.New(targetType).Elem()
+	signed := value.CanInt()
+
+	switch {
+	case result.CanInt():
+		if signed {
+			if result.OverflowInt(value.Int()) {
+				return nil, fmt.Errorf("value %d overflows %s", value.Int(), targetType)
+			}
+			result.SetInt(value.Int())
+		} else {
+			if value.Uint() > math.MaxInt64 || result.OverflowInt(int64(value.Uint())) {
+				return nil, fmt.Errorf("value %d overflows %s", value.Uint(), targetType)
+			}
+			result.SetInt(int64(value.Uint()))
+		}
+	default:
+		if signed {
+			if value.Int() < 0 || result.OverflowUint(uint64(value.Int())) {
+				return nil, fmt.Errorf("value %d overflows %s", value.Int(), targetType)
+			}
+			result.SetUint(uint64(value.Int()))
+		} else {
+			if result.OverflowUint(value.Uint()) {
+				return nil, fmt.Errorf("value %d overflows %s", value.Uint(), targetType)
+			}
+			result.SetUint(value.Uint())
+		}
+	}
+	return result.Interface(), nil
+}
+
 // logWarningForDesigner logs warnings for CLI designers about configuration issues
 func logWarningForDesigner(message string) {
 	// Use log package with a distinct prefix for designer warnings
@@ -212,17 +304,9 @@ func getErrorDisplayName(err GetError, c *Config) string {
 func Get[T any](ctx *CommandContext, key string) (T, error) {
 	var zero T
 
-	// Determine which config to use (command config takes precedence)
-	var c *Config
-	if ctx.CommandConfig != nil {
-		// Check if the key is defined in command config
-		if _, hasDef := ctx.CommandConfig.definitions[key]; hasDef {
-			c = ctx.CommandConfig
-		} else {
-			// Key not in command config, check global config
-			c = ctx.GlobalConfig
-		}
-	} else {
+	// Command definitions override the global ones they layer over
+	c := getConfig(ctx).layerFor(key)
+	if _, defined := c.definitions[key]; !defined {
 		c = ctx.GlobalConfig
 	}
 
@@ -235,7 +319,7 @@ func Get[T any](ctx *CommandContext, key string) (T, error) {
 		return zero, result.Error
 	}
 
-	value, exists := c.values[key]
+	value, exists := c.lookupValue(key)
 	if !exists {
 		// Check if this is required data - if so, return validation error
 		if hasDef && def.required {
@@ -327,6 +411,25 @@ func Get[T any](ctx *CommandContext, key string) (T, error) {
 	return zero, newTypeError[T](key, value)
 }
 
+// GetStringMap retrieves a StringMap value as a copy the caller may modify
+func GetStringMap(ctx *CommandContext, key string) (map[string]string, error) {
+	value, err := Get[map[string]string](ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return maps.Clone(value), nil
+}
+
+// GetURL retrieves a URL value parsed into a *url.URL the caller may modify
+func GetURL(ctx *CommandContext, key string) (*url.URL, error) {
+	return Get[*url.URL](ctx, key)
+}
+
+// GetRegexp retrieves a Regexp value, compiled when the configuration was processed
+func GetRegexp(ctx *CommandContext, key string) (*regexp.Regexp, error) {
+	return Get[*regexp.Regexp](ctx, key)
+}
+
 // MustGet retrieves a configuration value and panics on error
 // Use when you expect the configuration to be valid and want to fail fast
 func MustGet[T any](ctx *CommandContext, key string) T {
@@ -340,22 +443,50 @@ func MustGet[T any](ctx *CommandContext, key string) T {
 // Has checks if a key exists and has a non-nil value
 // Note: This function will return false for secret keys to prevent exposure
 func (c *Config) Has(key string) bool {
+	c = c.layerFor(key)
+
 	// Check if this is a secret key - if so, always return false to prevent exposure
 	if def, hasDef := c.definitions[key]; hasDef && def.secret {
 		return false
 	}
 
-	value, exists := c.values[key]
+	value, exists := c.lookupValue(key)
 	return exists && value != nil
 }
 
+// layerFor returns the config defining key: c itself or, for a command config,
+// the global config it layers over. Keys nobody defines stay on c.
+func (c *Config) layerFor(key string) *Config {
+	for layer := c; layer != nil; layer = layer.parent {
+		if _, defined := layer.definitions[key]; defined {
+			return layer
+		}
+	}
+	return c
+}
+
+// lookupValue reads a processed value, guarding against a concurrent reload swap
+func (c *Config) lookupValue(key string) (any, bool) {
+	c = c.layerFor(key)
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	value, exists := c.values[key]
+	return value, exists
+}
+
 // GetSecret retrieves a secret value securely
 func (c *Config) GetSecret(key string) *Secret {
+	c = c.layerFor(key)
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.secrets.Get(key)
 }
 
 // HasSecret checks if a secret exists and is set
 func (c *Config) HasSecret(key string) bool {
+	c = c.layerFor(key)
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.secrets.Has(key)
 }
 
@@ -211,3 +211,43 @@ func TestDisplayGetErrorsAndExit(t *testing.T) {
 		t.Error("Expected templated output to contain PORT validation error")
 	}
 }
+
+func TestIntegerTypes(t *testing.T) {
+	cfg := New()
+	cfg.Define("WORKERS").Int64().Default(8)
+	cfg.Define("OFFSET").Int64().Default(-1)
+	cfg.Define("BUFFER").Int32().Flag("buffer")
+	cfg.Define("LIMIT").Uint64().Flag("limit")
+
+	if err := cfg.Process([]string{"app", "--buffer", "4096", "--limit", "18446744073709551615"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := NewCommandContext([]string{}, cfg, "", "")
+
+	if workers := MustGet[int](ctx, "WORKERS"); workers != 8 {
+		t.Errorf("Get[int] = %d, want 8", workers)
+	}
+	if workers := MustGet[uint16](ctx, "WORKERS"); workers != 8 {
+		t.Errorf("Get[uint16] = %d, want 8", workers)
+	}
+	if buffer := MustGet[int32](ctx, "BUFFER"); buffer != 4096 {
+		t.Errorf("Get[int32] = %d, want 4096", buffer)
+	}
+	if limit := MustGet[uint64](ctx, "LIMIT"); limit != 1<<64-1 {
+		t.Errorf("Get[uint64] = %d, want the maximum uint64", limit)
+	}
+
+	// Values the requested type cannot hold are rejected instead of wrapping
+	if _, err := Get[uint](ctx, "OFFSET"); err == nil {
+		t.Error("expected an error reading a negative value as uint")
+	}
+	if _, err := Get[int64](ctx, "LIMIT"); err == nil {
+		t.Error("expected an error reading the maximum uint64 as int64")
+	}
+
+	bad := New()
+	bad.Define("BUFFER").Int32().Flag("buffer")
+	if err := bad.Process([]string{"app", "--buffer", "3000000000"}); err == nil {
+		t.Error("expected an out of range int32 to be rejected")
+	}
+}
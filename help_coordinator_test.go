@@ -169,3 +169,47 @@ func TestStringHelpOutput(t *testing.T) {
 		t.Errorf("Expected 'test output more', got '%s'", result)
 	}
 }
+
+func TestHelpCoordinator_CommandHelpShowsInheritedGlobals(t *testing.T) {
+	config := New()
+	config.Define("LOG_LEVEL").String().Flag("log-level").Default("info").Description("Logging level")
+	config.Define("DATABASE_URL").String().Env("DATABASE_URL").Required().Description("Database connection")
+	config.Define("TIMEOUT").Duration().Flag("timeout").Description("Global timeout")
+
+	server := config.Command("server").ShortHelp("Server commands").Config(func(cc *CommandConfig) {
+		cc.Define("TIMEOUT").Duration().Flag("timeout").Default("5s").Description("Server timeout")
+	})
+	server.SubCommand("start").ShortHelp("Start the server").Config(func(cc *CommandConfig) {
+		cc.Define("PORT").Int64().Flag("port").Description("Port to listen on")
+		cc.Define("WORKERS").Int64().Env("WORKERS").Description("Worker count")
+	})
+
+	stringOutput := &StringHelpOutput{}
+	helpService := config.getHelpService()
+	helpService.SetOutput(stringOutput)
+
+	if err := helpService.ShowHelpUnified("server", "", false, []GetError{}, config.commands); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	output := stringOutput.Get()
+
+	flagsSection, globalSection, found := strings.Cut(output, "Global Options:")
+	if !found {
+		t.Fatalf("Expected a Global Options section, got:\n%s", output)
+	}
+	if !strings.Contains(flagsSection, "Server timeout") {
+		t.Error("Expected the command's own redefinition of TIMEOUT in its flags section")
+	}
+	if !strings.Contains(globalSection, "--log-level") || !strings.Contains(globalSection, "DATABASE_URL") {
+		t.Errorf("Expected inherited flag and env var under Global Options, got:\n%s", globalSection)
+	}
+	if strings.Contains(globalSection, "Global timeout") {
+		t.Error("Expected globals redefined by the command to be left out of Global Options")
+	}
+	if strings.Contains(flagsSection, "--log-level") {
+		t.Error("Expected inherited globals to be listed only under Global Options")
+	}
+	if !strings.Contains(output, "options: --port, WORKERS") {
+		t.Errorf("Expected subcommand option summary, got:\n%s", output)
+	}
+}
@@ -89,7 +89,8 @@ func (ue *unifiedExtractor) ExtractFlags(defs map[string]*Definition) []flagInfo
 			Key:         key,
 			Name:        def.flag,
 			Description: def.description,
-			Type:        def.valueType.String(),
+			Example:     def.example,
+			Type:        def.typeName(),
 			Required:    def.required,
 			Default:     def.defaultValue,
 			EnvVar:      def.envVar,
@@ -135,7 +136,8 @@ func (ue *unifiedExtractor) ExtractEnvVars(defs map[string]*Definition, mode hel
 			Key:         key,
 			Name:        def.flag,
 			Description: def.description,
-			Type:        def.valueType.String(),
+			Example:     def.example,
+			Type:        def.typeName(),
 			Required:    def.required,
 			Default:     def.defaultValue,
 			EnvVar:      def.envVar,
@@ -182,6 +184,11 @@ func (ue *unifiedExtractor) FilterEnvVars(envVars []flagInfo, mode helpMode) []f
 
 // ExtractSubcommands extracts subcommand information
 func (ue *unifiedExtractor) ExtractSubcommands(cmd *Command) []subcommandInfo {
+	return ue.extractSubcommands(cmd, nil)
+}
+
+// extractSubcommands extracts subcommand information, leaving inherited globals out of the option summaries
+func (ue *unifiedExtractor) extractSubcommands(cmd *Command, globals map[string]*Definition) []subcommandInfo {
 	if cmd == nil || len(cmd.SubCommands) == 0 {
 		return []subcommandInfo{}
 	}
@@ -206,12 +213,31 @@ func (ue *unifiedExtractor) ExtractSubcommands(cmd *Command) []subcommandInfo {
 			Name:        name,
 			Description: desc,
 			Aliases:     subCmd.Aliases,
+			Options:     ue.extractOptionNames(subCmd.Definitions, globals),
 		})
 	}
 
 	return subcommands
 }
 
+// extractOptionNames lists the options of a definition set for one-line summaries.
+// Flags are shown as --name; environment-only options use their variable name.
+func (ue *unifiedExtractor) extractOptionNames(defs, globals map[string]*Definition) []string {
+	var names []string
+	for key, def := range defs {
+		if globals[key] == def {
+			continue // Inherited global, listed under Global Options
+		}
+		if def.flag != "" {
+			names = append(names, "--"+def.flag)
+		} else if def.envVar != "" {
+			names = append(names, def.envVar)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
 // buildUsage builds the usage string for a command
 func (ue *unifiedExtractor) buildUsage(cmd *Command) string {
 	if cmd == nil {
@@ -269,6 +295,11 @@ func (ue *unifiedExtractor) extractCommandsData(commands map[string]*Command, ex
 		}
 	}
 
+	// Sort commands for consistent display
+	sort.Slice(commandSummaries, func(i, j int) bool {
+		return commandSummaries[i].Name < commandSummaries[j].Name
+	})
+
 	return &commandsData{
 		commands:   commandSummaries,
 		executable: executable,
@@ -287,6 +318,40 @@ func (ue *unifiedExtractor) extractFlagsData(cmd *Command) *flagsData {
 	}
 }
 
+// splitInheritedDefinitions separates a command's own definitions from the global ones it inherits.
+// Command.Config copies global definitions into the command, so a definition is inherited when
+// the command holds the very same *Definition (or none at all) for a global key; a redefinition
+// by the command belongs to the command.
+func (ue *unifiedExtractor) splitInheritedDefinitions(defs, globals map[string]*Definition) (own, inherited map[string]*Definition) {
+	own = make(map[string]*Definition, len(defs))
+	inherited = make(map[string]*Definition)
+	for key, def := range defs {
+		if globals[key] != def {
+			own[key] = def
+		}
+	}
+	for key, def := range globals {
+		if _, redefined := own[key]; !redefined {
+			inherited[key] = def
+		}
+	}
+	return own, inherited
+}
+
+// extractGlobalFlagsData extracts inherited global options layer data
+func (ue *unifiedExtractor) extractGlobalFlagsData(inherited map[string]*Definition, mode helpMode) *globalFlagsData {
+	flags := ue.ExtractFlags(inherited)
+	for _, envVar := range ue.FilterEnvVars(ue.ExtractEnvVars(inherited, mode), mode) {
+		if envVar.NoFlag {
+			flags = append(flags, envVar)
+		}
+	}
+
+	return &globalFlagsData{
+		flags: flags,
+	}
+}
+
 // extractEnvVarsData extracts environment variables layer data
 func (ue *unifiedExtractor) extractEnvVarsData(cmd *Command, mode helpMode) *envVarsData {
 	if cmd == nil {
@@ -303,12 +368,12 @@ func (ue *unifiedExtractor) extractEnvVarsData(cmd *Command, mode helpMode) *env
 }
 
 // extractSubcommandsData extracts subcommands layer data
-func (ue *unifiedExtractor) extractSubcommandsData(cmd *Command) *subcommandsData {
+func (ue *unifiedExtractor) extractSubcommandsData(cmd *Command, globals map[string]*Definition) *subcommandsData {
 	if cmd == nil {
 		return &subcommandsData{}
 	}
 
-	subcommands := ue.ExtractSubcommands(cmd)
+	subcommands := ue.extractSubcommands(cmd, globals)
 	return &subcommandsData{
 		subcommands: subcommands,
 	}
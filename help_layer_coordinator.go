@@ -15,6 +15,8 @@ type helpCoordinator struct {
 	output     helpOutput
 	executable string
 	extractor  *unifiedExtractor
+	globals    map[string]*Definition // Global definitions inherited by every command
+	tags       []string               // Only options having one of these tags are listed, nil for all
 }
 
 // newHelpCoordinator creates a new help coordinator
@@ -75,6 +77,19 @@ func (hc *helpCoordinator) RenderFlags(data *flagsData) string {
 	return hc.executeTemplate(templateStr, templateData)
 }
 
+// RenderGlobalFlags renders the inherited global options layer using templates
+func (hc *helpCoordinator) RenderGlobalFlags(data *globalFlagsData) string {
+	templateStr := hc.templates.partials["global_flags"]
+
+	templateData := struct {
+		Flags []flagInfo
+	}{
+		Flags: data.flags,
+	}
+
+	return hc.executeTemplate(templateStr, templateData)
+}
+
 // RenderEnvVars renders the environment variables layer using templates
 func (hc *helpCoordinator) RenderEnvVars(data *envVarsData) string {
 	var templateStr string
@@ -119,6 +134,16 @@ func (hc *helpCoordinator) RenderErrors(data *errorsData) string {
 	return hc.executeTemplate(templateStr, templateData)
 }
 
+// SetGlobalDefinitions sets the global definitions shown as inherited options in command help
+func (hc *helpCoordinator) SetGlobalDefinitions(defs map[string]*Definition) {
+	hc.globals = defs
+}
+
+// SetTags stores the tag filter applied when extracting the options of the next help
+func (hc *helpCoordinator) SetTags(tags []string) {
+	hc.tags = tags
+}
+
 // SetOutput sets the help output destination
 func (hc *helpCoordinator) SetOutput(output helpOutput) {
 	hc.output = output
@@ -130,8 +155,9 @@ func (hc *helpCoordinator) TriggerHelp(ctx *CommandContext, errors []GetError) e
 		return fmt.Errorf("command context cannot be nil")
 	}
 
-	// Detect help mode from arguments
+	// Detect help mode and tag filter from arguments
 	full := argsContainFullHelp(ctx.Args)
+	hc.SetTags(helpTagsFromArgs(ctx.Args))
 
 	// Show help for the current command/subcommand context
 	return hc.ShowHelpWithCommands(ctx.Command, ctx.SubCommand, full, errors, ctx.GlobalConfig.getCommands())
@@ -177,6 +203,11 @@ func (hc *helpCoordinator) ShowHelpWithCommands(command, subcommand string, full
 				return fmt.Errorf("subcommand %s not found in command %s", subcommand, command)
 			}
 			cmd = subCmd
+			if len(parentCmd.RequiredEnv) > 0 {
+				view := *subCmd
+				view.RequiredEnv = requiredEnv(parentCmd, subCmd)
+				cmd = &view
+			}
 		} else {
 			// Command help
 			var exists bool
@@ -227,6 +258,22 @@ func (hc *helpCoordinator) renderCommandHelp(cmd *Command, command, subcommand s
 		output.WriteString("\n\n")
 	}
 
+	// Separate the command's own options from inherited globals (the root command owns the globals)
+	var inherited map[string]*Definition
+	if command != "" && len(hc.globals) > 0 {
+		view := *cmd
+		view.Definitions, inherited = hc.extractor.splitInheritedDefinitions(cmd.Definitions, hc.globals)
+		cmd = &view
+	}
+
+	// Keep the options having one of the requested tags
+	if len(hc.tags) > 0 {
+		view := *cmd
+		view.Definitions = taggedDefinitions(cmd.Definitions, hc.tags)
+		cmd = &view
+		inherited = taggedDefinitions(inherited, hc.tags)
+	}
+
 	// Flags layer
 	flagsData := hc.extractor.extractFlagsData(cmd)
 	if len(flagsData.flags) > 0 {
@@ -241,8 +288,26 @@ func (hc *helpCoordinator) renderCommandHelp(cmd *Command, command, subcommand s
 		output.WriteString("\n\n")
 	}
 
+	// Required environment layer
+	if len(cmd.RequiredEnv) > 0 {
+		output.WriteString("Required Environment:\n")
+		for _, name := range cmd.RequiredEnv {
+			output.WriteString("  " + name + "\n")
+		}
+		output.WriteString("\n")
+	}
+
+	// Global options layer
+	if len(inherited) > 0 {
+		globalFlagsData := hc.extractor.extractGlobalFlagsData(inherited, mode)
+		if len(globalFlagsData.flags) > 0 {
+			output.WriteString(hc.RenderGlobalFlags(globalFlagsData))
+			output.WriteString("\n\n")
+		}
+	}
+
 	// Subcommands layer
-	subcommandsData := hc.extractor.extractSubcommandsData(cmd)
+	subcommandsData := hc.extractor.extractSubcommandsData(cmd, hc.globals)
 	if len(subcommandsData.subcommands) > 0 {
 		output.WriteString(hc.RenderSubcommands(subcommandsData))
 	}
@@ -1,7 +1,10 @@
 // commandkit/help_models.go
 package commandkit
 
-import "slices"
+import (
+	"slices"
+	"strings"
+)
 
 // helpType represents the type of help request
 type helpType int
@@ -33,6 +36,7 @@ type flagInfo struct {
 	Key           string
 	Name          string
 	Description   string
+	Example       string
 	Type          string
 	Required      bool
 	Default       any
@@ -49,18 +53,45 @@ type subcommandInfo struct {
 	Name        string
 	Description string
 	Aliases     []string
+	Options     []string // Flag names (or env vars for env-only options) accepted by the subcommand
 }
 
 // --- Centralized help flag detection functions ---
 
-// isHelpFlag returns true if the given argument is any help flag
+// isHelpFlag returns true if the given argument is any help flag, including the
+// --help=<tags> and --full-help=<tags> forms
 func isHelpFlag(arg string) bool {
-	return arg == "--help" || arg == "-h" || arg == "help" || arg == "--full-help"
+	return arg == "--help" || arg == "-h" || arg == "help" || isFullHelpFlag(arg) || strings.HasPrefix(arg, "--help=")
+}
+
+// IsHelpRequest reports whether args hold a help flag, which commandkit answers with
+// help output instead of running the command
+func IsHelpRequest(args []string) bool {
+	return slices.ContainsFunc(args, isHelpFlag)
 }
 
 // isFullHelpFlag returns true if the given argument requests full/extended help
 func isFullHelpFlag(arg string) bool {
-	return arg == "--full-help"
+	return arg == "--full-help" || strings.HasPrefix(arg, "--full-help=")
+}
+
+// helpTagsFromArgs returns the tags of a --help=network,tuning argument, which limit
+// the options listed to the keys having one of them; nil when help is not filtered
+func helpTagsFromArgs(args []string) []string {
+	for _, arg := range args {
+		_, list, found := strings.Cut(arg, "=")
+		if !found || !isHelpFlag(arg) {
+			continue
+		}
+		var tags []string
+		for tag := range strings.SplitSeq(list, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+		return tags
+	}
+	return nil
 }
 
 // argsContainHelpFlag returns true if any argument in the slice is a help flag
@@ -107,6 +138,11 @@ type flagsData struct {
 	flags []flagInfo
 }
 
+// globalFlagsData represents data for the inherited global options layer rendering
+type globalFlagsData struct {
+	flags []flagInfo
+}
+
 // envVarsData represents data for environment variables layer rendering
 type envVarsData struct {
 	envVars []flagInfo
@@ -74,3 +74,16 @@ func TestSubcommandInfo(t *testing.T) {
 		t.Errorf("Expected subcommand name 'server', got '%s'", info.Name)
 	}
 }
+
+func TestIsHelpRequest(t *testing.T) {
+	for _, args := range [][]string{{"--help"}, {"deploy", "-h"}, {"help"}, {"--full-help=network"}, {"--help=tuning"}} {
+		if !IsHelpRequest(args) {
+			t.Errorf("IsHelpRequest(%q) = false, want true", args)
+		}
+	}
+	for _, args := range [][]string{nil, {"deploy", "--helper"}, {"--verbose"}} {
+		if IsHelpRequest(args) {
+			t.Errorf("IsHelpRequest(%q) = true, want false", args)
+		}
+	}
+}
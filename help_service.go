@@ -73,6 +73,16 @@ func (hs *helpService) SetOutput(output helpOutput) {
 	hs.coordinator.SetOutput(output)
 }
 
+// SetGlobalDefinitions sets the global definitions listed as inherited options in command help
+func (hs *helpService) SetGlobalDefinitions(defs map[string]*Definition) {
+	hs.coordinator.SetGlobalDefinitions(defs)
+}
+
+// SetTags limits the options listed to the keys having one of tags, all for none
+func (hs *helpService) SetTags(tags []string) {
+	hs.coordinator.SetTags(tags)
+}
+
 // GetOutput returns the current help output
 func (hs *helpService) GetOutput() helpOutput {
 	return hs.output
@@ -121,6 +131,7 @@ func (hs *helpService) ShowHelp(args []string, commands map[string]*Command) err
 	}
 
 	isFull := argsContainFullHelp(args)
+	hs.SetTags(helpTagsFromArgs(args))
 
 	// Find the command name by matching non-help-flag args against known commands
 	if commands != nil {
@@ -33,21 +33,30 @@ func (tc *templateComposer) registerDefaultPartials() {
 	tc.partials["flags"] = `{{if .Flags}}Flags:
 {{range .Flags}}  {{.DisplayLine}}
 {{if .Description}}        {{.Description}}
+{{end}}{{if .Example}}        Example: {{.Example}}
+{{end}}{{end}}{{end}}`
+	tc.partials["global_flags"] = `{{if .Flags}}Global Options:
+{{range .Flags}}  {{.DisplayLine}}
+{{if .Description}}        {{.Description}}
+{{end}}{{if .Example}}        Example: {{.Example}}
 {{end}}{{end}}{{end}}`
 	tc.partials["envvars_basic"] = `{{if .EnvVars}}Environment Variables:
 {{range .EnvVars}}  {{.EnvVarDisplay}}
         {{.Description}}
-{{end}}{{end}}`
+{{if .Example}}        Example: {{.Example}}
+{{end}}{{end}}{{end}}`
 	tc.partials["envvars_full"] = `{{if .EnvVars}}Environment Variables:
 {{range .EnvVars}}  {{.EnvVarDisplay}}
         {{.Description}}
-{{end}}{{end}}`
+{{if .Example}}        Example: {{.Example}}
+{{end}}{{end}}{{end}}`
 	tc.partials["errors"] = `{{if .Errors}}Configuration errors:
 {{range .Errors}}  {{.Display}} -> {{.ErrorDescription}}
 {{end}}{{end}}`
 	tc.partials["subcommands"] = `{{if .Subcommands}}Subcommands:
 {{range .Subcommands}}  {{printf "%-12s" .Name}} {{.Description}}{{if .Aliases}} (aliases: {{join .Aliases ", "}}){{end}}
-{{end}}{{end}}`
+{{if .Options}}               options: {{join .Options ", "}}
+{{end}}{{end}}{{end}}`
 	tc.partials["global_commands"] = `{{if .Commands}}Available commands:
 
 {{range .Commands}}{{if .Aliases}}  {{printf "%-12s" .Name}} (aliases: {{join .Aliases ", "}}) {{.Description}}
@@ -177,7 +186,7 @@ func (tc *templateComposer) ClearCache() {
 
 // ValidatePartials checks if all required partials are present
 func (tc *templateComposer) ValidatePartials() error {
-	required := []string{"usage", "global_usage", "description", "flags", "envvars_basic", "envvars_full", "errors", "subcommands", "global_commands"}
+	required := []string{"usage", "global_usage", "description", "flags", "global_flags", "envvars_basic", "envvars_full", "errors", "subcommands", "global_commands"}
 
 	for _, name := range required {
 		if _, exists := tc.partials[name]; !exists {
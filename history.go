@@ -0,0 +1,94 @@
+// commandkit/history.go
+package commandkit
+
+import (
+	"fmt"
+	"maps"
+	"time"
+)
+
+// defaultHistoryLimit is how many versions History keeps unless SetHistoryLimit changes it
+const defaultHistoryLimit = 10
+
+// SetHistoryLimit sets how many processed versions are kept for History and Rollback
+// (at least 1, the active one)
+func (c *Config) SetHistoryLimit(limit int) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.historyLimit = max(limit, 1)
+	c.trimHistory()
+	return c
+}
+
+// Version returns the version of the active values: 1 after the first successful
+// Process, then incremented by every reload and rollback; 0 before processing
+func (c *Config) Version() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.version
+}
+
+// History returns the retained versions, oldest first; the last one is active.
+// Secrets are not kept in the history, only the non-secret values.
+func (c *Config) History() []Snapshot {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]Snapshot(nil), c.history...)
+}
+
+// Rollback makes the values of a retained version active again, e.g. to revert a bad
+// hot reload. The restored values are committed as a new version, so versions keep
+// increasing, and OnChange callbacks and Watch channels see them like any reload.
+// Secrets keep their current values.
+func (c *Config) Rollback(version int) error {
+	c.mu.RLock()
+	var target *Snapshot
+	for i := range c.history {
+		if c.history[i].version == version {
+			target = &c.history[i]
+		}
+	}
+	secrets, fileConfig := c.secrets, c.fileConfig
+	c.mu.RUnlock()
+
+	if target == nil {
+		return fmt.Errorf("version %d is not in the configuration history", version)
+	}
+	c.commitSnapshot(&Snapshot{
+		values:     maps.Clone(target.values),
+		secrets:    secrets,
+		sources:    target.sources,
+		fileConfig: fileConfig,
+	})
+	return nil
+}
+
+// recordVersion numbers newly committed values and adds them to the history; c.mu
+// must be held
+func (c *Config) recordVersion(values map[string]any, sources map[string]SourceType) (int, time.Time) {
+	c.version++
+	loadedAt := time.Now()
+	c.loadedAt = loadedAt
+	c.history = append(c.history, Snapshot{values: values, sources: sources, version: c.version, loadedAt: loadedAt})
+	c.trimHistory()
+	return c.version, loadedAt
+}
+
+// commitSourceCache ends the startup of the source cache and persists it. It writes
+// the cache file, so it is called after c.mu is released.
+func (c *Config) commitSourceCache() {
+	if c.sourceCache != nil {
+		c.sourceCache.commit()
+	}
+}
+
+// trimHistory drops the oldest versions beyond the limit; c.mu must be held
+func (c *Config) trimHistory() {
+	limit := c.historyLimit
+	if limit == 0 {
+		limit = defaultHistoryLimit
+	}
+	if excess := len(c.history) - limit; excess > 0 {
+		c.history = append([]Snapshot(nil), c.history[excess:]...)
+	}
+}
@@ -0,0 +1,68 @@
+// commandkit/history_test.go
+package commandkit
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestHistoryAndRollback(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfigFile(t, path, "workers: 1\n")
+
+	cfg := New()
+	cfg.SetDefaultPriority(PriorityFileEnvFlagDefault)
+	cfg.SetHistoryLimit(3)
+	cfg.Define("WORKERS").Int64().File("workers")
+	if err := cfg.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if cfg.Version() != 0 {
+		t.Errorf("Version() = %d before processing", cfg.Version())
+	}
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Version() != 1 {
+		t.Errorf("Version() = %d after the first Process, want 1", cfg.Version())
+	}
+
+	for _, content := range []string{"workers: 2\n", "workers: 3\n", "workers: 4\n"} {
+		writeConfigFile(t, path, content)
+		if err := cfg.Reload(); err != nil {
+			t.Fatalf("Reload failed: %v", err)
+		}
+	}
+
+	history := cfg.History()
+	if len(history) != 3 {
+		t.Fatalf("expected the history to keep 3 versions, got %d", len(history))
+	}
+	for i, want := range []int64{2, 3, 4} {
+		if history[i].Version() != i+2 {
+			t.Errorf("history[%d] is version %d, want %d", i, history[i].Version(), i+2)
+		}
+		if workers, _ := GetFrom[int64](history[i], "WORKERS"); workers != want {
+			t.Errorf("history[%d] WORKERS = %d, want %d", i, workers, want)
+		}
+	}
+
+	var changed []any
+	cfg.OnChange("WORKERS", func(_, value any) { changed = append(changed, value) })
+	if err := cfg.Rollback(3); err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+	if workers, _ := cfg.lookupValue("WORKERS"); workers != int64(3) {
+		t.Errorf("WORKERS = %v after rolling back to version 3", workers)
+	}
+	if cfg.Version() != 5 {
+		t.Errorf("Version() = %d, a rollback must commit a new version", cfg.Version())
+	}
+	if len(changed) != 1 || changed[0] != int64(3) {
+		t.Errorf("OnChange saw %v, want [3]", changed)
+	}
+
+	if err := cfg.Rollback(1); err == nil {
+		t.Error("expected an error for a version no longer in the history")
+	}
+}
@@ -0,0 +1,33 @@
+// commandkit/hostport.go
+package commandkit
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+)
+
+// HostPort is a network address such as "db.internal:5432" or "[::1]:9000", the value
+// of HostPort() keys. An empty Host, as in ":8080", means every interface.
+type HostPort struct {
+	Host string
+	Port int
+}
+
+// String returns the address in host:port form, bracketing IPv6 hosts
+func (hp HostPort) String() string {
+	return net.JoinHostPort(hp.Host, strconv.Itoa(hp.Port))
+}
+
+// parseHostPort parses a host:port address with a numeric port from 0 to 65535
+func parseHostPort(raw string) (HostPort, error) {
+	host, portText, err := net.SplitHostPort(raw)
+	if err != nil {
+		return HostPort{}, fmt.Errorf("invalid address: %s (expected host:port)", raw)
+	}
+	port, err := strconv.Atoi(portText)
+	if err != nil || port < 0 || port > 65535 {
+		return HostPort{}, fmt.Errorf("invalid address: %s (port must be 0-65535)", raw)
+	}
+	return HostPort{Host: host, Port: port}, nil
+}
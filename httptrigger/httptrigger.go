@@ -0,0 +1,127 @@
+// Package httptrigger exposes a commandkit command tree over HTTP, so maintenance
+// commands of a running daemon can be triggered remotely.
+//
+//	mux.Handle("/commands/", httptrigger.Handler(cfg))
+//
+//	POST /commands/db/migrate
+//	{"args": ["--dry-run"]}
+//
+// Runs go through the configured global and command middleware, and authentication is
+// done there too: the triggering request is available to middleware via Request(ctx).
+//
+//	cfg.UseMiddleware(commandkit.AuthMiddleware(func(ctx *commandkit.CommandContext) error {
+//		if r := httptrigger.Request(ctx); r != nil && r.Header.Get("Authorization") != "Bearer "+token {
+//			return errors.New("invalid token")
+//		}
+//		return nil
+//	}))
+//
+// The response describes the run as JSON, including the command result. Runs are
+// serialized, since each one resolves the configuration.
+package httptrigger
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fernandezvara/commandkit"
+)
+
+// requestKey is the context data key holding the triggering request
+const requestKey = "httptrigger.request"
+
+// Request returns the HTTP request that triggered the run, or nil when the command was
+// started from the command line
+func Request(ctx *commandkit.CommandContext) *http.Request {
+	value, _ := ctx.GetData(requestKey)
+	request, _ := value.(*http.Request)
+	return request
+}
+
+// runRequest is the body of a trigger request
+type runRequest struct {
+	Args []string `json:"args"`
+}
+
+// runResponse describes a run
+type runResponse struct {
+	Command    []string `json:"command"`
+	Args       []string `json:"args"`
+	Duration   string   `json:"duration"`
+	Middleware []string `json:"middleware"`
+	Result     any      `json:"result,omitempty"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// Handler serves POST /commands/{command path}, running the command with the
+// arguments of the JSON body
+func Handler(cfg *commandkit.Config) http.Handler {
+	program := filepath.Base(os.Args[0])
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /commands/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		path := strings.Split(strings.Trim(r.PathValue("path"), "/"), "/")
+		if cmd := cfg.FindCommand(path...); cmd == nil || cmd.Func == nil {
+			writeError(w, http.StatusNotFound, "unknown command: "+strings.Join(path, " "))
+			return
+		}
+
+		var body runRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+			writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+			return
+		}
+		if commandkit.IsHelpRequest(body.Args) {
+			writeError(w, http.StatusBadRequest, "help is not available over HTTP")
+			return
+		}
+
+		args := append([]string{program}, path...)
+		args = append(args, body.Args...)
+
+		report := cfg.ExecuteReport(args, commandkit.WithContextData(requestKey, r))
+
+		response := runResponse{
+			Command:    report.Command,
+			Args:       report.Args,
+			Duration:   report.Duration.String(),
+			Middleware: report.Middleware,
+			Result:     report.Result,
+		}
+		if report.Err != nil {
+			response.Error = cfg.Redact(report.Err.Error())
+		}
+		writeJSON(w, statusFor(report.Err), response)
+	})
+	return mux
+}
+
+// statusFor maps a run error to an HTTP status
+func statusFor(err error) int {
+	var configErrs commandkit.ConfigErrors
+	switch {
+	case err == nil:
+		return http.StatusOK
+	case errors.Is(err, commandkit.ErrAuthFailed):
+		return http.StatusForbidden
+	case errors.As(err, &configErrs):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,83 @@
+package httptrigger
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fernandezvara/commandkit"
+)
+
+func newTestConfig() *commandkit.Config {
+	cfg := commandkit.New()
+	cfg.UseMiddleware(commandkit.AuthMiddleware(func(ctx *commandkit.CommandContext) error {
+		if r := Request(ctx); r != nil && r.Header.Get("Authorization") != "Bearer ops-token" {
+			return errors.New("invalid token")
+		}
+		return nil
+	}))
+
+	db := cfg.Command("db")
+	db.SubCommand("migrate").
+		Config(func(cc *commandkit.CommandConfig) {
+			cc.Define("STEPS").Int64().Flag("steps").Default(int64(1)).Range(1, 10)
+		}).
+		ResultFunc(func(ctx *commandkit.CommandContext) (any, error) {
+			steps, err := commandkit.Get[int64](ctx, "STEPS")
+			return map[string]int64{"applied": steps}, err
+		})
+	cfg.Command("fail").Func(func(ctx *commandkit.CommandContext) error {
+		return errors.New("boom")
+	})
+	return cfg
+}
+
+func TestHandler(t *testing.T) {
+	server := httptest.NewServer(Handler(newTestConfig()))
+	defer server.Close()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		body   string
+		status int
+		want   string
+	}{
+		{"runs the command", "POST", "/commands/db/migrate", "ops-token", `{"args": ["--steps", "3"]}`, 200, `"result":{"applied":3}`},
+		{"empty body", "POST", "/commands/db/migrate", "ops-token", "", 200, `"command":["db","migrate"]`},
+		{"bad token", "POST", "/commands/db/migrate", "wrong", "", 403, "authentication failed: invalid token"},
+		{"invalid config", "POST", "/commands/db/migrate", "ops-token", `{"args": ["--steps", "50"]}`, 400, `"error"`},
+		{"command error", "POST", "/commands/fail", "ops-token", "", 500, `"error":"boom"`},
+		{"unknown command", "POST", "/commands/nope", "ops-token", "", 404, "unknown command: nope"},
+		{"command group", "POST", "/commands/db", "ops-token", "", 404, "unknown command: db"},
+		{"help", "POST", "/commands/fail", "ops-token", `{"args": ["--help"]}`, 400, "help is not available"},
+		{"bad body", "POST", "/commands/fail", "ops-token", `{"args": 1}`, 400, "invalid request body"},
+		{"wrong method", "GET", "/commands/fail", "ops-token", "", 405, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, _ := http.NewRequest(tt.method, server.URL+tt.path, strings.NewReader(tt.body))
+			request.Header.Set("Authorization", "Bearer "+tt.token)
+			response, err := http.DefaultClient.Do(request)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer response.Body.Close()
+
+			var body json.RawMessage
+			json.NewDecoder(response.Body).Decode(&body)
+			if response.StatusCode != tt.status {
+				t.Errorf("status = %d, want %d (body %s)", response.StatusCode, tt.status, body)
+			}
+			if !strings.Contains(string(body), tt.want) {
+				t.Errorf("body = %s, want it to contain %s", body, tt.want)
+			}
+		})
+	}
+}
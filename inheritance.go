@@ -0,0 +1,109 @@
+// commandkit/inheritance.go
+package commandkit
+
+import "maps"
+
+// Commands see configuration in two layers: the global config, holding every global
+// definition the command does not redefine, and a command config with the command's
+// own definitions whose parent is the global config. Values and secrets are looked up
+// in the layer that defines the key, so global values stay visible inside commands and
+// to middleware, and command definitions override global ones.
+
+// inheritedDefinitions returns the global definitions cmd does not redefine.
+// Command.Config copies globals into the command, so a copy holding the very
+// same *Definition still counts as inherited.
+func (c *Config) inheritedDefinitions(cmd *Command) map[string]*Definition {
+	defs := make(map[string]*Definition, len(c.definitions))
+	for key, def := range c.definitions {
+		if own, redefined := cmd.Definitions[key]; !redefined || own == def {
+			defs[key] = def
+		}
+	}
+	return defs
+}
+
+// ownDefinitions returns the definitions cmd declares itself
+func ownDefinitions(cmd *Command, globals map[string]*Definition) map[string]*Definition {
+	defs := make(map[string]*Definition, len(cmd.Definitions))
+	for key, def := range cmd.Definitions {
+		if globals[key] != def {
+			defs[key] = def
+		}
+	}
+	return defs
+}
+
+// flagDefinitions returns every definition whose flag a command accepts: its own
+// plus the global ones, which can be given after the command name
+func flagDefinitions(cmd *Command, globals map[string]*Definition) map[string]*Definition {
+	defs := make(map[string]*Definition, len(globals)+len(cmd.Definitions))
+	for key, def := range globals {
+		defs[key] = def
+	}
+	for key, def := range cmd.Definitions {
+		defs[key] = def
+	}
+	return defs
+}
+
+// processInherited resolves the global definitions inherited by cmd into the global
+// config before the command (and global middleware) runs. Persistent flags come from
+// persistentArgs; other global flags are read from the command's arguments, where
+// unknown flags are left for the command's own parsing to report.
+func (c *Config) processInherited(cmd *Command, persistentArgs []string, ctx *CommandContext) []ConfigError {
+	defs := c.inheritedDefinitions(cmd)
+	if len(defs) == 0 {
+		return nil
+	}
+
+	flagParser := c.createServices().FlagParser
+	persistentFlags, err := flagParser.ParseCommand(persistentArgs, c.persistentDefinitions())
+	var flagErrs []error
+	if err != nil {
+		flagErrs = append(flagErrs, err)
+	}
+	flagErrs = append(flagErrs, persistentFlags.Errors...)
+	if len(flagErrs) > 0 {
+		return flagParser.ConvertFlagErrorsToConfigErrors(flagErrs, defs)
+	}
+
+	commandFlags, _ := flagParser.ParseCommand(ctx.Args, flagDefinitions(cmd, c.definitions))
+	flagValues := make(map[string]*string, len(defs))
+	for key, def := range defs {
+		if def.persistent {
+			flagValues[key] = persistentFlags.Values[key]
+		} else {
+			flagValues[key] = commandFlags.Values[key]
+		}
+	}
+
+	// Resolve into a copy of the global values that is merged back under the lock, so
+	// concurrent runs never write the map while others read it
+	c.mu.RLock()
+	values := maps.Clone(c.values)
+	c.mu.RUnlock()
+
+	inherited := c.deriveChild()
+	inherited.definitions = defs
+	inherited.values = values
+	inherited.secrets = c.secrets
+	inherited.flagValues = flagValues
+	inherited.fileConfig = c.fileConfig
+	errs := inherited.processDefinitionsWithContext(ctx)
+
+	c.mu.Lock()
+	for key := range defs {
+		if value, exists := values[key]; exists {
+			c.values[key] = value
+		}
+	}
+	// The committed map may be shared with the history, so it is replaced, not written
+	sources := maps.Clone(c.valueSources)
+	if sources == nil {
+		sources = make(map[string]SourceType, len(inherited.valueSources))
+	}
+	maps.Copy(sources, inherited.valueSources)
+	c.valueSources = sources
+	c.mu.Unlock()
+	return errs
+}
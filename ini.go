@@ -0,0 +1,84 @@
+// commandkit/ini.go
+package commandkit
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// parseINI decodes an INI document. Keys before the first section are top-level,
+// and keys in a [section] (or [section.subsection]) are nested under it, so they are
+// reached with dotted file keys such as "database.url". Lines starting with ; or #
+// are comments, keys are separated from values by = or :, and values may be quoted.
+func parseINI(data []byte) (map[string]any, error) {
+	config := make(map[string]any)
+	current := config
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == ';' || line[0] == '#' {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			name, ok := strings.CutSuffix(line, "]")
+			name = strings.TrimSpace(name[1:])
+			if !ok || name == "" {
+				return nil, fmt.Errorf("line %d: invalid section header", lineNumber)
+			}
+			section, err := iniSection(config, name)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+			}
+			current = section
+			continue
+		}
+
+		i := strings.IndexAny(line, "=:")
+		if i <= 0 {
+			return nil, fmt.Errorf("line %d: expected key = value", lineNumber)
+		}
+		key := strings.TrimSpace(line[:i])
+		current[key] = iniValue(strings.TrimSpace(line[i+1:]))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// iniSection returns the table for a dotted section name, creating it if needed
+func iniSection(config map[string]any, name string) (map[string]any, error) {
+	table := config
+	for _, part := range strings.Split(name, ".") {
+		part = strings.TrimSpace(part)
+		next, exists := table[part]
+		if !exists {
+			next = make(map[string]any)
+			table[part] = next
+		}
+		nested, ok := next.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("section %s conflicts with key %s", name, part)
+		}
+		table = nested
+	}
+	return table, nil
+}
+
+// iniValue strips matching quotes, or an inline ; or # comment from unquoted values
+func iniValue(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	for _, marker := range []string{" ;", " #"} {
+		if i := strings.Index(value, marker); i >= 0 {
+			value = strings.TrimSpace(value[:i])
+		}
+	}
+	return value
+}
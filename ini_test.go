@@ -0,0 +1,79 @@
+package commandkit
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseINI(t *testing.T) {
+	data := `
+; global settings
+name = my-app
+debug: true
+
+[database]
+url = "postgres://db/app ; not a comment"
+pool = 10 ; inline comment
+
+[database.replica]
+url = postgres://replica/app
+
+# per-environment overrides
+[environments.production]
+name = my-app-prod
+`
+	got, err := parseINI([]byte(data))
+	if err != nil {
+		t.Fatalf("parseINI failed: %v", err)
+	}
+
+	want := map[string]any{
+		"name":  "my-app",
+		"debug": "true",
+		"database": map[string]any{
+			"url":     "postgres://db/app ; not a comment",
+			"pool":    "10",
+			"replica": map[string]any{"url": "postgres://replica/app"},
+		},
+		"environments": map[string]any{
+			"production": map[string]any{"name": "my-app-prod"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseINI =\n%v\nwant\n%v", got, want)
+	}
+
+	for _, invalid := range []string{"[unterminated", "[]", "no separator", "name = x\n[name]"} {
+		if _, err := parseINI([]byte(invalid)); err == nil {
+			t.Errorf("expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestLoadINIFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.cfg")
+	writeConfigFile(t, path, "port = 8080\n\n[database]\nurl = postgres://db/app\n\n[environments.production]\nport = 443\n")
+
+	cfg := New()
+	cfg.SetDefaultPriority(PriorityFileEnvFlagDefault)
+	cfg.Define("PORT").Int64().File("port")
+	cfg.Define("DATABASE_URL").String().File("database.url")
+	if err := cfg.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if err := cfg.SetEnvironment("production"); err != nil {
+		t.Fatalf("SetEnvironment failed: %v", err)
+	}
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx := NewCommandContext(nil, cfg, "", "")
+	if port, _ := Get[int64](ctx, "PORT"); port != 443 {
+		t.Errorf("PORT = %d, want 443 from the production section", port)
+	}
+	if url, _ := Get[string](ctx, "DATABASE_URL"); url != "postgres://db/app" {
+		t.Errorf("DATABASE_URL = %q, want the [database] url", url)
+	}
+}
@@ -0,0 +1,131 @@
+// commandkit/inspect.go
+package commandkit
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Keys of the flags of the inspector's show command
+const (
+	inspectGrepKey    = "INSPECT_GREP"
+	inspectTagsKey    = "INSPECT_TAGS"
+	inspectSourcesKey = "INSPECT_SOURCES"
+)
+
+// ConfigEntry is one key listed by the configuration inspector. Secrets, and values
+// duplicating a secret, are masked as in Dump.
+type ConfigEntry struct {
+	Key    string   `json:"key"`
+	Value  string   `json:"value"`
+	Source string   `json:"source"` // "default", "flag", "environment", "file", "remote", or "" when not set
+	Tags   []string `json:"tags,omitempty"`
+}
+
+// InspectCommand adds a read-only command, such as "config", whose "show" subcommand
+// lists the resolved global configuration with the source of each value. The list can
+// be narrowed with --grep (fuzzy match on the key, or a substring of the environment
+// variable, flag or description), --tag and --source, and is rendered like any other
+// command result:
+//
+//	app config show --grep token --source environment,file
+func (c *Config) InspectCommand(name string) *CommandBuilder {
+	cmd := c.Command(name).ShortHelp("Inspect the configuration")
+	cmd.SubCommand("show").
+		ShortHelp("List configuration values and their sources").
+		ResultFunc(func(ctx *CommandContext) (any, error) {
+			// The flags are optional, so unset ones are not reported as errors
+			flags := getConfig(ctx)
+			grep, _ := flags.lookupValue(inspectGrepKey)
+			tags, _ := flags.lookupValue(inspectTagsKey)
+			sources, _ := flags.lookupValue(inspectSourcesKey)
+			text, _ := grep.(string)
+			tagList, _ := tags.([]string)
+			sourceList, _ := sources.([]string)
+			return ctx.GlobalConfig.inspect(text, tagList, sourceList)
+		}).
+		Config(func(cc *CommandConfig) {
+			cc.Define(inspectGrepKey).String().Flag("grep").Description("Only keys matching this text")
+			cc.Define(inspectTagsKey).StringSlice().Flag("tag").Description("Only keys having one of these tags")
+			cc.Define(inspectSourcesKey).StringSlice().Flag("source").
+				Description("Only values from these sources (default, flag, environment, file, remote)")
+		})
+	return cmd
+}
+
+// inspect lists the keys matching grep, one of tags and one of sources, sorted by key
+func (c *Config) inspect(grep string, tags, sources []string) ([]ConfigEntry, error) {
+	for _, source := range sources {
+		if !slices.Contains(sourceNames, source) {
+			return nil, fmt.Errorf("unknown source %q (expected one of %s)", source, strings.Join(sourceNames, ", "))
+		}
+	}
+
+	values := c.Dump(tags...)
+	entries := make([]ConfigEntry, 0, len(values))
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		def := c.definitions[key]
+		if !def.matches(grep) {
+			continue
+		}
+		source := c.valueSource(key)
+		if len(sources) > 0 && !slices.Contains(sources, source) {
+			continue
+		}
+		entries = append(entries, ConfigEntry{Key: key, Value: values[key], Source: source, Tags: def.Tags()})
+	}
+	return entries, nil
+}
+
+// sourceNames are the names of the sources values may come from, as SourceType prints them
+var sourceNames = []string{
+	SourceDefault.String(), SourceFlag.String(), SourceEnv.String(), SourceFile.String(), SourceRemote.String(),
+}
+
+// valueSource returns the name of the source the value of key was resolved from when
+// the configuration was last committed, "" if none provided it
+func (c *Config) valueSource(key string) string {
+	if value, set := c.lookupValue(key); (!set || value == nil) && !c.secrets.Has(key) {
+		return ""
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if source, resolved := c.valueSources[key]; resolved {
+		return source.String()
+	}
+	return ""
+}
+
+// matches reports whether the key fuzzily matches query: its letters appear in the key
+// in order, ignoring case and separators, or query is part of the environment variable,
+// flag or description. Any key matches an empty query.
+func (d *Definition) matches(query string) bool {
+	query = strings.ToLower(query)
+	if query == "" {
+		return true
+	}
+	for _, text := range []string{d.envVar, d.flag, d.description} {
+		if strings.Contains(strings.ToLower(text), query) {
+			return true
+		}
+	}
+
+	wanted := []rune(strings.Map(dropSeparators, query))
+	for _, r := range strings.ToLower(d.key) {
+		if len(wanted) > 0 && r == wanted[0] {
+			wanted = wanted[1:]
+		}
+	}
+	return len(wanted) == 0
+}
+
+// dropSeparators removes the characters separating words in keys, for strings.Map
+func dropSeparators(r rune) rune {
+	if unicode.IsLetter(r) || unicode.IsDigit(r) {
+		return r
+	}
+	return -1
+}
@@ -0,0 +1,96 @@
+package commandkit
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestInspectCommand(t *testing.T) {
+	defer func(original func() bool) { outputIsTerminal = original }(outputIsTerminal)
+	outputIsTerminal = func() bool { return false }
+
+	show := func(args ...string) []ConfigEntry {
+		t.Helper()
+		cfg := New()
+		cfg.WithEnviron([]string{"API_TOKEN=s3cr3t", "PORT=9000"})
+		cfg.Define("API_TOKEN").String().Env("API_TOKEN").Secret()
+		cfg.Define("PORT").Int64().Env("PORT").Default(8080).Tags("network")
+		cfg.Define("READ_TIMEOUT").Duration().Default("5s").Tags("network", "tuning")
+		cfg.Define("WORKERS").Int64().Default(4).Description("Token bucket workers").Tags("tuning")
+		cfg.InspectCommand("config")
+
+		var err error
+		output := captureStdout(t, func() {
+			err = cfg.Execute(append([]string{"app", "config", "show"}, args...))
+		})
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", args, err)
+		}
+		var entries []ConfigEntry
+		if err := json.Unmarshal([]byte(output), &entries); err != nil {
+			t.Fatalf("%v: invalid output %q: %v", args, output, err)
+		}
+		return entries
+	}
+	keys := func(entries []ConfigEntry) string {
+		var keys []string
+		for _, entry := range entries {
+			keys = append(keys, entry.Key)
+		}
+		return strings.Join(keys, ",")
+	}
+
+	all := show()
+	if keys(all) != "API_TOKEN,PORT,READ_TIMEOUT,WORKERS" {
+		t.Fatalf("unexpected keys: %s", keys(all))
+	}
+	if all[0].Value == "s3cr3t" || all[0].Source != "environment" {
+		t.Errorf("unexpected secret entry: %+v", all[0])
+	}
+	if all[1].Value != "9000" || all[1].Source != "environment" || all[2].Source != "default" {
+		t.Errorf("unexpected entries: %+v", all[1:3])
+	}
+
+	for want, args := range map[string][]string{
+		"API_TOKEN,WORKERS":    {"--grep", "token"},
+		"READ_TIMEOUT":         {"--grep", "rdtmo"},
+		"PORT,READ_TIMEOUT":    {"--tag", "network"},
+		"API_TOKEN,PORT":       {"--source", "environment"},
+		"READ_TIMEOUT,WORKERS": {"--tag", "tuning", "--source", "default,file"},
+	} {
+		if got := keys(show(args...)); got != want {
+			t.Errorf("%v: got %s, want %s", args, got, want)
+		}
+	}
+
+	cfg := New()
+	cfg.InspectCommand("config")
+	if err := cfg.Execute([]string{"app", "config", "show", "--source", "vault"}); err == nil || !strings.Contains(err.Error(), "unknown source") {
+		t.Errorf("expected unknown source error, got %v", err)
+	}
+}
+
+func TestInspectReportsResolvedSource(t *testing.T) {
+	source := &countingSource{mapSource: mapSource{values: map[string]string{"HOST": "consul.example.com"}}}
+	cfg := New()
+	cfg.Define("HOST").String().Default("localhost")
+	cfg.UseSource(source)
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The source is neither asked again nor credited with a value it no longer has
+	source.lookups = 0
+	delete(source.values, "HOST")
+	entries, err := cfg.inspect("", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Source != "remote" || entries[0].Value != "consul.example.com" {
+		t.Errorf("entries = %+v, want HOST from remote", entries)
+	}
+	if source.lookups != 0 {
+		t.Errorf("inspecting asked the source %d times", source.lookups)
+	}
+}
@@ -0,0 +1,189 @@
+// Package jobqueue turns a commandkit command tree into a job dispatcher: invocations
+// are consumed from a queue and run through the configured middleware, a few at a
+// time.
+//
+//	jobs := make(chan jobqueue.Job)
+//	go jobqueue.Run(ctx, cfg, jobqueue.Channel(jobs), jobqueue.WithConcurrency(4))
+//
+//	jobs <- jobqueue.Job{Args: []string{"thumbnail", "--id", "42"}}
+//
+// Other brokers plug in through the Queue interface; QueueFunc adapts a receive
+// function, e.g. for a NATS queue subscription:
+//
+//	sub, _ := nc.QueueSubscribeSync("jobs", "workers")
+//	queue := jobqueue.QueueFunc(func(ctx context.Context) (jobqueue.Job, error) {
+//		msg, err := sub.NextMsgWithContext(ctx)
+//		if err != nil {
+//			return jobqueue.Job{}, err
+//		}
+//		return jobqueue.Job{
+//			Args: strings.Fields(string(msg.Data)),
+//			Done: func(*commandkit.RunReport) { msg.Ack() },
+//		}, nil
+//	})
+//
+// Jobs share the global configuration, so per-job settings belong in command flags.
+package jobqueue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fernandezvara/commandkit"
+)
+
+// jobKey is the context data key holding the running job
+const jobKey = "jobqueue.job"
+
+// ErrClosed is returned by a Queue that will not deliver more jobs
+var ErrClosed = errors.New("job queue closed")
+
+// Job is a command invocation taken from a queue
+type Job struct {
+	ID   string                      // Optional identifier, used in logs
+	Args []string                    // Command path followed by its arguments, e.g. ["db", "migrate", "--steps", "3"]
+	Done func(*commandkit.RunReport) // Optional, called once the job ran, e.g. to acknowledge a message
+}
+
+// Queue delivers jobs to Run
+type Queue interface {
+	// Next blocks until a job is available. It returns ErrClosed once the queue is
+	// drained, or the context error when ctx is done.
+	Next(ctx context.Context) (Job, error)
+}
+
+// QueueFunc adapts a receive function to the Queue interface
+type QueueFunc func(ctx context.Context) (Job, error)
+
+// Next calls f
+func (f QueueFunc) Next(ctx context.Context) (Job, error) {
+	return f(ctx)
+}
+
+// Channel returns a queue reading jobs from ch; closing ch closes the queue
+func Channel(ch <-chan Job) Queue {
+	return QueueFunc(func(ctx context.Context) (Job, error) {
+		select {
+		case job, ok := <-ch:
+			if !ok {
+				return Job{}, ErrClosed
+			}
+			return job, nil
+		case <-ctx.Done():
+			return Job{}, ctx.Err()
+		}
+	})
+}
+
+// Current returns the job being run, or nil when the command was started from the
+// command line
+func Current(ctx *commandkit.CommandContext) *Job {
+	value, _ := ctx.GetData(jobKey)
+	job, _ := value.(*Job)
+	return job
+}
+
+// Option configures Run
+type Option func(*worker)
+
+// worker holds the Run settings
+type worker struct {
+	concurrency int
+	onReport    func(Job, *commandkit.RunReport)
+}
+
+// WithConcurrency sets how many jobs are taken from the queue at the same time
+// (default 1). Their commands run one at a time, as ExecuteReport serializes runs
+// sharing the configuration, while Done callbacks and report handlers overlap them.
+func WithConcurrency(n int) Option {
+	return func(w *worker) {
+		if n > 0 {
+			w.concurrency = n
+		}
+	}
+}
+
+// WithReportHandler sets a function receiving the report of every job, e.g. to export
+// metrics. Failed jobs are logged either way.
+func WithReportHandler(fn func(Job, *commandkit.RunReport)) Option {
+	return func(w *worker) {
+		w.onReport = fn
+	}
+}
+
+// Run takes jobs from queue and executes them with cfg until ctx is done or the queue
+// is closed, then waits for running jobs. It returns nil in those cases and the queue
+// error otherwise.
+func Run(ctx context.Context, cfg *commandkit.Config, queue Queue, opts ...Option) error {
+	w := &worker{concurrency: 1}
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	program := filepath.Base(os.Args[0])
+	slots := make(chan struct{}, w.concurrency)
+	var running sync.WaitGroup
+	defer running.Wait()
+
+	for {
+		// Only take a job once it can start
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil
+		}
+
+		job, err := queue.Next(ctx)
+		if err != nil {
+			<-slots
+			if errors.Is(err, ErrClosed) || ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to receive job: %w", err)
+		}
+
+		running.Go(func() {
+			defer func() { <-slots }()
+			w.run(cfg, program, job)
+		})
+	}
+}
+
+// run executes one job and reports its outcome
+func (w *worker) run(cfg *commandkit.Config, program string, job Job) {
+	var report *commandkit.RunReport
+	switch {
+	case len(job.Args) == 0:
+		report = &commandkit.RunReport{Start: time.Now(), Err: errors.New("job has no command")}
+	case commandkit.IsHelpRequest(job.Args):
+		report = &commandkit.RunReport{Start: time.Now(), Err: errors.New("help is not available for jobs")}
+	default:
+		report = cfg.ExecuteReport(append([]string{program}, job.Args...), commandkit.WithContextData(jobKey, &job))
+	}
+
+	if report.Err != nil {
+		log.Print(cfg.Redact(fmt.Sprintf("Job %s failed: %s", describe(job), report.Err)))
+	}
+	if w.onReport != nil {
+		w.onReport(job, report)
+	}
+	if job.Done != nil {
+		job.Done(report)
+	}
+}
+
+// describe names a job in logs
+func describe(job Job) string {
+	name := fmt.Sprintf("%q", strings.Join(job.Args, " "))
+	if job.ID != "" {
+		name = job.ID + " " + name
+	}
+	return name
+}
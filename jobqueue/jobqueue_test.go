@@ -0,0 +1,140 @@
+package jobqueue
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fernandezvara/commandkit"
+)
+
+func TestRun(t *testing.T) {
+	var mu sync.Mutex
+	var ids []string
+	var active, peak atomic.Int32
+
+	cfg := commandkit.New()
+	cfg.Define("REGION").String().Flag("region").Default("eu")
+	cfg.Command("resize").
+		Config(func(cc *commandkit.CommandConfig) {
+			cc.Define("WIDTH").Int64().Flag("width").Range(1, 4096)
+		}).
+		Func(func(ctx *commandkit.CommandContext) error {
+			n := active.Add(1)
+			defer active.Add(-1)
+			for {
+				if p := peak.Load(); n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+
+			if _, err := commandkit.Get[string](ctx, "REGION"); err != nil {
+				return err
+			}
+			mu.Lock()
+			ids = append(ids, Current(ctx).ID)
+			mu.Unlock()
+			return nil
+		})
+	cfg.Command("fail").Func(func(ctx *commandkit.CommandContext) error {
+		return errors.New("boom")
+	})
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	jobs := make(chan Job)
+	reports := make(map[string]*commandkit.RunReport)
+	var done atomic.Int32
+	go func() {
+		for i, args := range [][]string{
+			{"resize", "--width", "100"},
+			{"resize", "--width", "200", "--region", "us"},
+			{"resize", "--width", "300"},
+			{"resize", "--width", "99999"},
+			{"fail"},
+			{"resize", "--help"},
+			{"nope"},
+		} {
+			jobs <- Job{
+				ID:   string(rune('a' + i)),
+				Args: args,
+				Done: func(*commandkit.RunReport) { done.Add(1) },
+			}
+		}
+		close(jobs)
+	}()
+
+	err := Run(context.Background(), cfg, Channel(jobs),
+		WithConcurrency(2),
+		WithReportHandler(func(job Job, report *commandkit.RunReport) {
+			mu.Lock()
+			reports[job.ID] = report
+			mu.Unlock()
+		}))
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if done.Load() != 7 || len(reports) != 7 {
+		t.Fatalf("expected 7 finished jobs, got %d done and %d reports", done.Load(), len(reports))
+	}
+	if len(ids) != 3 {
+		t.Errorf("expected 3 successful resizes, got %v", ids)
+	}
+	// Jobs are taken two at a time, but their commands share cfg and never overlap
+	if peak.Load() != 1 {
+		t.Errorf("expected commands to run one at a time, peak was %d", peak.Load())
+	}
+	for _, id := range []string{"a", "b", "c"} {
+		if reports[id].Err != nil || strings.Join(reports[id].Command, " ") != "resize" {
+			t.Errorf("job %s: unexpected report %+v", id, reports[id])
+		}
+	}
+
+	var configErrs commandkit.ConfigErrors
+	if !errors.As(reports["d"].Err, &configErrs) {
+		t.Errorf("expected configuration errors for job d, got %v", reports["d"].Err)
+	}
+	if reports["e"].Err == nil || reports["e"].Err.Error() != "boom" {
+		t.Errorf("expected job e to fail with boom, got %v", reports["e"].Err)
+	}
+	if reports["f"].Err == nil || !strings.Contains(reports["f"].Err.Error(), "help is not available") {
+		t.Errorf("expected job f to be rejected, got %v", reports["f"].Err)
+	}
+	if reports["g"].Err == nil {
+		t.Error("expected job g to fail for an unknown command")
+	}
+	if !strings.Contains(buf.String(), `Job e "fail" failed: boom`) {
+		t.Errorf("expected the failure to be logged, got:\n%s", buf.String())
+	}
+}
+
+func TestRunStops(t *testing.T) {
+	cfg := commandkit.New()
+	cfg.Command("noop").Func(func(ctx *commandkit.CommandContext) error { return nil })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() { result <- Run(ctx, cfg, Channel(make(chan Job))) }()
+	cancel()
+	if err := <-result; err != nil {
+		t.Errorf("expected nil once the context is done, got %v", err)
+	}
+
+	broken := QueueFunc(func(context.Context) (Job, error) {
+		return Job{}, errors.New("connection lost")
+	})
+	if err := Run(context.Background(), cfg, broken); err == nil || !strings.Contains(err.Error(), "connection lost") {
+		t.Errorf("expected the queue error, got %v", err)
+	}
+}
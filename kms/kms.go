@@ -0,0 +1,95 @@
+// Package kms provides a commandkit decryptor backed by AWS KMS.
+//
+//	cfg.UseDecryptor(kms.Scheme, kms.New())
+//
+// Encrypted values are written in configuration files as ENC[KMS,<base64 ciphertext>],
+// the base64 encoding of the CiphertextBlob returned by "aws kms encrypt". The key is
+// identified by the ciphertext itself, so a single decryptor serves every KMS key the
+// caller is allowed to use.
+package kms
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+)
+
+// Scheme is the scheme used in ENC[KMS,...] file values
+const Scheme = "KMS"
+
+// Client is the subset of the KMS API used by Decryptor
+type Client interface {
+	Decrypt(ctx context.Context, params *kms.DecryptInput, optFns ...func(*kms.Options)) (*kms.DecryptOutput, error)
+}
+
+// Option configures a Decryptor
+type Option func(*Decryptor)
+
+// WithClient sets the KMS client; by default one is created from the default AWS
+// configuration (environment, shared config files, instance role, ...)
+func WithClient(client Client) Option {
+	return func(d *Decryptor) {
+		d.client = client
+	}
+}
+
+// WithContext sets the context used for the AWS calls
+func WithContext(ctx context.Context) Option {
+	return func(d *Decryptor) {
+		d.ctx = ctx
+	}
+}
+
+// Decryptor decrypts file values encrypted with KMS
+type Decryptor struct {
+	client Client
+	ctx    context.Context
+	mu     sync.Mutex
+}
+
+// New creates a KMS decryptor
+func New(opts ...Option) *Decryptor {
+	d := &Decryptor{ctx: context.Background()}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// Decrypt decrypts a base64 encoded KMS ciphertext blob
+func (d *Decryptor) Decrypt(payload string) ([]byte, error) {
+	blob, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, fmt.Errorf("invalid payload: %w", err)
+	}
+
+	client, err := d.getClient()
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := client.Decrypt(d.ctx, &kms.DecryptInput{CiphertextBlob: blob})
+	if err != nil {
+		return nil, fmt.Errorf("kms decrypt failed: %w", err)
+	}
+	return output.Plaintext, nil
+}
+
+// getClient returns the configured client, creating the default one on first use
+func (d *Decryptor) getClient() (Client, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.client == nil {
+		awsConfig, err := config.LoadDefaultConfig(d.ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load AWS configuration: %w", err)
+		}
+		d.client = kms.NewFromConfig(awsConfig)
+	}
+	return d.client, nil
+}
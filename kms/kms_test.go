@@ -0,0 +1,73 @@
+package kms
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/fernandezvara/commandkit"
+)
+
+// fakeClient "decrypts" blobs by stripping a fixed prefix
+type fakeClient struct {
+	calls int
+}
+
+func (f *fakeClient) Decrypt(_ context.Context, input *kms.DecryptInput, _ ...func(*kms.Options)) (*kms.DecryptOutput, error) {
+	f.calls++
+	plaintext, ok := bytes.CutPrefix(input.CiphertextBlob, []byte("kms:"))
+	if !ok {
+		return nil, errors.New("InvalidCiphertextException")
+	}
+	return &kms.DecryptOutput{Plaintext: plaintext}, nil
+}
+
+func encrypt(plaintext string) string {
+	return "ENC[" + Scheme + "," + base64.StdEncoding.EncodeToString([]byte("kms:"+plaintext)) + "]"
+}
+
+func TestDecrypt(t *testing.T) {
+	client := &fakeClient{}
+	decryptor := New(WithClient(client))
+
+	plaintext, err := decryptor.Decrypt(base64.StdEncoding.EncodeToString([]byte("kms:hunter2")))
+	if err != nil || string(plaintext) != "hunter2" {
+		t.Fatalf("Decrypt = %q, %v; want hunter2", plaintext, err)
+	}
+	if _, err := decryptor.Decrypt("not base64!"); err == nil {
+		t.Error("expected an error for an invalid payload")
+	}
+	if _, err := decryptor.Decrypt(base64.StdEncoding.EncodeToString([]byte("garbage"))); err == nil {
+		t.Error("expected the client error to be returned")
+	}
+	if client.calls != 2 {
+		t.Errorf("expected 2 KMS calls, got %d", client.calls)
+	}
+}
+
+func TestDecryptorDecryptsFileValues(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("password: "+encrypt("hunter2")+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := commandkit.New()
+	cfg.SetDefaultPriority(commandkit.PriorityFileEnvFlagDefault)
+	cfg.UseDecryptor(Scheme, New(WithClient(&fakeClient{})))
+	cfg.Define("PASSWORD").String().File("password").Secret()
+	if err := cfg.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := cfg.GetSecret("PASSWORD").String(); got != "hunter2" {
+		t.Errorf("PASSWORD = %q, want hunter2", got)
+	}
+}
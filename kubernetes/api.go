@@ -0,0 +1,229 @@
+package kubernetes
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// serviceAccountDir is where Kubernetes mounts the pod's service account credentials
+const serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+// Option configures an APISource
+type Option func(*APISource)
+
+// WithHost sets the API server URL; by default the in-cluster address from
+// KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT is used
+func WithHost(host string) Option {
+	return func(s *APISource) {
+		s.host = strings.TrimSuffix(host, "/")
+	}
+}
+
+// WithHTTPClient sets the HTTP client; by default one trusting the service account
+// CA certificate is created
+func WithHTTPClient(client *http.Client) Option {
+	return func(s *APISource) {
+		s.client = client
+	}
+}
+
+// WithToken sets the bearer token; by default the service account token is read
+func WithToken(token string) Option {
+	return func(s *APISource) {
+		s.token = token
+	}
+}
+
+// WithContext sets the context used for the API calls
+func WithContext(ctx context.Context) Option {
+	return func(s *APISource) {
+		s.ctx = ctx
+	}
+}
+
+// APISource resolves configuration keys from a ConfigMap or Secret read through the
+// Kubernetes API
+type APISource struct {
+	resource  string
+	namespace string
+	name      string
+
+	host   string
+	client *http.Client
+	token  string
+	ctx    context.Context
+
+	mu     sync.Mutex
+	values map[string]string // nil until the object was read successfully
+}
+
+// ConfigMap creates a source for a ConfigMap; an empty namespace is the pod's own
+func ConfigMap(namespace, name string, opts ...Option) *APISource {
+	return newAPISource("configmaps", namespace, name, opts)
+}
+
+// Secret creates a source for a Secret; an empty namespace is the pod's own. Its values
+// are sensitive.
+func Secret(namespace, name string, opts ...Option) *APISource {
+	return newAPISource("secrets", namespace, name, opts)
+}
+
+func newAPISource(resource, namespace, name string, opts []Option) *APISource {
+	s := &APISource{
+		resource:  resource,
+		namespace: namespace,
+		name:      name,
+		ctx:       context.Background(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Name identifies the source in error messages
+func (s *APISource) Name() string {
+	return fmt.Sprintf("kubernetes:%s/%s", strings.TrimSuffix(s.resource, "s"), s.name)
+}
+
+// Lookup returns the value of the ConfigMap or Secret key matching key
+func (s *APISource) Lookup(key string) (string, bool, error) {
+	values, err := s.load()
+	if err != nil {
+		return "", false, err
+	}
+	value, found := values[normalize(key)]
+	return value, found, nil
+}
+
+// Refresh drops the values read so far; the next lookup reads the object again
+func (s *APISource) Refresh() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values = nil
+}
+
+// load reads the object on first use; a failed read is retried by the next lookup, so
+// an API server briefly unavailable at startup does not fail the source for good
+func (s *APISource) load() (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.values == nil {
+		values, err := s.fetch()
+		if err != nil {
+			return nil, err
+		}
+		s.values = values
+	}
+	return s.values, nil
+}
+
+// IsSensitive reports whether the source reads a Secret
+func (s *APISource) IsSensitive(key string) bool {
+	return s.resource == "secrets"
+}
+
+// fetch reads the object from the API server, decoding Secret values
+func (s *APISource) fetch() (map[string]string, error) {
+	if err := s.inCluster(); err != nil {
+		return nil, err
+	}
+
+	endpoint := fmt.Sprintf("%s/api/v1/namespaces/%s/%s/%s",
+		s.host, url.PathEscape(s.namespace), s.resource, url.PathEscape(s.name))
+	request, err := http.NewRequestWithContext(s.ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Accept", "application/json")
+	if s.token != "" {
+		request.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", s.name, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(response.Body, 512))
+		return nil, fmt.Errorf("failed to read %s: %s: %s", s.name, response.Status, strings.TrimSpace(string(body)))
+	}
+
+	var object struct {
+		Data map[string]string `json:"data"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&object); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", s.name, err)
+	}
+
+	values := make(map[string]string, len(object.Data))
+	for key, value := range object.Data {
+		if s.resource == "secrets" {
+			decoded, err := base64.StdEncoding.DecodeString(value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode key %s of %s: %w", key, s.name, err)
+			}
+			value = string(decoded)
+		}
+		values[normalize(key)] = value
+	}
+	return values, nil
+}
+
+// inCluster fills in whatever was not configured from the pod's environment and
+// service account
+func (s *APISource) inCluster() error {
+	if s.host == "" {
+		host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+		if host == "" || port == "" {
+			return fmt.Errorf("not running in a cluster: KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT are not set")
+		}
+		s.host = "https://" + net.JoinHostPort(host, port)
+	}
+
+	if s.namespace == "" {
+		namespace, err := os.ReadFile(filepath.Join(serviceAccountDir, "namespace"))
+		if err != nil {
+			return fmt.Errorf("failed to read the pod namespace: %w", err)
+		}
+		s.namespace = strings.TrimSpace(string(namespace))
+	}
+
+	if s.token == "" {
+		token, err := os.ReadFile(filepath.Join(serviceAccountDir, "token"))
+		if err != nil {
+			return fmt.Errorf("failed to read the service account token: %w", err)
+		}
+		s.token = strings.TrimSpace(string(token))
+	}
+
+	if s.client == nil {
+		ca, err := os.ReadFile(filepath.Join(serviceAccountDir, "ca.crt"))
+		if err != nil {
+			return fmt.Errorf("failed to read the cluster CA certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return fmt.Errorf("invalid cluster CA certificate")
+		}
+		s.client = &http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: pool},
+		}}
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce groups the events of a single kubelet volume update
+const watchDebounce = 100 * time.Millisecond
+
+// DirSource resolves configuration keys from a mounted ConfigMap or Secret volume
+type DirSource struct {
+	dir       string
+	sensitive bool
+
+	mu     sync.RWMutex
+	loaded bool
+	values map[string]string
+}
+
+// ConfigMapDir creates a source for a mounted ConfigMap volume
+func ConfigMapDir(dir string) *DirSource {
+	return &DirSource{dir: dir}
+}
+
+// SecretDir creates a source for a mounted Secret volume; its values are sensitive
+func SecretDir(dir string) *DirSource {
+	return &DirSource{dir: dir, sensitive: true}
+}
+
+// Name identifies the source in error messages
+func (s *DirSource) Name() string {
+	return "kubernetes:" + s.dir
+}
+
+// Lookup returns the content of the file matching key
+func (s *DirSource) Lookup(key string) (string, bool, error) {
+	if err := s.load(); err != nil {
+		return "", false, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	value, found := s.values[normalize(key)]
+	return value, found, nil
+}
+
+// IsSensitive reports whether the source is a Secret volume
+func (s *DirSource) IsSensitive(key string) bool {
+	return s.sensitive
+}
+
+// Watch follows kubelet updates of the volume, re-reading the files and calling
+// changed after each update, until ctx is done
+func (s *DirSource) Watch(ctx context.Context, changed func()) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create watcher: %w", err)
+	}
+	if err := watcher.Add(s.dir); err != nil {
+		watcher.Close()
+		return fmt.Errorf("failed to watch %s: %w", s.dir, err)
+	}
+
+	go func() {
+		defer watcher.Close()
+		var pending <-chan time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case _, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				pending = time.After(watchDebounce)
+			case <-watcher.Errors:
+			case <-pending:
+				pending = nil
+				values, err := readDir(s.dir)
+				if err != nil {
+					continue
+				}
+				s.mu.Lock()
+				s.values, s.loaded = values, true
+				s.mu.Unlock()
+				changed()
+			}
+		}
+	}()
+	return nil
+}
+
+// load reads the files the first time it is called
+func (s *DirSource) load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.loaded {
+		return nil
+	}
+	values, err := readDir(s.dir)
+	if err != nil {
+		return err
+	}
+	s.values, s.loaded = values, true
+	return nil
+}
+
+// readDir reads one value per file. The kubelet's bookkeeping entries (..data and
+// the timestamped directories) are skipped, and a trailing newline is removed.
+func readDir(dir string) (map[string]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+
+	values := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		// Keys are symlinks into ..data, so stat follows them
+		if info, err := os.Stat(path); err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		values[normalize(entry.Name())] = strings.TrimRight(string(data), "\r\n")
+	}
+	return values, nil
+}
@@ -0,0 +1,23 @@
+module github.com/fernandezvara/commandkit/kubernetes
+
+go 1.25.5
+
+require (
+	github.com/fernandezvara/commandkit v0.0.0-00010101000000-000000000000
+	github.com/fsnotify/fsnotify v1.10.1
+)
+
+require (
+	github.com/BurntSushi/toml v1.6.0 // indirect
+	github.com/awnumar/memcall v0.2.0 // indirect
+	github.com/awnumar/memguard v0.22.5 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/kr/text v0.2.0 // indirect
+	golang.org/x/crypto v0.16.0 // indirect
+	golang.org/x/sys v0.47.0 // indirect
+	golang.org/x/term v0.45.0 // indirect
+	golang.org/x/text v0.41.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/fernandezvara/commandkit => ../
@@ -0,0 +1,24 @@
+// Package kubernetes provides commandkit sources for Kubernetes ConfigMaps and Secrets.
+//
+// The usual deployment mounts them as volumes, one file per key:
+//
+//	cfg.UseSource(kubernetes.ConfigMapDir("/etc/config"))
+//	cfg.UseSource(kubernetes.SecretDir("/etc/secrets"))
+//	cfg.WatchSources(ctx, nil) // optional: reload when the kubelet updates the volume
+//
+// They can also be read from the API server with the pod's service account:
+//
+//	cfg.UseSource(kubernetes.ConfigMap("", "myapp-config")) // "" is the pod's namespace
+//	cfg.UseSource(kubernetes.Secret("", "myapp-secrets"))
+//
+// Keys are matched to definition keys by name: "database-url", "database.url" and
+// "DATABASE_URL" all provide DATABASE_URL. Secret values are sensitive, so they are
+// only accepted by Secret() definitions and are kept in protected memory.
+package kubernetes
+
+import "strings"
+
+// normalize maps ConfigMap and Secret keys and definition keys to a common form
+func normalize(name string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name))
+}
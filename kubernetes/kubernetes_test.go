@@ -0,0 +1,171 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fernandezvara/commandkit"
+)
+
+// writeVolume lays files out the way the kubelet does: a timestamped directory,
+// a ..data symlink pointing at it and one symlink per key
+func writeVolume(t *testing.T, dir, version string, files map[string]string) {
+	t.Helper()
+	data := filepath.Join(dir, "..2026_"+version)
+	if err := os.Mkdir(data, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(data, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		link := filepath.Join(dir, name)
+		if _, err := os.Lstat(link); os.IsNotExist(err) {
+			if err := os.Symlink(filepath.Join("..data", name), link); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	// Swap ..data atomically, like the kubelet
+	tmp := filepath.Join(dir, "..data_tmp")
+	if err := os.Symlink(filepath.Base(data), tmp); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, filepath.Join(dir, "..data")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDirSourceLookup(t *testing.T) {
+	dir := t.TempDir()
+	writeVolume(t, dir, "1", map[string]string{
+		"database-url": "postgres://db/app\n",
+		"log.level":    "info",
+	})
+
+	source := ConfigMapDir(dir)
+	if value, found, err := source.Lookup("DATABASE_URL"); err != nil || !found || value != "postgres://db/app" {
+		t.Fatalf("Lookup(DATABASE_URL) = %q, %v, %v", value, found, err)
+	}
+	if value, _, _ := source.Lookup("LOG_LEVEL"); value != "info" {
+		t.Errorf("LOG_LEVEL = %q, want info", value)
+	}
+	if _, found, _ := source.Lookup("DATA"); found {
+		t.Error("kubelet bookkeeping entries must be skipped")
+	}
+	if source.IsSensitive("LOG_LEVEL") || !SecretDir(dir).IsSensitive("LOG_LEVEL") {
+		t.Error("only Secret volumes should be sensitive")
+	}
+
+	if _, _, err := ConfigMapDir(filepath.Join(dir, "missing")).Lookup("LOG_LEVEL"); err == nil {
+		t.Error("expected an error for a missing volume")
+	}
+}
+
+func TestDirSourceWatchReloadsConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeVolume(t, dir, "1", map[string]string{"log-level": "info"})
+
+	cfg := commandkit.New()
+	cfg.Define("LOG_LEVEL").String().Default("warn")
+	cfg.UseSource(ConfigMapDir(dir))
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	watchCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reloaded := make(chan error, 1)
+	if err := cfg.WatchSources(watchCtx, func(err error) { reloaded <- err }); err != nil {
+		t.Fatalf("WatchSources failed: %v", err)
+	}
+
+	writeVolume(t, dir, "2", map[string]string{"log-level": "debug"})
+
+	select {
+	case err := <-reloaded:
+		if err != nil {
+			t.Fatalf("reload failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the reload")
+	}
+
+	ctx := commandkit.NewCommandContext(nil, cfg, "", "")
+	if level, _ := commandkit.Get[string](ctx, "LOG_LEVEL"); level != "debug" {
+		t.Errorf("LOG_LEVEL = %q after the volume update, want debug", level)
+	}
+}
+
+func TestAPISource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer sa-token" {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/v1/namespaces/prod/configmaps/myapp":
+			w.Write([]byte(`{"data": {"log-level": "debug"}}`))
+		case "/api/v1/namespaces/prod/secrets/myapp":
+			w.Write([]byte(`{"data": {"db.password": "` + base64.StdEncoding.EncodeToString([]byte("hunter2")) + `"}}`))
+		default:
+			http.Error(w, `{"reason": "NotFound"}`, http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	opts := []Option{WithHost(server.URL), WithHTTPClient(server.Client()), WithToken("sa-token")}
+
+	cfg := commandkit.New()
+	cfg.Define("LOG_LEVEL").String().Default("warn")
+	cfg.Define("DB_PASSWORD").String().Secret().Required()
+	cfg.UseSource(ConfigMap("prod", "myapp", opts...))
+	cfg.UseSource(Secret("prod", "myapp", opts...))
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx := commandkit.NewCommandContext(nil, cfg, "", "")
+	if level, _ := commandkit.Get[string](ctx, "LOG_LEVEL"); level != "debug" {
+		t.Errorf("LOG_LEVEL = %q, want debug", level)
+	}
+	if password := cfg.GetSecret("DB_PASSWORD").String(); password != "hunter2" {
+		t.Errorf("DB_PASSWORD = %q, want hunter2", password)
+	}
+
+	if _, _, err := ConfigMap("prod", "missing", opts...).Lookup("LOG_LEVEL"); err == nil {
+		t.Error("expected an error for a missing ConfigMap")
+	}
+	if _, _, err := ConfigMap("prod", "myapp", WithHost(server.URL), WithHTTPClient(server.Client()), WithToken("expired")).Lookup("LOG_LEVEL"); err == nil {
+		t.Error("expected an error for a rejected token")
+	}
+}
+
+func TestAPISourceRetriesFailedReads(t *testing.T) {
+	failures := 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failures > 0 {
+			failures--
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"data": {"log-level": "debug"}}`))
+	}))
+	defer server.Close()
+
+	source := ConfigMap("prod", "myapp", WithHost(server.URL), WithHTTPClient(server.Client()), WithToken("sa-token"))
+	if _, _, err := source.Lookup("LOG_LEVEL"); err == nil {
+		t.Fatal("expected an error while the API server is unavailable")
+	}
+	if value, found, err := source.Lookup("LOG_LEVEL"); err != nil || !found || value != "debug" {
+		t.Errorf("Lookup = %q, %v, %v; want the ConfigMap read once the API server is back", value, found, err)
+	}
+}
@@ -0,0 +1,127 @@
+// commandkit/limits.go
+package commandkit
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Limits bound the size of configuration input, so hostile or corrupted flags,
+// environment variables, files or remote values are rejected instead of using
+// pathological amounts of memory while processing. Zero fields are unlimited.
+type Limits struct {
+	MaxValueLength int   // Bytes in one value, or in one item of a list or map
+	MaxItems       int   // Items in one list or entries in one map
+	MaxFileSize    int64 // Bytes in one configuration file or reader, before decoding
+}
+
+// Limits sets the input limits, checked for every value given by a flag, environment
+// variable, file or remote source; defaults set in code are trusted
+func (c *Config) Limits(limits Limits) *Config {
+	c.limits = limits
+	return c
+}
+
+// checkLimits rejects a raw source value exceeding the limits. The error never
+// includes the value, which may be secret.
+func (c *Config) checkLimits(def *Definition, value any) error {
+	limits := c.limits
+	if limits.MaxValueLength <= 0 && limits.MaxItems <= 0 {
+		return nil
+	}
+
+	var texts []string
+	items := -1 // Not a list
+	switch v := value.(type) {
+	case string:
+		texts = []string{v}
+		if _, isList := indexedElementTypes[def.valueType]; isList || def.valueType == TypeStringMap {
+			// Count the items as parsed, so quoted delimiters and blank items are not counted
+			items = 0
+			for _, item := range splitList(v, def.delimiter) {
+				if strings.TrimSpace(item) != "" {
+					items++
+				}
+			}
+		}
+	case indexedEnv:
+		texts, items = v, len(v)
+	case []any:
+		items = len(v)
+		for _, item := range v {
+			if text, ok := item.(string); ok {
+				texts = append(texts, text)
+			}
+		}
+	case map[string]any:
+		items = len(v)
+		for key, item := range v {
+			texts = append(texts, key)
+			if text, ok := item.(string); ok {
+				texts = append(texts, text)
+			}
+		}
+	}
+
+	if limits.MaxItems > 0 && items > limits.MaxItems {
+		return fmt.Errorf("value has %d items, more than the limit of %d", items, limits.MaxItems)
+	}
+	if limits.MaxValueLength > 0 {
+		for _, text := range texts {
+			if len(text) > limits.MaxValueLength {
+				return fmt.Errorf("value is %d bytes long, more than the limit of %d", len(text), limits.MaxValueLength)
+			}
+		}
+	}
+	return nil
+}
+
+// readLimited reads r to the end, failing once it exceeds the MaxFileSize limit
+func (c *Config) readLimited(r io.Reader, name string) ([]byte, error) {
+	if c.limits.MaxFileSize <= 0 {
+		return io.ReadAll(r)
+	}
+	data, err := io.ReadAll(io.LimitReader(r, c.limits.MaxFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > c.limits.MaxFileSize {
+		return nil, fmt.Errorf("%s is larger than the limit of %d bytes", name, c.limits.MaxFileSize)
+	}
+	return data, nil
+}
+
+// readValueFile reads a file holding a single value, such as one named by a <VAR>_FILE
+// variable, enforcing the MaxFileSize limit and giving up once the content is longer
+// than MaxValueLength allows, so a device like /dev/zero is not read forever
+func (c *Config) readValueFile(filename string) ([]byte, error) {
+	limit := int64(c.limits.MaxValueLength) + 2 // Room for a trailing "\r\n"
+	if c.limits.MaxValueLength <= 0 || (c.limits.MaxFileSize > 0 && c.limits.MaxFileSize < limit) {
+		return c.readFileLimited(filename)
+	}
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%s is longer than the value limit of %d bytes", filename, c.limits.MaxValueLength)
+	}
+	return data, nil
+}
+
+// readFileLimited reads a configuration file, enforcing the MaxFileSize limit
+func (c *Config) readFileLimited(filename string) ([]byte, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return c.readLimited(f, filename)
+}
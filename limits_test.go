@@ -0,0 +1,65 @@
+package commandkit
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLimits(t *testing.T) {
+	limits := Limits{MaxValueLength: 16, MaxItems: 3}
+	longFile := filepath.Join(t.TempDir(), "name")
+	writeConfigFile(t, longFile, strings.Repeat("x", 64)+"\n")
+
+	cfg := New().Limits(limits)
+	cfg.WithEnviron([]string{"NAME=short", `TAGS="a,b",c,,d`}) // Three items once parsed
+	cfg.Define("NAME").String().Env("NAME")
+	cfg.Define("TAGS").StringSlice().Env("TAGS")
+	cfg.Define("BANNER").String().Default(strings.Repeat("x", 64)) // Defaults are trusted
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for name, env := range map[string]string{
+		"long value": "NAME=" + strings.Repeat("x", 17),
+		"many items": "TAGS=a,b,c,d",
+		"long item":  "TAGS_0=" + strings.Repeat("x", 17),
+		"long file":  "NAME_FILE=" + longFile,
+	} {
+		cfg := New().Limits(limits)
+		cfg.WithEnviron([]string{env})
+		cfg.Define("NAME").String().Env("NAME")
+		cfg.Define("TAGS").StringSlice().Env("TAGS")
+		err := cfg.Process([]string{"app"})
+		if err == nil || !strings.Contains(err.Error(), "limit") {
+			t.Errorf("%s: expected limit error, got %v", name, err)
+		}
+		if err != nil && strings.Contains(err.Error(), "xxxxxxxx") {
+			t.Errorf("%s: error includes the value: %v", name, err)
+		}
+	}
+}
+
+func TestLimitsFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	writeConfigFile(t, path, `{"tags": ["a", "b", "c", "d"]}`)
+
+	cfg := New().Limits(Limits{MaxItems: 3})
+	cfg.SetDefaultPriority(PriorityFileEnvFlagDefault)
+	cfg.Define("tags").StringSlice()
+	if err := cfg.LoadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Process([]string{"app"}); err == nil || !strings.Contains(err.Error(), "4 items") {
+		t.Errorf("expected item limit error, got %v", err)
+	}
+
+	cfg = New().Limits(Limits{MaxFileSize: 16})
+	if err := cfg.LoadFile(path); err == nil || !strings.Contains(err.Error(), "larger than the limit") {
+		t.Errorf("expected file size error, got %v", err)
+	}
+	if err := cfg.LoadReader(strings.NewReader(`{"tags": ["a", "b", "c", "d"]}`), "json"); err == nil {
+		t.Error("expected reader size error")
+	}
+}
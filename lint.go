@@ -0,0 +1,170 @@
+// commandkit/lint.go
+package commandkit
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Lint check names
+const (
+	LintDuplicateFlag   = "duplicate-flag"   // Two keys share a flag within a command
+	LintDuplicateEnv    = "duplicate-env"    // Two keys share an environment variable within a command
+	LintSecretFlagOnly  = "secret-flag-only" // A secret can only be given as a flag, visible in process listings
+	LintRequiredDefault = "required-default" // A required key has a default, so it can never be missing
+)
+
+// Finding is a definition mistake reported by Lint
+type Finding struct {
+	Check   string // One of the Lint* check names
+	Command string // Command path, empty for global definitions
+	Key     string
+	Message string
+}
+
+// String formats the finding for test failures and logs
+func (f Finding) String() string {
+	if f.Command != "" {
+		return fmt.Sprintf("%s: %s (command %s): %s", f.Check, f.Key, f.Command, f.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", f.Check, f.Key, f.Message)
+}
+
+// Lint checks the global and command definitions of cfg for common mistakes. It is
+// meant for unit tests:
+//
+//	if findings := commandkit.Lint(cfg); len(findings) > 0 {
+//		t.Errorf("configuration mistakes: %v", findings)
+//	}
+func Lint(cfg *Config) []Finding {
+	var findings []Finding
+	var lintScope func(command string, defs map[string]*Definition, commands map[string]*Command)
+	lintScope = func(command string, defs map[string]*Definition, commands map[string]*Command) {
+		keys := make([]string, 0, len(defs))
+		for key := range defs {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			findings = append(findings, lintDefinition(cfg, command, key, defs[key])...)
+		}
+		findings = append(findings, lintShared(cfg, command, keys, defs)...)
+
+		names := make([]string, 0, len(commands))
+		for name := range commands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			cmd := commands[name]
+			lintScope(strings.TrimSpace(command+" "+name), ownDefinitions(cmd, cfg.definitions), cmd.SubCommands)
+		}
+	}
+	lintScope("", cfg.definitions, cfg.commands)
+	return findings
+}
+
+// lintDefinition checks a single definition
+func lintDefinition(cfg *Config, command, key string, def *Definition) []Finding {
+	var findings []Finding
+	report := func(check, format string, args ...any) {
+		findings = append(findings, Finding{Check: check, Command: command, Key: key, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if def.secret && def.envVar == "" && !def.promptIfMissing {
+		priority := def.getEffectivePriority(cfg.defaultPriority)
+		if !slices.Contains(priority, SourceFile) && !slices.Contains(priority, SourceRemote) {
+			report(LintSecretFlagOnly, "secret has no environment variable or file source")
+		}
+	}
+
+	if def.required && def.defaultValue != nil {
+		report(LintRequiredDefault, "required key has a default value, so it is never missing")
+	}
+	return findings
+}
+
+// lintShared reports keys sharing a flag or an environment variable within one
+// command, global definitions included
+func lintShared(cfg *Config, command string, keys []string, defs map[string]*Definition) []Finding {
+	flagOwners := make(map[string]string)
+	envOwners := make(map[string]string)
+	if command != "" {
+		globalKeys := make([]string, 0, len(cfg.definitions))
+		for key := range cfg.definitions {
+			globalKeys = append(globalKeys, key)
+		}
+		sort.Strings(globalKeys)
+		for _, key := range globalKeys {
+			if _, redefined := defs[key]; redefined {
+				continue
+			}
+			def := cfg.definitions[key]
+			claim(flagOwners, def.flag, key)
+			claim(envOwners, def.envVar, key)
+		}
+	}
+
+	var findings []Finding
+	for _, key := range keys {
+		def := defs[key]
+		if owner := claim(flagOwners, def.flag, key); owner != "" {
+			findings = append(findings, Finding{
+				Check:   LintDuplicateFlag,
+				Command: command,
+				Key:     key,
+				Message: fmt.Sprintf("flag --%s is also used by %s", def.flag, owner),
+			})
+		}
+		if owner := claim(envOwners, def.envVar, key); owner != "" {
+			findings = append(findings, Finding{
+				Check:   LintDuplicateEnv,
+				Command: command,
+				Key:     key,
+				Message: fmt.Sprintf("environment variable %s is also used by %s", def.envVar, owner),
+			})
+		}
+	}
+	return findings
+}
+
+// claim records key as the owner of name, returning the other key already owning it
+func claim(owners map[string]string, name, key string) string {
+	if name == "" {
+		return ""
+	}
+	if owner, taken := owners[name]; taken && owner != key {
+		return owner
+	}
+	owners[name] = key
+	return ""
+}
+
+// definitionConflicts reports keys sharing a flag or an environment variable as
+// configuration errors, since the last definition would silently win
+func (c *Config) definitionConflicts() []ConfigError {
+	var errs []ConfigError
+	for _, finding := range Lint(c) {
+		if finding.Check != LintDuplicateFlag && finding.Check != LintDuplicateEnv {
+			continue
+		}
+		def := c.definitions[finding.Key]
+		if cmd := c.FindCommand(strings.Fields(finding.Command)...); cmd != nil {
+			def = cmd.Definitions[finding.Key]
+		}
+		description := finding.Message
+		if finding.Command != "" {
+			description += " in command " + finding.Command
+		}
+		errs = append(errs, ConfigError{
+			Key:              finding.Key,
+			Source:           "definition",
+			Display:          buildErrorDisplay(def),
+			ErrorDescription: description,
+		})
+	}
+	return errs
+}
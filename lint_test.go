@@ -0,0 +1,85 @@
+package commandkit
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	cfg := New()
+	cfg.Define("PORT").Int64().Flag("port").Env("PORT").Default(int64(8080))
+	cfg.Define("DEBUG").Bool().Flag("debug")
+	cfg.Define("TRACE").Bool().Flag("debug")
+	cfg.Define("TOKEN").String().Flag("token").Secret()
+	cfg.Define("NAME").String().Env("NAME").Required().Default("app")
+	cfg.Command("serve").Func(testCommand).Config(func(cc *CommandConfig) {
+		cc.Define("PORT").Int64().Flag("port").Env("PORT")    // Redefining a global is fine
+		cc.Define("LISTEN").String().Flag("port").Env("NAME") // Shares the redefined flag and a global env
+	})
+	cfg.Command("sync").Func(testCommand).Config(func(cc *CommandConfig) {
+		cc.Define("HOST").String().Flag("token").Env("SYNC_HOST") // Shares a global flag
+	})
+
+	var got []string
+	for _, finding := range Lint(cfg) {
+		got = append(got, finding.String())
+	}
+	want := []string{
+		"duplicate-env: LISTEN (command serve): environment variable NAME is also used by NAME",
+		"duplicate-flag: TRACE: flag --debug is also used by DEBUG",
+		"duplicate-flag: PORT (command serve): flag --port is also used by LISTEN",
+		"duplicate-flag: HOST (command sync): flag --token is also used by TOKEN",
+		"required-default: NAME: required key has a default value, so it is never missing",
+		"secret-flag-only: TOKEN: secret has no environment variable or file source",
+	}
+	for _, w := range want {
+		found := false
+		for _, g := range got {
+			found = found || g == w
+		}
+		if !found {
+			t.Errorf("missing finding %q in:\n%s", w, strings.Join(got, "\n"))
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("expected %d findings, got %d:\n%s", len(want), len(got), strings.Join(got, "\n"))
+	}
+
+	clean := New()
+	clean.Define("TOKEN").String().Env("TOKEN").Secret()
+	clean.Define("KEY").String().Flag("key").Secret().PromptIfMissing()
+	clean.SetDefaultPriority(PriorityFileEnvFlagDefault)
+	clean.Define("FILE_SECRET").String().Flag("file-secret").Secret()
+	if findings := Lint(clean); len(findings) != 0 {
+		t.Errorf("expected no findings, got %v", findings)
+	}
+}
+
+func TestDefinitionConflicts(t *testing.T) {
+	cfg := New()
+	cfg.Define("HOST").String().Flag("host").Env("HOST")
+	cfg.Define("ADDRESS").String().Flag("host")
+	err := cfg.Process([]string{"app", "--host", "example.com"})
+	if err == nil || !strings.Contains(err.Error(), "flag --host is also used by ADDRESS") {
+		t.Errorf("expected a duplicate flag error from Process, got %v", err)
+	}
+
+	cfg = New()
+	cfg.Define("TOKEN").String().Env("TOKEN")
+	cfg.Command("deploy").Func(testCommand).Config(func(cc *CommandConfig) {
+		cc.Define("DEPLOY_TOKEN").String().Env("TOKEN")
+	})
+	runErr := cfg.ExecuteReport([]string{"app", "deploy"}).Err
+	if runErr == nil || !strings.Contains(runErr.Error(), "environment variable TOKEN is also used by TOKEN in command deploy") {
+		t.Errorf("expected a duplicate env error from Execute, got %v", runErr)
+	}
+
+	defer func() {
+		if recovered := recover(); recovered == nil || !strings.Contains(recovered.(string), "flag port of ADMIN_PORT is already used by PORT") {
+			t.Errorf("expected MustDefine to panic, got %v", recovered)
+		}
+	}()
+	strict := New()
+	strict.MustDefine("PORT").Int64().Flag("port")
+	strict.MustDefine("ADMIN_PORT").Int64().Env("ADMIN_PORT").Flag("port")
+}
@@ -0,0 +1,50 @@
+// commandkit/list.go
+package commandkit
+
+import (
+	"encoding/csv"
+	"strings"
+	"unicode/utf8"
+)
+
+// splitList splits a slice value on delimiter. Items may be double quoted to contain
+// the delimiter, with "" standing for a quote inside a quoted item, as in CSV:
+// a,"b,c",d has three items. Values without quotes are split as they are.
+func splitList(raw, delimiter string) []string {
+	comma, size := utf8.DecodeRuneInString(delimiter)
+	if !strings.Contains(raw, `"`) || size != len(delimiter) || comma == '"' || comma == '\r' || comma == '\n' || comma == utf8.RuneError {
+		return strings.Split(raw, delimiter)
+	}
+
+	reader := csv.NewReader(strings.NewReader(raw))
+	reader.Comma = comma
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return strings.Split(raw, delimiter)
+	}
+	var items []string
+	for _, record := range records {
+		items = append(items, record...)
+	}
+	return items
+}
+
+// joinList joins slice items with delimiter, quoting those that contain the delimiter
+// or a quote so that splitList returns the same items
+func joinList(items []string, delimiter string) string {
+	if delimiter == "" {
+		return strings.Join(items, delimiter)
+	}
+	quoted := make([]string, len(items))
+	for i, item := range items {
+		if strings.Contains(item, delimiter) || strings.HasPrefix(strings.TrimSpace(item), `"`) {
+			item = `"` + strings.ReplaceAll(item, `"`, `""`) + `"`
+		}
+		quoted[i] = item
+	}
+	return strings.Join(quoted, delimiter)
+}
@@ -0,0 +1,136 @@
+// commandkit/lock.go
+package commandkit
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+)
+
+// lockFile is the content of a configuration lock file
+type lockFile struct {
+	Key    string            `json:"key"`    // Random HMAC key of this lock, hex encoded
+	Hash   string            `json:"hash"`   // Hash of every entry of Values
+	Values map[string]string `json:"values"` // HMAC-SHA256 of each resolved value, by key
+}
+
+// WriteLock writes a lock file pinning the resolved non-secret values, so VerifyLock
+// can detect unexpected configuration changes in later runs. Values are not written in
+// clear but as HMACs under a random key kept in the file, which defeats precomputed
+// tables; a low-entropy value such as a port can still be guessed from the file, so
+// treat a lock file as you would the configuration itself.
+func (c *Config) WriteLock(path string) error {
+	key := make([]byte, sha256.Size)
+	if _, err := rand.Read(key); err != nil {
+		return fmt.Errorf("failed to generate lock key: %w", err)
+	}
+	lock, err := c.currentLock(key)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// VerifyLock compares the resolved non-secret values with the lock file written by
+// WriteLock, and returns an error naming the keys that changed, appeared or
+// disappeared since
+func (c *Config) VerifyLock(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read lock file: %w", err)
+	}
+	var locked lockFile
+	if err := json.Unmarshal(data, &locked); err != nil {
+		return fmt.Errorf("failed to parse lock file %s: %w", path, err)
+	}
+	key, err := hex.DecodeString(locked.Key)
+	if err != nil || len(key) == 0 {
+		return fmt.Errorf("invalid lock file %s: missing or malformed key", path)
+	}
+	current, err := c.currentLock(key)
+	if err != nil {
+		return err
+	}
+	if current.Hash == locked.Hash {
+		return nil
+	}
+
+	var changed, added, removed []string
+	for key, hash := range current.Values {
+		if lockedHash, exists := locked.Values[key]; !exists {
+			added = append(added, key)
+		} else if lockedHash != hash {
+			changed = append(changed, key)
+		}
+	}
+	for key := range locked.Values {
+		if _, exists := current.Values[key]; !exists {
+			removed = append(removed, key)
+		}
+	}
+
+	var parts []string
+	for _, group := range []struct {
+		name string
+		keys []string
+	}{{"changed", changed}, {"added", added}, {"removed", removed}} {
+		if len(group.keys) > 0 {
+			slices.Sort(group.keys)
+			parts = append(parts, group.name+" "+strings.Join(group.keys, ", "))
+		}
+	}
+	if len(parts) == 0 {
+		parts = append(parts, "hash mismatch")
+	}
+	return fmt.Errorf("configuration differs from lock file %s: %s", path, strings.Join(parts, "; "))
+}
+
+// currentLock hashes the resolved values of the non-secret definitions with key
+func (c *Config) currentLock(key []byte) (*lockFile, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if !c.processed {
+		return nil, fmt.Errorf("configuration must be processed before it can be locked")
+	}
+
+	converter := NewTypeConverter()
+	lock := &lockFile{Key: hex.EncodeToString(key), Values: make(map[string]string)}
+	for name, value := range c.values {
+		def, exists := c.definitions[name]
+		if !exists || def.secret || value == nil {
+			continue
+		}
+		var text string
+		if def.valueType == TypeComplex {
+			data, err := json.Marshal(value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to hash %s: %w", name, err)
+			}
+			text = string(data)
+		} else {
+			text = converter.ConvertToDisplayString(value, def.delimiter)
+		}
+		text = c.maskSecretCopy(name, text)
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(text))
+		lock.Values[name] = hex.EncodeToString(mac.Sum(nil))
+	}
+
+	overall := sha256.New()
+	for _, name := range slices.Sorted(maps.Keys(lock.Values)) {
+		fmt.Fprintf(overall, "%s\x00%s\n", name, lock.Values[name])
+	}
+	lock.Hash = hex.EncodeToString(overall.Sum(nil))
+	return lock, nil
+}
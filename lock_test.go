@@ -0,0 +1,55 @@
+// commandkit/lock_test.go
+package commandkit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfigLock(t *testing.T) {
+	lockPath := filepath.Join(t.TempDir(), "config.lock")
+	newConfig := func(environ ...string) *Config {
+		cfg := New()
+		cfg.WithEnviron(environ)
+		cfg.Define("HOST").String().Env("HOST").Default("localhost")
+		cfg.Define("PORT").Int64().Env("PORT").Default(int64(8080))
+		cfg.Define("REGION").String().Env("REGION")
+		cfg.Define("TOKEN").String().Env("TOKEN").Secret()
+		if err := cfg.Process([]string{"app"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return cfg
+	}
+
+	if err := New().WriteLock(lockPath); err == nil {
+		t.Error("expected an error locking an unprocessed configuration")
+	}
+
+	if err := newConfig("TOKEN=first").WriteLock(lockPath); err != nil {
+		t.Fatalf("WriteLock failed: %v", err)
+	}
+	data, err := os.ReadFile(lockPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "localhost") || strings.Contains(string(data), "TOKEN") {
+		t.Errorf("lock file must hold hashes of non-secret values only:\n%s", data)
+	}
+	if sum := sha256.Sum256([]byte("localhost")); strings.Contains(string(data), hex.EncodeToString(sum[:])) {
+		t.Errorf("lock file must not hold unkeyed hashes:\n%s", data)
+	}
+
+	// Secrets are not pinned
+	if err := newConfig("TOKEN=rotated").VerifyLock(lockPath); err != nil {
+		t.Errorf("VerifyLock failed for an unchanged configuration: %v", err)
+	}
+
+	err = newConfig("HOST=db.internal", "PORT=9090", "REGION=eu").VerifyLock(lockPath)
+	if err == nil || !strings.Contains(err.Error(), "changed HOST, PORT; added REGION") {
+		t.Errorf("expected the changed and added keys to be reported, got %v", err)
+	}
+}
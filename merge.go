@@ -0,0 +1,163 @@
+// commandkit/merge.go
+package commandkit
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// MergeStrategy selects how a file loaded later combines with earlier ones
+type MergeStrategy int
+
+const (
+	// MergeDeep merges nested tables key by key, so a later file overriding one nested
+	// key keeps its siblings; any other value is replaced (default)
+	MergeDeep MergeStrategy = iota
+	// MergeReplace replaces each top-level key as a whole
+	MergeReplace
+)
+
+// SetMergeStrategy sets how files loaded later combine with earlier ones; it applies
+// to files loaded afterwards and to reloads
+func (c *Config) SetMergeStrategy(strategy MergeStrategy) *Config {
+	c.mergeStrategy = strategy
+	return c
+}
+
+// SliceMerge selects how a list in a file loaded later combines with the same list
+// loaded earlier, e.g. cors_origins defined in two files
+type SliceMerge int
+
+const (
+	// SliceDefault uses the config-wide slice merge (SetSliceMerge), which replaces by default
+	SliceDefault SliceMerge = iota
+	// SliceReplace keeps only the later list
+	SliceReplace
+	// SliceAppend appends the later items to the earlier ones
+	SliceAppend
+	// SliceUnion appends the later items that are not present yet
+	SliceUnion
+)
+
+// fileSlices holds the lists successive files set at the same path, in load order.
+// They are combined when the value is looked up, so SetSliceMerge and MergeSlices
+// apply whether they are set before or after the files are loaded.
+type fileSlices [][]any
+
+// SetSliceMerge sets how lists in files loaded later combine with the same lists loaded
+// earlier, for keys without their own MergeSlices; lists are replaced by default
+func (c *Config) SetSliceMerge(merge SliceMerge) *Config {
+	c.sliceMerge = merge
+	return c
+}
+
+// MergeSlices sets how the list of this key in files loaded later combines with the
+// same list loaded earlier, overriding SetSliceMerge
+func (b *DefinitionBuilder) MergeSlices(merge SliceMerge) *DefinitionBuilder {
+	b.def.sliceMerge = merge
+	return b
+}
+
+// sliceMergeFor returns how lists combine at a file key path: the merge of the
+// definition read from that path, or else the config-wide one. Paths inside an
+// environment section match the same keys as top-level paths.
+func (c *Config) sliceMergeFor(path string) SliceMerge {
+	path = strings.ToLower(path)
+	if rest, ok := strings.CutPrefix(path, environmentsKey+"."); ok {
+		if _, key, found := strings.Cut(rest, "."); found {
+			path = key
+		}
+	}
+
+	merge := SliceDefault
+	var find func(defs map[string]*Definition, commands map[string]*Command)
+	find = func(defs map[string]*Definition, commands map[string]*Command) {
+		for key, def := range defs {
+			if def.fileKey != "" {
+				key = def.fileKey
+			}
+			if def.sliceMerge != SliceDefault && strings.ToLower(key) == path {
+				merge = def.sliceMerge
+				return
+			}
+		}
+		for _, cmd := range commands {
+			if merge == SliceDefault {
+				find(cmd.Definitions, cmd.SubCommands)
+			}
+		}
+	}
+	find(c.definitions, c.commands)
+
+	if merge == SliceDefault {
+		return c.sliceMerge
+	}
+	return merge
+}
+
+// combine merges incoming into existing without modifying either: tables key by key,
+// recursively, and lists are stacked for combineFileSlices; anything else is replaced
+func combine(existing, incoming any) any {
+	current, ok := existing.(map[string]any)
+	additions, ok2 := incoming.(map[string]any)
+	if !ok || !ok2 {
+		return stackSlices(existing, incoming)
+	}
+
+	merged := make(map[string]any, len(current)+len(additions))
+	for key, value := range current {
+		merged[key] = value
+	}
+	for key, value := range additions {
+		merged[key] = combine(merged[key], value)
+	}
+	return merged
+}
+
+// stackSlices adds the incoming list to the lists already set at the same path,
+// returning incoming when either value is not a list
+func stackSlices(existing, incoming any) any {
+	additions, ok := incoming.([]any)
+	if !ok {
+		return incoming
+	}
+	switch current := existing.(type) {
+	case []any:
+		return fileSlices{current, additions}
+	case fileSlices:
+		return append(current[:len(current):len(current)], additions)
+	default:
+		return incoming
+	}
+}
+
+// combineFileSlices combines the lists stacked at path as configured for it; other
+// values are returned unchanged
+func (c *Config) combineFileSlices(path string, value any) any {
+	lists, ok := value.(fileSlices)
+	if !ok {
+		return value
+	}
+
+	switch c.sliceMergeFor(path) {
+	case SliceAppend:
+		var merged []any
+		for _, list := range lists {
+			merged = append(merged, list...)
+		}
+		return merged
+	case SliceUnion:
+		merged := append([]any(nil), lists[0]...)
+		for _, list := range lists[1:] {
+			for _, item := range list {
+				if !slices.ContainsFunc(merged, func(existing any) bool { return reflect.DeepEqual(existing, item) }) {
+					merged = append(merged, item)
+				}
+			}
+		}
+		return merged
+	default:
+		return lists[len(lists)-1]
+	}
+}
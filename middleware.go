@@ -2,8 +2,9 @@
 package commandkit
 
 import (
+	"errors"
 	"fmt"
-	"log"
+	"os"
 	"time"
 )
 
@@ -23,9 +24,9 @@ func LoggingMiddleware(logger func(*CommandContext, time.Duration)) CommandMiddl
 			start := time.Now()
 
 			// Log command start
-			log.Printf("Starting command: %s", ctx.Command)
+			ctx.logf(VerbosityNormal, "Starting command: %s", ctx.Command)
 			if ctx.SubCommand != "" {
-				log.Printf("Subcommand: %s", ctx.SubCommand)
+				ctx.logf(VerbosityNormal, "Subcommand: %s", ctx.SubCommand)
 			}
 
 			// Execute next in chain
@@ -48,10 +49,13 @@ func DefaultLoggingMiddleware() CommandMiddleware {
 			status = "FAILED"
 		}
 
-		log.Printf("%s Command %s completed in %v", status, ctx.Command, duration)
+		ctx.logf(VerbosityNormal, "%s Command %s completed in %v", status, ctx.Command, duration)
 	})
 }
 
+// ErrAuthFailed is wrapped by the errors AuthMiddleware returns when authentication fails
+var ErrAuthFailed = errors.New("authentication failed")
+
 // AuthMiddleware creates middleware that validates authentication before command execution
 // The auth function should return nil if authentication succeeds, or an error if it fails
 func AuthMiddleware(authFunc func(*CommandContext) error) CommandMiddleware {
@@ -59,11 +63,11 @@ func AuthMiddleware(authFunc func(*CommandContext) error) CommandMiddleware {
 		return func(ctx *CommandContext) error {
 			// Check authentication before executing command
 			if err := authFunc(ctx); err != nil {
-				log.Printf("Authentication failed for command %s: %v", ctx.Command, err)
-				return fmt.Errorf("authentication failed: %w", err)
+				ctx.logf(VerbosityNormal, "Authentication failed for command %s: %v", ctx.Command, err)
+				return fmt.Errorf("%w: %w", ErrAuthFailed, err)
 			}
 
-			log.Printf("Authentication successful for command %s", ctx.Command)
+			ctx.logf(VerbosityNormal, "Authentication successful for command %s", ctx.Command)
 
 			// Auth passed, execute command
 			return next(ctx)
@@ -131,7 +135,7 @@ func ErrorHandlingMiddleware(errorHandler func(error, *CommandContext)) CommandM
 // DefaultErrorHandlingMiddleware creates standard error handling with logging
 func DefaultErrorHandlingMiddleware() CommandMiddleware {
 	return ErrorHandlingMiddleware(func(err error, ctx *CommandContext) {
-		log.Printf("💥 Error in command %s: %v", ctx.Command, err)
+		ctx.logf(VerbosityQuiet, "💥 Error in command %s: %v", ctx.Command, err)
 
 		// You could add monitoring integration here:
 		// monitor.Error("command_failed", map[string]any{
@@ -153,7 +157,7 @@ func TimingMiddleware() CommandMiddleware {
 			// Store timing in context for other middleware
 			ctx.Set("duration", duration)
 
-			log.Printf("Command %s took %v", ctx.Command, duration)
+			ctx.logf(VerbosityNormal, "Command %s took %v", ctx.Command, duration)
 
 			return err
 		}
@@ -166,10 +170,10 @@ func ConditionalMiddleware(condition func(*CommandContext) bool, middleware Comm
 	return func(next CommandFunc) CommandFunc {
 		return func(ctx *CommandContext) error {
 			if condition(ctx) {
-				log.Printf("Applying conditional middleware for command %s", ctx.Command)
+				ctx.logf(VerbosityNormal, "Applying conditional middleware for command %s", ctx.Command)
 				return middleware(next)(ctx)
 			}
-			log.Printf("Skipping conditional middleware for command %s", ctx.Command)
+			ctx.logf(VerbosityNormal, "Skipping conditional middleware for command %s", ctx.Command)
 			return next(ctx)
 		}
 	}
@@ -210,7 +214,8 @@ func RecoveryMiddleware() CommandMiddleware {
 		return func(ctx *CommandContext) error {
 			defer func() {
 				if r := recover(); r != nil {
-					log.Printf("Panic recovered in command %s: %v", ctx.Command, r)
+					// Scrub the panic text so crash output never carries live secrets
+					ctx.logf(VerbosityQuiet, "Panic recovered in command %s: %s", ctx.Command, redactContext(ctx, fmt.Sprint(r)))
 
 					// Store panic in context for error handling middleware
 					ctx.Set("panic", r)
@@ -222,33 +227,119 @@ func RecoveryMiddleware() CommandMiddleware {
 	}
 }
 
-// RateLimitMiddleware creates middleware that implements basic rate limiting
-// It tracks command execution count in the context
+// RateLimitMiddleware creates middleware allowing at most maxExecutions command
+// executions per window, counted across every execution that goes through it
 func RateLimitMiddleware(maxExecutions int, window time.Duration) CommandMiddleware {
+	return KeyedRateLimitMiddleware("", maxExecutions, window)
+}
+
+// KeyedRateLimitMiddleware creates middleware allowing at most maxExecutions per window
+// for each value of the context data key (e.g. a user, token or tenant stored with Set
+// by earlier middleware), so commands triggered through HTTP or queue adapters get
+// per-caller limits. Executions without that data share one limit; an empty dataKey
+// limits all executions together.
+func KeyedRateLimitMiddleware(dataKey string, maxExecutions int, window time.Duration) CommandMiddleware {
+	limiter := newRateLimiter(maxExecutions, window)
 	return func(next CommandFunc) CommandFunc {
 		return func(ctx *CommandContext) error {
-			// Initialize rate limit tracking in context
-
-			// Get current execution count
-			var count int
-			if c, exists := ctx.GetData("execution_count"); exists {
-				count = c.(int)
+			var caller string
+			if dataKey != "" {
+				if value, exists := ctx.GetData(dataKey); exists {
+					caller = fmt.Sprint(value)
+				}
 			}
 
-			count++
+			count, allowed := limiter.allow(caller)
 			ctx.Set("execution_count", count)
-
-			if count > maxExecutions {
+			if !allowed {
 				return fmt.Errorf("rate limit exceeded: %d executions allowed per %v", maxExecutions, window)
 			}
 
-			log.Printf("Command %s execution count: %d/%d", ctx.Command, count, maxExecutions)
+			ctx.logf(VerbosityNormal, "Command %s execution count: %d/%d", ctx.Command, count, maxExecutions)
+
+			return next(ctx)
+		}
+	}
+}
+
+// ChdirMiddleware creates middleware that runs commands in the directory held by the
+// config key pathKey (e.g. a daemon's data directory) and changes back afterwards.
+// Commands run in the current directory when the key has no value. The working
+// directory belongs to the whole process, so commands wrapped by it must not run
+// concurrently with other code relying on relative paths; ExecuteReport runs one
+// command at a time.
+func ChdirMiddleware(pathKey string) CommandMiddleware {
+	return func(next CommandFunc) CommandFunc {
+		return func(ctx *CommandContext) error {
+			dir, set, err := optionalValue[string](ctx, pathKey)
+			if err != nil {
+				return fmt.Errorf("failed to read working directory (config key: %s): %w", pathKey, err)
+			}
+			if !set || dir == "" {
+				return next(ctx)
+			}
+
+			previous, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("failed to get working directory: %w", err)
+			}
+			if err := os.Chdir(dir); err != nil {
+				return fmt.Errorf("failed to change to working directory (config key: %s): %w", pathKey, err)
+			}
+			defer os.Chdir(previous)
+
+			ctx.logf(VerbosityVerbose, "Command %s running in %s", ctx.Command, dir)
+
+			return next(ctx)
+		}
+	}
+}
+
+// UmaskMiddleware creates middleware that sets the file mode creation mask held by the
+// FileMode config key maskKey (e.g. 0027) while commands run, restoring the previous
+// mask afterwards. It does nothing when the key has no value or on platforms without
+// Unix permissions. Like the working directory, the umask is process-wide state: files
+// created concurrently by other goroutines get the same mask.
+func UmaskMiddleware(maskKey string) CommandMiddleware {
+	return func(next CommandFunc) CommandFunc {
+		return func(ctx *CommandContext) error {
+			mask, set, err := optionalValue[os.FileMode](ctx, maskKey)
+			if err != nil {
+				return fmt.Errorf("failed to read umask (config key: %s): %w", maskKey, err)
+			}
+			if !set || !umaskSupported {
+				return next(ctx)
+			}
+
+			previous := setUmask(mask)
+			defer setUmask(previous)
+
+			ctx.logf(VerbosityVerbose, "Command %s running with umask %04o", ctx.Command, mask.Perm())
 
 			return next(ctx)
 		}
 	}
 }
 
+// optionalValue returns the value of key for middleware reading optional settings,
+// with false when the key is defined but has no value; an undefined key or a value of
+// another type is an error
+func optionalValue[T any](ctx *CommandContext, key string) (T, bool, error) {
+	var zero T
+	c := getConfig(ctx).layerFor(key)
+	if _, defined := c.definitions[key]; !defined {
+		c = ctx.GlobalConfig
+	}
+	if _, defined := c.definitions[key]; !defined {
+		return zero, false, fmt.Errorf("configuration '%s' is not defined", key)
+	}
+	if value, exists := c.lookupValue(key); !exists || value == nil {
+		return zero, false, nil
+	}
+	value, err := Get[T](ctx, key)
+	return value, err == nil, err
+}
+
 // MetricsMiddleware creates middleware for collecting command metrics
 // This is useful for monitoring and analytics
 func MetricsMiddleware(metricsCollector func(*CommandContext, time.Duration, error)) CommandMiddleware {
@@ -274,7 +365,7 @@ func DefaultMetricsMiddleware() CommandMiddleware {
 			status = "error"
 		}
 
-		log.Printf("Metrics: command=%s duration=%v status=%s", ctx.Command, duration, status)
+		ctx.logf(VerbosityNormal, "Metrics: command=%s duration=%v status=%s", ctx.Command, duration, status)
 
 		// In a real application, you'd send this to a metrics system:
 		// metrics.Counter("command_executions", map[string]string{
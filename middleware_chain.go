@@ -1,6 +1,12 @@
 // commandkit/middleware_chain.go
 package commandkit
 
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
 // MiddlewareChain manages middleware application and composition
 type MiddlewareChain interface {
 	// Apply combines command-specific and global middleware and applies them in correct order
@@ -78,3 +84,62 @@ func (mc *middlewareChain) ApplyGlobalOnly(globalMiddleware []CommandMiddleware,
 
 	return finalFunc
 }
+
+// middlewareStep describes a global middleware entry and the commands it wraps
+type middlewareStep struct {
+	name        string
+	scope       string   // Why the entry applies, "" for every command
+	commands    []string // Commands it is limited to, nil for every command
+	subcommands []string // Subcommands it is limited to, nil for any
+}
+
+// applies reports whether the step wraps the command invoked as command [subcommand]
+func (s middlewareStep) applies(command, subcommand string) bool {
+	if s.commands != nil && !slices.Contains(s.commands, command) {
+		return false
+	}
+	return s.subcommands == nil || slices.Contains(s.subcommands, subcommand)
+}
+
+// MiddlewareChain lists the middleware that wraps a command, outermost first, e.g.
+// MiddlewareChain("start", "worker"). Global middleware comes first, including the
+// entries limited to some commands or subcommands, then the command's own middleware;
+// limited and command entries name their scope in parentheses. Middleware built with
+// ConditionalMiddleware is listed as such, since its condition is only known at run time.
+func (c *Config) MiddlewareChain(path ...string) ([]string, error) {
+	if len(path) == 0 || len(path) > 2 {
+		return nil, fmt.Errorf("expected a command and an optional subcommand, got %q", path)
+	}
+
+	cmd, exists := c.commands[path[0]]
+	if !exists {
+		return nil, fmt.Errorf("unknown command: %q", path[0])
+	}
+	var subcommand string
+	if len(path) == 2 {
+		subcommand = path[1]
+		if cmd = cmd.FindSubCommand(subcommand); cmd == nil {
+			return nil, fmt.Errorf("unknown subcommand: %q", strings.Join(path, " "))
+		}
+	}
+
+	var chain []string
+	for _, step := range c.middlewareSteps {
+		if !step.applies(path[0], subcommand) {
+			continue
+		}
+		if step.scope == "" {
+			chain = append(chain, step.name)
+		} else {
+			chain = append(chain, fmt.Sprintf("%s (%s)", step.name, step.scope))
+		}
+	}
+	for i, middleware := range cmd.Middleware {
+		name := middlewareName(middleware)
+		if i < len(cmd.traced) {
+			name = cmd.traced[i]
+		}
+		chain = append(chain, fmt.Sprintf("%s (command %s)", name, strings.Join(path, " ")))
+	}
+	return chain, nil
+}
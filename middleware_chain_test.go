@@ -3,6 +3,7 @@ package commandkit
 
 import (
 	"errors"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -374,3 +375,46 @@ func TestMiddlewareChain_Integration(t *testing.T) {
 		t.Error("Timing middleware did not measure correct duration")
 	}
 }
+
+func TestConfigMiddlewareChain(t *testing.T) {
+	cfg := New()
+	cfg.UseMiddleware(RecoveryMiddleware())
+	cfg.UseMiddlewareForCommands([]string{"admin"}, TokenAuthMiddleware("ADMIN_TOKEN"))
+	cfg.UseMiddlewareForSubcommands("start", []string{"worker"}, TimingMiddleware())
+	cfg.UseMiddleware(DefaultMetricsMiddleware())
+
+	start := cfg.Command("start").Func(func(*CommandContext) error { return nil })
+	start.SubCommand("worker").
+		Func(func(*CommandContext) error { return nil }).
+		Middleware(ChdirMiddleware("DATA_DIR"))
+	start.SubCommand("web").Func(func(*CommandContext) error { return nil })
+
+	tests := []struct {
+		path []string
+		want []string
+	}{
+		{[]string{"start", "worker"}, []string{
+			"commandkit.RecoveryMiddleware",
+			"commandkit.TimingMiddleware (start subcommands: worker)",
+			"commandkit.MetricsMiddleware",
+			"commandkit.ChdirMiddleware (command start worker)",
+		}},
+		{[]string{"start", "web"}, []string{
+			"commandkit.RecoveryMiddleware",
+			"commandkit.MetricsMiddleware",
+		}},
+	}
+	for _, tt := range tests {
+		chain, err := cfg.MiddlewareChain(tt.path...)
+		if err != nil {
+			t.Fatalf("MiddlewareChain(%v) failed: %v", tt.path, err)
+		}
+		if !reflect.DeepEqual(chain, tt.want) {
+			t.Errorf("MiddlewareChain(%v) = %q, want %q", tt.path, chain, tt.want)
+		}
+	}
+
+	if _, err := cfg.MiddlewareChain("stop"); err == nil {
+		t.Error("expected an error for an unknown command")
+	}
+}
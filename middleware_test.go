@@ -2,6 +2,8 @@ package commandkit
 
 import (
 	"errors"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -374,6 +376,29 @@ func TestRecoveryMiddleware(t *testing.T) {
 	}
 }
 
+func TestRecoveryMiddlewareRedactsSecrets(t *testing.T) {
+	cfg := New()
+	cfg.secrets.Store("DB_PASSWORD", "hunter2-secret")
+	defer cfg.Destroy()
+
+	middleware := RecoveryMiddleware()
+	next := func(ctx *CommandContext) error {
+		panic("connect failed with password hunter2-secret")
+	}
+
+	ctx := NewCommandContext([]string{}, cfg, "test", "")
+	logs := captureLogs(t, func() {
+		_ = middleware(next)(ctx)
+	})
+
+	if strings.Contains(logs, "hunter2-secret") {
+		t.Errorf("Expected panic output to be redacted, got %q", logs)
+	}
+	if !strings.Contains(logs, "[REDACTED]") {
+		t.Errorf("Expected redaction placeholder in output, got %q", logs)
+	}
+}
+
 func TestRateLimitMiddleware(t *testing.T) {
 	middleware := RateLimitMiddleware(2, time.Minute)
 
@@ -407,6 +432,97 @@ func TestRateLimitMiddleware(t *testing.T) {
 	}
 }
 
+func TestKeyedRateLimitMiddleware(t *testing.T) {
+	middleware := KeyedRateLimitMiddleware("tenant", 2, 50*time.Millisecond)
+	handler := middleware(func(ctx *CommandContext) error { return nil })
+
+	run := func(tenant string) error {
+		ctx := NewCommandContext([]string{}, New(), "test", "")
+		if tenant != "" {
+			ctx.Set("tenant", tenant)
+		}
+		return handler(ctx)
+	}
+
+	// Each tenant has its own limit, shared across executions
+	for _, tenant := range []string{"acme", "acme", "globex", "globex"} {
+		if err := run(tenant); err != nil {
+			t.Fatalf("unexpected error for %s: %v", tenant, err)
+		}
+	}
+	if err := run("acme"); err == nil {
+		t.Error("expected the third acme execution to be limited")
+	}
+	if err := run(""); err != nil {
+		t.Errorf("executions without a tenant have their own limit, got %v", err)
+	}
+
+	// The window slides
+	time.Sleep(60 * time.Millisecond)
+	if err := run("acme"); err != nil {
+		t.Errorf("expected acme to be allowed once the window passed, got %v", err)
+	}
+}
+
+func TestChdirAndUmaskMiddleware(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("EvalSymlinks failed: %v", err)
+	}
+	start, _ := os.Getwd()
+
+	cfg := New()
+	cfg.Define("DATA_DIR").String().Default(dir)
+	cfg.Define("UMASK").FileMode().Default(0o077)
+	cfg.Define("UNSET_DIR").String()
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := NewCommandContext([]string{}, cfg, "serve", "")
+
+	var workdir string
+	var mode os.FileMode
+	handler := ChdirMiddleware("DATA_DIR")(UmaskMiddleware("UMASK")(func(ctx *CommandContext) error {
+		workdir, _ = os.Getwd()
+		if err := os.WriteFile("state", nil, 0o666); err != nil {
+			return err
+		}
+		info, err := os.Stat("state")
+		if err != nil {
+			return err
+		}
+		mode = info.Mode().Perm()
+		return nil
+	}))
+	if err := handler(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if workdir != dir {
+		t.Errorf("command ran in %s, want %s", workdir, dir)
+	}
+	if current, _ := os.Getwd(); current != start {
+		t.Errorf("working directory not restored: %s", current)
+	}
+	if umaskSupported && mode != 0o600 {
+		t.Errorf("file created with mode %04o, want 0600 under umask 0077", mode)
+	}
+
+	// Keys without a value leave the environment alone
+	noop := func(*CommandContext) error { return nil }
+	if err := ChdirMiddleware("UNSET_DIR")(noop)(ctx); err != nil {
+		t.Errorf("unexpected error for a key without a value: %v", err)
+	}
+
+	// Undefined keys and values of another type are configuration mistakes
+	if err := ChdirMiddleware("MISSING")(noop)(ctx); err == nil || !strings.Contains(err.Error(), "not defined") {
+		t.Errorf("expected an error for an undefined key, got %v", err)
+	}
+	if err := UmaskMiddleware("DATA_DIR")(noop)(ctx); err == nil || !strings.Contains(err.Error(), "DATA_DIR") {
+		t.Errorf("expected an error for a key that is not a FileMode, got %v", err)
+	}
+}
+
 func TestMetricsMiddleware(t *testing.T) {
 	var metricsCtx *CommandContext
 	var metricsDuration time.Duration
@@ -0,0 +1,261 @@
+// commandkit/output.go
+package commandkit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"golang.org/x/term"
+	"gopkg.in/yaml.v3"
+)
+
+// OutputFormat selects how command results are rendered
+type OutputFormat string
+
+const (
+	OutputTable OutputFormat = "table" // Aligned columns for humans
+	OutputJSON  OutputFormat = "json"  // Indented JSON
+	OutputYAML  OutputFormat = "yaml"  // YAML document
+)
+
+// outputKey is the configuration key defined by OutputFlag
+const outputKey = "OUTPUT"
+
+// outputIsTerminal reports whether stdout is a terminal; replaced in tests
+var outputIsTerminal = func() bool {
+	return term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// OutputFlag defines a persistent flag (e.g. --output) selecting the format used to
+// render command output: table, json or yaml. When it is not given, output is a table
+// on a terminal and JSON when stdout is piped.
+func (c *Config) OutputFlag(flag string) *DefinitionBuilder {
+	return c.PersistentFlag(outputKey, flag).
+		String().
+		OneOf(string(OutputTable), string(OutputJSON), string(OutputYAML)).
+		Description("Output format (table, json, yaml; default: table on a terminal, json otherwise)")
+}
+
+// ResultFunc sets a command function that returns its result instead of printing it;
+// the result is rendered in the selected output format once the command succeeds
+func (b *CommandBuilder) ResultFunc(fn func(*CommandContext) (any, error)) *CommandBuilder {
+	b.cmd.Func = func(ctx *CommandContext) error {
+		result, err := fn(ctx)
+		if err != nil {
+			return err
+		}
+		ctx.Result(result)
+		return nil
+	}
+	return b
+}
+
+// Result sets the command result, rendered in the selected output format after the
+// command and its middleware succeed
+func (ctx *CommandContext) Result(v any) {
+	ctx.result = v
+	ctx.hasResult = true
+}
+
+// OutputFormat returns the output format selected with the OutputFlag flag or, when
+// none was selected, table on a terminal and JSON for pipes
+func (ctx *CommandContext) OutputFormat() OutputFormat {
+	if ctx.GlobalConfig != nil {
+		if value, exists := ctx.GlobalConfig.lookupValue(outputKey); exists {
+			if format, ok := value.(string); ok && format != "" {
+				return OutputFormat(format)
+			}
+		}
+	}
+	if outputIsTerminal() {
+		return OutputTable
+	}
+	return OutputJSON
+}
+
+// Print writes v to stdout in the selected output format
+func (ctx *CommandContext) Print(v any) error {
+	return writeOutput(os.Stdout, ctx.OutputFormat(), v)
+}
+
+// Table writes rows to stdout in the selected output format: aligned columns under
+// headers for tables, or one object per row keyed by header for JSON and YAML
+func (ctx *CommandContext) Table(headers []string, rows [][]string) error {
+	format := ctx.OutputFormat()
+	if format != OutputTable {
+		objects := make([]map[string]string, len(rows))
+		for i, row := range rows {
+			objects[i] = make(map[string]string, len(headers))
+			for j, header := range headers {
+				if j < len(row) {
+					objects[i][header] = row[j]
+				}
+			}
+		}
+		return writeOutput(os.Stdout, format, objects)
+	}
+	return writeRows(os.Stdout, headers, rows)
+}
+
+// writeResult renders the command result, if one was set
+func (ctx *CommandContext) writeResult(w io.Writer) error {
+	if !ctx.hasResult || ctx.result == nil {
+		return nil
+	}
+	return writeOutput(w, ctx.OutputFormat(), ctx.result)
+}
+
+// writeOutput renders v in format
+func writeOutput(w io.Writer, format OutputFormat, v any) error {
+	switch format {
+	case OutputJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	case OutputYAML:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(v); err != nil {
+			return err
+		}
+		return encoder.Close()
+	case OutputTable:
+		return writeTable(w, v)
+	default:
+		return fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+// writeTable renders slices of structs or maps as one row per element, a single struct
+// or map as key/value rows, and anything else as plain text
+func writeTable(w io.Writer, v any) error {
+	value := reflect.Indirect(reflect.ValueOf(v))
+
+	var headers []string
+	var rows [][]string
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		if value.Len() == 0 {
+			return nil
+		}
+		headers = tableColumns(reflect.Indirect(value.Index(0)))
+		if headers == nil {
+			for i := 0; i < value.Len(); i++ {
+				fmt.Fprintln(w, formatCell(value.Index(i)))
+			}
+			return nil
+		}
+		for i := 0; i < value.Len(); i++ {
+			rows = append(rows, tableRow(reflect.Indirect(value.Index(i)), headers))
+		}
+	case reflect.Struct, reflect.Map:
+		for _, column := range tableColumns(value) {
+			rows = append(rows, []string{column, tableRow(value, []string{column})[0]})
+		}
+	default:
+		_, err := fmt.Fprintln(w, formatCell(value))
+		return err
+	}
+
+	return writeRows(w, headers, rows)
+}
+
+// writeRows writes aligned columns, under upper-cased headers if there are any
+func writeRows(w io.Writer, headers []string, rows [][]string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if headers != nil {
+		upper := make([]string, len(headers))
+		for i, header := range headers {
+			upper[i] = strings.ToUpper(header)
+		}
+		fmt.Fprintln(tw, strings.Join(upper, "\t"))
+	}
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// tableColumns lists the exported fields of a struct (honoring json names) or the
+// sorted keys of a map; nil for other kinds
+func tableColumns(value reflect.Value) []string {
+	switch value.Kind() {
+	case reflect.Struct:
+		var columns []string
+		for _, field := range reflect.VisibleFields(value.Type()) {
+			if name, ok := fieldColumn(field); ok {
+				columns = append(columns, name)
+			}
+		}
+		return columns
+	case reflect.Map:
+		columns := make([]string, 0, value.Len())
+		for _, key := range value.MapKeys() {
+			columns = append(columns, fmt.Sprint(key.Interface()))
+		}
+		sort.Strings(columns)
+		return columns
+	default:
+		return nil
+	}
+}
+
+// tableRow returns the cells of value for columns
+func tableRow(value reflect.Value, columns []string) []string {
+	cells := make([]string, len(columns))
+	for i, column := range columns {
+		switch value.Kind() {
+		case reflect.Struct:
+			for _, field := range reflect.VisibleFields(value.Type()) {
+				if name, ok := fieldColumn(field); ok && name == column {
+					cells[i] = formatCell(value.FieldByIndex(field.Index))
+					break
+				}
+			}
+		case reflect.Map:
+			for _, key := range value.MapKeys() {
+				if fmt.Sprint(key.Interface()) == column {
+					cells[i] = formatCell(value.MapIndex(key))
+					break
+				}
+			}
+		}
+	}
+	return cells
+}
+
+// fieldColumn returns the column name of a struct field, skipping unexported,
+// embedded and json:"-" fields
+func fieldColumn(field reflect.StructField) (string, bool) {
+	if !field.IsExported() || field.Anonymous {
+		return "", false
+	}
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return "", false
+	}
+	if name == "" {
+		name = field.Name
+	}
+	return name, true
+}
+
+// formatCell formats a single value for a table cell
+func formatCell(value reflect.Value) string {
+	if !value.IsValid() {
+		return ""
+	}
+	if value.Kind() == reflect.Interface || value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return ""
+		}
+		value = value.Elem()
+	}
+	return fmt.Sprint(value.Interface())
+}
@@ -0,0 +1,125 @@
+package commandkit
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+type testService struct {
+	Name     string `json:"name" yaml:"name"`
+	Replicas int    `json:"replicas" yaml:"replicas"`
+	internal string
+}
+
+func TestWriteOutput(t *testing.T) {
+	services := []testService{{Name: "api", Replicas: 3}, {Name: "worker", Replicas: 12}}
+
+	tests := []struct {
+		name   string
+		format OutputFormat
+		value  any
+		want   string
+	}{
+		{"table of structs", OutputTable, services, "NAME    REPLICAS\napi     3\nworker  12\n"},
+		{"table of one struct", OutputTable, &services[0], "name      api\nreplicas  3\n"},
+		{"table of a map", OutputTable, map[string]int{"b": 2, "a": 1}, "a  1\nb  2\n"},
+		{"table of scalars", OutputTable, []string{"x", "y"}, "x\ny\n"},
+		{"plain value", OutputTable, "done", "done\n"},
+		{"json", OutputJSON, services[:1], "[\n  {\n    \"name\": \"api\",\n    \"replicas\": 3\n  }\n]\n"},
+		{"yaml", OutputYAML, services[:1], "- name: api\n  replicas: 3\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeOutput(&buf, tt.format, tt.value); err != nil {
+				t.Fatalf("writeOutput failed: %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("got:\n%q\nwant:\n%q", buf.String(), tt.want)
+			}
+		})
+	}
+
+	if err := writeOutput(&bytes.Buffer{}, "xml", services); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestCommandResultOutput(t *testing.T) {
+	newConfig := func() *Config {
+		cfg := New()
+		cfg.OutputFlag("output")
+		cfg.Command("list").ResultFunc(func(ctx *CommandContext) (any, error) {
+			return []testService{{Name: "api", Replicas: 3}}, nil
+		})
+		cfg.Command("noop").Func(func(ctx *CommandContext) error { return nil })
+		return cfg
+	}
+
+	tests := []struct {
+		args     []string
+		terminal bool
+		want     string
+	}{
+		{[]string{"app", "list"}, true, "NAME  REPLICAS\napi   3\n"},
+		{[]string{"app", "list"}, false, "\"replicas\": 3"},
+		{[]string{"app", "list", "--output", "yaml"}, false, "- name: api\n  replicas: 3\n"},
+		{[]string{"app", "--output=json", "list"}, true, "\"replicas\": 3"},
+		{[]string{"app", "noop", "--output", "json"}, true, ""},
+	}
+
+	defer func(original func() bool) { outputIsTerminal = original }(outputIsTerminal)
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args[1:], " "), func(t *testing.T) {
+			outputIsTerminal = func() bool { return tt.terminal }
+			var err error
+			out := captureStdout(t, func() {
+				err = newConfig().Execute(tt.args)
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !strings.Contains(out, tt.want) || tt.want == "" && out != "" {
+				t.Errorf("output = %q, want %q", out, tt.want)
+			}
+		})
+	}
+}
+
+func TestContextPrintAndTable(t *testing.T) {
+	defer func(original func() bool) { outputIsTerminal = original }(outputIsTerminal)
+	outputIsTerminal = func() bool { return true }
+
+	headers := []string{"name", "status"}
+	rows := [][]string{{"api", "running"}, {"worker", "stopped"}}
+
+	cfg := New()
+	cfg.OutputFlag("output")
+	cfg.Command("status").Func(func(ctx *CommandContext) error {
+		if err := ctx.Table(headers, rows); err != nil {
+			return err
+		}
+		return ctx.Print(map[string]int{"total": 2})
+	})
+
+	out := captureStdout(t, func() {
+		if err := cfg.Execute([]string{"app", "status"}); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+	if want := "NAME    STATUS\napi     running\nworker  stopped\ntotal  2\n"; out != want {
+		t.Errorf("terminal output = %q, want %q", out, want)
+	}
+
+	out = captureStdout(t, func() {
+		if err := cfg.Execute([]string{"app", "status", "--output", "yaml"}); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+	if want := "- name: api\n  status: running\n- name: worker\n  status: stopped\ntotal: 2\n"; out != want {
+		t.Errorf("yaml output = %q, want %q", out, want)
+	}
+}
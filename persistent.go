@@ -0,0 +1,52 @@
+// commandkit/persistent.go
+package commandkit
+
+import "strings"
+
+// PersistentFlag defines a global configuration key whose flag is accepted by every
+// command and subcommand, e.g. --config, --verbose or --output
+func (c *Config) PersistentFlag(key, flag string) *DefinitionBuilder {
+	return c.Define(key).Flag(flag).Persistent()
+}
+
+// persistentDefinitions returns the global definitions marked as persistent
+func (c *Config) persistentDefinitions() map[string]*Definition {
+	defs := make(map[string]*Definition)
+	for key, def := range c.definitions {
+		if def.persistent && def.flag != "" {
+			defs[key] = def
+		}
+	}
+	return defs
+}
+
+// splitPersistentArgs pulls persistent flags (and their values) out of the command
+// line, wherever they appear, so routing and command flag parsing never see them
+func (c *Config) splitPersistentArgs(args []string) (persistent, rest []string) {
+	defs := c.persistentDefinitions()
+	if len(defs) == 0 {
+		return nil, args
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+
+		name, _, hasValue := strings.Cut(flagName(arg), "=")
+		def := findFlagDefinition(defs, name)
+		if def == nil {
+			rest = append(rest, arg)
+			continue
+		}
+
+		persistent = append(persistent, arg)
+		if !hasValue && def.takesValue() && i+1 < len(args) {
+			i++
+			persistent = append(persistent, args[i])
+		}
+	}
+	return persistent, rest
+}
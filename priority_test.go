@@ -2,6 +2,7 @@ package commandkit
 
 import (
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -314,3 +315,34 @@ func TestOverrideDetectionWithCustomPriority(t *testing.T) {
 		t.Errorf("Expected env_value, got %s", value)
 	}
 }
+
+func TestSourcePinning(t *testing.T) {
+	newConfig := func() *Config {
+		cfg := New().WithEnviron([]string{"API_TOKEN=from-env"})
+		cfg.Define("API_TOKEN").String().Env("API_TOKEN").Flag("api-token").NeverFrom(SourceFlag)
+		cfg.Define("REGION").String().Env("REGION").Flag("region").Default("eu-west-1").OnlyFrom(SourceEnv)
+		return cfg
+	}
+
+	cfg := newConfig()
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.values["API_TOKEN"] != "from-env" || cfg.values["REGION"] != "eu-west-1" {
+		t.Errorf("unexpected values: %v", cfg.values)
+	}
+
+	cfg = newConfig()
+	err := cfg.Process([]string{"app", "--api-token", "leaked", "--region", "us-east-1"})
+	if err == nil {
+		t.Fatal("expected pinned sources to reject flag values")
+	}
+	for _, want := range []string{
+		"value from flag is not allowed",
+		"value from flag is not allowed, only from environment",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in error, got: %v", want, err)
+		}
+	}
+}
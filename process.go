@@ -0,0 +1,173 @@
+// commandkit/process.go
+package commandkit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/awnumar/memguard"
+)
+
+// ExitCodeConfigError is the exit code used by MustProcess when the configuration is invalid
+// (EX_CONFIG from sysexits.h)
+const ExitCodeConfigError = 78
+
+// ErrHelpRequested is returned by Process when help was requested and has been shown
+var ErrHelpRequested = errors.New("help requested")
+
+// ErrorFormat selects how configuration errors are printed
+type ErrorFormat int
+
+const (
+	ErrorFormatText ErrorFormat = iota // Usage line plus one line per error (default)
+	ErrorFormatJSON                    // A single JSON document for machine consumption
+)
+
+// SetErrorFormat sets the format used when printing configuration errors
+func (c *Config) SetErrorFormat(format ErrorFormat) *Config {
+	c.errorFormat = format
+	return c
+}
+
+// Process resolves and validates the global configuration from args, laid out like
+// os.Args (program name first). All errors are aggregated into a ConfigErrors value.
+// If help was requested it is shown and ErrHelpRequested is returned.
+func (c *Config) Process(args []string) error {
+	if errs := c.definitionConflicts(); len(errs) > 0 {
+		return ConfigErrors(errs)
+	}
+
+	args, errs := c.applyConfigFlag(args)
+	errs = append(errs, c.checkEnvironment(c.fileConfig)...)
+	if len(errs) > 0 {
+		return ConfigErrors(errs)
+	}
+
+	var rest []string
+	if len(args) > 1 {
+		rest = args[1:]
+	}
+
+	ctx := NewCommandContext(rest, c, "", "")
+	errs = c.processConfigWithContext(rest, ctx)
+	if len(errs) == 0 {
+		return nil
+	}
+
+	if ctx.IsHelpRequested() {
+		if err := c.ShowGlobalHelp(); err != nil {
+			return err
+		}
+		return ErrHelpRequested
+	}
+
+	return ConfigErrors(errs)
+}
+
+// ProcessInto runs Process, stores its error in *errp and reports whether it succeeded.
+// It suits functions with a named error result:
+//
+//	func run() (err error) {
+//		if !cfg.ProcessInto(os.Args, &err) {
+//			return
+//		}
+//		...
+//	}
+func (c *Config) ProcessInto(args []string, errp *error) bool {
+	err := c.Process(args)
+	if errp != nil {
+		*errp = err
+	}
+	return err == nil
+}
+
+// MustProcess runs Process and exits when it fails: after help with code 0, otherwise
+// the errors are printed to stderr in the configured ErrorFormat and the process exits
+// with ExitCodeConfigError
+func (c *Config) MustProcess(args []string) {
+	err := c.Process(args)
+	if err == nil {
+		return
+	}
+	if errors.Is(err, ErrHelpRequested) {
+		memguard.SafeExit(0)
+	}
+
+	c.PrintErrors(os.Stderr, err)
+	memguard.SafeExit(ExitCodeConfigError)
+}
+
+// PrintErrors writes err to w in the configured ErrorFormat, with secrets redacted
+func (c *Config) PrintErrors(w io.Writer, err error) error {
+	return c.printErrors(w, getExecutableName(), err)
+}
+
+// printErrors renders err for the given executable name
+func (c *Config) printErrors(w io.Writer, executable string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var configErrs ConfigErrors
+	if !errors.As(err, &configErrs) {
+		configErrs = ConfigErrors{{Key: "config", ErrorDescription: err.Error()}}
+	}
+
+	var text string
+	if c.errorFormat == ErrorFormatJSON {
+		data, jsonErr := json.Marshal(newErrorReport(configErrs))
+		if jsonErr != nil {
+			return jsonErr
+		}
+		text = string(data) + "\n"
+	} else {
+		execCtx := NewExecutionContext(executable)
+		for _, configErr := range configErrs {
+			execCtx.CollectConfigError(c, configErr)
+		}
+		rendered, renderErr := execCtx.renderErrorsWithCommand(nil, c.getHelpService())
+		if renderErr != nil {
+			return renderErr
+		}
+		text = rendered
+		if !strings.HasSuffix(text, "\n") {
+			text += "\n"
+		}
+	}
+
+	_, writeErr := fmt.Fprint(w, c.Redact(text))
+	return writeErr
+}
+
+// errorReport is the JSON document written for ErrorFormatJSON
+type errorReport struct {
+	Errors []errorReportEntry `json:"errors"`
+}
+
+// errorReportEntry describes a single configuration error in an errorReport
+type errorReportEntry struct {
+	Key     string `json:"key"`
+	Source  string `json:"source,omitempty"`
+	Value   string `json:"value,omitempty"`
+	Display string `json:"display,omitempty"`
+	Error   string `json:"error"`
+}
+
+// newErrorReport converts configuration errors into their JSON representation
+func newErrorReport(errs ConfigErrors) errorReport {
+	report := errorReport{Errors: make([]errorReportEntry, 0, len(errs))}
+	for _, err := range errs {
+		report.Errors = append(report.Errors, errorReportEntry{
+			Key:     err.Key,
+			Source:  err.Source,
+			Value:   err.Value,
+			Display: err.Display,
+			Error:   err.ErrorDescription,
+		})
+	}
+	return report
+}
@@ -0,0 +1,103 @@
+// commandkit/process_test.go
+package commandkit
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestProcessAggregatesErrors(t *testing.T) {
+	cfg := New()
+	cfg.Define("API_URL").String().Env("TEST_PROCESS_API_URL").Required()
+	cfg.Define("PORT").Int64().Flag("port").Range(1, 65535)
+
+	err := cfg.Process([]string{"app", "--port", "70000"})
+	if err == nil {
+		t.Fatal("Expected error for invalid configuration")
+	}
+
+	var configErrs ConfigErrors
+	if !errors.As(err, &configErrs) {
+		t.Fatalf("Expected ConfigErrors, got %T", err)
+	}
+	if len(configErrs) != 2 {
+		t.Errorf("Expected 2 aggregated errors, got %d: %v", len(configErrs), err)
+	}
+	if !strings.Contains(err.Error(), "API_URL") || !strings.Contains(err.Error(), "PORT") {
+		t.Errorf("Expected error message to name both keys, got %q", err.Error())
+	}
+}
+
+func TestProcessInto(t *testing.T) {
+	cfg := New()
+	cfg.Define("PORT").Int64().Flag("port").Default(int64(8080))
+
+	var err error
+	if !cfg.ProcessInto([]string{"app", "--port", "9090"}, &err) || err != nil {
+		t.Fatalf("Expected processing to succeed, got %v", err)
+	}
+	if value, _ := cfg.lookupValue("PORT"); value != int64(9090) {
+		t.Errorf("Expected PORT 9090, got %v", value)
+	}
+
+	cfg = New()
+	cfg.Define("PORT").Int64().Flag("port").Required()
+	if cfg.ProcessInto([]string{"app"}, &err) || err == nil {
+		t.Error("Expected processing to fail and store the error")
+	}
+}
+
+func TestProcessHelpRequested(t *testing.T) {
+	cfg := New()
+	cfg.Define("PORT").Int64().Flag("port").Required()
+
+	var err error
+	captureStdout(t, func() {
+		err = cfg.Process([]string{"app", "--help"})
+	})
+	if !errors.Is(err, ErrHelpRequested) {
+		t.Errorf("Expected ErrHelpRequested, got %v", err)
+	}
+}
+
+func TestPrintErrorsFormats(t *testing.T) {
+	cfg := New()
+	cfg.Define("TOKEN").String().Flag("token").Secret().MinLength(20)
+
+	err := cfg.Process([]string{"app", "--token", "short-secret"})
+	if err == nil {
+		t.Fatal("Expected validation error")
+	}
+
+	var text strings.Builder
+	if printErr := cfg.PrintErrors(&text, err); printErr != nil {
+		t.Fatalf("PrintErrors failed: %v", printErr)
+	}
+	if !strings.Contains(text.String(), "Configuration errors:") || !strings.Contains(text.String(), "--token") {
+		t.Errorf("Expected text error listing, got %q", text.String())
+	}
+
+	var jsonOut strings.Builder
+	cfg.SetErrorFormat(ErrorFormatJSON)
+	if printErr := cfg.PrintErrors(&jsonOut, err); printErr != nil {
+		t.Fatalf("PrintErrors failed: %v", printErr)
+	}
+	if strings.Contains(jsonOut.String(), "short-secret") {
+		t.Error("Expected secret value to be kept out of JSON output")
+	}
+
+	var report struct {
+		Errors []struct {
+			Key   string `json:"key"`
+			Error string `json:"error"`
+		} `json:"errors"`
+	}
+	if jsonErr := json.Unmarshal([]byte(jsonOut.String()), &report); jsonErr != nil {
+		t.Fatalf("Expected valid JSON, got %q: %v", jsonOut.String(), jsonErr)
+	}
+	if len(report.Errors) != 1 || report.Errors[0].Key != "TOKEN" || report.Errors[0].Error == "" {
+		t.Errorf("Unexpected JSON report: %+v", report)
+	}
+}
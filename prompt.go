@@ -0,0 +1,72 @@
+// commandkit/prompt.go
+package commandkit
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/awnumar/memguard"
+	"golang.org/x/term"
+)
+
+// Terminal access used by secret prompting; replaced in tests
+var (
+	promptInputFd              = int(os.Stdin.Fd())
+	promptOutput     io.Writer = os.Stderr
+	promptIsTerminal           = term.IsTerminal
+	promptReadHidden           = term.ReadPassword
+)
+
+// hasSourceValue reports whether any source in the definition's priority provides a value
+func (c *Config) hasSourceValue(key string, def *Definition) bool {
+	for _, source := range def.getEffectivePriority(c.defaultPriority) {
+		if _, exists := c.getValueFromSource(key, def, source); exists {
+			return true
+		}
+	}
+	return false
+}
+
+// promptSecret asks for a secret on the terminal with echo disabled and stores the
+// input straight into a LockedBuffer. It returns false when stdin is not a terminal or
+// c stages a reload, leaving the definition to the normal "not provided" handling.
+func (c *Config) promptSecret(key string, def *Definition) (bool, error) {
+	if c.staging || !promptIsTerminal(promptInputFd) {
+		return false, nil
+	}
+
+	label := key
+	if def.description != "" {
+		label = fmt.Sprintf("%s (%s)", def.description, key)
+	}
+	fmt.Fprintf(promptOutput, "%s: ", label)
+	input, err := promptReadHidden(promptInputFd)
+	fmt.Fprintln(promptOutput)
+	if err != nil {
+		memguard.WipeBytes(input)
+		return true, fmt.Errorf("failed to read from terminal: %w", err)
+	}
+
+	if len(input) == 0 {
+		if def.required {
+			return true, fmt.Errorf("Not provided")
+		}
+		return true, nil
+	}
+
+	// The buffer takes ownership of input and wipes it
+	buf := memguard.NewBufferFromBytes(input)
+	for _, validation := range def.validations {
+		if validation.Name == "required" {
+			continue
+		}
+		if err := validation.Check(buf.String()); err != nil {
+			buf.Destroy()
+			return true, err
+		}
+	}
+
+	c.secrets.storeBuffer(key, buf)
+	return true, nil
+}
@@ -0,0 +1,133 @@
+// commandkit/prompt_test.go
+package commandkit
+
+import (
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeTerminal replaces terminal access for the duration of a test
+func fakeTerminal(t *testing.T, isTerminal bool, input string, readErr error) *strings.Builder {
+	t.Helper()
+	origIsTerminal, origRead, origOutput := promptIsTerminal, promptReadHidden, promptOutput
+	t.Cleanup(func() {
+		promptIsTerminal, promptReadHidden, promptOutput = origIsTerminal, origRead, origOutput
+	})
+
+	var output strings.Builder
+	promptOutput = &output
+	promptIsTerminal = func(int) bool { return isTerminal }
+	promptReadHidden = func(int) ([]byte, error) { return []byte(input), readErr }
+	return &output
+}
+
+func TestPromptIfMissingStoresSecret(t *testing.T) {
+	output := fakeTerminal(t, true, "s3cr3t-token", nil)
+
+	cfg := New()
+	cfg.Define("API_TOKEN").String().Env("TEST_PROMPT_API_TOKEN").Required().PromptIfMissing().Description("API token")
+	defer cfg.Destroy()
+
+	if errs := cfg.processConfigWithContext([]string{}, nil); len(errs) > 0 {
+		t.Fatalf("Unexpected errors: %v", errs)
+	}
+	if got := cfg.GetSecret("API_TOKEN").String(); got != "s3cr3t-token" {
+		t.Errorf("Expected prompted secret to be stored, got %q", got)
+	}
+	if !cfg.IsSecret("API_TOKEN") {
+		t.Error("Expected PromptIfMissing to mark the definition secret")
+	}
+	if !strings.Contains(output.String(), "API token (API_TOKEN): ") {
+		t.Errorf("Expected prompt label, got %q", output.String())
+	}
+}
+
+func TestPromptIfMissingSkippedWhenProvided(t *testing.T) {
+	fakeTerminal(t, true, "", errors.New("prompt should not be shown"))
+	t.Setenv("TEST_PROMPT_API_TOKEN", "from-env")
+
+	cfg := New()
+	cfg.Define("API_TOKEN").String().Env("TEST_PROMPT_API_TOKEN").Required().PromptIfMissing()
+	defer cfg.Destroy()
+
+	if errs := cfg.processConfigWithContext([]string{}, nil); len(errs) > 0 {
+		t.Fatalf("Unexpected errors: %v", errs)
+	}
+	if got := cfg.GetSecret("API_TOKEN").String(); got != "from-env" {
+		t.Errorf("Expected env value, got %q", got)
+	}
+}
+
+func TestPromptIfMissingErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		isTerminal bool
+		input      string
+		readErr    error
+		expected   string
+	}{
+		{name: "not a terminal", isTerminal: false, expected: "Not provided"},
+		{name: "empty input", isTerminal: true, input: "", expected: "Not provided"},
+		{name: "read failure", isTerminal: true, readErr: io.ErrUnexpectedEOF, expected: "failed to read from terminal"},
+		{name: "validation", isTerminal: true, input: "short", expected: "length"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeTerminal(t, tt.isTerminal, tt.input, tt.readErr)
+
+			cfg := New()
+			cfg.Define("API_TOKEN").String().Required().MinLength(10).PromptIfMissing()
+			defer cfg.Destroy()
+
+			errs := cfg.processConfigWithContext([]string{}, nil)
+			if len(errs) != 1 || !strings.Contains(errs[0].ErrorDescription, tt.expected) {
+				t.Errorf("Expected error containing %q, got %v", tt.expected, errs)
+			}
+		})
+	}
+}
+
+func TestPromptIfMissingNotRepeatedOnReload(t *testing.T) {
+	fakeTerminal(t, true, "", nil)
+	prompts := 0
+	promptReadHidden = func(int) ([]byte, error) {
+		prompts++
+		return nil, nil
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfigFile(t, path, "name: api\n")
+
+	cfg := New()
+	cfg.SetDefaultPriority(PriorityFileEnvFlagDefault)
+	cfg.Define("NAME").String().File("name")
+	cfg.Define("API_TOKEN").String().PromptIfMissing()
+	defer cfg.Destroy()
+	if err := cfg.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if errs := cfg.processConfigWithContext([]string{}, nil); len(errs) > 0 {
+		t.Fatalf("Unexpected errors: %v", errs)
+	}
+
+	writeConfigFile(t, path, "name: worker\n")
+	if err := cfg.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if errs := cfg.processConfigWithContext([]string{}, nil); len(errs) > 0 {
+		t.Fatalf("Unexpected errors processing again: %v", errs)
+	}
+	if prompts != 1 {
+		t.Errorf("Expected a single prompt, got %d", prompts)
+	}
+	if cfg.GetSecret("API_TOKEN").IsSet() {
+		t.Error("Expected the empty secret to stay unset")
+	}
+	if name, _ := cfg.lookupValue("NAME"); name != "worker" {
+		t.Errorf("NAME = %v, want worker", name)
+	}
+}
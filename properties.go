@@ -0,0 +1,143 @@
+// commandkit/properties.go
+package commandkit
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+// parseProperties decodes a Java .properties document. Keys are stored lower-cased, both
+// as written ("database.url") and with dots replaced by underscores ("database_url"),
+// so they match dotted file keys and definition keys case-insensitively. Comments start
+// with # or !, keys end at =, : or whitespace, lines ending with a backslash continue on
+// the next line, and \t, \n, \r, \f, \uXXXX and escaped separators are decoded.
+func parseProperties(data []byte) (map[string]any, error) {
+	config := make(map[string]any)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimLeft(scanner.Text(), " \t\f")
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+
+		// Join continuation lines, dropping their leading whitespace
+		start := lineNumber
+		for continuesLine(line) {
+			line = line[:len(line)-1]
+			if !scanner.Scan() {
+				break
+			}
+			lineNumber++
+			line += strings.TrimLeft(scanner.Text(), " \t\f")
+		}
+
+		rawKey, rawValue := splitProperty(line)
+		key, err := unescapeProperty(rawKey)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", start, err)
+		}
+		value, err := unescapeProperty(rawValue)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", start, err)
+		}
+
+		key = strings.ToLower(key)
+		config[key] = value
+		if flat := strings.ReplaceAll(key, ".", "_"); flat != key {
+			config[flat] = value
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// continuesLine reports whether line ends with an odd number of backslashes
+func continuesLine(line string) bool {
+	count := len(line) - len(strings.TrimRight(line, `\`))
+	return count%2 == 1
+}
+
+// splitProperty splits a logical line at the first unescaped =, : or whitespace
+func splitProperty(line string) (key, value string) {
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '=', ':':
+			return line[:i], strings.TrimLeft(line[i+1:], " \t\f")
+		case ' ', '\t', '\f':
+			rest := strings.TrimLeft(line[i:], " \t\f")
+			if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+				rest = strings.TrimLeft(rest[1:], " \t\f")
+			}
+			return line[:i], rest
+		}
+	}
+	return line, ""
+}
+
+// unescapeProperty decodes the escapes allowed in keys and values
+func unescapeProperty(text string) (string, error) {
+	if !strings.Contains(text, `\`) {
+		return text, nil
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(text); i++ {
+		if text[i] != '\\' || i+1 == len(text) {
+			sb.WriteByte(text[i])
+			continue
+		}
+		i++
+		switch text[i] {
+		case 't':
+			sb.WriteByte('\t')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 'f':
+			sb.WriteByte('\f')
+		case 'u':
+			r, err := readUnicodeEscape(text, i)
+			if err != nil {
+				return "", err
+			}
+			i += 4
+			// Characters outside the BMP are written as a surrogate pair
+			if utf16.IsSurrogate(r) && strings.HasPrefix(text[i+1:], `\u`) {
+				if low, err := readUnicodeEscape(text, i+2); err == nil {
+					if pair := utf16.DecodeRune(r, low); pair != unicode.ReplacementChar {
+						r = pair
+						i += 6
+					}
+				}
+			}
+			sb.WriteRune(r)
+		default:
+			sb.WriteByte(text[i])
+		}
+	}
+	return sb.String(), nil
+}
+
+// readUnicodeEscape decodes the four hex digits following the u at text[i]
+func readUnicodeEscape(text string, i int) (rune, error) {
+	if i+5 > len(text) {
+		return 0, fmt.Errorf("malformed \\u escape")
+	}
+	code, err := strconv.ParseUint(text[i+1:i+5], 16, 16)
+	if err != nil {
+		return 0, fmt.Errorf("malformed \\u escape: \\u%s", text[i+1:i+5])
+	}
+	return rune(code), nil
+}
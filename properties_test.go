@@ -0,0 +1,72 @@
+package commandkit
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseProperties(t *testing.T) {
+	data := `# Database settings
+! also a comment
+Database.URL = jdbc:postgresql://db/app
+server.port:8080
+app.name   My Application
+greeting = caf\u00e9 \ud83d\ude00
+path = C:\\temp\\logs
+key\=with\:separators = yes
+fruits = apple, \
+         banana, \
+         cherry
+empty
+`
+	got, err := parseProperties([]byte(data))
+	if err != nil {
+		t.Fatalf("parseProperties failed: %v", err)
+	}
+
+	want := map[string]any{
+		"database.url":        "jdbc:postgresql://db/app",
+		"database_url":        "jdbc:postgresql://db/app",
+		"server.port":         "8080",
+		"server_port":         "8080",
+		"app.name":            "My Application",
+		"app_name":            "My Application",
+		"greeting":            "café 😀",
+		"path":                `C:\temp\logs`,
+		"key=with:separators": "yes",
+		"fruits":              "apple, banana, cherry",
+		"empty":               "",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseProperties =\n%v\nwant\n%v", got, want)
+	}
+
+	if _, err := parseProperties([]byte(`bad = \u12`)); err == nil {
+		t.Error("expected an error for a malformed unicode escape")
+	}
+}
+
+func TestLoadPropertiesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "application.properties")
+	writeConfigFile(t, path, "Database.URL=jdbc:postgresql://db/app\nserver.port=8080\n")
+
+	cfg := New()
+	cfg.SetDefaultPriority(PriorityFileEnvFlagDefault)
+	cfg.Define("DATABASE_URL").String()
+	cfg.Define("PORT").Int64().File("Server.Port")
+	if err := cfg.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx := NewCommandContext(nil, cfg, "", "")
+	if url, _ := Get[string](ctx, "DATABASE_URL"); url != "jdbc:postgresql://db/app" {
+		t.Errorf("DATABASE_URL = %q, want the database.url property", url)
+	}
+	if port, _ := Get[int64](ctx, "PORT"); port != 8080 {
+		t.Errorf("PORT = %d, want 8080 from server.port", port)
+	}
+}
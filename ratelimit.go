@@ -0,0 +1,58 @@
+// commandkit/ratelimit.go
+package commandkit
+
+import (
+	"sync"
+	"time"
+)
+
+// rateLimiter counts executions per key over a sliding window
+type rateLimiter struct {
+	mu        sync.Mutex
+	limit     int
+	window    time.Duration
+	hits      map[string][]time.Time // Recent execution times per key, oldest first
+	lastSweep time.Time
+}
+
+// newRateLimiter creates a limiter allowing limit executions per key within window
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
+	return &rateLimiter{limit: limit, window: window, hits: make(map[string][]time.Time)}
+}
+
+// allow records an execution for key and returns the executions counted in the
+// current window, including this one, and whether it is within the limit. Rejected
+// executions are not counted.
+func (l *rateLimiter) allow(key string) (int, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	cutoff := now.Add(-l.window)
+	hits := recentHits(l.hits[key], cutoff)
+	if len(hits) >= l.limit {
+		l.hits[key] = hits
+		return len(hits) + 1, false
+	}
+	l.hits[key] = append(hits, now)
+
+	// Forget idle keys so callers seen once don't accumulate
+	if now.Sub(l.lastSweep) >= l.window {
+		for k, times := range l.hits {
+			if len(recentHits(times, cutoff)) == 0 {
+				delete(l.hits, k)
+			}
+		}
+		l.lastSweep = now
+	}
+	return len(hits) + 1, true
+}
+
+// recentHits drops the times at or before cutoff
+func recentHits(times []time.Time, cutoff time.Time) []time.Time {
+	i := 0
+	for i < len(times) && !times[i].After(cutoff) {
+		i++
+	}
+	return times[i:]
+}
@@ -0,0 +1,54 @@
+// commandkit/redaction.go
+package commandkit
+
+import (
+	"fmt"
+
+	"github.com/awnumar/memcall"
+)
+
+// redactedPlaceholder replaces live secret material in diagnostic output
+const redactedPlaceholder = "[REDACTED]"
+
+// Redact replaces any loaded secret value found in text with a placeholder
+// Use it before printing diagnostics that may have captured secret material
+func (c *Config) Redact(text string) string {
+	if c == nil || c.secrets == nil {
+		return text
+	}
+	return c.secrets.Redact(text)
+}
+
+// maskSecretCopy returns the placeholder instead of text when text is the value of
+// a loaded secret, and warns about it, so a non-secret key that duplicates secret
+// content is not exported in clear by Dump, WriteLock or WriteExampleConfig
+func (c *Config) maskSecretCopy(key, text string) string {
+	for layer := c; layer != nil; layer = layer.parent {
+		if layer.secrets == nil {
+			continue
+		}
+		if secretKey, found := layer.secrets.match(text); found {
+			logWarningForDesigner(fmt.Sprintf("Key '%s' has the same value as secret '%s'; it is masked in exports", key, secretKey))
+			return redactedPlaceholder
+		}
+	}
+	return text
+}
+
+// redactContext scrubs text against both the command and the global secret stores
+func redactContext(ctx *CommandContext, text string) string {
+	if ctx == nil {
+		return text
+	}
+	if ctx.CommandConfig != nil {
+		text = ctx.CommandConfig.Redact(text)
+	}
+	return ctx.GlobalConfig.Redact(text)
+}
+
+// DisableCoreDumps sets the core dump size limit to zero so a crash cannot write
+// secret material to disk. memguard already does this when the package loads;
+// call it again if something in the process may have raised the limit since.
+func DisableCoreDumps() error {
+	return memcall.DisableCoreDumps()
+}
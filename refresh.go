@@ -0,0 +1,82 @@
+// commandkit/refresh.go
+package commandkit
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+// refreshLoop is a running StartRefresh loop
+type refreshLoop struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// StartRefresh reloads the loaded files and registered sources every interval, plus a
+// random delay of up to jitter so that many instances don't hit a backend at once. It
+// suits sources that cannot watch for changes, such as HTTP or cloud parameter stores;
+// sources caching their values are refreshed first (see Refresher). As with
+// WatchSources, an invalid result keeps the previous values, OnChange callbacks fire
+// for changed keys, and callback (which may be nil) receives the outcome of each
+// refresh. When a value with a TTL would go stale before the next refresh, the refresh
+// happens at that moment instead. Starting again replaces the running refresher; it
+// stops with StopRefresh or Destroy, which callback must not call.
+func (c *Config) StartRefresh(interval, jitter time.Duration, callback func(error)) error {
+	if interval <= 0 {
+		return fmt.Errorf("refresh interval must be positive, got %s", interval)
+	}
+	if jitter < 0 {
+		return fmt.Errorf("refresh jitter cannot be negative, got %s", jitter)
+	}
+	if callback == nil {
+		callback = func(error) {}
+	}
+	c.StopRefresh()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &refreshLoop{cancel: cancel, done: make(chan struct{})}
+	c.mu.Lock()
+	c.refresher = r
+	c.mu.Unlock()
+
+	go func() {
+		defer close(r.done)
+		for {
+			delay := interval
+			if jitter > 0 {
+				delay += rand.N(jitter)
+			}
+			// Values with a TTL are refreshed as soon as they go stale
+			if until, ok := c.untilStale(); ok && until > 0 && until < delay {
+				delay = until
+			}
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+				if ctx.Err() == nil {
+					callback(c.Reload())
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// StopRefresh stops the refresher started with StartRefresh, waiting for a refresh in
+// progress to finish
+func (c *Config) StopRefresh() {
+	c.mu.Lock()
+	r := c.refresher
+	c.refresher = nil
+	c.mu.Unlock()
+
+	if r != nil {
+		r.cancel()
+		<-r.done
+	}
+}
@@ -0,0 +1,121 @@
+package commandkit
+
+import (
+	"maps"
+	"sync"
+	"testing"
+	"time"
+)
+
+// lockedSource is a Source whose values can change while it is polled
+type lockedSource struct {
+	mu     sync.Mutex
+	values map[string]string
+}
+
+func (s *lockedSource) Name() string { return "locked" }
+
+func (s *lockedSource) Lookup(key string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, found := s.values[key]
+	return value, found, nil
+}
+
+func (s *lockedSource) set(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = value
+}
+
+func TestStartRefresh(t *testing.T) {
+	source := &lockedSource{values: map[string]string{"RATE_LIMIT": "100"}}
+	cfg := New()
+	cfg.Define("RATE_LIMIT").Int64()
+	cfg.UseSource(source)
+
+	changes := make(chan any, 4)
+	cfg.OnChange("RATE_LIMIT", func(old, new any) { changes <- new })
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := cfg.StartRefresh(0, 0, nil); err == nil {
+		t.Error("expected an error for a zero interval")
+	}
+	if err := cfg.StartRefresh(5*time.Millisecond, 5*time.Millisecond, nil); err != nil {
+		t.Fatalf("StartRefresh failed: %v", err)
+	}
+	defer cfg.Destroy()
+
+	source.set("RATE_LIMIT", "250")
+	select {
+	case value := <-changes:
+		if value != int64(250) {
+			t.Errorf("OnChange received %v, want 250", value)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a refresh")
+	}
+
+	cfg.StopRefresh()
+	source.set("RATE_LIMIT", "500")
+	time.Sleep(30 * time.Millisecond)
+	if value, _ := cfg.lookupValue("RATE_LIMIT"); value != int64(250) {
+		t.Errorf("values changed after StopRefresh: %v", value)
+	}
+}
+
+// cachingSource reads its backend once, until Refresh, like the cloud providers
+type cachingSource struct {
+	backend *lockedSource
+	mu      sync.Mutex
+	cached  map[string]string
+}
+
+func (s *cachingSource) Name() string { return "caching" }
+
+func (s *cachingSource) Lookup(key string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cached == nil {
+		s.backend.mu.Lock()
+		s.cached = maps.Clone(s.backend.values)
+		s.backend.mu.Unlock()
+	}
+	value, found := s.cached[key]
+	return value, found, nil
+}
+
+func (s *cachingSource) Refresh() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cached = nil
+}
+
+func TestStartRefreshRefreshesCachingSources(t *testing.T) {
+	backend := &lockedSource{values: map[string]string{"RATE_LIMIT": "100"}}
+	cfg := New()
+	cfg.Define("RATE_LIMIT").Int64()
+	cfg.UseSource(&cachingSource{backend: backend})
+
+	changes := make(chan any, 4)
+	cfg.OnChange("RATE_LIMIT", func(old, new any) { changes <- new })
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := cfg.StartRefresh(5*time.Millisecond, 0, nil); err != nil {
+		t.Fatalf("StartRefresh failed: %v", err)
+	}
+	defer cfg.Destroy()
+
+	backend.set("RATE_LIMIT", "250")
+	select {
+	case value := <-changes:
+		if value != int64(250) {
+			t.Errorf("OnChange received %v, want 250", value)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the cached value to be refreshed")
+	}
+}
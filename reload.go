@@ -0,0 +1,517 @@
+// commandkit/reload.go
+package commandkit
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/awnumar/memguard"
+	"github.com/fsnotify/fsnotify"
+)
+
+// defaultReloadInterval is how long file events settle before a reload, and how often
+// files are polled when they cannot be watched
+const defaultReloadInterval = time.Second
+
+// Snapshot is an immutable view of one successfully processed set of configuration values
+type Snapshot struct {
+	values     map[string]any
+	secrets    *SecretStore
+	sources    map[string]SourceType // Source each value was resolved from
+	fileConfig *FileConfig
+	version    int
+	loadedAt   time.Time
+	done       chan struct{}
+}
+
+// Get returns the raw value stored for key
+func (s Snapshot) Get(key string) (any, bool) {
+	value, exists := s.values[key]
+	return value, exists
+}
+
+// Has checks if a key exists and has a non-nil value
+func (s Snapshot) Has(key string) bool {
+	value, exists := s.values[key]
+	return exists && value != nil
+}
+
+// GetSecret retrieves a secret value as it was when the snapshot was taken
+func (s Snapshot) GetSecret(key string) *Secret {
+	if s.secrets == nil {
+		return &Secret{}
+	}
+	return s.secrets.Get(key)
+}
+
+// Keys returns the non-secret keys held by the snapshot in sorted order
+func (s Snapshot) Keys() []string {
+	keys := make([]string, 0, len(s.values))
+	for key := range s.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Version returns the snapshot version (1 for the first processed configuration)
+func (s Snapshot) Version() int {
+	return s.version
+}
+
+// LoadedAt returns when the snapshot was committed
+func (s Snapshot) LoadedAt() time.Time {
+	return s.loadedAt
+}
+
+// Done returns a channel that is closed when the snapshot is being replaced or the
+// reload loop is stopping. Code running against a snapshot should return once it fires.
+func (s Snapshot) Done() <-chan struct{} {
+	return s.done
+}
+
+// GetFrom retrieves a typed value from a snapshot
+func GetFrom[T any](s Snapshot, key string) (T, error) {
+	var zero T
+
+	value, exists := s.values[key]
+	if !exists || value == nil {
+		return zero, fmt.Errorf("configuration '%s' not found", key)
+	}
+	return typedValue[T](key, value)
+}
+
+// typedValue converts a stored value to T
+func typedValue[T any](key string, value any) (T, error) {
+	var zero T
+	if result, ok := value.(T); ok {
+		return result, nil
+	}
+
+	converted, err := convertValue(value, reflect.TypeOf(zero))
+	if err == nil {
+		if result, ok := converted.(T); ok {
+			return result, nil
+		}
+	}
+
+	return zero, newTypeError[T](key, value)
+}
+
+// SetReloadInterval sets how long RunWithReload lets file events settle before reloading,
+// so an editor saving in several writes causes a single reload. Files that cannot be
+// watched, e.g. when the system runs out of watches, are polled at this interval instead.
+func (c *Config) SetReloadInterval(interval time.Duration) *Config {
+	if interval > 0 {
+		c.reloadInterval = interval
+	}
+	return c
+}
+
+// OnReload registers a handoff callback invoked after a reloaded snapshot is accepted.
+// It runs once the previous run function has returned and before the next one starts.
+func (c *Config) OnReload(fn func(previous, current Snapshot)) *Config {
+	c.reloadHandoffs = append(c.reloadHandoffs, fn)
+	return c
+}
+
+// OnChange registers fn to be called when a reload (WatchFile, WatchSources or
+// RunWithReload) changes the value of key, with the value before and after the change.
+// Callbacks run after the new values are live, in key order, so they may read the
+// configuration; a key that disappears is reported with a nil new value. Secret() keys
+// are reported with masked values, e.g. to reconnect with the value of GetSecret.
+func (c *Config) OnChange(key string, fn func(old, new any)) *Config {
+	if c.changeHandlers == nil {
+		c.changeHandlers = make(map[string][]func(old, new any))
+	}
+	c.changeHandlers[key] = append(c.changeHandlers[key], fn)
+	return c
+}
+
+// OnReloadError registers fn to receive the errors of every rejected reload, whether it
+// came from Reload, WatchFile, StartRefresh or RunWithReload, so failures can go to the
+// application's own logging or alerting. Once one is registered, RunWithReload no
+// longer prints rejected reloads to stderr.
+func (c *Config) OnReloadError(fn func([]ConfigError)) *Config {
+	c.reloadFailed = append(c.reloadFailed, fn)
+	return c
+}
+
+// OnReloadSuccess registers fn to be called after every reload that commits new values,
+// once the OnChange callbacks have run
+func (c *Config) OnReloadSuccess(fn func()) *Config {
+	c.reloadApplied = append(c.reloadApplied, fn)
+	return c
+}
+
+// reloadRejected passes the errors of a rejected reload to the OnReloadError callbacks
+// and reports whether there were any
+func (c *Config) reloadRejected(errs []ConfigError) bool {
+	for _, fn := range c.reloadFailed {
+		fn(errs)
+	}
+	return len(c.reloadFailed) > 0
+}
+
+// reloadCommitted calls the OnReloadSuccess callbacks
+func (c *Config) reloadCommitted() {
+	for _, fn := range c.reloadApplied {
+		fn()
+	}
+}
+
+// RunWithReload processes the configuration and calls run with the resulting snapshot.
+// Whenever a loaded file changes (files are watched with fsnotify) the configuration is
+// processed again; a candidate that
+// fails validation is rejected and the last good snapshot stays active. An accepted
+// candidate closes the current snapshot's Done channel, waits for run to return, calls
+// the OnReload handoff callbacks and starts run again with the new snapshot.
+//
+// RunWithReload returns when ctx is cancelled (after run returns) or when run returns
+// on its own without a reload being requested. The Done channel of every snapshot is
+// closed once it is superseded or the loop ends.
+func (c *Config) RunWithReload(ctx context.Context, run func(Snapshot) error) error {
+	if run == nil {
+		return fmt.Errorf("run function cannot be nil")
+	}
+
+	current, errs := c.stageSnapshot()
+	if len(errs) > 0 {
+		return ConfigErrors(errs)
+	}
+	c.commitSnapshot(&current).DestroyAll()
+
+	changes := c.watchFiles(ctx)
+
+	for {
+		finished := make(chan error, 1)
+		go func(s Snapshot) {
+			finished <- run(s)
+		}(current)
+
+		var next *Snapshot
+		for next == nil {
+			select {
+			case <-ctx.Done():
+				close(current.done)
+				return <-finished
+			case err := <-finished:
+				close(current.done)
+				return err
+			case <-changes:
+				candidate, errs := c.stageSnapshot()
+				if len(errs) > 0 {
+					if !c.reloadRejected(errs) {
+						c.reportRejectedReload(errs)
+					}
+					continue
+				}
+				next = &candidate
+			}
+		}
+
+		// Graceful handoff: stop the current run before starting the next one
+		close(current.done)
+		if err := <-finished; err != nil {
+			next.secrets.DestroyAll()
+			return err
+		}
+
+		previousSecrets := c.commitSnapshot(next)
+		for _, fn := range c.reloadHandoffs {
+			fn(current, *next)
+		}
+		previousSecrets.DestroyAll()
+		c.reloadCommitted()
+		current = *next
+	}
+}
+
+// stageSnapshot re-reads loaded files and resolves every definition into a staging
+// Config, leaving the live values untouched
+func (c *Config) stageSnapshot() (Snapshot, []ConfigError) {
+	fileConfig, err := c.reloadFiles()
+	if err != nil {
+		return Snapshot{}, []ConfigError{{
+			Key:              "file",
+			Source:           SourceFile.String(),
+			ErrorDescription: err.Error(),
+		}}
+	}
+	// A reloaded file may have dropped the selected environment
+	if errs := c.checkEnvironment(fileConfig); len(errs) > 0 {
+		return Snapshot{}, errs
+	}
+
+	staged := c.stagingConfig(fileConfig, c.flagValues)
+	errs := staged.processDefinitions()
+	if len(errs) == 0 {
+		errs = c.immutableChanges(staged)
+	}
+	if len(errs) > 0 {
+		staged.secrets.DestroyAll()
+		return Snapshot{}, errs
+	}
+
+	return Snapshot{
+		values:     staged.values,
+		secrets:    staged.secrets,
+		sources:    staged.valueSources,
+		fileConfig: fileConfig,
+		done:       make(chan struct{}),
+	}, nil
+}
+
+// stagingConfig returns an empty Config resolving the same definitions as c from
+// fileConfig and flagValues, keeping the secrets typed at a prompt
+func (c *Config) stagingConfig(fileConfig *FileConfig, flagValues map[string]*string) *Config {
+	staged := c.deriveChild()
+	staged.definitions = c.definitions
+	staged.values = make(map[string]any)
+	staged.secrets = newSecretStore()
+	staged.flagValues = flagValues
+	staged.fileConfig = fileConfig
+	staged.staging = true
+	c.mu.RLock()
+	staged.liveValues = maps.Clone(c.values)
+	c.mu.RUnlock()
+
+	// Keep secrets typed at a prompt rather than asking again on every reload
+	for key, def := range c.definitions {
+		if secret := c.secrets.Get(key); def.promptIfMissing && secret.IsSet() {
+			buf := memguard.NewBuffer(secret.Size())
+			buf.Copy(secret.Bytes())
+			staged.secrets.storeBuffer(key, buf)
+		}
+	}
+
+	return staged
+}
+
+// immutableChanges reports Immutable keys whose staged value differs from the live one;
+// nothing is reported before the first processing
+func (c *Config) immutableChanges(staged *Config) []ConfigError {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if !c.processed {
+		return nil
+	}
+
+	var errs []ConfigError
+	for key, def := range c.definitions {
+		if !def.immutable {
+			continue
+		}
+		if def.secret {
+			if subtle.ConstantTimeCompare(c.secrets.Get(key).Bytes(), staged.secrets.Get(key).Bytes()) != 1 {
+				errs = append(errs, ConfigError{
+					Key:              key,
+					Source:           "reload",
+					Display:          buildErrorDisplay(def),
+					ErrorDescription: "value is immutable and cannot change without a restart",
+				})
+			}
+			continue
+		}
+		if old, now := c.values[key], staged.values[key]; !reflect.DeepEqual(old, now) {
+			errs = append(errs, ConfigError{
+				Key:              key,
+				Source:           "reload",
+				Value:            fmt.Sprintf("%v", now),
+				Display:          buildErrorDisplay(def),
+				ErrorDescription: fmt.Sprintf("value is immutable and cannot change without a restart (current: %v)", old),
+			})
+		}
+	}
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Key < errs[j].Key })
+	return errs
+}
+
+// commitSnapshot swaps the snapshot into the live config and returns the replaced secret store
+func (c *Config) commitSnapshot(s *Snapshot) *SecretStore {
+	c.mu.Lock()
+	s.version, s.loadedAt = c.recordVersion(s.values, s.sources)
+
+	previous := c.secrets
+	previousValues, reprocessed := c.values, c.processed
+	c.values = maps.Clone(s.values)
+	c.secrets = s.secrets
+	c.valueSources = s.sources
+	c.fileConfig = s.fileConfig
+	c.processed = true
+	c.mu.Unlock()
+	c.commitSourceCache()
+
+	if reprocessed {
+		c.notifyChanges(previousValues, s.values, previous, s.secrets)
+	}
+	return previous
+}
+
+// notifyChanges calls the OnChange callbacks of the keys whose values differ
+func (c *Config) notifyChanges(previous, current map[string]any, previousSecrets, currentSecrets *SecretStore) {
+	keys := make([]string, 0, len(c.changeHandlers))
+	for key := range c.changeHandlers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		old, now := previous[key], current[key]
+		if c.IsSecret(key) {
+			before, after := previousSecrets.Get(key), currentSecrets.Get(key)
+			if subtle.ConstantTimeCompare(before.Bytes(), after.Bytes()) == 1 {
+				continue
+			}
+			old, now = maskedSecret(before), maskedSecret(after)
+		} else if reflect.DeepEqual(old, now) {
+			continue
+		}
+		for _, fn := range c.changeHandlers[key] {
+			fn(old, now)
+		}
+	}
+}
+
+// maskedSecret returns the masked value of secret for change callbacks, nil when unset
+func maskedSecret(secret *Secret) any {
+	if !secret.IsSet() {
+		return nil
+	}
+	return maskSecret(secret.String())
+}
+
+// reportRejectedReload prints why a reload candidate was discarded
+func (c *Config) reportRejectedReload(errs []ConfigError) {
+	var sb strings.Builder
+	sb.WriteString("Configuration reload rejected, keeping previous values:\n")
+	for _, err := range errs {
+		sb.WriteString(fmt.Sprintf("  %s -> %s\n", err.Key, err.ErrorDescription))
+	}
+	fmt.Fprint(os.Stderr, c.Redact(sb.String()))
+}
+
+// watchFiles signals whenever one of the loaded files changes, once events settle
+func (c *Config) watchFiles(ctx context.Context) <-chan struct{} {
+	changes := make(chan struct{}, 1)
+
+	var files []string
+	if c.fileConfig != nil {
+		files = append(files, c.fileConfig.files...)
+		files = append(files, c.fileConfig.included...)
+	}
+	if len(files) == 0 {
+		return changes
+	}
+
+	watcher, targets, err := watchDirectories(files)
+	if err != nil {
+		go c.pollFiles(ctx, files, changes)
+		return changes
+	}
+
+	go func() {
+		defer watcher.Close()
+		var pending <-chan time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				// Editors often save by renaming a new file over the old one
+				if targets[event.Name] && !event.Has(fsnotify.Chmod) {
+					pending = time.After(c.reloadInterval)
+				}
+			case _, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+			case <-pending:
+				pending = nil
+				signalChange(changes)
+			}
+		}
+	}()
+
+	return changes
+}
+
+// watchDirectories watches the directories holding files, which survives files being
+// replaced, and returns the absolute paths of the files to filter events with
+func watchDirectories(files []string) (*fsnotify.Watcher, map[string]bool, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, nil, err
+	}
+	targets := make(map[string]bool, len(files))
+	for _, file := range files {
+		target, err := filepath.Abs(file)
+		if err != nil {
+			watcher.Close()
+			return nil, nil, err
+		}
+		if !slices.Contains(watcher.WatchList(), filepath.Dir(target)) {
+			if err := watcher.Add(filepath.Dir(target)); err != nil {
+				watcher.Close()
+				return nil, nil, err
+			}
+		}
+		targets[target] = true
+	}
+	return watcher, targets, nil
+}
+
+// pollFiles compares the modification time and size of files at every reload interval
+func (c *Config) pollFiles(ctx context.Context, files []string, changes chan<- struct{}) {
+	last := fileStamps(files)
+	ticker := time.NewTicker(c.reloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			current := fileStamps(files)
+			if maps.Equal(last, current) {
+				continue
+			}
+			last = current
+			signalChange(changes)
+		}
+	}
+}
+
+// signalChange requests a reload unless one is already pending
+func signalChange(changes chan<- struct{}) {
+	select {
+	case changes <- struct{}{}:
+	default:
+	}
+}
+
+// fileStamps records the modification time and size of each file
+func fileStamps(files []string) map[string]string {
+	stamps := make(map[string]string, len(files))
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			stamps[file] = "missing"
+			continue
+		}
+		stamps[file] = fmt.Sprintf("%d:%d", info.ModTime().UnixNano(), info.Size())
+	}
+	return stamps
+}
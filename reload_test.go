@@ -0,0 +1,140 @@
+package commandkit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeConfigFile writes content and bumps the modification time so pollers notice the change
+func writeConfigFile(t *testing.T, path, content string) {
+	t.Helper()
+	stamp := time.Now()
+	if info, err := os.Stat(path); err == nil {
+		stamp = info.ModTime().Add(time.Second)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+	if err := os.Chtimes(path, stamp, stamp); err != nil {
+		t.Fatalf("failed to touch %s: %v", path, err)
+	}
+}
+
+func TestRunWithReloadAppliesValidChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeConfigFile(t, path, `{"workers": 2}`)
+
+	cfg := New()
+	cfg.Define("WORKERS").Int64().File("workers").Range(1, 10)
+	cfg.SetDefaultPriority(PriorityFileEnvFlagDefault).SetReloadInterval(10 * time.Millisecond)
+	if err := cfg.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+
+	var handoffs []int
+	cfg.OnReload(func(previous, current Snapshot) {
+		handoffs = append(handoffs, previous.Version(), current.Version())
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	seen := make(chan int64, 4)
+	err := cfg.RunWithReload(ctx, func(s Snapshot) error {
+		workers, err := GetFrom[int64](s, "WORKERS")
+		if err != nil {
+			return err
+		}
+		seen <- workers
+		if workers == 2 {
+			writeConfigFile(t, path, `{"workers": 5}`)
+		} else {
+			cancel()
+		}
+		<-s.Done()
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("RunWithReload returned error: %v", err)
+	}
+
+	if first, second := <-seen, <-seen; first != 2 || second != 5 {
+		t.Errorf("Expected snapshots with 2 then 5 workers, got %d then %d", first, second)
+	}
+	if len(handoffs) != 2 || handoffs[0] != 1 || handoffs[1] != 2 {
+		t.Errorf("Expected handoff from version 1 to 2, got %v", handoffs)
+	}
+	if !cfg.Has("WORKERS") {
+		t.Error("Expected live config to hold the reloaded value")
+	}
+}
+
+func TestRunWithReloadKeepsLastGoodSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeConfigFile(t, path, `{"workers": 2}`)
+
+	cfg := New()
+	cfg.Define("WORKERS").Int64().File("workers").Range(1, 10)
+	cfg.SetDefaultPriority(PriorityFileEnvFlagDefault).SetReloadInterval(10 * time.Millisecond)
+	if err := cfg.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runs := 0
+	err := cfg.RunWithReload(ctx, func(s Snapshot) error {
+		runs++
+		writeConfigFile(t, path, `{"workers": 50}`)
+		time.AfterFunc(100*time.Millisecond, cancel)
+		<-s.Done()
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("RunWithReload returned error: %v", err)
+	}
+
+	if runs != 1 {
+		t.Errorf("Expected invalid reload to be rejected without restarting run, got %d runs", runs)
+	}
+	value, _ := cfg.lookupValue("WORKERS")
+	if value != int64(2) {
+		t.Errorf("Expected last good value 2 to remain active, got %v", value)
+	}
+}
+
+func TestRunWithReloadClosesDoneWhenRunReturns(t *testing.T) {
+	cfg := New()
+	cfg.Define("WORKERS").Int64().Default(int64(2))
+
+	var snapshot Snapshot
+	err := cfg.RunWithReload(context.Background(), func(s Snapshot) error {
+		snapshot = s
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("RunWithReload returned error: %v", err)
+	}
+	select {
+	case <-snapshot.Done():
+	default:
+		t.Error("Expected Done to be closed once the loop ends")
+	}
+}
+
+func TestRunWithReloadRejectsInvalidInitialConfig(t *testing.T) {
+	cfg := New()
+	cfg.Define("API_URL").String().Required()
+
+	err := cfg.RunWithReload(context.Background(), func(s Snapshot) error {
+		t.Error("run should not be called with invalid configuration")
+		return nil
+	})
+	if err == nil {
+		t.Fatal("Expected error for invalid initial configuration")
+	}
+}
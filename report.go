@@ -0,0 +1,105 @@
+// commandkit/report.go
+package commandkit
+
+import (
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// RunReport describes one execution, for applications that embed commands and need to
+// log or react to the outcome without parsing the output
+type RunReport struct {
+	Command    []string      // Resolved command path, e.g. ["db", "migrate"]; empty when no command ran
+	Args       []string      // Arguments given to the command
+	Start      time.Time     // When execution started
+	Duration   time.Duration // Total execution time, configuration processing included
+	Middleware []string      // Middleware entered, outermost first
+	Result     any           // Value set with ctx.Result or returned by a ResultFunc
+	Err        error         // Error returned by Execute
+
+	data map[string]any // Context data set with WithContextData
+}
+
+// RunOption customizes a run started with ExecuteReport
+type RunOption func(*RunReport)
+
+// WithContextData makes value available to middleware and the command through
+// ctx.GetData(key), e.g. the HTTP request that triggered the run
+func WithContextData(key string, value any) RunOption {
+	return func(r *RunReport) {
+		if r.data == nil {
+			r.data = make(map[string]any)
+		}
+		r.data[key] = value
+	}
+}
+
+// ExecuteReport executes args like Execute and reports what ran. The command result is
+// returned in the report instead of being rendered on stdout.
+//
+// Runs share the configuration, so concurrent calls, e.g. from the scheduler, an HTTP
+// handler and a job queue, run one at a time; a command started by ExecuteReport must
+// not call it again.
+func (c *Config) ExecuteReport(args []string, opts ...RunOption) *RunReport {
+	c.runMu.Lock()
+	defer c.runMu.Unlock()
+
+	report := &RunReport{Start: time.Now()}
+	for _, opt := range opts {
+		opt(report)
+	}
+	report.Err = c.execute(args, report)
+	report.Duration = time.Since(report.Start)
+	return report
+}
+
+// record fills the report with the routed command and seeds the context data
+func (r *RunReport) record(ctx *CommandContext) {
+	for key, value := range r.data {
+		ctx.Set(key, value)
+	}
+	r.Args = ctx.Args
+	if ctx.Command != "" {
+		r.Command = append(r.Command, ctx.Command)
+	}
+	if ctx.SubCommand != "" {
+		r.Command = append(r.Command, ctx.SubCommand)
+	}
+}
+
+// traceMiddleware wraps middleware so that entering it is recorded in the run report
+func traceMiddleware(middleware CommandMiddleware) CommandMiddleware {
+	name := middlewareName(middleware)
+	return func(next CommandFunc) CommandFunc {
+		wrapped := middleware(next)
+		return func(ctx *CommandContext) error {
+			if ctx.report != nil {
+				ctx.report.Middleware = append(ctx.report.Middleware, name)
+			}
+			return wrapped(ctx)
+		}
+	}
+}
+
+// closureSuffix matches the suffixes the compiler gives closures (".func1", ".func2.1")
+var closureSuffix = regexp.MustCompile(`(\.func\d+)(\.\d+)*$`)
+
+// middlewareName names middleware after the function that built it, e.g.
+// "commandkit.TimingMiddleware"
+func middlewareName(middleware CommandMiddleware) string {
+	fn := runtime.FuncForPC(reflect.ValueOf(middleware).Pointer())
+	if fn == nil {
+		return "middleware"
+	}
+	name := fn.Name()
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	for closureSuffix.MatchString(name) {
+		name = closureSuffix.ReplaceAllString(name, "")
+	}
+	return name
+}
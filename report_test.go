@@ -0,0 +1,112 @@
+package commandkit
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestExecuteReport(t *testing.T) {
+	cfg := New()
+	cfg.UseMiddleware(TimingMiddleware())
+	cfg.UseMiddlewareForCommands([]string{"other"}, RecoveryMiddleware())
+	cfg.UseMiddlewareForSubcommands("db", []string{"migrate"}, MetricsMiddleware(func(*CommandContext, time.Duration, error) {}))
+
+	db := cfg.Command("db")
+	db.SubCommand("migrate").
+		Middleware(RecoveryMiddleware()).
+		ResultFunc(func(ctx *CommandContext) (any, error) {
+			return map[string]int{"applied": 3}, nil
+		})
+	cfg.Command("fail").Func(func(ctx *CommandContext) error {
+		return errors.New("boom")
+	})
+
+	var report *RunReport
+	out := captureStdout(t, func() {
+		report = cfg.ExecuteReport([]string{"app", "db", "migrate", "extra"})
+	})
+
+	if report.Err != nil {
+		t.Fatalf("unexpected error: %v", report.Err)
+	}
+	if out != "" {
+		t.Errorf("the result should be reported, not printed; got %q", out)
+	}
+	if want := []string{"db", "migrate"}; !reflect.DeepEqual(report.Command, want) {
+		t.Errorf("Command = %v, want %v", report.Command, want)
+	}
+	if want := []string{"extra"}; !reflect.DeepEqual(report.Args, want) {
+		t.Errorf("Args = %v, want %v", report.Args, want)
+	}
+	wantMiddleware := []string{"commandkit.TimingMiddleware", "commandkit.MetricsMiddleware", "commandkit.RecoveryMiddleware"}
+	if !reflect.DeepEqual(report.Middleware, wantMiddleware) {
+		t.Errorf("Middleware = %v, want %v", report.Middleware, wantMiddleware)
+	}
+	if result, ok := report.Result.(map[string]int); !ok || result["applied"] != 3 {
+		t.Errorf("Result = %v, want the command result", report.Result)
+	}
+	if report.Start.IsZero() || report.Duration <= 0 {
+		t.Errorf("expected timing information, got start=%v duration=%v", report.Start, report.Duration)
+	}
+
+	report = cfg.ExecuteReport([]string{"app", "fail"})
+	if report.Err == nil || report.Err.Error() != "boom" {
+		t.Errorf("Err = %v, want boom", report.Err)
+	}
+	if want := []string{"fail"}; !reflect.DeepEqual(report.Command, want) {
+		t.Errorf("Command = %v, want %v", report.Command, want)
+	}
+}
+
+func TestExecuteReportReturnsConfigErrors(t *testing.T) {
+	cfg := New()
+	cfg.Command("scale").
+		Config(func(cc *CommandConfig) {
+			cc.Define("REPLICAS").Int64().Flag("replicas").Required().Range(1, 5)
+		}).
+		Func(func(ctx *CommandContext) error { return nil })
+
+	report := cfg.ExecuteReport([]string{"app", "scale", "--replicas", "9"})
+
+	var configErrs ConfigErrors
+	if !errors.As(report.Err, &configErrs) || len(configErrs) != 1 || configErrs[0].Key != "REPLICAS" {
+		t.Fatalf("expected the REPLICAS error to be returned instead of exiting, got %v", report.Err)
+	}
+}
+
+func TestExecuteReportSerializesConcurrentRuns(t *testing.T) {
+	var active, overlaps atomic.Int32
+	cfg := New()
+	cfg.Command("resize").
+		Config(func(cc *CommandConfig) {
+			cc.Define("WIDTH").Int64().Flag("width").Required()
+		}).
+		ResultFunc(func(ctx *CommandContext) (any, error) {
+			if active.Add(1) > 1 {
+				overlaps.Add(1)
+			}
+			defer active.Add(-1)
+			time.Sleep(time.Millisecond)
+			return Get[int64](ctx, "WIDTH")
+		})
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Go(func() {
+			report := cfg.ExecuteReport([]string{"app", "resize", "--width", strconv.Itoa(i)})
+			if report.Err != nil || report.Result != int64(i) {
+				t.Errorf("run %d = %v, %v; want its own width", i, report.Result, report.Err)
+			}
+		})
+	}
+	wg.Wait()
+
+	if overlaps.Load() != 0 {
+		t.Errorf("%d runs overlapped", overlaps.Load())
+	}
+}
@@ -0,0 +1,191 @@
+// commandkit/runs.go
+package commandkit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// runsFile is the file under the state directory recording command runs
+const runsFile = "runs.json"
+
+// maxRunRecords caps the runs listed by RunHistory, oldest dropped first; the last run
+// of each command is kept regardless
+const maxRunRecords = 100
+
+// RunRecord describes a past run of a command, as recorded in the state directory
+type RunRecord struct {
+	Command  []string      `json:"command"`         // Command path, e.g. ["db", "migrate"]
+	Args     []string      `json:"args"`            // Arguments, with secret values redacted
+	Start    time.Time     `json:"start"`           // When the command started
+	Duration time.Duration `json:"duration"`        // How long the command and its middleware ran
+	Error    string        `json:"error,omitempty"` // Error returned, "" when the run succeeded
+}
+
+// StateDir enables recording every command run (its time, redacted arguments and
+// outcome) in dir, created if needed. Commands read their previous run with
+// ctx.LastRun(), e.g. for "since last run" backups or migrations. Recording is best
+// effort: a state directory that cannot be read or written never makes a command fail.
+func (c *Config) StateDir(dir string) *Config {
+	c.stateDir = dir
+	return c
+}
+
+// RunHistory returns the latest recorded runs, newest first; nil without a state
+// directory. An unreadable or corrupt runs file is reported as an error.
+func (c *Config) RunHistory() ([]RunRecord, error) {
+	state, err := c.readRuns()
+	slices.Reverse(state.Runs)
+	return state.Runs, err
+}
+
+// RunHistoryCommand adds a command, such as "history", listing the recorded runs newest
+// first, rendered like any other command result
+func (c *Config) RunHistoryCommand(name string) *CommandBuilder {
+	return c.Command(name).
+		ShortHelp("List recent command runs").
+		ResultFunc(func(ctx *CommandContext) (any, error) {
+			return ctx.GlobalConfig.RunHistory()
+		})
+}
+
+// LastRun returns the previous run of the current command, recorded in the state
+// directory set with StateDir; false on the first run or without a state directory
+func (ctx *CommandContext) LastRun() (RunRecord, bool) {
+	if ctx.lastRun == nil {
+		return RunRecord{}, false
+	}
+	return *ctx.lastRun, true
+}
+
+// runsMu serializes updates of the runs file, so concurrent runs in this process, such
+// as those of a job queue, never lose each other's records. Separate processes sharing
+// a state directory are not coordinated: when two finish at once, the records of one
+// may be dropped, including its last run.
+var runsMu sync.Mutex
+
+// errCorruptRuns reports a runs file that cannot be parsed
+var errCorruptRuns = errors.New("corrupt run history")
+
+// runState is the content of the runs file
+type runState struct {
+	Runs []RunRecord          `json:"runs"` // Latest runs of every command, oldest first
+	Last map[string]RunRecord `json:"last"` // Last run of each command, by command path
+}
+
+// readRuns reads the recorded runs; a zero state without a state directory or runs file
+func (c *Config) readRuns() (runState, error) {
+	var state runState
+	if c.stateDir == "" {
+		return state, nil
+	}
+	path := filepath.Join(c.stateDir, runsFile)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return state, err
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return runState{}, fmt.Errorf("%w %s: %v", errCorruptRuns, path, err)
+	}
+	return state, nil
+}
+
+// runKey identifies a command path in runState.Last
+func runKey(path []string) string {
+	return strings.Join(path, " ")
+}
+
+// loadLastRun gives ctx the previous recorded run of its command
+func (c *Config) loadLastRun(ctx *CommandContext) {
+	state, _ := c.readRuns()
+	if run, found := state.Last[runKey(commandPath(ctx))]; found {
+		ctx.lastRun = &run
+	}
+}
+
+// recordRun adds the run of ctx's command to the state directory. The last run of each
+// command is kept apart from the bounded list of runs, so frequent commands never
+// evict the record LastRun reads for a rare one.
+func (c *Config) recordRun(ctx *CommandContext, start time.Time, runErr error) {
+	if c.stateDir == "" {
+		return
+	}
+	record := RunRecord{
+		Command:  commandPath(ctx),
+		Args:     make([]string, len(ctx.Args)),
+		Start:    start,
+		Duration: time.Since(start),
+	}
+	for i, arg := range ctx.Args {
+		record.Args[i] = redactContext(ctx, arg)
+	}
+	if runErr != nil {
+		record.Error = redactContext(ctx, runErr.Error())
+	}
+
+	runsMu.Lock()
+	defer runsMu.Unlock()
+	path := filepath.Join(c.stateDir, runsFile)
+	state, err := c.readRuns()
+	if errors.Is(err, errCorruptRuns) {
+		// Keep the damaged file for inspection rather than overwriting it
+		if os.Rename(path, path+".corrupt") != nil {
+			return
+		}
+	} else if err != nil {
+		return
+	}
+	state.Runs = append(state.Runs, record)
+	if len(state.Runs) > maxRunRecords {
+		state.Runs = state.Runs[len(state.Runs)-maxRunRecords:]
+	}
+	if state.Last == nil {
+		state.Last = make(map[string]RunRecord)
+	}
+	state.Last[runKey(record.Command)] = record
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(c.stateDir, 0o700); err != nil {
+		return
+	}
+	// Write then rename, so a crash never leaves a truncated file
+	tmp, err := os.CreateTemp(c.stateDir, runsFile+".*")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+// commandPath returns the path of the command ctx runs, e.g. ["db", "migrate"]
+func commandPath(ctx *CommandContext) []string {
+	path := []string{}
+	if ctx.Command != "" {
+		path = append(path, ctx.Command)
+	}
+	if ctx.SubCommand != "" {
+		path = append(path, ctx.SubCommand)
+	}
+	return path
+}
@@ -0,0 +1,141 @@
+package commandkit
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStateDirRuns(t *testing.T) {
+	dir := t.TempDir()
+	var last []RunRecord
+	var seen []bool
+
+	newConfig := func() *Config {
+		cfg := New().StateDir(dir)
+		cfg.Command("backup").Func(func(ctx *CommandContext) error {
+			run, ok := ctx.LastRun()
+			last, seen = append(last, run), append(seen, ok)
+			if MustGet[bool](ctx, "FAIL") {
+				return errors.New("disk full")
+			}
+			return nil
+		}).Config(func(cc *CommandConfig) {
+			cc.Define("TOKEN").String().Flag("token").Secret()
+			cc.Define("FAIL").Bool().Flag("fail").Default(false)
+		})
+		cfg.RunHistoryCommand("history")
+		return cfg
+	}
+
+	if err := newConfig().Execute([]string{"app", "backup", "--token", "s3cr3t"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := newConfig().ExecuteReport([]string{"app", "backup", "--fail=true"}).Err; err == nil {
+		t.Fatal("expected the second run to fail")
+	}
+	if err := newConfig().Execute([]string{"app", "backup"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !slices.Equal(seen, []bool{false, true, true}) {
+		t.Fatalf("LastRun found = %v", seen)
+	}
+	if !slices.Equal(last[1].Args, []string{"--token", "[REDACTED]"}) || last[1].Error != "" {
+		t.Errorf("unexpected first run: %+v", last[1])
+	}
+	if last[2].Error != "disk full" || last[2].Start.Before(last[1].Start) {
+		t.Errorf("unexpected second run: %+v", last[2])
+	}
+
+	runs, err := newConfig().RunHistory()
+	if err != nil || len(runs) != 3 || runs[0].Error != "" || runs[1].Error != "disk full" {
+		t.Fatalf("unexpected history: %+v, %v", runs, err)
+	}
+
+	defer func(original func() bool) { outputIsTerminal = original }(outputIsTerminal)
+	outputIsTerminal = func() bool { return false }
+	output := captureStdout(t, func() {
+		if err := newConfig().Execute([]string{"app", "history"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+	var listed []RunRecord
+	if err := json.Unmarshal([]byte(output), &listed); err != nil || len(listed) != 3 || !slices.Equal(listed[2].Command, []string{"backup"}) {
+		t.Errorf("unexpected history output %q: %v", output, err)
+	}
+
+	if runs, err := New().RunHistory(); runs != nil || err != nil {
+		t.Errorf("expected no history without a state dir, got %v, %v", runs, err)
+	}
+}
+
+func TestStateDirKeepsLastRunPerCommand(t *testing.T) {
+	dir := t.TempDir()
+	var found bool
+	cfg := New().StateDir(dir)
+	cfg.Command("backup").Func(func(ctx *CommandContext) error {
+		_, found = ctx.LastRun()
+		return nil
+	})
+	cfg.Command("ping").Func(func(ctx *CommandContext) error { return nil })
+
+	if err := cfg.Execute([]string{"app", "backup"}); err != nil {
+		t.Fatal(err)
+	}
+	// Runs of a frequent command push the backup out of the history, not out of LastRun
+	for range maxRunRecords + 1 {
+		if err := cfg.Execute([]string{"app", "ping"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := cfg.Execute([]string{"app", "backup"}); err != nil {
+		t.Fatal(err)
+	}
+	if !found {
+		t.Error("expected the backup's last run to survive frequent runs of other commands")
+	}
+
+	// Concurrent runs keep every record
+	concurrent := New().StateDir(t.TempDir())
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			concurrent.recordRun(&CommandContext{Command: "ping"}, time.Now(), nil)
+		}()
+	}
+	wg.Wait()
+	if runs, err := concurrent.RunHistory(); err != nil || len(runs) != 20 {
+		t.Errorf("expected 20 runs after concurrent runs, got %d, %v", len(runs), err)
+	}
+}
+
+func TestStateDirCorruptRuns(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, runsFile)
+	if err := os.WriteFile(path, []byte("{not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg := New().StateDir(dir)
+	cfg.Command("backup").Func(func(ctx *CommandContext) error { return nil })
+
+	if _, err := cfg.RunHistory(); err == nil {
+		t.Error("expected a corrupt runs file to be reported")
+	}
+	if err := cfg.Execute([]string{"app", "backup"}); err != nil {
+		t.Fatalf("a corrupt runs file must not fail commands: %v", err)
+	}
+	if data, err := os.ReadFile(path + ".corrupt"); err != nil || string(data) != "{not json" {
+		t.Errorf("expected the corrupt file to be kept, got %q, %v", data, err)
+	}
+	if runs, err := cfg.RunHistory(); err != nil || len(runs) != 1 {
+		t.Errorf("expected recording to start over, got %v, %v", runs, err)
+	}
+}
@@ -0,0 +1,99 @@
+// commandkit/scheduler.go
+package commandkit
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Clock used by the scheduler; replaced in tests
+var (
+	schedulerNow   = time.Now
+	schedulerAfter = time.After
+)
+
+// scheduledCommand is a command run periodically by RunScheduler
+type scheduledCommand struct {
+	spec     string
+	schedule schedule
+	args     []string // Command path followed by its arguments
+}
+
+// Schedule runs a command periodically once RunScheduler is started. spec is a
+// five-field cron expression ("0 3 * * *"), a macro (@hourly, @daily, @weekly,
+// @monthly, @yearly) or "@every <duration>"; command may name a subcommand too
+// ("db vacuum"). Times follow the local time zone.
+func (c *Config) Schedule(spec, command string, args ...string) error {
+	s, err := parseSchedule(spec)
+	if err != nil {
+		return err
+	}
+	path := strings.Fields(command)
+	if c.FindCommand(path...) == nil {
+		return fmt.Errorf("cannot schedule unknown command %q", command)
+	}
+
+	c.schedules = append(c.schedules, scheduledCommand{
+		spec:     spec,
+		schedule: s,
+		args:     append(path, args...),
+	})
+	return nil
+}
+
+// OnScheduledRun sets a function receiving the report of every scheduled run, e.g. to
+// export metrics. Failed runs are logged either way.
+func (c *Config) OnScheduledRun(fn func(*RunReport)) *Config {
+	c.scheduleHook = fn
+	return c
+}
+
+// RunScheduler runs the scheduled commands through the normal middleware chain until
+// ctx is done. Runs never overlap: a command due while another one runs starts when it
+// finishes. It returns an error if nothing is scheduled.
+func (c *Config) RunScheduler(ctx context.Context) error {
+	if len(c.schedules) == 0 {
+		return fmt.Errorf("no commands are scheduled")
+	}
+
+	program := filepath.Base(os.Args[0])
+	now := schedulerNow()
+	next := make([]time.Time, len(c.schedules))
+	for i, entry := range c.schedules {
+		next[i] = entry.schedule.next(now)
+	}
+
+	for ctx.Err() == nil {
+		due := -1
+		for i, at := range next {
+			if !at.IsZero() && (due < 0 || at.Before(next[due])) {
+				due = i
+			}
+		}
+		if due < 0 {
+			return fmt.Errorf("no scheduled command will run again")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-schedulerAfter(next[due].Sub(schedulerNow())):
+		}
+
+		entry := c.schedules[due]
+		report := c.ExecuteReport(append([]string{program}, entry.args...))
+		if report.Err != nil {
+			log.Printf("Scheduled command %q (%s) failed: %s", strings.Join(report.Command, " "), entry.spec, c.Redact(report.Err.Error()))
+		}
+		if c.scheduleHook != nil {
+			c.scheduleHook(report)
+		}
+		next[due] = entry.schedule.next(schedulerNow())
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package commandkit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseScheduleNext(t *testing.T) {
+	// Wednesday 15 January 2025, 10:17:30
+	from := time.Date(2025, 1, 15, 10, 17, 30, 0, time.UTC)
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2025, 1, 15, 10, 18, 0, 0, time.UTC)},
+		{"0 3 * * *", time.Date(2025, 1, 16, 3, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)},
+		{"5,45 10-12 * * *", time.Date(2025, 1, 15, 10, 45, 0, 0, time.UTC)},
+		{"0 9 * * mon-fri", time.Date(2025, 1, 16, 9, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2025, 1, 19, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 feb *", time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 13 * fri", time.Date(2025, 1, 17, 0, 0, 0, 0, time.UTC)}, // Either day field matches
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2025, 1, 15, 11, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"@every 90s", from.Add(90 * time.Second)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			s, err := parseSchedule(tt.spec)
+			if err != nil {
+				t.Fatalf("parseSchedule failed: %v", err)
+			}
+			if got := s.next(from); !got.Equal(tt.want) {
+				t.Errorf("next = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	for _, invalid := range []string{"* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "* * * foo *", "@every soon"} {
+		if _, err := parseSchedule(invalid); err == nil {
+			t.Errorf("expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestRunScheduler(t *testing.T) {
+	now := time.Date(2025, 1, 15, 2, 59, 0, 0, time.UTC)
+	defer func(originalNow func() time.Time, originalAfter func(time.Duration) <-chan time.Time) {
+		schedulerNow, schedulerAfter = originalNow, originalAfter
+	}(schedulerNow, schedulerAfter)
+
+	// The fake clock jumps straight to each activation
+	var waits []time.Duration
+	schedulerNow = func() time.Time { return now }
+	schedulerAfter = func(d time.Duration) <-chan time.Time {
+		waits = append(waits, d)
+		now = now.Add(d)
+		ch := make(chan time.Time, 1)
+		ch <- now
+		return ch
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var runs []string
+
+	cfg := New()
+	cfg.UseMiddleware(TimingMiddleware())
+	cfg.Command("backup").Func(func(cc *CommandContext) error {
+		runs = append(runs, "backup "+strings.Join(cc.Args, " "))
+		return nil
+	})
+	cfg.Command("cleanup").Func(func(cc *CommandContext) error {
+		runs = append(runs, "cleanup")
+		return errors.New("disk busy")
+	})
+
+	if err := cfg.Schedule("0 3 * * *", "backup", "--full"); err != nil {
+		t.Fatalf("Schedule failed: %v", err)
+	}
+	if err := cfg.Schedule("@every 45m", "cleanup"); err != nil {
+		t.Fatalf("Schedule failed: %v", err)
+	}
+	if err := cfg.Schedule("@daily", "missing"); err == nil {
+		t.Error("expected an error when scheduling an unknown command")
+	}
+
+	var reports []*RunReport
+	cfg.OnScheduledRun(func(report *RunReport) {
+		reports = append(reports, report)
+		if len(reports) == 3 {
+			cancel()
+		}
+	})
+
+	logs := captureLogs(t, func() {
+		if err := cfg.RunScheduler(ctx); err != nil {
+			t.Errorf("RunScheduler failed: %v", err)
+		}
+	})
+
+	if want := []string{"backup --full", "cleanup", "cleanup"}; strings.Join(runs, "|") != strings.Join(want, "|") {
+		t.Errorf("runs = %v, want %v", runs, want)
+	}
+	if want := []time.Duration{time.Minute, 44 * time.Minute, 45 * time.Minute}; len(waits) < 3 || waits[0] != want[0] || waits[1] != want[1] || waits[2] != want[2] {
+		t.Errorf("waits = %v, want %v", waits, want)
+	}
+	if len(reports) != 3 || reports[1].Err == nil || reports[0].Middleware[0] != "commandkit.TimingMiddleware" {
+		t.Errorf("unexpected reports: %+v", reports)
+	}
+	if !strings.Contains(logs, `Scheduled command "cleanup" (@every 45m) failed: disk busy`) {
+		t.Errorf("expected the failure to be logged, got:\n%s", logs)
+	}
+
+	if err := New().RunScheduler(context.Background()); err == nil {
+		t.Error("expected an error when nothing is scheduled")
+	}
+}
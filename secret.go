@@ -2,7 +2,10 @@
 package commandkit
 
 import (
+	"bytes"
+	"crypto/subtle"
 	"runtime"
+	"sort"
 	"sync"
 	"sync/atomic"
 
@@ -21,7 +24,11 @@ func newSecret(value string) *Secret {
 		return &Secret{}
 	}
 
-	buf := memguard.NewBufferFromBytes([]byte(value))
+	return newSecretFromBuffer(memguard.NewBufferFromBytes([]byte(value)))
+}
+
+// newSecretFromBuffer wraps an existing LockedBuffer, taking ownership of it
+func newSecretFromBuffer(buf *memguard.LockedBuffer) *Secret {
 	s := &Secret{buffer: buf}
 
 	// Set finalizer for automatic cleanup
@@ -143,6 +150,19 @@ func (ss *SecretStore) Store(key, value string) {
 	ss.secrets[key] = newSecret(value)
 }
 
+// storeBuffer stores a secret already held in a LockedBuffer, so the plaintext
+// never passes through an ordinary Go string
+func (ss *SecretStore) storeBuffer(key string, buf *memguard.LockedBuffer) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	if existing, exists := ss.secrets[key]; exists {
+		existing.Destroy()
+	}
+
+	ss.secrets[key] = newSecretFromBuffer(buf)
+}
+
 // Get retrieves a secret with thread safety
 func (ss *SecretStore) Get(key string) *Secret {
 	ss.mu.RLock()
@@ -228,3 +248,45 @@ func (ss *SecretStore) VerifyAllDestroyed() bool {
 func (ss *SecretStore) IsDestroyed() bool {
 	return atomic.LoadInt32(&ss.destroyed) == 1
 }
+
+// match returns the key of the stored secret whose value is exactly text
+func (ss *SecretStore) match(text string) (string, bool) {
+	if text == "" {
+		return "", false
+	}
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+
+	for key, s := range ss.secrets {
+		if s.IsSet() && subtle.ConstantTimeCompare(s.Bytes(), []byte(text)) == 1 {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// Redact replaces every occurrence of a stored secret value in text with a placeholder
+// Longer secrets are replaced first so a secret containing another is fully removed
+func (ss *SecretStore) Redact(text string) string {
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+
+	values := make([][]byte, 0, len(ss.secrets))
+	for _, s := range ss.secrets {
+		if s.IsSet() {
+			values = append(values, s.Bytes())
+		}
+	}
+	if len(values) == 0 {
+		return text
+	}
+	sort.Slice(values, func(i, j int) bool {
+		return len(values[i]) > len(values[j])
+	})
+
+	out := []byte(text)
+	for _, value := range values {
+		out = bytes.ReplaceAll(out, value, []byte(redactedPlaceholder))
+	}
+	return string(out)
+}
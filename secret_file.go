@@ -0,0 +1,45 @@
+// commandkit/secret_file.go
+package commandkit
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// StrictSecretFiles rejects secrets read from files other users can tamper with or
+// read, like ssh does for key files: config files and <VAR>_FILE files providing a
+// Secret() value must be owned by the current user or root, must not be world
+// readable and must not be group or world writable. The check is skipped on platforms
+// without Unix permissions.
+func (c *Config) StrictSecretFiles() *Config {
+	c.secretFileCheck = true
+	return c
+}
+
+// checkSecretFile reports why path may not hold a secret, if strict checks are enabled
+func (c *Config) checkSecretFile(def *Definition, path string) error {
+	if !c.secretFileCheck || !def.secret || path == "" || !permissionsSupported {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("cannot check permissions of %s: %w", path, err)
+	}
+
+	var problems []string
+	mode := info.Mode().Perm()
+	if mode&0o004 != 0 {
+		problems = append(problems, "readable by others")
+	}
+	if mode&0o022 != 0 {
+		problems = append(problems, "writable by group or others")
+	}
+	if uid, ok := fileOwner(info); ok && uid != os.Getuid() && uid != 0 {
+		problems = append(problems, fmt.Sprintf("owned by uid %d", uid))
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("secret file %s is %s (mode %#o)", path, strings.Join(problems, " and "), mode)
+	}
+	return nil
+}
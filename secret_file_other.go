@@ -0,0 +1,14 @@
+//go:build !unix
+
+// commandkit/secret_file_other.go
+package commandkit
+
+import "io/fs"
+
+// permissionsSupported reports whether file modes and owners can be checked
+const permissionsSupported = false
+
+// fileOwner is not available without Unix permissions
+func fileOwner(info fs.FileInfo) (int, bool) {
+	return 0, false
+}
@@ -0,0 +1,75 @@
+package commandkit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStrictSecretFiles(t *testing.T) {
+	if !permissionsSupported {
+		t.Skip("file permissions are not checked on this platform")
+	}
+
+	dir := t.TempDir()
+	writeSecretFile := func(name, content string, mode os.FileMode) string {
+		path := filepath.Join(dir, name)
+		writeConfigFile(t, path, content)
+		if err := os.Chmod(path, mode); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	private := writeSecretFile("secrets.yaml", "api_key: private\n", 0o600)
+	shared := writeSecretFile("shared.yaml", "api_key: shared\nport: 8080\n", 0o644)
+	writable := writeSecretFile("writable.yaml", "api_key: writable\n", 0o620)
+	passwordFile := writeSecretFile("db_password", "hunter2", 0o644)
+
+	newConfig := func(files ...string) *Config {
+		cfg := New().StrictSecretFiles()
+		cfg.SetDefaultPriority(PriorityFileEnvFlagDefault)
+		cfg.Define("API_KEY").String().File("api_key").Secret()
+		cfg.Define("PORT").Int64().File("port").Default(int64(80))
+		for _, file := range files {
+			if err := cfg.LoadFile(file); err != nil {
+				t.Fatalf("LoadFile failed: %v", err)
+			}
+		}
+		return cfg
+	}
+
+	// Non-secret values may come from shared files
+	cfg := newConfig(shared, private)
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := cfg.GetSecret("API_KEY").String(); got != "private" {
+		t.Errorf("API_KEY = %q, want private", got)
+	}
+
+	for file, want := range map[string]string{shared: "readable by others", writable: "writable by group or others"} {
+		err := newConfig(private, file).Process([]string{"app"})
+		if err == nil || !strings.Contains(err.Error(), want) || !strings.Contains(err.Error(), file) {
+			t.Errorf("expected %q for %s, got %v", want, file, err)
+		}
+	}
+
+	cfg = New().StrictSecretFiles()
+	cfg.Define("DB_PASSWORD").String().Env("DB_PASSWORD").Secret()
+	cfg.WithEnviron([]string{"DB_PASSWORD_FILE=" + passwordFile})
+	if err := cfg.Process([]string{"app"}); err == nil || !strings.Contains(err.Error(), "readable by others") {
+		t.Errorf("expected the _FILE file to be rejected, got %v", err)
+	}
+
+	// Checks are opt-in
+	cfg = New()
+	cfg.SetDefaultPriority(PriorityFileEnvFlagDefault)
+	cfg.Define("API_KEY").String().File("api_key").Secret()
+	if err := cfg.LoadFile(shared); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Errorf("unexpected error without StrictSecretFiles: %v", err)
+	}
+}
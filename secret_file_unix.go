@@ -0,0 +1,21 @@
+//go:build unix
+
+// commandkit/secret_file_unix.go
+package commandkit
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// permissionsSupported reports whether file modes and owners can be checked
+const permissionsSupported = true
+
+// fileOwner returns the uid owning a file
+func fileOwner(info fs.FileInfo) (int, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return int(stat.Uid), true
+}
@@ -1,8 +1,13 @@
 package commandkit
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"os"
 	"runtime"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -428,3 +433,75 @@ func TestSecretStoreVerification(t *testing.T) {
 		t.Error("Destroyed SecretStore should have no keys")
 	}
 }
+
+func TestSecretStoreRedact(t *testing.T) {
+	ss := newSecretStore()
+	defer ss.DestroyAll()
+
+	ss.Store("short", "pass")
+	ss.Store("long", "password123")
+
+	got := ss.Redact("login with password123 then pass")
+	want := "login with [REDACTED] then [REDACTED]"
+	if got != want {
+		t.Errorf("Redact() = %q, want %q", got, want)
+	}
+
+	if got := ss.Redact("nothing sensitive"); got != "nothing sensitive" {
+		t.Errorf("Redact() changed text without secrets: %q", got)
+	}
+}
+
+func TestConfigRedact(t *testing.T) {
+	os.Setenv("REDACT_API_KEY", "sk-live-abcdef")
+	defer os.Unsetenv("REDACT_API_KEY")
+
+	cfg := New()
+	cfg.Define("API_KEY").String().Env("REDACT_API_KEY").Secret()
+	if err := cfg.Execute([]string{"test"}); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	defer cfg.Destroy()
+
+	got := cfg.Redact("request failed: bearer sk-live-abcdef rejected")
+	if strings.Contains(got, "sk-live-abcdef") {
+		t.Errorf("Redact() leaked secret: %q", got)
+	}
+}
+
+func TestSecretCopiesMaskedInExports(t *testing.T) {
+	cfg := New()
+	cfg.WithEnviron([]string{"API_KEY=sk-live-abcdef", "API_KEY_COPY=sk-live-abcdef", "REGION=eu"})
+	cfg.Define("API_KEY").String().Env("API_KEY").Secret()
+	cfg.Define("API_KEY_COPY").String().Env("API_KEY_COPY")
+	cfg.Define("REGION").String().Env("REGION")
+	cfg.Define("TOKEN_EXAMPLE").String().Example("sk-live-abcdef")
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	defer cfg.Destroy()
+
+	dump := cfg.Dump()
+	if dump["API_KEY_COPY"] != redactedPlaceholder || dump["REGION"] != "eu" {
+		t.Errorf("Dump() = %v", dump)
+	}
+
+	var example strings.Builder
+	if err := cfg.WriteExampleConfig(&example, "env"); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(example.String(), "sk-live-abcdef") {
+		t.Errorf("example config leaked secret:\n%s", example.String())
+	}
+
+	lockKey := []byte("lock-key")
+	lock, err := cfg.currentLock(lockKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secretHash := hmac.New(sha256.New, lockKey)
+	secretHash.Write([]byte("sk-live-abcdef"))
+	if lock.Values["API_KEY_COPY"] == hex.EncodeToString(secretHash.Sum(nil)) {
+		t.Error("lock file holds the hash of a secret")
+	}
+}
@@ -0,0 +1,197 @@
+// Package secretsmanager provides a commandkit source backed by AWS Secrets Manager.
+//
+//	cfg.UseSource(secretsmanager.New().
+//		Map("API_TOKEN", "prod/api-token").
+//		Map("DB_PASSWORD", "prod/db#credentials.password"))
+//
+// A reference is a secret name or ARN, optionally followed by "#" and a dotted path
+// selecting one field of a JSON secret. Every value is sensitive, so it is only
+// accepted by Secret() definitions and is kept in protected memory, never in the
+// plain configuration values.
+package secretsmanager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/awnumar/memguard"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+)
+
+// Client is the subset of the Secrets Manager API used by Source
+type Client interface {
+	GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error)
+}
+
+// Option configures a Source
+type Option func(*Source)
+
+// WithClient sets the Secrets Manager client; by default one is created from the
+// default AWS configuration (environment, shared config files, instance role, ...)
+func WithClient(client Client) Option {
+	return func(s *Source) {
+		s.client = client
+	}
+}
+
+// WithContext sets the context used for the AWS calls
+func WithContext(ctx context.Context) Option {
+	return func(s *Source) {
+		s.ctx = ctx
+	}
+}
+
+// Source resolves mapped configuration keys from Secrets Manager
+type Source struct {
+	client Client
+	ctx    context.Context
+	refs   map[string]reference
+
+	mu      sync.Mutex
+	secrets map[string]*memguard.Enclave // Secrets fetched so far, encrypted in memory
+}
+
+// reference points at a secret, or at one field of a JSON secret
+type reference struct {
+	secretID string
+	path     []string
+}
+
+// New creates an empty source; keys are added with Map
+func New(opts ...Option) *Source {
+	s := &Source{
+		ctx:     context.Background(),
+		refs:    make(map[string]reference),
+		secrets: make(map[string]*memguard.Enclave),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Map resolves key from ref: a secret name or ARN, optionally followed by
+// "#field.path" to select a field of a JSON secret
+func (s *Source) Map(key, ref string) *Source {
+	secretID, path, _ := strings.Cut(ref, "#")
+	r := reference{secretID: secretID}
+	if path != "" {
+		r.path = strings.Split(path, ".")
+	}
+	s.refs[key] = r
+	return s
+}
+
+// Name identifies the source in error messages
+func (s *Source) Name() string {
+	return "secretsmanager"
+}
+
+// Lookup returns the secret value mapped to key
+func (s *Source) Lookup(key string) (string, bool, error) {
+	ref, mapped := s.refs[key]
+	if !mapped {
+		return "", false, nil
+	}
+
+	secret, err := s.fetch(ref.secretID)
+	if err != nil {
+		return "", false, err
+	}
+	defer secret.Destroy()
+
+	if len(ref.path) == 0 {
+		return strings.Clone(secret.String()), true, nil
+	}
+
+	value, err := selectField(secret.Bytes(), ref.path)
+	if err != nil {
+		return "", false, fmt.Errorf("secret %s: %w", ref.secretID, err)
+	}
+	return value, true, nil
+}
+
+// IsSensitive reports true for every key: secrets never leave protected storage
+func (s *Source) IsSensitive(key string) bool {
+	return true
+}
+
+// Refresh drops the secrets fetched so far; the next lookups read them again, e.g.
+// after a rotation
+func (s *Source) Refresh() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clear(s.secrets)
+}
+
+// fetch returns a secret's value, reading it from Secrets Manager the first time.
+// The caller must destroy the returned buffer.
+func (s *Source) fetch(secretID string) (*memguard.LockedBuffer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if enclave, ok := s.secrets[secretID]; ok {
+		return enclave.Open()
+	}
+
+	if s.client == nil {
+		awsConfig, err := config.LoadDefaultConfig(s.ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load AWS configuration: %w", err)
+		}
+		s.client = secretsmanager.NewFromConfig(awsConfig)
+	}
+
+	output, err := s.client.GetSecretValue(s.ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(secretID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secret %s: %w", secretID, err)
+	}
+	if output.SecretString == nil {
+		return nil, fmt.Errorf("secret %s has no string value", secretID)
+	}
+
+	// The enclave takes and wipes the copy; only ciphertext stays cached
+	enclave := memguard.NewEnclave([]byte(*output.SecretString))
+	s.secrets[secretID] = enclave
+	return enclave.Open()
+}
+
+// selectField extracts the value at path from a JSON document
+func selectField(document []byte, path []string) (string, error) {
+	// Numbers keep their original text, so 1000000 is not turned into 1e+06
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.UseNumber()
+	var current any
+	if err := decoder.Decode(&current); err != nil {
+		return "", fmt.Errorf("value is not JSON: %w", err)
+	}
+
+	for i, field := range path {
+		object, ok := current.(map[string]any)
+		if !ok {
+			return "", fmt.Errorf("%s is not an object", strings.Join(path[:i], "."))
+		}
+		if current, ok = object[field]; !ok {
+			return "", fmt.Errorf("field %s not found", strings.Join(path[:i+1], "."))
+		}
+	}
+
+	switch value := current.(type) {
+	case string:
+		return value, nil
+	case json.Number:
+		return value.String(), nil
+	case bool:
+		return fmt.Sprint(value), nil
+	default:
+		return "", fmt.Errorf("field %s is not a scalar value", strings.Join(path, "."))
+	}
+}
@@ -0,0 +1,106 @@
+package secretsmanager
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/fernandezvara/commandkit"
+)
+
+// fakeClient serves secrets from a map and counts the calls
+type fakeClient struct {
+	secrets map[string]string
+	calls   int
+}
+
+func (f *fakeClient) GetSecretValue(_ context.Context, input *secretsmanager.GetSecretValueInput, _ ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error) {
+	f.calls++
+	secret, ok := f.secrets[aws.ToString(input.SecretId)]
+	if !ok {
+		return nil, errors.New("ResourceNotFoundException")
+	}
+	return &secretsmanager.GetSecretValueOutput{SecretString: aws.String(secret)}, nil
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{secrets: map[string]string{
+		"prod/api-token": "tok-123",
+		"prod/db":        `{"username": "app", "credentials": {"password": "hunter2", "port": 5432, "max_bytes": 1000000}}`,
+	}}
+}
+
+func TestSourceLookup(t *testing.T) {
+	client := newFakeClient()
+	source := New(WithClient(client)).
+		Map("API_TOKEN", "prod/api-token").
+		Map("DB_USER", "prod/db#username").
+		Map("DB_PASSWORD", "prod/db#credentials.password").
+		Map("DB_PORT", "prod/db#credentials.port").
+		Map("DB_MAX_BYTES", "prod/db#credentials.max_bytes")
+
+	tests := map[string]string{
+		"API_TOKEN":    "tok-123",
+		"DB_USER":      "app",
+		"DB_PASSWORD":  "hunter2",
+		"DB_PORT":      "5432",
+		"DB_MAX_BYTES": "1000000",
+	}
+	for key, want := range tests {
+		value, found, err := source.Lookup(key)
+		if err != nil || !found || value != want {
+			t.Errorf("Lookup(%s) = %q, %v, %v; want %q", key, value, found, err, want)
+		}
+	}
+
+	if _, found, err := source.Lookup("UNMAPPED"); found || err != nil {
+		t.Errorf("unmapped keys should not be found, got %v, %v", found, err)
+	}
+	if client.calls != 2 {
+		t.Errorf("expected each secret to be fetched once, got %d calls", client.calls)
+	}
+	if !source.IsSensitive("API_TOKEN") {
+		t.Error("secrets should always be sensitive")
+	}
+}
+
+func TestSourceLookupErrors(t *testing.T) {
+	source := New(WithClient(newFakeClient())).
+		Map("MISSING", "prod/missing").
+		Map("NO_FIELD", "prod/db#credentials.user").
+		Map("NOT_JSON", "prod/api-token#field").
+		Map("NOT_SCALAR", "prod/db#credentials")
+
+	for _, key := range []string{"MISSING", "NO_FIELD", "NOT_JSON", "NOT_SCALAR"} {
+		if _, _, err := source.Lookup(key); err == nil {
+			t.Errorf("Lookup(%s) should fail", key)
+		}
+	}
+}
+
+func TestSourceWithConfig(t *testing.T) {
+	cfg := commandkit.New()
+	cfg.Define("DB_PASSWORD").String().Secret().Required()
+	cfg.Define("DB_USER").String()
+	cfg.UseSource(New(WithClient(newFakeClient())).Map("DB_PASSWORD", "prod/db#credentials.password"))
+
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if password := cfg.GetSecret("DB_PASSWORD").String(); password != "hunter2" {
+		t.Errorf("DB_PASSWORD = %q, want hunter2", password)
+	}
+
+	// A non-secret definition must not receive a secret
+	cfg = commandkit.New()
+	cfg.Define("DB_USER").String()
+	cfg.UseSource(New(WithClient(newFakeClient())).Map("DB_USER", "prod/db#username"))
+
+	err := cfg.Process([]string{"app"})
+	if err == nil || !strings.Contains(err.Error(), "Secret()") {
+		t.Fatalf("expected the secret to be refused, got %v", err)
+	}
+}
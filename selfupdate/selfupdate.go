@@ -0,0 +1,499 @@
+// Package selfupdate adds a command replacing the running binary with its latest
+// release, for command line tools distributed as a single binary.
+//
+//	selfupdate.Command(cfg, "update", version, selfupdate.GitHub("acme", "tool"),
+//		selfupdate.WithPublicKey(releaseKey))
+//
+//	tool update               # download, verify and install the latest release
+//	tool update --check=true  # only report whether one is available
+//
+// Releases are found on GitHub or, for self-hosted downloads, in a JSON manifest; other
+// backends plug in through the Source interface. With WithPublicKey, every download
+// must come with a valid Ed25519 signature of its Statement, so a compromised download
+// server can neither ship a binary nor pass an old release off as a new one. Without
+// it, the download or its SHA-256 checksum must be served over https, and a checksum
+// is verified whenever the release has one. Downloads are either the binary itself or
+// a .tar.gz or .zip archive holding it under the name of the running binary.
+package selfupdate
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"cmp"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fernandezvara/commandkit"
+)
+
+// checkKey is the key of the command's --check flag
+const checkKey = "SELFUPDATE_CHECK"
+
+// signatureSuffix follows the name of a GitHub release asset to name its signature
+const signatureSuffix = ".sig"
+
+// checksumSuffix follows the name of a GitHub release asset to name its checksum
+const checksumSuffix = ".sha256"
+
+// githubAPI is the root of the GitHub REST API, replaced in tests
+var githubAPI = "https://api.github.com"
+
+// Release is a published version of the program
+type Release struct {
+	Version      string // e.g. "v1.4.0"
+	URL          string // Download URL of the binary, or of a .tar.gz or .zip archive holding it
+	SignatureURL string // Download URL of the Ed25519 signature of the Statement, "" if unsigned
+	ChecksumURL  string // Download URL of the hex SHA-256 of the download, "" if none
+}
+
+// Statement returns what a release signature covers: the version and the SHA-256 of
+// the download, as the line "<version> <hex digest>\n". Release tooling signs it with
+// ed25519.Sign; binding the version stops an old signed binary from being served as a
+// newer release.
+func Statement(version string, download []byte) []byte {
+	sum := sha256.Sum256(download)
+	return fmt.Appendf(nil, "%s %s\n", version, hex.EncodeToString(sum[:]))
+}
+
+// Source finds the latest release of the program for the running platform
+type Source interface {
+	Latest(ctx context.Context, client *http.Client) (Release, error)
+}
+
+// SourceFunc adapts a function to the Source interface
+type SourceFunc func(ctx context.Context, client *http.Client) (Release, error)
+
+// Latest calls f
+func (f SourceFunc) Latest(ctx context.Context, client *http.Client) (Release, error) {
+	return f(ctx, client)
+}
+
+// GitHub returns a source reading the latest release of the owner/repo repository. The
+// download is the asset whose name holds the running GOOS and GOARCH as separate words,
+// such as "tool_linux_amd64.tar.gz", its signature the asset of the same name followed
+// by ".sig", and its checksum the one followed by ".sha256".
+func GitHub(owner, repo string) Source {
+	return SourceFunc(func(ctx context.Context, client *http.Client) (Release, error) {
+		var release struct {
+			TagName string `json:"tag_name"`
+			Assets  []struct {
+				Name string `json:"name"`
+				URL  string `json:"browser_download_url"`
+			} `json:"assets"`
+		}
+		endpoint := fmt.Sprintf("%s/repos/%s/%s/releases/latest", githubAPI, owner, repo)
+		if err := getJSON(ctx, client, endpoint, &release); err != nil {
+			return Release{}, err
+		}
+
+		urls := make(map[string]string, len(release.Assets))
+		for _, asset := range release.Assets {
+			urls[asset.Name] = asset.URL
+		}
+		for _, asset := range release.Assets {
+			name := strings.ToLower(asset.Name)
+			if strings.HasSuffix(name, signatureSuffix) || strings.HasSuffix(name, checksumSuffix) ||
+				!matchesPlatform(name, runtime.GOOS, runtime.GOARCH) {
+				continue
+			}
+			return Release{
+				Version:      release.TagName,
+				URL:          asset.URL,
+				SignatureURL: urls[asset.Name+signatureSuffix],
+				ChecksumURL:  urls[asset.Name+checksumSuffix],
+			}, nil
+		}
+		return Release{}, fmt.Errorf("release %s has no asset for %s/%s", release.TagName, runtime.GOOS, runtime.GOARCH)
+	})
+}
+
+// Manifest returns a source reading the latest release from a JSON document, for
+// self-hosted downloads. "{os}" and "{arch}" in the URLs are replaced by the running
+// GOOS and GOARCH, and the signature and checksum are optional:
+//
+//	{
+//	  "version": "v1.4.0",
+//	  "url": "https://dl.example.com/tool/v1.4.0/tool-{os}-{arch}",
+//	  "signature": "https://dl.example.com/tool/v1.4.0/tool-{os}-{arch}.sig",
+//	  "checksum": "https://dl.example.com/tool/v1.4.0/tool-{os}-{arch}.sha256"
+//	}
+func Manifest(manifestURL string) Source {
+	return SourceFunc(func(ctx context.Context, client *http.Client) (Release, error) {
+		var manifest struct {
+			Version   string `json:"version"`
+			URL       string `json:"url"`
+			Signature string `json:"signature"`
+			Checksum  string `json:"checksum"`
+		}
+		if err := getJSON(ctx, client, manifestURL, &manifest); err != nil {
+			return Release{}, err
+		}
+		if manifest.Version == "" || manifest.URL == "" {
+			return Release{}, fmt.Errorf("manifest %s has no version or url", manifestURL)
+		}
+		platform := strings.NewReplacer("{os}", runtime.GOOS, "{arch}", runtime.GOARCH)
+		return Release{
+			Version:      manifest.Version,
+			URL:          platform.Replace(manifest.URL),
+			SignatureURL: platform.Replace(manifest.Signature),
+			ChecksumURL:  platform.Replace(manifest.Checksum),
+		}, nil
+	})
+}
+
+// Result describes a run of the update command
+type Result struct {
+	Current   string `json:"current"`   // Running version
+	Latest    string `json:"latest"`    // Latest released version
+	Available bool   `json:"available"` // Whether Latest is newer than Current
+	Updated   bool   `json:"updated"`   // Whether the binary was replaced
+}
+
+// Option configures Command
+type Option func(*updater)
+
+// updater holds the Command settings
+type updater struct {
+	client     *http.Client
+	publicKey  ed25519.PublicKey
+	executable func() (string, error) // Path of the binary to replace
+}
+
+// WithPublicKey requires every download to be signed with the private key matching
+// key. Signature files hold the 64 byte Ed25519 signature of the release's Statement,
+// raw or base64 encoded.
+func WithPublicKey(key ed25519.PublicKey) Option {
+	return func(u *updater) {
+		u.publicKey = key
+	}
+}
+
+// WithHTTPClient sets the client used for every request (default: a client with a five
+// minute timeout), e.g. to go through a proxy or authenticate against GitHub
+func WithHTTPClient(client *http.Client) Option {
+	return func(u *updater) {
+		if client != nil {
+			u.client = client
+		}
+	}
+}
+
+// Command adds a command, such as "update", replacing the running binary with the
+// latest release of source when it is newer than version, the running version usually
+// set at build time with -ldflags. Its --check flag only reports whether an update is
+// available. The outcome is a Result, rendered like any other command result.
+func Command(cfg *commandkit.Config, name, version string, source Source, opts ...Option) *commandkit.CommandBuilder {
+	u := &updater{
+		client:     &http.Client{Timeout: 5 * time.Minute},
+		executable: executablePath,
+	}
+	for _, opt := range opts {
+		opt(u)
+	}
+
+	return cfg.Command(name).
+		ShortHelp("Update to the latest release").
+		ResultFunc(func(ctx *commandkit.CommandContext) (any, error) {
+			check, err := commandkit.Get[bool](ctx, checkKey)
+			if err != nil {
+				return nil, err
+			}
+			return u.update(context.Background(), version, source, check)
+		}).
+		Config(func(cc *commandkit.CommandConfig) {
+			cc.Define(checkKey).Bool().Flag("check").Default(false).
+				Description("Only report whether an update is available")
+		})
+}
+
+// update installs the latest release of source when it is newer than current
+func (u *updater) update(ctx context.Context, current string, source Source, checkOnly bool) (Result, error) {
+	release, err := source.Latest(ctx, u.client)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to find the latest release: %w", err)
+	}
+	result := Result{Current: current, Latest: release.Version, Available: compareVersions(release.Version, current) > 0}
+	if !result.Available || checkOnly {
+		return result, nil
+	}
+
+	if u.publicKey != nil && release.SignatureURL == "" {
+		return result, fmt.Errorf("release %s is not signed", release.Version)
+	}
+	if u.publicKey == nil && !isHTTPS(release.URL) && !isHTTPS(release.ChecksumURL) {
+		return result, fmt.Errorf("release %s is not signed and neither it nor its checksum is served over https", release.Version)
+	}
+	data, err := download(ctx, u.client, release.URL)
+	if err != nil {
+		return result, err
+	}
+	if release.ChecksumURL != "" {
+		checksum, err := download(ctx, u.client, release.ChecksumURL)
+		if err != nil {
+			return result, err
+		}
+		sum := sha256.Sum256(data)
+		if fields := strings.Fields(string(checksum)); len(fields) == 0 || !strings.EqualFold(fields[0], hex.EncodeToString(sum[:])) {
+			return result, fmt.Errorf("checksum mismatch for release %s", release.Version)
+		}
+	}
+	if u.publicKey != nil {
+		signature, err := download(ctx, u.client, release.SignatureURL)
+		if err != nil {
+			return result, err
+		}
+		if !ed25519.Verify(u.publicKey, Statement(release.Version, data), decodeSignature(signature)) {
+			return result, fmt.Errorf("invalid signature for release %s", release.Version)
+		}
+	}
+
+	exe, err := u.executable()
+	if err != nil {
+		return result, fmt.Errorf("failed to locate the running binary: %w", err)
+	}
+	binary, err := extract(release.URL, data, filepath.Base(exe))
+	if err != nil {
+		return result, err
+	}
+	if err := replace(exe, binary); err != nil {
+		return result, fmt.Errorf("failed to install release %s: %w", release.Version, err)
+	}
+	result.Updated = true
+	return result, nil
+}
+
+// matchesPlatform reports whether an asset name holds goos and goarch as separate
+// words, so that "arm" does not match "tool_linux_arm64"
+func matchesPlatform(name, goos, goarch string) bool {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < '0' || r > '9')
+	})
+	return slices.Contains(words, goos) && slices.Contains(words, goarch)
+}
+
+// executablePath returns the path of the running binary, symbolic links resolved
+func executablePath() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(exe)
+}
+
+// decodeSignature accepts raw and base64 encoded signatures
+func decodeSignature(data []byte) []byte {
+	if len(data) == ed25519.SignatureSize {
+		return data
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil
+	}
+	return decoded
+}
+
+// extract returns the binary named name from a downloaded archive, or the download
+// itself when it is not an archive
+func extract(downloadURL string, data []byte, name string) ([]byte, error) {
+	file := downloadURL
+	if parsed, err := url.Parse(downloadURL); err == nil {
+		file = parsed.Path
+	}
+	file = strings.ToLower(path.Base(file))
+
+	switch {
+	case strings.HasSuffix(file, ".tar.gz") || strings.HasSuffix(file, ".tgz"):
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("invalid archive %s: %w", file, err)
+		}
+		archive := tar.NewReader(gz)
+		for {
+			header, err := archive.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("invalid archive %s: %w", file, err)
+			}
+			if header.Typeflag == tar.TypeReg && path.Base(header.Name) == name {
+				return io.ReadAll(archive)
+			}
+		}
+	case strings.HasSuffix(file, ".zip"):
+		archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid archive %s: %w", file, err)
+		}
+		for _, entry := range archive.File {
+			if entry.Mode().IsRegular() && path.Base(entry.Name) == name {
+				rc, err := entry.Open()
+				if err != nil {
+					return nil, err
+				}
+				defer rc.Close()
+				return io.ReadAll(rc)
+			}
+		}
+	default:
+		return data, nil
+	}
+	return nil, fmt.Errorf("archive %s has no %s binary", file, name)
+}
+
+// replace swaps the binary at exe for binary, keeping its permissions. The running
+// binary is moved aside rather than overwritten, as some systems refuse to write it.
+func replace(exe string, binary []byte) error {
+	info, err := os.Stat(exe)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(exe), "."+filepath.Base(exe)+".new-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(binary); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()); err != nil {
+		return err
+	}
+
+	old := exe + ".old"
+	os.Remove(old) // Left behind by a previous update on Windows
+	if err := os.Rename(exe, old); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), exe); err != nil {
+		os.Rename(old, exe)
+		return err
+	}
+	os.Remove(old) // Fails on Windows while the old binary runs; removed next time
+	return nil
+}
+
+// compareVersions compares versions such as "v1.10.0" and "1.9.2" part by part,
+// numerically where both parts are numbers. Numbers sort after other parts, so
+// releases are newer than development builds such as "dev". As in semantic
+// versioning, a pre-release ("1.2.0-rc.1") sorts before its release and build
+// metadata ("1.2.0+build.5") is ignored.
+func compareVersions(a, b string) int {
+	a, _, _ = strings.Cut(a, "+")
+	b, _, _ = strings.Cut(b, "+")
+	a, aPre, _ := strings.Cut(strings.TrimPrefix(a, "v"), "-")
+	b, bPre, _ := strings.Cut(strings.TrimPrefix(b, "v"), "-")
+	aParts, bParts := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < max(len(aParts), len(bParts)); i++ {
+		aPart, bPart := "0", "0"
+		if i < len(aParts) {
+			aPart = aParts[i]
+		}
+		if i < len(bParts) {
+			bPart = bParts[i]
+		}
+		aNum, aErr := strconv.Atoi(aPart)
+		bNum, bErr := strconv.Atoi(bPart)
+		switch {
+		case aErr == nil && bErr == nil && aNum != bNum:
+			return cmp.Compare(aNum, bNum)
+		case aErr == nil && bErr != nil:
+			return 1
+		case aErr != nil && bErr == nil:
+			return -1
+		case aErr != nil && bErr != nil && aPart != bPart:
+			return strings.Compare(aPart, bPart)
+		}
+	}
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+	return comparePrerelease(aPre, bPre)
+}
+
+// comparePrerelease compares pre-release tags by semantic versioning precedence:
+// identifier by identifier, numerically for numbers ("rc.2" before "rc.10"), numbers
+// before other identifiers, and a tag before a longer one it starts ("alpha" before
+// "alpha.1")
+func comparePrerelease(a, b string) int {
+	aIDs, bIDs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < min(len(aIDs), len(bIDs)); i++ {
+		aNum, aErr := strconv.Atoi(aIDs[i])
+		bNum, bErr := strconv.Atoi(bIDs[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if aNum != bNum {
+				return cmp.Compare(aNum, bNum)
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		case aIDs[i] != bIDs[i]:
+			return strings.Compare(aIDs[i], bIDs[i])
+		}
+	}
+	return cmp.Compare(len(aIDs), len(bIDs))
+}
+
+// getJSON decodes the JSON document at endpoint into v
+func getJSON(ctx context.Context, client *http.Client, endpoint string, v any) error {
+	data, err := download(ctx, client, endpoint)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("invalid response from %s: %w", endpoint, err)
+	}
+	return nil
+}
+
+// isHTTPS reports whether target is an https URL
+func isHTTPS(target string) bool {
+	return strings.HasPrefix(strings.ToLower(target), "https://")
+}
+
+// download returns the body of a GET request to target
+func download(ctx context.Context, client *http.Client, target string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", target, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,258 @@
+package selfupdate
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/fernandezvara/commandkit"
+)
+
+// tarGz returns a .tar.gz archive holding name with content
+func tarGz(t *testing.T, name string, content []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	archive := tar.NewWriter(gz)
+	if err := archive.WriteHeader(&tar.Header{Name: "dist/" + name, Mode: 0o755, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+		t.Fatal(err)
+	}
+	archive.Write(content)
+	archive.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+// installTestBinary writes an "old" binary named tool and returns an option making
+// the command replace it instead of the test binary
+func installTestBinary(t *testing.T) (string, Option) {
+	t.Helper()
+	exe := filepath.Join(t.TempDir(), "tool")
+	if err := os.WriteFile(exe, []byte("old"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return exe, func(u *updater) {
+		u.executable = func() (string, error) { return exe, nil }
+	}
+}
+
+func runUpdate(t *testing.T, source Source, opts []Option, args ...string) (Result, error) {
+	t.Helper()
+	cfg := commandkit.New()
+	Command(cfg, "update", "v1.0.0", source, opts...)
+	report := cfg.ExecuteReport(append([]string{"tool", "update"}, args...))
+	result, _ := report.Result.(Result)
+	return result, report.Err
+}
+
+func TestGitHubUpdate(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive := tarGz(t, "tool", []byte("new"))
+	asset := fmt.Sprintf("tool_%s_%s.tar.gz", runtime.GOOS, runtime.GOARCH)
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, Statement("v1.2.0", archive)))
+
+	tag := "v1.2.0"
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/acme/tool/releases/latest":
+			json.NewEncoder(w).Encode(map[string]any{
+				"tag_name": tag,
+				"assets": []map[string]string{
+					{"name": "tool_plan9_mips.tar.gz", "browser_download_url": server.URL + "/other"},
+					{"name": asset + ".sig", "browser_download_url": server.URL + "/download/" + asset + ".sig"},
+					{"name": asset, "browser_download_url": server.URL + "/download/" + asset},
+				},
+			})
+		case "/download/" + asset:
+			w.Write(archive)
+		case "/download/" + asset + ".sig":
+			w.Write([]byte(signature))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	defer func(original string) { githubAPI = original }(githubAPI)
+	githubAPI = server.URL
+
+	exe, target := installTestBinary(t)
+	source := GitHub("acme", "tool")
+
+	result, err := runUpdate(t, source, []Option{target, WithPublicKey(publicKey)}, "--check=true")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := (Result{Current: "v1.0.0", Latest: "v1.2.0", Available: true}); result != want {
+		t.Errorf("check result = %+v, want %+v", result, want)
+	}
+	if data, _ := os.ReadFile(exe); string(data) != "old" {
+		t.Fatal("--check replaced the binary")
+	}
+
+	otherKey, _, _ := ed25519.GenerateKey(rand.Reader)
+	if _, err := runUpdate(t, source, []Option{target, WithPublicKey(otherKey)}); err == nil || !strings.Contains(err.Error(), "invalid signature") {
+		t.Errorf("expected an invalid signature error, got %v", err)
+	}
+	if data, _ := os.ReadFile(exe); string(data) != "old" {
+		t.Fatal("a release with an invalid signature was installed")
+	}
+
+	// The signature binds the version, so the release cannot be relabeled
+	tag = "v9.9.9"
+	if _, err := runUpdate(t, source, []Option{target, WithPublicKey(publicKey)}); err == nil || !strings.Contains(err.Error(), "invalid signature") {
+		t.Errorf("expected a relabeled release to be refused, got %v", err)
+	}
+	tag = "v1.2.0"
+
+	result, err = runUpdate(t, source, []Option{target, WithPublicKey(publicKey)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Updated {
+		t.Errorf("result = %+v, want an update", result)
+	}
+	if data, _ := os.ReadFile(exe); string(data) != "new" {
+		t.Errorf("binary = %q, want the released one", data)
+	}
+	if info, err := os.Stat(exe); err != nil || info.Mode().Perm() != 0o755 {
+		t.Errorf("binary mode = %v, %v; want 0755", info.Mode(), err)
+	}
+}
+
+func TestManifestUpdate(t *testing.T) {
+	sum := sha256.Sum256([]byte("new"))
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/latest.json":
+			fmt.Fprintf(w, `{"version": "1.0.0", "url": "%[1]s/tool-{os}-{arch}", "checksum": "%[1]s/tool-{os}-{arch}.sha256"}`, server.URL)
+		case fmt.Sprintf("/tool-%s-%s", runtime.GOOS, runtime.GOARCH):
+			w.Write([]byte("new"))
+		case fmt.Sprintf("/tool-%s-%s.sha256", runtime.GOOS, runtime.GOARCH):
+			fmt.Fprintf(w, "%x  tool\n", sum)
+		case "/bad.sha256":
+			fmt.Fprintf(w, "%x\n", sha256.Sum256([]byte("other")))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	exe, installTarget := installTestBinary(t)
+	target := func(u *updater) {
+		installTarget(u)
+		WithHTTPClient(server.Client())(u)
+	}
+	source := Manifest(server.URL + "/latest.json")
+
+	// Same version: nothing to do
+	result, err := runUpdate(t, source, []Option{target})
+	if err != nil || result.Available || result.Updated {
+		t.Errorf("result = %+v, %v; want no update", result, err)
+	}
+
+	newer := func(change func(*Release)) Source {
+		return SourceFunc(func(ctx context.Context, client *http.Client) (Release, error) {
+			release, err := source.Latest(ctx, client)
+			release.Version = "v1.0.1"
+			change(&release)
+			return release, err
+		})
+	}
+
+	// Unsigned releases are refused once a public key is set
+	publicKey, _, _ := ed25519.GenerateKey(rand.Reader)
+	if _, err := runUpdate(t, newer(func(*Release) {}), []Option{target, WithPublicKey(publicKey)}); err == nil || !strings.Contains(err.Error(), "not signed") {
+		t.Errorf("expected an unsigned release error, got %v", err)
+	}
+
+	// Without a key, the download or its checksum must come over https, and a
+	// checksum must match
+	plainHTTP := newer(func(r *Release) {
+		r.URL = strings.Replace(r.URL, "https://", "http://", 1)
+		r.ChecksumURL = strings.Replace(r.ChecksumURL, "https://", "http://", 1)
+	})
+	if _, err := runUpdate(t, plainHTTP, []Option{target}); err == nil || !strings.Contains(err.Error(), "https") {
+		t.Errorf("expected an http download with an http checksum to be refused, got %v", err)
+	}
+	noChecksum := newer(func(r *Release) {
+		r.URL = strings.Replace(r.URL, "https://", "http://", 1)
+		r.ChecksumURL = ""
+	})
+	if _, err := runUpdate(t, noChecksum, []Option{target}); err == nil || !strings.Contains(err.Error(), "https") {
+		t.Errorf("expected an unverified http download to be refused, got %v", err)
+	}
+	badChecksum := newer(func(r *Release) { r.ChecksumURL = server.URL + "/bad.sha256" })
+	if _, err := runUpdate(t, badChecksum, []Option{target}); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("expected a checksum mismatch, got %v", err)
+	}
+	if data, _ := os.ReadFile(exe); string(data) != "old" {
+		t.Fatal("an unverified release was installed")
+	}
+
+	result, err = runUpdate(t, newer(func(*Release) {}), []Option{target})
+	if err != nil || !result.Updated {
+		t.Fatalf("result = %+v, %v; want an update", result, err)
+	}
+	if data, _ := os.ReadFile(exe); string(data) != "new" {
+		t.Errorf("binary = %q, want the released one", data)
+	}
+}
+
+func TestMatchesPlatform(t *testing.T) {
+	tests := []struct {
+		name, goos, goarch string
+		want               bool
+	}{
+		{"tool_linux_arm64.tar.gz", "linux", "arm64", true},
+		{"tool_linux_arm64.tar.gz", "linux", "arm", false},
+		{"tool-Linux-AMD64.zip", "linux", "amd64", true},
+		{"tool_darwin_amd64.tar.gz", "linux", "amd64", false},
+	}
+	for _, tt := range tests {
+		if got := matchesPlatform(tt.name, tt.goos, tt.goarch); got != tt.want {
+			t.Errorf("matchesPlatform(%q, %s, %s) = %v, want %v", tt.name, tt.goos, tt.goarch, got, tt.want)
+		}
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"v1.10.0", "v1.9.2", 1},
+		{"1.2", "v1.2.0", 0},
+		{"v1.2.0-rc.1", "v1.2.0", -1},
+		{"v1.2.0", "v1.2.0-rc.1", 1},
+		{"v2.0.0", "dev", 1},
+		{"v1.2.0-rc.10", "v1.2.0-rc.2", 1},
+		{"v1.2.0-alpha", "v1.2.0-alpha.1", -1},
+		{"v1.2.0-1", "v1.2.0-alpha", -1},
+		{"v1.2.0-beta", "v1.2.0-alpha.9", 1},
+		{"v1.2.0+build.5", "v1.2.0", 0},
+		{"v1.2.1+x", "v1.2.0+y", 1},
+	}
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
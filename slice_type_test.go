@@ -2,7 +2,11 @@
 package commandkit
 
 import (
+	"net/url"
+	"path/filepath"
+	"slices"
 	"testing"
+	"time"
 )
 
 func TestSliceTypeNoPanic(t *testing.T) {
@@ -325,3 +329,113 @@ func TestValueTypeStringMethods(t *testing.T) {
 		})
 	}
 }
+
+func TestFloatAndDurationSlices(t *testing.T) {
+	cfg := New()
+	cfg.WithEnviron([]string{"BACKOFF=1s, 5s,30s", "WEIGHTS_0=0.5", "WEIGHTS_1=1.5"})
+	cfg.Define("BACKOFF").DurationSlice().Env("BACKOFF").MinItems(2).MaxItems(5)
+	cfg.Define("WEIGHTS").Float64Slice().Env("WEIGHTS").MaxItems(2)
+	cfg.Define("RETENTION").DurationSlice().Default("1d,7d")
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := NewCommandContext([]string{}, cfg, "", "")
+
+	if backoff := MustGet[[]time.Duration](ctx, "BACKOFF"); !slices.Equal(backoff, []time.Duration{time.Second, 5 * time.Second, 30 * time.Second}) {
+		t.Errorf("BACKOFF = %v", backoff)
+	}
+	if weights := MustGet[[]float64](ctx, "WEIGHTS"); !slices.Equal(weights, []float64{0.5, 1.5}) {
+		t.Errorf("WEIGHTS = %v", weights)
+	}
+	if retention := MustGet[[]time.Duration](ctx, "RETENTION"); !slices.Equal(retention, []time.Duration{24 * time.Hour, 7 * 24 * time.Hour}) {
+		t.Errorf("RETENTION = %v", retention)
+	}
+
+	invalid := []struct {
+		name   string
+		define func(*Config)
+		env    string
+	}{
+		{"bad duration", func(c *Config) { c.Define("BACKOFF").DurationSlice().Env("BACKOFF") }, "BACKOFF=1s,soon"},
+		{"too few durations", func(c *Config) { c.Define("BACKOFF").DurationSlice().Env("BACKOFF").MinItems(2) }, "BACKOFF=1s"},
+		{"too many floats", func(c *Config) { c.Define("WEIGHTS").Float64Slice().Env("WEIGHTS").MaxItems(1) }, "WEIGHTS=1,2"},
+	}
+	for _, tt := range invalid {
+		cfg := New()
+		cfg.WithEnviron([]string{tt.env})
+		tt.define(cfg)
+		if err := cfg.Process([]string{"app"}); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
+
+func TestURLSlice(t *testing.T) {
+	cfg := New()
+	cfg.WithEnviron([]string{"BROKERS=https://a:9092, https://b:9092"})
+	cfg.Define("BROKERS").URLSlice().Env("BROKERS").MinItems(1)
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := NewCommandContext([]string{}, cfg, "", "")
+
+	if brokers := MustGet[[]string](ctx, "BROKERS"); !slices.Equal(brokers, []string{"https://a:9092", "https://b:9092"}) {
+		t.Errorf("BROKERS = %v", brokers)
+	}
+	urls := MustGet[[]*url.URL](ctx, "BROKERS")
+	if len(urls) != 2 || urls[1].Host != "b:9092" || urls[1].Scheme != "https" {
+		t.Errorf("BROKERS as URLs = %v", urls)
+	}
+
+	for _, value := range []string{"https://a:9092,b:9092", "https://a:9092,https://"} {
+		cfg := New()
+		cfg.WithEnviron([]string{"BROKERS=" + value})
+		cfg.Define("BROKERS").URLSlice().Env("BROKERS")
+		if err := cfg.Process([]string{"app"}); err == nil {
+			t.Errorf("expected %q to be rejected", value)
+		}
+	}
+}
+
+func TestBoolSlice(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeConfigFile(t, path, `{"shards": [true, false, "on"]}`)
+
+	cfg := New()
+	cfg.SetDefaultPriority(PriorityFileEnvFlagDefault)
+	cfg.WithEnviron([]string{"TOGGLES=true,false,yes", "PIPED=1|0"})
+	cfg.Define("TOGGLES").BoolSlice().Env("TOGGLES").MinItems(2).MaxItems(3)
+	cfg.Define("PIPED").BoolSlice().Env("PIPED").Delimiter("|")
+	cfg.Define("DEFAULTED").BoolSlice().Default("true,false")
+	cfg.Define("shards").BoolSlice()
+	if err := cfg.LoadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := NewCommandContext([]string{}, cfg, "", "")
+	for key, want := range map[string][]bool{
+		"TOGGLES":   {true, false, true},
+		"PIPED":     {true, false},
+		"DEFAULTED": {true, false},
+		"shards":    {true, false, true},
+	} {
+		if got := MustGet[[]bool](ctx, key); !slices.Equal(got, want) {
+			t.Errorf("%s = %v, want %v", key, got, want)
+		}
+	}
+
+	for name, env := range map[string]string{
+		"invalid item": "TOGGLES=true,maybe",
+		"too few":      "TOGGLES=true",
+		"too many":     "TOGGLES=true,false,true,false",
+	} {
+		cfg := New()
+		cfg.WithEnviron([]string{env})
+		cfg.Define("TOGGLES").BoolSlice().Env("TOGGLES").MinItems(2).MaxItems(3)
+		if err := cfg.Process([]string{"app"}); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
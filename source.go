@@ -0,0 +1,235 @@
+// commandkit/source.go
+package commandkit
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// Source provides configuration values from an external backend, such as a
+// parameter store. Sources registered with Config.UseSource or Config.AddSource are
+// consulted wherever SourceRemote appears in a priority, highest source priority
+// first and in registration order among equal priorities.
+type Source interface {
+	// Name identifies the source in error messages
+	Name() string
+
+	// Lookup returns the raw value for a definition key and whether it was found
+	Lookup(key string) (string, bool, error)
+}
+
+// SensitiveSource is implemented by sources that know which of their values are
+// sensitive (for example SSM SecureString parameters). A sensitive value is only
+// accepted by a Secret() definition, so it always ends up in protected memory.
+type SensitiveSource interface {
+	Source
+	IsSensitive(key string) bool
+}
+
+// WatchableSource is implemented by sources that can report changes to their values
+type WatchableSource interface {
+	Source
+
+	// Watch calls changed whenever values may have changed, until ctx is done
+	Watch(ctx context.Context, changed func()) error
+}
+
+// Refresher is implemented by sources that cache what they read from their backend,
+// so that Reload and StartRefresh see new values
+type Refresher interface {
+	// Refresh drops the cached values; the next Lookup reads the backend again
+	Refresh()
+}
+
+// refreshSources makes the sources caching their values read them again on next use
+func (c *Config) refreshSources() {
+	for _, source := range c.sources {
+		if refresher, ok := source.Source.(Refresher); ok {
+			refresher.Refresh()
+		}
+	}
+}
+
+// SourceFailurePolicy decides what happens when a source fails to answer a lookup,
+// for example because the backend is unreachable
+type SourceFailurePolicy int
+
+const (
+	SourceFailureFail    SourceFailurePolicy = iota // The error fails processing (the default)
+	SourceFailureCached                             // Use the last value the source returned
+	SourceFailureDefault                            // Skip the source, so lower sources and defaults apply
+)
+
+// registeredSource is a source with the priority it was added with
+type registeredSource struct {
+	Source
+	priority int
+	failure  SourceFailurePolicy
+	cache    *sourceCache // Last known values, for SourceFailureCached
+}
+
+// UseSource registers an external configuration source. If the default priority
+// does not mention SourceRemote yet, remote sources are placed just above defaults,
+// so flags, environment variables and files keep overriding them; use
+// SetDefaultPriority or a definition's Priority to choose another precedence.
+func (c *Config) UseSource(source Source) *Config {
+	return c.AddSource(source, 0)
+}
+
+// AddSource registers an external configuration source like UseSource, ranked among
+// the other sources by priority: a source with a higher priority is asked first, and
+// sources with the same priority are asked in registration order. UseSource adds
+// sources with priority 0.
+func (c *Config) AddSource(source Source, priority int) *Config {
+	i := len(c.sources)
+	for i > 0 && c.sources[i-1].priority < priority {
+		i--
+	}
+	registered := registeredSource{Source: source, priority: priority}
+	if policy, found := c.sourcePolicies[source.Name()]; found {
+		registered.failure = policy
+		registered.cache = c.lastKnownValues()
+	}
+	c.sources = slices.Insert(c.sources, i, registered)
+	c.ensureSource(SourceRemote)
+	return c
+}
+
+// SetSourceFailurePolicy sets what happens when the source with the given name fails,
+// so that an outage of a backend such as Consul or Vault need not prevent startup.
+// With SourceFailureCached the source's values are remembered as they are read
+// (persisted to the SetSourceCache file, if any) and the last known value is used
+// while the source is failing; keys it never returned fall through to lower sources
+// and defaults. Values of Secret() definitions and sensitive values are never cached.
+// The policy may be set before or after the source is registered; each fallback is
+// reported to the OnSourceFailure handlers.
+func (c *Config) SetSourceFailurePolicy(name string, policy SourceFailurePolicy) *Config {
+	if c.sourcePolicies == nil {
+		c.sourcePolicies = make(map[string]SourceFailurePolicy)
+	}
+	c.sourcePolicies[name] = policy
+	for i := range c.sources {
+		if c.sources[i].Name() == name {
+			c.sources[i].failure = policy
+			c.sources[i].cache = c.lastKnownValues()
+		}
+	}
+	return c
+}
+
+// OnSourceFailure registers a function called when a failing source is skipped or
+// its cached value is used because of its SourceFailurePolicy, with the error the
+// source returned. Without handlers each fallback is logged as a warning.
+func (c *Config) OnSourceFailure(handler func(source, key string, err error)) *Config {
+	c.sourceFailed = append(c.sourceFailed, handler)
+	return c
+}
+
+// reportSourceFailure tells the OnSourceFailure handlers that source failed for key
+// and a fallback was used
+func (c *Config) reportSourceFailure(source, key, fallback string, err error) {
+	if len(c.sourceFailed) == 0 {
+		logWarningForDesigner(fmt.Sprintf("Source '%s' failed for '%s' (%v); %s", source, key, err, fallback))
+		return
+	}
+	for _, handler := range c.sourceFailed {
+		handler(source, key, err)
+	}
+}
+
+// WatchSources starts watching every registered source that supports it and reloads the
+// configuration whenever one reports a change. As with WatchFile, an invalid result keeps
+// the previous values, and callback (which may be nil) receives the outcome of each reload.
+// Watching stops when ctx is done.
+func (c *Config) WatchSources(ctx context.Context, callback func(error)) error {
+	if callback == nil {
+		callback = func(error) {}
+	}
+
+	for _, source := range c.sources {
+		watchable, ok := source.Source.(WatchableSource)
+		if !ok {
+			continue
+		}
+		if err := watchable.Watch(ctx, func() { callback(c.Reload()) }); err != nil {
+			return fmt.Errorf("%s: %w", source.Name(), err)
+		}
+	}
+	return nil
+}
+
+// remoteValue is a value found in a registered source
+type remoteValue struct {
+	value     string
+	source    string
+	sensitive bool
+}
+
+// lookupSources asks each registered source for key, by priority and then in
+// registration order, applying the failure policy of a source that returns an error
+func (c *Config) lookupSources(key string, def *Definition) (remoteValue, bool, error) {
+	for _, source := range c.sources {
+		var value string
+		var found, cached bool
+		var err error
+		if source.failure == SourceFailureCached {
+			value, cached = source.cache.fresh(source.Name(), key)
+			found = cached
+		}
+		if !cached {
+			value, found, err = source.Lookup(key)
+		}
+		if err != nil {
+			switch source.failure {
+			case SourceFailureCached:
+				if value, found = source.cache.get(source.Name(), key); !found {
+					c.reportSourceFailure(source.Name(), key, "no cached value, skipping it", err)
+					continue
+				}
+				c.reportSourceFailure(source.Name(), key, "using its cached value", err)
+			case SourceFailureDefault:
+				c.reportSourceFailure(source.Name(), key, "skipping it", err)
+				continue
+			default:
+				return remoteValue{}, false, fmt.Errorf("%s: %w", source.Name(), err)
+			}
+		}
+		if !found {
+			continue
+		}
+
+		result := remoteValue{value: value, source: source.Name()}
+		if sensitive, ok := source.Source.(SensitiveSource); ok {
+			result.sensitive = sensitive.IsSensitive(key)
+		}
+		if err == nil && !cached && source.failure == SourceFailureCached && !result.sensitive && !def.secret {
+			source.cache.put(source.Name(), key, value)
+		}
+		return result, true, nil
+	}
+	return remoteValue{}, false, nil
+}
+
+// getRemoteValue looks key up in the registered sources. Sensitive values are refused
+// for definitions that are not secrets, so they never end up in plain memory.
+func (c *Config) getRemoteValue(key string, def *Definition) (any, bool, error) {
+	var remote remoteValue
+	var found bool
+	for _, name := range append([]string{key}, def.fallbackKeys...) {
+		var err error
+		if remote, found, err = c.lookupSources(name, def); err != nil {
+			return nil, false, err
+		}
+		if found {
+			break
+		}
+	}
+	if !found {
+		return nil, false, nil
+	}
+	if remote.sensitive && !def.secret {
+		return nil, false, fmt.Errorf("%s provides a sensitive value, the definition must be declared with Secret()", remote.source)
+	}
+	return remote.value, true, nil
+}
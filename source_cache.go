@@ -0,0 +1,221 @@
+// commandkit/source_cache.go
+package commandkit
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/awnumar/memguard"
+)
+
+// sourceCache holds the last value each source returned for each key, optionally
+// persisted to a file, encrypted or not, so it survives restarts
+type sourceCache struct {
+	mu     sync.Mutex
+	path   string                            // File the values are persisted to, "" for memory only
+	key    *memguard.Enclave                 // AES-256 key of an encrypted file
+	maxAge time.Duration                     // How long a value may be served, 0 for ever
+	warm   bool                              // Serve fresh values without asking the sources
+	dirty  bool                              // Values changed since the file was written
+	values map[string]map[string]cachedValue // Values by source name, then key
+}
+
+// cachedValue is a source value and when the source last returned it
+type cachedValue struct {
+	Value   string    `json:"value"`
+	Fetched time.Time `json:"fetched"`
+}
+
+// SetSourceCache sets the file where the last known values of sources with the
+// SourceFailureCached policy are persisted, and loads the values it already holds so
+// they are available if a source fails at startup. The file is written with 0600
+// permissions after each successful processing that changed a cached value.
+func (c *Config) SetSourceCache(path string) error {
+	return c.lastKnownValues().load(path, nil)
+}
+
+// SetEncryptedSourceCache is SetSourceCache with the file encrypted with AES-256-GCM,
+// using a 32-byte key read from keyFile (raw, hex or base64, as for NewKeyFileDecryptor)
+func (c *Config) SetEncryptedSourceCache(path, keyFile string) error {
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to read key file: %w", err)
+	}
+	defer memguard.WipeBytes(data)
+
+	key, err := decodeKey(data)
+	if err != nil {
+		return fmt.Errorf("invalid key file %s: %w", keyFile, err)
+	}
+	return c.lastKnownValues().load(path, memguard.NewEnclave(key))
+}
+
+// SetSourceCacheMaxAge limits how old a cached value may be. Within that age, values
+// read from the cache file are used at startup without asking the sources, so restarts
+// are fast and work offline; older values are neither served nor used as a fallback.
+// Without a max age cached values are only used while a source is failing.
+func (c *Config) SetSourceCacheMaxAge(maxAge time.Duration) *Config {
+	cache := c.lastKnownValues()
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	cache.maxAge = maxAge
+	return c
+}
+
+// lastKnownValues returns the cache shared by the sources of the config
+func (c *Config) lastKnownValues() *sourceCache {
+	if c.sourceCache == nil {
+		c.sourceCache = &sourceCache{values: make(map[string]map[string]cachedValue)}
+	}
+	return c.sourceCache
+}
+
+// load reads the cache file at path, decrypting it with key when one is given
+func (sc *sourceCache) load(path string, key *memguard.Enclave) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to read source cache: %w", err)
+	}
+	if len(data) > 0 && key != nil {
+		if data, err = openCache(key, data); err != nil {
+			return fmt.Errorf("failed to decrypt source cache %s: %w", path, err)
+		}
+		defer memguard.WipeBytes(data)
+	}
+	values := make(map[string]map[string]cachedValue)
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &values); err != nil {
+			return fmt.Errorf("failed to parse source cache %s: %w", path, err)
+		}
+	}
+
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.path = path
+	sc.key = key
+	sc.warm = len(values) > 0
+	sc.values = values
+	return nil
+}
+
+// get returns the last value source returned for key, unless it is older than the
+// max age
+func (sc *sourceCache) get(source, key string) (string, bool) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	return sc.lookup(source, key)
+}
+
+// fresh returns a value loaded from the cache file that may be used instead of asking
+// the source: only before the first successful processing, and only with a max age
+func (sc *sourceCache) fresh(source, key string) (string, bool) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if !sc.warm || sc.maxAge <= 0 {
+		return "", false
+	}
+	return sc.lookup(source, key)
+}
+
+// lookup returns a cached value within the max age; sc.mu must be held
+func (sc *sourceCache) lookup(source, key string) (string, bool) {
+	cached, found := sc.values[source][key]
+	if !found || (sc.maxAge > 0 && time.Since(cached.Fetched) > sc.maxAge) {
+		return "", false
+	}
+	return cached.Value, true
+}
+
+// put remembers a value the source just returned
+func (sc *sourceCache) put(source, key, value string) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if sc.values[source] == nil {
+		sc.values[source] = make(map[string]cachedValue)
+	}
+	sc.values[source][key] = cachedValue{Value: value, Fetched: time.Now()}
+	sc.dirty = true
+}
+
+// commit ends startup, so later lookups ask the sources again, and persists the cache
+// if it changed. Persisting is best effort: a cache that cannot be written must not
+// fail the processing that fed it.
+func (sc *sourceCache) commit() {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.warm = false
+	if !sc.dirty || sc.path == "" {
+		return
+	}
+
+	data, err := json.MarshalIndent(sc.values, "", "  ")
+	if err == nil && sc.key != nil {
+		plaintext := data
+		data, err = sealCache(sc.key, plaintext)
+		memguard.WipeBytes(plaintext)
+	}
+	if err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(sc.path), filepath.Base(sc.path)+".*")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), sc.path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+	sc.dirty = false
+}
+
+// sealCache encrypts the cache file contents as the GCM nonce followed by the ciphertext
+func sealCache(key *memguard.Enclave, plaintext []byte) ([]byte, error) {
+	buf, err := key.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer buf.Destroy()
+
+	gcm, err := newGCM(buf.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// openCache decrypts cache file contents written by sealCache
+func openCache(key *memguard.Enclave, data []byte) ([]byte, error) {
+	buf, err := key.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer buf.Destroy()
+
+	gcm, err := newGCM(buf.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("file too short")
+	}
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
@@ -0,0 +1,262 @@
+package commandkit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// mapSource is an in-memory Source for tests
+type mapSource struct {
+	values    map[string]string
+	sensitive map[string]bool
+	err       error
+}
+
+func (m *mapSource) Name() string { return "map" }
+
+func (m *mapSource) Lookup(key string) (string, bool, error) {
+	if m.err != nil {
+		return "", false, m.err
+	}
+	value, found := m.values[key]
+	return value, found, nil
+}
+
+func (m *mapSource) IsSensitive(key string) bool { return m.sensitive[key] }
+
+func TestUseSourcePrecedence(t *testing.T) {
+	os.Setenv("SOURCE_TEST_HOST", "env.example.com")
+	defer os.Unsetenv("SOURCE_TEST_HOST")
+
+	cfg := New()
+	cfg.Define("HOST").String().Env("SOURCE_TEST_HOST").Default("localhost")
+	cfg.Define("PORT").Int64().Default(int64(80))
+	cfg.UseSource(&mapSource{values: map[string]string{"HOST": "remote.example.com", "PORT": "8080"}})
+
+	want := SourcePriority{SourceFlag, SourceEnv, SourceRemote, SourceDefault}
+	if got := cfg.GetDefaultPriority(); !slices.Equal(got, want) {
+		t.Errorf("default priority = %v, want %v", got, want)
+	}
+	if errs := cfg.processConfigWithContext(nil, nil); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if value, _ := cfg.lookupValue("HOST"); value != "env.example.com" {
+		t.Errorf("environment should override the remote source, got %v", value)
+	}
+	if value, _ := cfg.lookupValue("PORT"); value != int64(8080) {
+		t.Errorf("remote source should override the default, got %v", value)
+	}
+
+	// An explicit priority can put the remote source first
+	cfg.SetDefaultPriority(SourcePriority{SourceRemote, SourceFlag, SourceEnv, SourceDefault})
+	if errs := cfg.processConfigWithContext(nil, nil); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if value, _ := cfg.lookupValue("HOST"); value != "remote.example.com" {
+		t.Errorf("remote source should win with its priority first, got %v", value)
+	}
+}
+
+func TestUseSourceSensitiveValues(t *testing.T) {
+	source := &mapSource{
+		values:    map[string]string{"TOKEN": "s3cr3t", "LEAKY": "hunter2"},
+		sensitive: map[string]bool{"TOKEN": true, "LEAKY": true},
+	}
+
+	cfg := New()
+	cfg.Define("TOKEN").String().Secret()
+	cfg.UseSource(source)
+	if errs := cfg.processConfigWithContext(nil, nil); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if got := cfg.GetSecret("TOKEN").String(); got != "s3cr3t" {
+		t.Errorf("TOKEN = %q, want s3cr3t", got)
+	}
+
+	cfg = New()
+	cfg.Define("LEAKY").String()
+	cfg.UseSource(source)
+	errs := cfg.processConfigWithContext(nil, nil)
+	if len(errs) != 1 || !strings.Contains(errs[0].ErrorDescription, "Secret()") {
+		t.Fatalf("expected a sensitive value to be refused, got %v", errs)
+	}
+}
+
+func TestUseSourceErrors(t *testing.T) {
+	cfg := New()
+	cfg.Define("PORT").Int64().Default(int64(80))
+	cfg.UseSource(&mapSource{err: errors.New("unreachable")})
+
+	errs := cfg.processConfigWithContext(nil, nil)
+	if len(errs) != 1 || errs[0].Source != "remote" || !strings.Contains(errs[0].ErrorDescription, "map: unreachable") {
+		t.Fatalf("expected the source error to be reported, got %v", errs)
+	}
+}
+
+func TestSourceFailurePolicy(t *testing.T) {
+	cachePath := filepath.Join(t.TempDir(), "sources.json")
+	source := &mapSource{values: map[string]string{"HOST": "consul.example.com", "TOKEN": "s3cr3t"}}
+	var failures []string
+	newConfig := func(policy SourceFailurePolicy) *Config {
+		cfg := New()
+		cfg.Define("HOST").String().Default("localhost")
+		cfg.Define("PORT").Int64().Default(int64(80))
+		cfg.Define("TOKEN").String().Secret()
+		// The policy applies whether it is set before or after the source is added
+		cfg.SetSourceFailurePolicy("map", policy)
+		cfg.UseSource(source)
+		cfg.OnSourceFailure(func(source, key string, err error) {
+			failures = append(failures, source+" "+key+": "+err.Error())
+		})
+		return cfg
+	}
+
+	cfg := newConfig(SourceFailureCached)
+	if err := cfg.SetSourceCache(cachePath); err != nil {
+		t.Fatal(err)
+	}
+	if errs := cfg.processConfigWithContext(nil, nil); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	data, err := os.ReadFile(cachePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "s3cr3t") {
+		t.Fatalf("secret values must not be cached: %s", data)
+	}
+
+	// The source is down at the next startup
+	source.err = errors.New("unreachable")
+
+	cfg = newConfig(SourceFailureCached)
+	cfg.SetSourceCache(cachePath)
+	if errs := cfg.processConfigWithContext(nil, nil); len(errs) > 0 {
+		t.Fatalf("expected the cached values to be used, got %v", errs)
+	}
+	if host, _ := cfg.lookupValue("HOST"); host != "consul.example.com" {
+		t.Errorf("expected the cached HOST, got %v", host)
+	}
+	if port, _ := cfg.lookupValue("PORT"); port != int64(80) {
+		t.Errorf("expected the default PORT, got %v", port)
+	}
+	if !slices.Contains(failures, "map HOST: unreachable") {
+		t.Errorf("expected the fallback to be reported, got %v", failures)
+	}
+
+	failures = nil
+	cfg = newConfig(SourceFailureFail)
+	cfg.Define("TOKEN").String().Default("dev")
+	cfg.SetSourceFailurePolicy("map", SourceFailureDefault)
+	if errs := cfg.processConfigWithContext(nil, nil); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if host, _ := cfg.lookupValue("HOST"); host != "localhost" {
+		t.Errorf("expected the default HOST, got %v", host)
+	}
+	for _, key := range []string{"HOST", "PORT", "TOKEN"} {
+		if !slices.Contains(failures, "map "+key+": unreachable") {
+			t.Errorf("expected the skipped %s to be reported, got %v", key, failures)
+		}
+	}
+}
+
+func TestEncryptedSourceCache(t *testing.T) {
+	dir := t.TempDir()
+	cachePath := filepath.Join(dir, "sources.cache")
+	keyPath := filepath.Join(dir, "cache.key")
+	if err := os.WriteFile(keyPath, []byte(strings.Repeat("42", 32)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	source := &countingSource{mapSource: mapSource{values: map[string]string{"HOST": "etcd.example.com"}}}
+	newConfig := func() *Config {
+		cfg := New()
+		cfg.Define("HOST").String().Default("localhost")
+		cfg.UseSource(source)
+		cfg.SetSourceFailurePolicy("map", SourceFailureCached)
+		cfg.SetSourceCacheMaxAge(time.Hour)
+		if err := cfg.SetEncryptedSourceCache(cachePath, keyPath); err != nil {
+			t.Fatal(err)
+		}
+		return cfg
+	}
+
+	cfg := newConfig()
+	if errs := cfg.processConfigWithContext(nil, nil); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	data, err := os.ReadFile(cachePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "etcd.example.com") {
+		t.Fatal("expected the cache file to be encrypted")
+	}
+
+	// A restart within the max age is served from the cache
+	source.lookups = 0
+	source.values["HOST"] = "changed.example.com"
+	cfg = newConfig()
+	if errs := cfg.processConfigWithContext(nil, nil); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if host, _ := cfg.lookupValue("HOST"); host != "etcd.example.com" || source.lookups != 0 {
+		t.Errorf("expected the cached HOST without lookups, got %v after %d lookups", host, source.lookups)
+	}
+
+	// Expired values are neither served nor used as a fallback
+	cfg = newConfig()
+	cfg.SetSourceCacheMaxAge(time.Nanosecond)
+	source.err = errors.New("unreachable")
+	if errs := cfg.processConfigWithContext(nil, nil); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if host, _ := cfg.lookupValue("HOST"); host != "localhost" || source.lookups == 0 {
+		t.Errorf("expected the source to be asked and the default used, got %v", host)
+	}
+
+	os.WriteFile(keyPath, []byte(strings.Repeat("24", 32)), 0o600)
+	if err := New().SetEncryptedSourceCache(cachePath, keyPath); err == nil {
+		t.Error("expected an error decrypting the cache with another key")
+	}
+}
+
+// countingSource counts the lookups of a mapSource
+type countingSource struct {
+	mapSource
+	lookups int
+}
+
+func (s *countingSource) Lookup(key string) (string, bool, error) {
+	s.lookups++
+	return s.mapSource.Lookup(key)
+}
+
+func TestAddSourcePriority(t *testing.T) {
+	cfg := New()
+	cfg.Define("HOST").String()
+	cfg.Define("PORT").Int64()
+	cfg.Define("REGION").String()
+	cfg.UseSource(&mapSource{values: map[string]string{"HOST": "registered-first", "REGION": "eu"}})
+	cfg.AddSource(&mapSource{values: map[string]string{"HOST": "flags", "PORT": "9090"}}, 10)
+	cfg.AddSource(&mapSource{values: map[string]string{"HOST": "low", "PORT": "1", "REGION": "us"}}, -1)
+
+	if errs := cfg.processConfigWithContext(nil, nil); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if value, _ := cfg.lookupValue("HOST"); value != "flags" {
+		t.Errorf("the highest priority source should win, got %v", value)
+	}
+	if value, _ := cfg.lookupValue("PORT"); value != int64(9090) {
+		t.Errorf("PORT = %v, want 9090", value)
+	}
+	if value, _ := cfg.lookupValue("REGION"); value != "eu" {
+		t.Errorf("UseSource should rank above negative priorities, got %v", value)
+	}
+}
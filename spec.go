@@ -0,0 +1,148 @@
+// commandkit/spec.go
+package commandkit
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// DefinitionSpec describes a definition as plain data, so configuration surfaces can
+// be generated from service descriptors or shared schemas, e.g. decoded from JSON.
+// Type is a type name as printed in help, such as "int64", "[]string" or "duration",
+// or a type registered with RegisterType; an empty Type means "string". Pointer fields
+// are only applied when set.
+type DefinitionSpec struct {
+	Key          string   `json:"key"`
+	Type         string   `json:"type,omitempty"`
+	Env          string   `json:"env,omitempty"`
+	Flag         string   `json:"flag,omitempty"`
+	FileKey      string   `json:"fileKey,omitempty"`
+	FallbackKeys []string `json:"fallbackKeys,omitempty"`
+	Description  string   `json:"description,omitempty"`
+	Example      string   `json:"example,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+	Default      any      `json:"default,omitempty"`
+	Delimiter    string   `json:"delimiter,omitempty"`
+	Required     bool     `json:"required,omitempty"`
+	Secret       bool     `json:"secret,omitempty"`
+	Min          *float64 `json:"min,omitempty"`
+	Max          *float64 `json:"max,omitempty"`
+	MinLength    *int     `json:"minLength,omitempty"`
+	MaxLength    *int     `json:"maxLength,omitempty"`
+	MinItems     *int     `json:"minItems,omitempty"`
+	MaxItems     *int     `json:"maxItems,omitempty"`
+	Regexp       string   `json:"regexp,omitempty"`
+	OneOf        []string `json:"oneOf,omitempty"`
+}
+
+// DefineFromSpec defines a key for each spec, as the equivalent builder calls would.
+// Every spec is checked before any is defined, so an error leaves the config as it was.
+func (c *Config) DefineFromSpec(specs []DefinitionSpec) error {
+	types := make([]ValueType, len(specs))
+	defaults := make([]any, len(specs))
+	for i, spec := range specs {
+		if spec.Key == "" {
+			return fmt.Errorf("definition spec %d has no key", i)
+		}
+		valueType, ok := valueTypeNamed(spec.Type)
+		if _, registered := registeredType(spec.Type); !ok && registered {
+			valueType, ok = TypeCustom, true
+		}
+		if !ok {
+			return fmt.Errorf("definition spec %s: unknown type %q", spec.Key, spec.Type)
+		}
+		types[i] = valueType
+		if spec.Regexp != "" {
+			if _, err := regexp.Compile(spec.Regexp); err != nil {
+				return fmt.Errorf("definition spec %s: invalid regexp: %w", spec.Key, err)
+			}
+		}
+
+		defaultValue := spec.Default
+		if items, isList := defaultValue.([]any); isList {
+			// Lists decoded from JSON or YAML convert item by item, as in files
+			parsed, err := parseFileList(items, valueType)
+			if err != nil {
+				return fmt.Errorf("definition spec %s: invalid default: %w", spec.Key, err)
+			}
+			defaultValue = parsed
+		}
+		if defaultValue != nil && valueType != TypeString {
+			converted, err := convertDefaultValue(defaultValue, valueType)
+			if err != nil {
+				return fmt.Errorf("definition spec %s: invalid default: %w", spec.Key, err)
+			}
+			defaultValue = converted
+		}
+		defaults[i] = defaultValue
+	}
+
+	for i, spec := range specs {
+		b := c.Define(spec.Key)
+		b.def.valueType = types[i]
+		if types[i] == TypeCustom {
+			b.Type(spec.Type)
+		}
+		if spec.Env != "" {
+			b.Env(spec.Env)
+		}
+		if spec.Flag != "" {
+			b.Flag(spec.Flag)
+		}
+		if spec.FileKey != "" {
+			b.File(spec.FileKey)
+		}
+		if len(spec.FallbackKeys) > 0 {
+			b.FallbackKeys(spec.FallbackKeys...)
+		}
+		if spec.Delimiter != "" {
+			b.Delimiter(spec.Delimiter)
+		}
+		b.Description(spec.Description).Example(spec.Example).Tags(spec.Tags...)
+		b.def.defaultValue = defaults[i]
+		if spec.Required {
+			b.Required()
+		}
+		if spec.Secret {
+			b.Secret()
+		}
+		if spec.Min != nil {
+			b.Min(*spec.Min)
+		}
+		if spec.Max != nil {
+			b.Max(*spec.Max)
+		}
+		if spec.MinLength != nil {
+			b.MinLength(*spec.MinLength)
+		}
+		if spec.MaxLength != nil {
+			b.MaxLength(*spec.MaxLength)
+		}
+		if spec.MinItems != nil {
+			b.MinItems(*spec.MinItems)
+		}
+		if spec.MaxItems != nil {
+			b.MaxItems(*spec.MaxItems)
+		}
+		if spec.Regexp != "" {
+			b.Regexp(spec.Regexp)
+		}
+		if len(spec.OneOf) > 0 {
+			b.OneOf(spec.OneOf...)
+		}
+	}
+	return nil
+}
+
+// valueTypeNamed returns the type whose String() is name, TypeString for ""
+func valueTypeNamed(name string) (ValueType, bool) {
+	if name == "" {
+		return TypeString, true
+	}
+	for t := TypeString; t.String() != "unknown"; t++ {
+		if t.String() == name && t != TypeCustom {
+			return t, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,71 @@
+package commandkit
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDefineFromSpec(t *testing.T) {
+	var specs []DefinitionSpec
+	err := json.Unmarshal([]byte(`[
+		{"key": "PORT", "type": "int64", "env": "PORT", "flag": "port", "default": 8080, "min": 1, "max": 65535},
+		{"key": "TIMEOUT", "type": "duration", "default": "30s"},
+		{"key": "REGIONS", "type": "[]string", "default": ["eu", "us"], "minItems": 1},
+		{"key": "LEVEL", "env": "LEVEL", "oneOf": ["debug", "info"], "regexp": "^[a-z]+$", "description": "Log level"},
+		{"key": "API_KEY", "env": "API_KEY", "secret": true, "required": true}
+	]`), &specs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := New()
+	if err := cfg.DefineFromSpec(specs); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cfg.WithEnviron([]string{"PORT=9090", "LEVEL=info", "API_KEY=k"})
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := NewCommandContext([]string{}, cfg, "", "")
+	if port := MustGet[int64](ctx, "PORT"); port != 9090 {
+		t.Errorf("PORT = %d", port)
+	}
+	if timeout := MustGet[time.Duration](ctx, "TIMEOUT"); timeout != 30*time.Second {
+		t.Errorf("TIMEOUT = %v", timeout)
+	}
+	if regions := MustGet[[]string](ctx, "REGIONS"); !slices.Equal(regions, []string{"eu", "us"}) {
+		t.Errorf("REGIONS = %v", regions)
+	}
+	if def, _ := cfg.Definition("LEVEL"); def.Type() != TypeString || def.Description() != "Log level" {
+		t.Errorf("LEVEL = %v %q", def.Type(), def.Description())
+	}
+
+	invalid := New()
+	invalid.WithEnviron([]string{"PORT=70000", "LEVEL=info", "API_KEY=k"})
+	if err := invalid.DefineFromSpec(specs); err != nil {
+		t.Fatal(err)
+	}
+	if err := invalid.Process([]string{"app"}); err == nil {
+		t.Error("expected max validation from spec to fail")
+	}
+}
+
+func TestDefineFromSpecErrors(t *testing.T) {
+	for name, specs := range map[string][]DefinitionSpec{
+		"no key":       {{Type: "int64"}},
+		"unknown type": {{Key: "A", Type: "int128"}},
+		"bad default":  {{Key: "A", Type: "int64", Default: "many"}},
+		"bad regexp":   {{Key: "A", Regexp: "(["}},
+	} {
+		cfg := New()
+		specs = append([]DefinitionSpec{{Key: "FIRST"}}, specs...)
+		if err := cfg.DefineFromSpec(specs); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+		if _, defined := cfg.Definition("FIRST"); defined {
+			t.Errorf("%s: expected no definition after an error", name)
+		}
+	}
+}
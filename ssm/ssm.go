@@ -0,0 +1,172 @@
+// Package ssm provides a commandkit source backed by AWS SSM Parameter Store.
+//
+//	cfg.UseSource(ssm.New("/myapp/prod/"))
+//
+// Every parameter below the prefix is loaded on first use, and again on each Reload or
+// StartRefresh, and matched to definition keys by name: "/myapp/prod/database/url" and "/myapp/prod/database-url"
+// both provide DATABASE_URL. SecureString parameters are decrypted and can only be
+// used by Secret() definitions, so they are kept in protected memory.
+package ssm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/awnumar/memguard"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+)
+
+// Client is the subset of the SSM API used by Source
+type Client = ssm.GetParametersByPathAPIClient
+
+// Option configures a Source
+type Option func(*Source)
+
+// WithClient sets the SSM client; by default one is created from the default AWS
+// configuration (environment, shared config files, instance role, ...)
+func WithClient(client Client) Option {
+	return func(s *Source) {
+		s.client = client
+	}
+}
+
+// WithContext sets the context used for the AWS calls
+func WithContext(ctx context.Context) Option {
+	return func(s *Source) {
+		s.ctx = ctx
+	}
+}
+
+// Source resolves configuration keys from the parameters below a path prefix
+type Source struct {
+	prefix string
+	client Client
+	ctx    context.Context
+
+	mu     sync.Mutex
+	params map[string]parameter // nil until the parameters were read successfully
+}
+
+// parameter is a loaded SSM parameter
+type parameter struct {
+	value  string            // Value of a plain parameter
+	sealed *memguard.Enclave // Decrypted SecureString value, encrypted in memory
+	secure bool
+}
+
+// New creates a source for the parameters below prefix
+func New(prefix string, opts ...Option) *Source {
+	s := &Source{
+		prefix: "/" + strings.Trim(prefix, "/") + "/",
+		ctx:    context.Background(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Name identifies the source in error messages
+func (s *Source) Name() string {
+	return "ssm:" + s.prefix
+}
+
+// Lookup returns the value of the parameter matching key
+func (s *Source) Lookup(key string) (string, bool, error) {
+	params, err := s.load()
+	if err != nil {
+		return "", false, err
+	}
+	param, found := params[normalize(key)]
+	if param.sealed == nil {
+		return param.value, found, nil
+	}
+
+	secret, err := param.sealed.Open()
+	if err != nil {
+		return "", false, fmt.Errorf("failed to open parameter %s: %w", key, err)
+	}
+	defer secret.Destroy()
+	return strings.Clone(secret.String()), true, nil
+}
+
+// IsSensitive reports whether the parameter matching key is a SecureString
+func (s *Source) IsSensitive(key string) bool {
+	params, err := s.load()
+	if err != nil {
+		return false
+	}
+	return params[normalize(key)].secure
+}
+
+// Refresh drops the parameters read so far; the next lookup reads them again
+func (s *Source) Refresh() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.params = nil
+}
+
+// load fetches every parameter below the prefix on first use, and again after a
+// failure or a Refresh
+func (s *Source) load() (map[string]parameter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.params == nil {
+		params, err := s.fetch()
+		if err != nil {
+			return nil, err
+		}
+		s.params = params
+	}
+	return s.params, nil
+}
+
+// fetch reads the parameters below the prefix, decrypting SecureString values and
+// sealing them in enclaves
+func (s *Source) fetch() (map[string]parameter, error) {
+	client := s.client
+	if client == nil {
+		awsConfig, err := config.LoadDefaultConfig(s.ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load AWS configuration: %w", err)
+		}
+		client = ssm.NewFromConfig(awsConfig)
+	}
+
+	params := make(map[string]parameter)
+	paginator := ssm.NewGetParametersByPathPaginator(client, &ssm.GetParametersByPathInput{
+		Path:           aws.String(strings.TrimSuffix(s.prefix, "/")),
+		Recursive:      aws.Bool(true),
+		WithDecryption: aws.Bool(true),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(s.ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read parameters: %w", err)
+		}
+		for _, p := range page.Parameters {
+			name := strings.TrimPrefix(aws.ToString(p.Name), s.prefix)
+			if p.Type != types.ParameterTypeSecureString {
+				params[normalize(name)] = parameter{value: aws.ToString(p.Value)}
+				continue
+			}
+			// The enclave takes and wipes the copy; only ciphertext stays cached
+			params[normalize(name)] = parameter{
+				sealed: memguard.NewEnclave([]byte(aws.ToString(p.Value))),
+				secure: true,
+			}
+		}
+	}
+	return params, nil
+}
+
+// normalize maps parameter names and definition keys to a common form
+func normalize(name string) string {
+	return strings.ToUpper(strings.NewReplacer("/", "_", "-", "_", ".", "_").Replace(name))
+}
@@ -0,0 +1,145 @@
+package ssm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/fernandezvara/commandkit"
+)
+
+// fakeClient serves parameters in pages of one
+type fakeClient struct {
+	params []types.Parameter
+	err    error
+	calls  int
+	input  *ssm.GetParametersByPathInput
+}
+
+func (f *fakeClient) GetParametersByPath(_ context.Context, input *ssm.GetParametersByPathInput, _ ...func(*ssm.Options)) (*ssm.GetParametersByPathOutput, error) {
+	f.calls++
+	f.input = input
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	index := 0
+	if input.NextToken != nil {
+		for i, p := range f.params {
+			if aws.ToString(p.Name) == aws.ToString(input.NextToken) {
+				index = i
+			}
+		}
+	}
+	output := &ssm.GetParametersByPathOutput{Parameters: f.params[index : index+1]}
+	if index+1 < len(f.params) {
+		output.NextToken = f.params[index+1].Name
+	}
+	return output, nil
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{params: []types.Parameter{
+		{Name: aws.String("/myapp/prod/database/host"), Value: aws.String("db.internal"), Type: types.ParameterTypeString},
+		{Name: aws.String("/myapp/prod/port"), Value: aws.String("5432"), Type: types.ParameterTypeString},
+		{Name: aws.String("/myapp/prod/api-key"), Value: aws.String("s3cr3t"), Type: types.ParameterTypeSecureString},
+	}}
+}
+
+func TestSourceLookup(t *testing.T) {
+	client := newFakeClient()
+	source := New("myapp/prod", WithClient(client))
+
+	value, found, err := source.Lookup("DATABASE_HOST")
+	if err != nil || !found || value != "db.internal" {
+		t.Fatalf("Lookup(DATABASE_HOST) = %q, %v, %v", value, found, err)
+	}
+	if _, found, _ := source.Lookup("MISSING"); found {
+		t.Error("Lookup(MISSING) should not find a value")
+	}
+	if !source.IsSensitive("API_KEY") || source.IsSensitive("PORT") {
+		t.Error("only SecureString parameters should be sensitive")
+	}
+
+	// SecureString values are cached sealed and opened on each lookup
+	for range 2 {
+		if value, found, err := source.Lookup("API_KEY"); err != nil || !found || value != "s3cr3t" {
+			t.Fatalf("Lookup(API_KEY) = %q, %v, %v", value, found, err)
+		}
+	}
+	if param := source.params["API_KEY"]; param.sealed == nil || param.value != "" {
+		t.Errorf("SecureString parameter cached in plain text: %+v", param)
+	}
+
+	if client.calls != 3 {
+		t.Errorf("expected all 3 pages to be read once, got %d calls", client.calls)
+	}
+	if aws.ToString(client.input.Path) != "/myapp/prod" || !aws.ToBool(client.input.Recursive) || !aws.ToBool(client.input.WithDecryption) {
+		t.Errorf("unexpected request: %+v", client.input)
+	}
+}
+
+func TestSourceLookupError(t *testing.T) {
+	source := New("/myapp/prod/", WithClient(&fakeClient{err: errors.New("access denied")}))
+
+	if _, _, err := source.Lookup("PORT"); err == nil {
+		t.Fatal("expected the client error to be returned")
+	}
+	if source.IsSensitive("PORT") {
+		t.Error("IsSensitive should be false when parameters cannot be loaded")
+	}
+}
+
+func TestSourceWithConfig(t *testing.T) {
+	cfg := commandkit.New()
+	cfg.Define("PORT").Int64().Default(int64(8080))
+	cfg.Define("API_KEY").String().Secret().Required()
+	cfg.UseSource(New("/myapp/prod/", WithClient(newFakeClient())))
+
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx := commandkit.NewCommandContext(nil, cfg, "", "")
+	if port := commandkit.MustGet[int64](ctx, "PORT"); port != 5432 {
+		t.Errorf("PORT = %d, want 5432", port)
+	}
+	if apiKey := cfg.GetSecret("API_KEY").String(); apiKey != "s3cr3t" {
+		t.Errorf("API_KEY = %q, want s3cr3t", apiKey)
+	}
+}
+
+func TestSourceRefresh(t *testing.T) {
+	client := newFakeClient()
+	cfg := commandkit.New()
+	cfg.Define("PORT").Int64()
+	cfg.UseSource(New("/myapp/prod/", WithClient(client)))
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	client.params[1].Value = aws.String("6543")
+	if err := cfg.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	ctx := commandkit.NewCommandContext(nil, cfg, "", "")
+	if port := commandkit.MustGet[int64](ctx, "PORT"); port != 6543 {
+		t.Errorf("PORT = %d after a reload, want the parameter read again", port)
+	}
+	if client.calls != 6 {
+		t.Errorf("expected the 3 pages to be read once per processing, got %d calls", client.calls)
+	}
+}
+
+func TestSecureStringRequiresSecret(t *testing.T) {
+	cfg := commandkit.New()
+	cfg.Define("API_KEY").String()
+	cfg.UseSource(New("/myapp/prod/", WithClient(newFakeClient())))
+
+	if err := cfg.Process([]string{"app"}); err == nil {
+		t.Fatal("a SecureString parameter should be refused by a non-secret definition")
+	}
+}
@@ -0,0 +1,226 @@
+// commandkit/struct.go
+package commandkit
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/text/language"
+)
+
+// structTypes maps the Go field types DefineStruct supports to their value types
+var structTypes = map[reflect.Type]ValueType{
+	reflect.TypeOf(""):                     TypeString,
+	reflect.TypeOf(int64(0)):               TypeInt64,
+	reflect.TypeOf(int(0)):                 TypeInt,
+	reflect.TypeOf(int32(0)):               TypeInt32,
+	reflect.TypeOf(uint(0)):                TypeUint,
+	reflect.TypeOf(uint8(0)):               TypeUint8,
+	reflect.TypeOf(uint16(0)):              TypeUint16,
+	reflect.TypeOf(uint32(0)):              TypeUint32,
+	reflect.TypeOf(uint64(0)):              TypeUint64,
+	reflect.TypeOf(float64(0)):             TypeFloat64,
+	reflect.TypeOf(float32(0)):             TypeFloat32,
+	reflect.TypeOf(false):                  TypeBool,
+	reflect.TypeOf(time.Duration(0)):       TypeDuration,
+	reflect.TypeOf(time.Time{}):            TypeTime,
+	reflect.TypeOf([]string(nil)):          TypeStringSlice,
+	reflect.TypeOf([]int64(nil)):           TypeInt64Slice,
+	reflect.TypeOf([]int(nil)):             TypeIntSlice,
+	reflect.TypeOf([]float64(nil)):         TypeFloat64Slice,
+	reflect.TypeOf([]bool(nil)):            TypeBoolSlice,
+	reflect.TypeOf([]time.Duration(nil)):   TypeDurationSlice,
+	reflect.TypeOf(map[string]string(nil)): TypeStringMap,
+	reflect.TypeOf(os.FileMode(0)):         TypeFileMode,
+	reflect.TypeOf(net.IP(nil)):            TypeIP,
+	reflect.TypeOf(uuid.UUID{}):            TypeUUID,
+	reflect.TypeOf((*regexp.Regexp)(nil)):  TypeRegexp,
+	reflect.TypeOf((*time.Location)(nil)):  TypeTimeZone,
+	reflect.TypeOf(language.Tag{}):         TypeLocale,
+	reflect.TypeOf(HostPort{}):             TypeHostPort,
+}
+
+// structField is a struct field tagged for configuration
+type structField struct {
+	index       int
+	key         string
+	envVar      string
+	flag        string
+	fileKey     string
+	defaultText string
+	hasDefault  bool
+	required    bool
+	secret      bool
+	description string
+}
+
+// DefineStruct defines a key for each field of the struct out points to that has a
+// config tag, such as
+//
+//	Port int `config:"PORT,env=PORT,flag=port,default=8080,required"`
+//
+// The type follows the field type. The tag options are env, flag, file (the file key)
+// and default, plus the required and secret flags. A default containing commas goes
+// in a separate default tag, and a description in a description tag. Without a
+// default, a non-zero field value is the default. Unmarshal fills the same struct
+// once the configuration is processed.
+func (c *Config) DefineStruct(out any) error {
+	target, fields, err := structFields(out)
+	if err != nil {
+		return err
+	}
+
+	type pending struct {
+		field     structField
+		valueType ValueType
+		value     any
+	}
+	definitions := make([]pending, 0, len(fields))
+	for _, field := range fields {
+		fieldValue := target.Field(field.index)
+		valueType, ok := structTypes[fieldValue.Type()]
+		if !ok {
+			return fmt.Errorf("field %s: unsupported type %s", target.Type().Field(field.index).Name, fieldValue.Type())
+		}
+		var defaultValue any
+		switch {
+		case field.hasDefault:
+			if defaultValue, err = parseValue(field.defaultText, valueType, ","); err != nil {
+				return fmt.Errorf("field %s: invalid default: %w", target.Type().Field(field.index).Name, err)
+			}
+		case !fieldValue.IsZero():
+			defaultValue = fieldValue.Interface()
+		}
+		definitions = append(definitions, pending{field, valueType, defaultValue})
+	}
+
+	for _, p := range definitions {
+		b := c.Define(p.field.key)
+		b.def.valueType = p.valueType
+		b.def.defaultValue = p.value
+		if p.field.envVar != "" {
+			b.Env(p.field.envVar)
+		}
+		if p.field.flag != "" {
+			b.Flag(p.field.flag)
+		}
+		if p.field.fileKey != "" {
+			b.File(p.field.fileKey)
+		}
+		if p.field.required {
+			b.Required()
+		}
+		if p.field.secret {
+			b.Secret()
+		}
+		b.Description(p.field.description)
+	}
+	return nil
+}
+
+// Unmarshal sets the fields of the struct out points to from the processed values of
+// their config tags, as defined with DefineStruct. Fields of keys without a value are
+// left unchanged, and so are secret fields, which are read with GetSecret.
+func (c *Config) Unmarshal(out any) error {
+	target, fields, err := structFields(out)
+	if err != nil {
+		return err
+	}
+	if !c.processed {
+		return fmt.Errorf("configuration must be processed before it can be unmarshaled")
+	}
+
+	for _, field := range fields {
+		layer := c.layerFor(field.key)
+		if def, defined := layer.definitions[field.key]; defined && def.secret {
+			continue
+		}
+		value, exists := layer.lookupValue(field.key)
+		if !exists || value == nil {
+			continue
+		}
+		fieldValue := target.Field(field.index)
+		fieldName := target.Type().Field(field.index).Name
+		converted := reflect.ValueOf(value)
+		if !converted.Type().AssignableTo(fieldValue.Type()) {
+			result, err := convertValue(value, fieldValue.Type())
+			if err != nil {
+				return fmt.Errorf("configuration '%s' cannot be set on field %s: %w", field.key, fieldName, err)
+			}
+			converted = reflect.ValueOf(result)
+		}
+		// convertValue returns values it cannot convert unchanged
+		if !converted.Type().AssignableTo(fieldValue.Type()) {
+			return fmt.Errorf("configuration '%s' (type %T) cannot be stored in field %s (type %s)", field.key, value, fieldName, fieldValue.Type())
+		}
+		fieldValue.Set(converted)
+	}
+	return nil
+}
+
+// structFields returns the struct out points to and its fields with a config tag
+func structFields(out any) (reflect.Value, []structField, error) {
+	ptr := reflect.ValueOf(out)
+	if ptr.Kind() != reflect.Pointer || ptr.IsNil() || ptr.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, nil, fmt.Errorf("expected a pointer to a struct, got %T", out)
+	}
+	target := ptr.Elem()
+
+	var fields []structField
+	for i := 0; i < target.NumField(); i++ {
+		sf := target.Type().Field(i)
+		tag, tagged := sf.Tag.Lookup("config")
+		if !tagged || tag == "-" {
+			continue
+		}
+		if !sf.IsExported() {
+			return reflect.Value{}, nil, fmt.Errorf("field %s: config tag on an unexported field", sf.Name)
+		}
+		field, err := parseConfigTag(tag)
+		if err != nil {
+			return reflect.Value{}, nil, fmt.Errorf("field %s: %w", sf.Name, err)
+		}
+		field.index = i
+		if text, ok := sf.Tag.Lookup("default"); ok {
+			field.defaultText, field.hasDefault = text, true
+		}
+		field.description = sf.Tag.Get("description")
+		fields = append(fields, field)
+	}
+	return target, fields, nil
+}
+
+// parseConfigTag parses "KEY,env=VAR,flag=name,file=key,default=value,required,secret"
+func parseConfigTag(tag string) (structField, error) {
+	parts := strings.Split(tag, ",")
+	field := structField{key: strings.TrimSpace(parts[0])}
+	if field.key == "" {
+		return field, fmt.Errorf("config tag %q has no key", tag)
+	}
+	for _, option := range parts[1:] {
+		name, value, _ := strings.Cut(strings.TrimSpace(option), "=")
+		switch name {
+		case "env":
+			field.envVar = value
+		case "flag":
+			field.flag = value
+		case "file":
+			field.fileKey = value
+		case "default":
+			field.defaultText, field.hasDefault = value, true
+		case "required":
+			field.required = true
+		case "secret":
+			field.secret = true
+		default:
+			return field, fmt.Errorf("unknown config tag option %q", name)
+		}
+	}
+	return field, nil
+}
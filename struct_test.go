@@ -0,0 +1,100 @@
+package commandkit
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+type structOptions struct {
+	Port     int           `config:"PORT,env=PORT,flag=port,default=8080,required" description:"HTTP port"`
+	Host     string        `config:"HOST,env=HOST"`
+	Timeout  time.Duration `config:"TIMEOUT,env=TIMEOUT"`
+	Regions  []string      `config:"REGIONS,env=REGIONS" default:"eu,us"`
+	Debug    bool          `config:"DEBUG,flag=debug"`
+	Password string        `config:"PASSWORD,env=PASSWORD,secret"`
+	Ignored  string
+}
+
+func TestDefineStruct(t *testing.T) {
+	opts := &structOptions{Timeout: 5 * time.Second}
+	cfg := New()
+	if err := cfg.DefineStruct(opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	port, _ := cfg.Definition("PORT")
+	if port.Type() != TypeInt || port.EnvVar() != "PORT" || port.Flag() != "port" || !port.Required() ||
+		port.Default() != 8080 || port.Description() != "HTTP port" {
+		t.Errorf("unexpected PORT definition: %v %s %s %v %v %q", port.Type(), port.EnvVar(), port.Flag(), port.Required(), port.Default(), port.Description())
+	}
+	if timeout, _ := cfg.Definition("TIMEOUT"); timeout.Default() != 5*time.Second {
+		t.Errorf("TIMEOUT default = %v, want the field value", timeout.Default())
+	}
+	if _, defined := cfg.Definition("Ignored"); defined {
+		t.Error("untagged field should not be defined")
+	}
+
+	cfg.WithEnviron([]string{"HOST=example.com", "PASSWORD=hunter2"})
+	if err := cfg.Process([]string{"app", "--debug=true"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := cfg.Unmarshal(opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.Port != 8080 || opts.Host != "example.com" || opts.Timeout != 5*time.Second || !opts.Debug {
+		t.Errorf("unexpected options: %+v", opts)
+	}
+	if !slices.Equal(opts.Regions, []string{"eu", "us"}) {
+		t.Errorf("Regions = %v", opts.Regions)
+	}
+	if opts.Password != "" {
+		t.Error("secret field should not be filled")
+	}
+}
+
+func TestDefineStructErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		out  any
+		want string
+	}{
+		{"not a pointer", structOptions{}, "pointer to a struct"},
+		{"unsupported type", &struct {
+			C chan int `config:"C"`
+		}{}, "unsupported type"},
+		{"unknown option", &struct {
+			A string `config:"A,envvar=A"`
+		}{}, "unknown config tag option"},
+		{"no key", &struct {
+			A string `config:",env=A"`
+		}{}, "has no key"},
+		{"bad default", &struct {
+			A int `config:"A,default=many"`
+		}{}, "invalid default"},
+	}
+	for _, tt := range tests {
+		err := New().DefineStruct(tt.out)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.name, tt.want, err)
+		}
+	}
+
+	if err := New().Unmarshal(&structOptions{}); err == nil {
+		t.Error("expected Unmarshal before Process to fail")
+	}
+
+	// A field whose type cannot hold the value is reported, not a panic
+	cfg := New()
+	cfg.Define("TIMEOUT").Duration().Default("5s")
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var mismatched struct {
+		Timeout string `config:"TIMEOUT"`
+	}
+	if err := cfg.Unmarshal(&mismatched); err == nil || !strings.Contains(err.Error(), "cannot be stored in field Timeout") {
+		t.Errorf("expected a field type error, got %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+// commandkit/subscribe.go
+package commandkit
+
+import "sync"
+
+// subscriber is a channel returned by Watch
+type subscriber interface {
+	close()
+}
+
+// subscription delivers the latest typed value of a key to a Watch channel
+type subscription[T any] struct {
+	mu     sync.Mutex
+	ch     chan T
+	closed bool
+}
+
+// Watch returns a channel receiving the value of key, converted to T, whenever a
+// reload changes it, so long-running code such as a worker pool can resize itself
+// without polling Get:
+//
+//	for size := range commandkit.Watch[int64](cfg, "MAX_CONNECTIONS") {
+//		pool.Resize(int(size))
+//	}
+//
+// The channel only holds the latest value, so a slow receiver skips intermediate ones.
+// Removed keys and values that cannot be converted to T are not sent. A Secret() key
+// sends its masked value as a string, a signal to read GetSecret again. The channel is
+// closed by Destroy.
+func Watch[T any](c *Config, key string) <-chan T {
+	sub := &subscription[T]{ch: make(chan T, 1)}
+	c.OnChange(key, func(_, value any) {
+		if value == nil {
+			return
+		}
+		if typed, err := typedValue[T](key, value); err == nil {
+			sub.send(typed)
+		}
+	})
+
+	c.mu.Lock()
+	c.subscriptions = append(c.subscriptions, sub)
+	c.mu.Unlock()
+	return sub.ch
+}
+
+// send replaces any value the receiver has not taken yet
+func (s *subscription[T]) send(value T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	select {
+	case <-s.ch:
+	default:
+	}
+	s.ch <- value
+}
+
+// close closes the channel; later changes are dropped
+func (s *subscription[T]) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.ch)
+	}
+}
+
+// closeSubscriptions closes every channel returned by Watch
+func (c *Config) closeSubscriptions() {
+	c.mu.Lock()
+	subscriptions := c.subscriptions
+	c.subscriptions = nil
+	c.mu.Unlock()
+
+	for _, sub := range subscriptions {
+		sub.close()
+	}
+}
@@ -0,0 +1,62 @@
+// commandkit/subscribe_test.go
+package commandkit
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchChannel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfigFile(t, path, "max_connections: 10\n")
+
+	cfg := New()
+	cfg.SetDefaultPriority(PriorityFileEnvFlagDefault)
+	cfg.Define("MAX_CONNECTIONS").Int64().File("max_connections")
+	if err := cfg.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sizes := Watch[int64](cfg, "MAX_CONNECTIONS")
+	hosts := Watch[[]string](cfg, "MAX_CONNECTIONS")
+
+	// Only the latest value is kept for a receiver that falls behind
+	for _, content := range []string{"max_connections: 20\n", "max_connections: 30\n"} {
+		writeConfigFile(t, path, content)
+		if err := cfg.Reload(); err != nil {
+			t.Fatalf("Reload failed: %v", err)
+		}
+	}
+	select {
+	case size := <-sizes:
+		if size != 30 {
+			t.Errorf("received %d, want 30", size)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no value received after reload")
+	}
+	select {
+	case value := <-hosts:
+		t.Errorf("unexpected value %v for a type the key cannot convert to", value)
+	default:
+	}
+
+	// An unchanged reload sends nothing
+	if err := cfg.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	select {
+	case size := <-sizes:
+		t.Errorf("unexpected value %d for an unchanged key", size)
+	default:
+	}
+
+	cfg.Destroy()
+	if _, open := <-sizes; open {
+		t.Error("expected Destroy to close the channel")
+	}
+}
@@ -0,0 +1,106 @@
+// commandkit/suggestions.go
+package commandkit
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"strings"
+)
+
+// maxSuggestions caps the "did you mean" list
+const maxSuggestions = 3
+
+// suggestionDistance is the largest edit distance still worth suggesting
+const suggestionDistance = 3
+
+// SuggestionHistory records how often each command runs in path (a JSON object of
+// counts) and uses those counts to rank "did you mean" suggestions, so commands used
+// more often come first among equally close candidates. Recording is best effort:
+// a history file that cannot be read or written never makes a command fail.
+func (c *Config) SuggestionHistory(path string) *Config {
+	c.usageHistory = path
+	return c
+}
+
+// SuggestCommands returns up to three command names close to input, best first.
+// Commands that start with input come first, then shorter edit distances, then
+// commands used more often according to the suggestion history, then by name.
+func (c *Config) SuggestCommands(input string) []string {
+	type candidate struct {
+		name     string
+		prefix   bool
+		distance int
+		uses     int
+	}
+
+	usage := c.commandUsage()
+	var candidates []candidate
+	for name := range c.commands {
+		if name == "" {
+			continue
+		}
+		prefix := input != "" && strings.HasPrefix(name, input)
+		distance := levenshteinDistance(input, name)
+		if !prefix && distance > suggestionDistance {
+			continue
+		}
+		candidates = append(candidates, candidate{name, prefix, distance, usage[name]})
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		switch {
+		case a.prefix != b.prefix:
+			return a.prefix
+		case a.distance != b.distance:
+			return a.distance < b.distance
+		case a.uses != b.uses:
+			return a.uses > b.uses
+		default:
+			return a.name < b.name
+		}
+	})
+
+	suggestions := make([]string, 0, maxSuggestions)
+	for _, candidate := range candidates {
+		if len(suggestions) == maxSuggestions {
+			break
+		}
+		suggestions = append(suggestions, candidate.name)
+	}
+	return suggestions
+}
+
+// commandUsage reads the suggestion history, nil when there is none
+func (c *Config) commandUsage() map[string]int {
+	if c.usageHistory == "" {
+		return nil
+	}
+	data, err := os.ReadFile(c.usageHistory)
+	if err != nil {
+		return nil
+	}
+	var usage map[string]int
+	if json.Unmarshal(data, &usage) != nil {
+		return nil
+	}
+	return usage
+}
+
+// recordCommandUse counts a run of command in the suggestion history
+func (c *Config) recordCommandUse(command string) {
+	if c.usageHistory == "" || command == "" {
+		return
+	}
+	usage := c.commandUsage()
+	if usage == nil {
+		usage = make(map[string]int)
+	}
+	usage[command]++
+	data, err := json.Marshal(usage)
+	if err != nil {
+		return
+	}
+	_ = os.WriteFile(c.usageHistory, data, 0o600)
+}
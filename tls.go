@@ -0,0 +1,166 @@
+// commandkit/tls.go
+package commandkit
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Suffixes of the keys DefineTLS defines
+const (
+	tlsCertSuffix       = "_CERT"
+	tlsKeySuffix        = "_KEY"
+	tlsCASuffix         = "_CA"
+	tlsMinVersionSuffix = "_MIN_VERSION"
+)
+
+// tlsVersions maps the accepted minimum versions to their crypto/tls constants
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// TLSBuilder customizes the keys of a TLS bundle defined with DefineTLS
+type TLSBuilder struct {
+	cert       *DefinitionBuilder
+	key        *DefinitionBuilder
+	ca         *DefinitionBuilder
+	minVersion *DefinitionBuilder
+}
+
+// DefineTLS defines a TLS bundle as four keys named after key, each with an environment
+// variable of the same name and a matching flag: for "SERVER_TLS", SERVER_TLS_CERT
+// (--server-tls-cert) and SERVER_TLS_KEY hold PEM certificate and key files,
+// SERVER_TLS_CA an optional PEM CA bundle, and SERVER_TLS_MIN_VERSION the minimum
+// version, "1.2" by default. Processing fails unless the files load and the certificate
+// matches the key. GetTLSConfig returns the bundle as a *tls.Config.
+func (c *Config) DefineTLS(key string) *TLSBuilder {
+	define := func(suffix, description string) *DefinitionBuilder {
+		flag := strings.ToLower(strings.ReplaceAll(key+suffix, "_", "-"))
+		return c.Define(key + suffix).Env(key + suffix).Flag(flag).Description(description)
+	}
+	b := &TLSBuilder{
+		cert:       define(tlsCertSuffix, "TLS certificate file (PEM)").Path(),
+		key:        define(tlsKeySuffix, "TLS private key file (PEM)").Path(),
+		ca:         define(tlsCASuffix, "TLS CA certificates file (PEM)").Path(),
+		minVersion: define(tlsMinVersionSuffix, "Minimum TLS version").String().Default("1.2").OneOf("1.0", "1.1", "1.2", "1.3"),
+	}
+	b.cert.def.tlsBundle = key
+	return b
+}
+
+// Required makes the certificate and key required
+func (b *TLSBuilder) Required() *TLSBuilder {
+	b.cert.Required()
+	b.key.Required()
+	return b
+}
+
+// Cert returns the builder of the certificate key, e.g. to set a default path
+func (b *TLSBuilder) Cert() *DefinitionBuilder {
+	return b.cert
+}
+
+// Key returns the builder of the private key key
+func (b *TLSBuilder) Key() *DefinitionBuilder {
+	return b.key
+}
+
+// CA returns the builder of the CA certificates key
+func (b *TLSBuilder) CA() *DefinitionBuilder {
+	return b.ca
+}
+
+// MinVersion returns the builder of the minimum version key
+func (b *TLSBuilder) MinVersion() *DefinitionBuilder {
+	return b.minVersion
+}
+
+// GetTLSConfig returns the TLS bundle defined with DefineTLS as a new *tls.Config with
+// the certificate, the minimum version and, when a CA file is set, the CA pool as both
+// RootCAs and ClientCAs; set ClientAuth to verify clients. It returns nil when none
+// of the files is set, so servers can fall back to plain connections.
+func GetTLSConfig(ctx *CommandContext, key string) (*tls.Config, error) {
+	c := getConfig(ctx).layerFor(key + tlsCertSuffix)
+	if def, defined := c.definitions[key+tlsCertSuffix]; !defined || def.tlsBundle != key {
+		c = ctx.GlobalConfig
+	}
+	if def, defined := c.definitions[key+tlsCertSuffix]; !defined || def.tlsBundle != key {
+		return nil, fmt.Errorf("configuration '%s' is not defined with DefineTLS", key)
+	}
+	return c.tlsConfig(key)
+}
+
+// tlsConfig loads the files of the TLS bundle key, nil when none is set
+func (c *Config) tlsConfig(key string) (*tls.Config, error) {
+	text := func(suffix string) string {
+		value, _ := c.lookupValue(key + suffix)
+		s, _ := value.(string)
+		return s
+	}
+	certFile, keyFile, caFile := text(tlsCertSuffix), text(tlsKeySuffix), text(tlsCASuffix)
+	if certFile == "" && keyFile == "" && caFile == "" {
+		return nil, nil
+	}
+
+	// Path values are expanded like Get does, e.g. "~/certs/tls.crt"
+	for _, file := range []*string{&certFile, &keyFile, &caFile} {
+		if *file == "" {
+			continue
+		}
+		expanded, err := expandPath(*file)
+		if err != nil {
+			return nil, err
+		}
+		*file = expanded
+	}
+
+	config := &tls.Config{MinVersion: tlsVersions[text(tlsMinVersionSuffix)]}
+	if certFile != "" || keyFile != "" {
+		if certFile == "" || keyFile == "" {
+			return nil, fmt.Errorf("%s and %s must be set together", key+tlsCertSuffix, key+tlsKeySuffix)
+		}
+		pair, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TLS certificate or key: %w", err)
+		}
+		config.Certificates = []tls.Certificate{pair}
+	}
+	if caFile != "" {
+		data, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read TLS CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificates found in TLS CA file %s", caFile)
+		}
+		config.RootCAs, config.ClientCAs = pool, pool
+	}
+	return config, nil
+}
+
+// checkTLSBundles loads every TLS bundle once its keys are resolved, so broken or
+// mismatched files fail at startup
+func (c *Config) checkTLSBundles(sources map[string]SourceType) []ConfigError {
+	var errs []ConfigError
+	for key, def := range c.definitions {
+		if def.tlsBundle == "" {
+			continue
+		}
+		if _, err := c.tlsConfig(def.tlsBundle); err != nil {
+			errs = append(errs, ConfigError{
+				Key:              def.tlsBundle,
+				Source:           sources[key].String(),
+				Display:          buildErrorDisplay(def),
+				ErrorDescription: err.Error(),
+			})
+		}
+	}
+	return errs
+}
@@ -0,0 +1,137 @@
+package commandkit
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeTestCertificate writes a self-signed certificate and its key as PEM files in dir
+func writeTestCertificate(t *testing.T, dir, name string) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile = filepath.Join(dir, name+".crt")
+	keyFile = filepath.Join(dir, name+".key")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err := os.WriteFile(certFile, certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, keyPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestDefineTLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCertificate(t, dir, "server")
+	_, otherKeyFile := writeTestCertificate(t, dir, "other")
+
+	cfg := New()
+	cfg.DefineTLS("SERVER_TLS")
+	err := cfg.Process([]string{"app",
+		"--server-tls-cert=" + certFile, "--server-tls-key=" + keyFile,
+		"--server-tls-ca=" + certFile, "--server-tls-min-version=1.3"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := NewCommandContext([]string{}, cfg, "", "")
+	tlsConfig, err := GetTLSConfig(ctx, "SERVER_TLS")
+	if err != nil {
+		t.Fatalf("GetTLSConfig: %v", err)
+	}
+	if len(tlsConfig.Certificates) != 1 || tlsConfig.MinVersion != tls.VersionTLS13 {
+		t.Errorf("certificates = %d, min version = %x", len(tlsConfig.Certificates), tlsConfig.MinVersion)
+	}
+	if tlsConfig.RootCAs == nil || tlsConfig.ClientCAs == nil {
+		t.Error("CA pool not set")
+	}
+	if _, err := GetTLSConfig(ctx, "CLIENT_TLS"); err == nil {
+		t.Error("expected an error for a bundle not defined with DefineTLS")
+	}
+
+	// Nothing set: no TLS, and the minimum version defaults to 1.2
+	empty := New()
+	empty.DefineTLS("SERVER_TLS")
+	if err := empty.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	emptyCtx := NewCommandContext([]string{}, empty, "", "")
+	if tlsConfig, err := GetTLSConfig(emptyCtx, "SERVER_TLS"); tlsConfig != nil || err != nil {
+		t.Errorf("GetTLSConfig = %v, %v; want nil, nil", tlsConfig, err)
+	}
+	if version := MustGet[string](emptyCtx, "SERVER_TLS_MIN_VERSION"); version != "1.2" {
+		t.Errorf("SERVER_TLS_MIN_VERSION = %q, want 1.2", version)
+	}
+
+	// A CA alone configures a client verifying its servers
+	caOnly := New()
+	caOnly.DefineTLS("CLIENT_TLS")
+	t.Setenv("CLIENT_TLS_CA", certFile)
+	if err := caOnly.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tlsConfig, err = GetTLSConfig(NewCommandContext([]string{}, caOnly, "", ""), "CLIENT_TLS")
+	if err != nil || tlsConfig.RootCAs == nil || len(tlsConfig.Certificates) != 0 {
+		t.Errorf("GetTLSConfig = %+v, %v; want only a CA pool", tlsConfig, err)
+	}
+
+	invalid := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"mismatched key", []string{"--server-tls-cert=" + certFile, "--server-tls-key=" + otherKeyFile}, "invalid TLS certificate or key"},
+		{"certificate without key", []string{"--server-tls-cert=" + certFile}, "must be set together"},
+		{"key as CA", []string{"--server-tls-ca=" + keyFile}, "no certificates found"},
+		{"unknown version", []string{"--server-tls-min-version=1.4"}, "SERVER_TLS_MIN_VERSION"},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := New()
+			cfg.DefineTLS("SERVER_TLS")
+			err := cfg.Process(append([]string{"app"}, tt.args...))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Process error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+
+	required := New()
+	required.DefineTLS("SERVER_TLS").Required()
+	if err := required.Process([]string{"app"}); err == nil {
+		t.Error("expected an error for a required bundle left unset")
+	}
+}
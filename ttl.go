@@ -0,0 +1,45 @@
+// commandkit/ttl.go
+package commandkit
+
+import (
+	"sort"
+	"time"
+)
+
+// Stale returns the keys whose TTL has passed since their value was last loaded, in
+// sorted order. Their values stay available until a reload replaces them.
+func (c *Config) Stale() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if !c.processed {
+		return nil
+	}
+
+	age := time.Since(c.loadedAt)
+	var keys []string
+	for key, def := range c.definitions {
+		if def.ttl > 0 && age >= def.ttl {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// untilStale returns how long until the first value with a TTL goes stale (negative
+// when one already is), and false when no definition has a TTL
+func (c *Config) untilStale() (time.Duration, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var shortest time.Duration
+	for _, def := range c.definitions {
+		if def.ttl > 0 && (shortest == 0 || def.ttl < shortest) {
+			shortest = def.ttl
+		}
+	}
+	if shortest == 0 {
+		return 0, false
+	}
+	return shortest - time.Since(c.loadedAt), true
+}
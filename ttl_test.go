@@ -0,0 +1,64 @@
+// commandkit/ttl_test.go
+package commandkit
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStaleKeys(t *testing.T) {
+	source := &lockedSource{values: map[string]string{"RATE": "1.5"}}
+
+	cfg := New()
+	cfg.UseSource(source)
+	cfg.Define("RATE").String().TTL(40 * time.Millisecond)
+	cfg.Define("REGION").String().Default("eu").TTL(time.Hour)
+	cfg.Define("NAME").String().Default("api")
+	if stale := cfg.Stale(); stale != nil {
+		t.Errorf("Stale() = %v before processing", stale)
+	}
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stale := cfg.Stale(); len(stale) != 0 {
+		t.Errorf("Stale() = %v right after processing", stale)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if stale := cfg.Stale(); !reflect.DeepEqual(stale, []string{"RATE"}) {
+		t.Errorf("Stale() = %v, want [RATE]", stale)
+	}
+	if rate, _ := cfg.lookupValue("RATE"); rate != "1.5" {
+		t.Errorf("a stale value must still be returned, got %v", rate)
+	}
+
+	// Once fresh again, the refresher reloads when RATE goes stale rather than after
+	// the full interval
+	source.set("RATE", "2.5")
+	if err := cfg.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	refreshed := make(chan error, 1)
+	if err := cfg.StartRefresh(time.Hour, 0, func(err error) {
+		select {
+		case refreshed <- err:
+		default:
+		}
+	}); err != nil {
+		t.Fatalf("StartRefresh failed: %v", err)
+	}
+	defer cfg.StopRefresh()
+
+	select {
+	case err := <-refreshed:
+		if err != nil {
+			t.Fatalf("refresh failed: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a refresh once RATE went stale")
+	}
+	if stale := cfg.Stale(); len(stale) != 0 {
+		t.Errorf("Stale() = %v after a refresh", stale)
+	}
+}
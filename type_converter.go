@@ -3,14 +3,19 @@ package commandkit
 
 import (
 	"fmt"
+	"maps"
+	"math"
 	"net"
 	"os"
 	"reflect"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"golang.org/x/text/language"
 )
 
 // TypeConverter handles type-to-string conversions consistently across all value sources
@@ -27,7 +32,7 @@ func (tc *TypeConverter) ConvertToString(value any, delimiter string) (string, e
 	switch v := value.(type) {
 	case string:
 		return v, nil
-	case bool, int, int64, float64:
+	case bool, int, int32, int64, float64:
 		return fmt.Sprintf("%v", v), nil
 	case uint, uint8, uint16, uint32:
 		return fmt.Sprintf("%v", v), nil
@@ -38,8 +43,8 @@ func (tc *TypeConverter) ConvertToString(value any, delimiter string) (string, e
 	case time.Time:
 		return v.Format(time.RFC3339), nil
 	case []string:
-		// Handle string slices - join with delimiter
-		return strings.Join(v, delimiter), nil
+		// Handle string slices - join with delimiter, quoting items containing it
+		return joinList(v, delimiter), nil
 	case []int64:
 		// Handle int64 slices - convert to strings and join
 		strs := make([]string, len(v))
@@ -68,13 +73,37 @@ func (tc *TypeConverter) ConvertToString(value any, delimiter string) (string, e
 			strs[i] = fmt.Sprintf("%t", item)
 		}
 		return strings.Join(strs, delimiter), nil
+	case time.Duration:
+		return FormatDuration(v), nil
+	case []time.Duration:
+		// Handle duration slices - convert to strings and join
+		strs := make([]string, len(v))
+		for i, item := range v {
+			strs[i] = FormatDuration(item)
+		}
+		return strings.Join(strs, delimiter), nil
 	case []any:
 		// Handle arrays from files - convert to strings and join
 		strs := make([]string, len(v))
 		for i, item := range v {
 			strs[i] = fmt.Sprintf("%v", item)
 		}
-		return strings.Join(strs, delimiter), nil
+		return joinList(strs, delimiter), nil
+	case *regexp.Regexp:
+		return v.String(), nil
+	case *time.Location:
+		return v.String(), nil
+	case language.Tag:
+		return v.String(), nil
+	case HostPort:
+		return v.String(), nil
+	case map[string]string:
+		// Handle string maps - sorted key=value entries joined with delimiter
+		entries := make([]string, 0, len(v))
+		for _, key := range slices.Sorted(maps.Keys(v)) {
+			entries = append(entries, key+"="+v[key])
+		}
+		return joinList(entries, delimiter), nil
 	case os.FileMode:
 		// Display FileMode in octal format
 		return fmt.Sprintf("0%o", v), nil
@@ -84,6 +113,9 @@ func (tc *TypeConverter) ConvertToString(value any, delimiter string) (string, e
 	case uuid.UUID:
 		// Display UUID in standard format
 		return v.String(), nil
+	case fmt.Stringer:
+		// Custom types parsed by a ParseFunc
+		return v.String(), nil
 	default:
 		return "", fmt.Errorf("unsupported value type: %T", v)
 	}
@@ -113,7 +145,9 @@ func (tc *TypeConverter) IsSupportedType(value any) bool {
 		return true
 	case []string, []int64, []int, []any:
 		return true
-	case []float64, []bool:
+	case []float64, []bool, []time.Duration:
+		return true
+	case map[string]string, *regexp.Regexp, *time.Location, language.Tag, HostPort:
 		return true
 	case os.FileMode:
 		return true
@@ -146,6 +180,10 @@ func convertDefaultValue(value any, targetType ValueType) (any, error) {
 		if _, ok := value.(int); ok {
 			return value, nil
 		}
+	case TypeInt32:
+		if _, ok := value.(int32); ok {
+			return value, nil
+		}
 	case TypeFloat64:
 		if _, ok := value.(float64); ok {
 			return value, nil
@@ -158,6 +196,10 @@ func convertDefaultValue(value any, targetType ValueType) (any, error) {
 		if _, ok := value.(time.Duration); ok {
 			return value, nil
 		}
+	case TypeDurationSlice:
+		if _, ok := value.([]time.Duration); ok {
+			return value, nil
+		}
 		// Add more direct type checks as needed
 	}
 
@@ -198,7 +240,7 @@ func convertDefaultValue(value any, targetType ValueType) (any, error) {
 			}
 			return int64(v), nil
 		case string:
-			parsed, err := strconv.ParseInt(v, 10, 64)
+			parsed, err := parseInt(v, 64)
 			if err != nil {
 				return nil, fmt.Errorf("cannot convert string '%s' to int64: %w", v, err)
 			}
@@ -247,7 +289,7 @@ func convertDefaultValue(value any, targetType ValueType) (any, error) {
 			}
 			return int(v), nil
 		case string:
-			parsed, err := strconv.ParseInt(v, 10, 64)
+			parsed, err := parseInt(v, 64)
 			if err != nil {
 				return nil, fmt.Errorf("cannot convert string '%s' to int: %w", v, err)
 			}
@@ -312,7 +354,7 @@ func convertDefaultValue(value any, targetType ValueType) (any, error) {
 	case TypeDuration:
 		switch v := value.(type) {
 		case string:
-			parsed, err := time.ParseDuration(v)
+			parsed, err := ParseDuration(v)
 			if err != nil {
 				return nil, fmt.Errorf("cannot convert string '%s' to duration: %w", v, err)
 			}
@@ -369,7 +411,7 @@ func convertDefaultValue(value any, targetType ValueType) (any, error) {
 		case []string:
 			result := make([]int64, len(v))
 			for i, item := range v {
-				parsed, err := strconv.ParseInt(item, 10, 64)
+				parsed, err := parseInt(item, 64)
 				if err != nil {
 					return nil, fmt.Errorf("cannot convert string '%s' to int64 in slice: %w", item, err)
 				}
@@ -396,7 +438,7 @@ func convertDefaultValue(value any, targetType ValueType) (any, error) {
 		case []string:
 			result := make([]int, len(v))
 			for i, item := range v {
-				parsed, err := strconv.ParseInt(item, 10, 64)
+				parsed, err := parseInt(item, 64)
 				if err != nil {
 					return nil, fmt.Errorf("cannot convert string '%s' to int in slice: %w", item, err)
 				}
@@ -410,6 +452,17 @@ func convertDefaultValue(value any, targetType ValueType) (any, error) {
 			return nil, fmt.Errorf("cannot convert %T to []int", value)
 		}
 
+	case TypeInt32:
+		converted, err := convertDefaultValue(value, TypeInt64)
+		if err != nil {
+			return nil, fmt.Errorf("cannot convert %T to int32", value)
+		}
+		v := converted.(int64)
+		if v > math.MaxInt32 || v < math.MinInt32 {
+			return nil, fmt.Errorf("value %d overflows int32", v)
+		}
+		return int32(v), nil
+
 	// Handle unsigned integer types
 	case TypeUint:
 		switch v := value.(type) {
@@ -426,7 +479,7 @@ func convertDefaultValue(value any, targetType ValueType) (any, error) {
 			}
 			return uint(v), nil
 		case string:
-			parsed, err := strconv.ParseUint(v, 10, 64)
+			parsed, err := parseUint(v, 64)
 			if err != nil {
 				return nil, fmt.Errorf("cannot convert string '%s' to uint: %w", v, err)
 			}
@@ -450,7 +503,7 @@ func convertDefaultValue(value any, targetType ValueType) (any, error) {
 			}
 			return uint8(v), nil
 		case string:
-			parsed, err := strconv.ParseUint(v, 10, 8)
+			parsed, err := parseUint(v, 8)
 			if err != nil {
 				return nil, fmt.Errorf("cannot convert string '%s' to uint8: %w", v, err)
 			}
@@ -474,7 +527,7 @@ func convertDefaultValue(value any, targetType ValueType) (any, error) {
 			}
 			return uint16(v), nil
 		case string:
-			parsed, err := strconv.ParseUint(v, 10, 16)
+			parsed, err := parseUint(v, 16)
 			if err != nil {
 				return nil, fmt.Errorf("cannot convert string '%s' to uint16: %w", v, err)
 			}
@@ -498,7 +551,7 @@ func convertDefaultValue(value any, targetType ValueType) (any, error) {
 			}
 			return uint32(v), nil
 		case string:
-			parsed, err := strconv.ParseUint(v, 10, 32)
+			parsed, err := parseUint(v, 32)
 			if err != nil {
 				return nil, fmt.Errorf("cannot convert string '%s' to uint32: %w", v, err)
 			}
@@ -522,7 +575,7 @@ func convertDefaultValue(value any, targetType ValueType) (any, error) {
 			}
 			return uint64(v), nil
 		case string:
-			parsed, err := strconv.ParseUint(v, 10, 64)
+			parsed, err := parseUint(v, 64)
 			if err != nil {
 				return nil, fmt.Errorf("cannot convert string '%s' to uint64: %w", v, err)
 			}
@@ -556,6 +609,104 @@ func convertDefaultValue(value any, targetType ValueType) (any, error) {
 			return nil, fmt.Errorf("cannot convert %T to float32", value)
 		}
 
+	case TypeDurationSlice:
+		switch v := value.(type) {
+		case string:
+			return parseValue(v, TypeDurationSlice, ",")
+		case []string:
+			return parseValue(joinList(v, ","), TypeDurationSlice, ",")
+		default:
+			return nil, fmt.Errorf("cannot convert %T to []time.Duration", value)
+		}
+
+	case TypeBoolSlice:
+		switch v := value.(type) {
+		case []bool:
+			return v, nil
+		case string:
+			return parseValue(v, TypeBoolSlice, ",")
+		case []string:
+			return parseValue(joinList(v, ","), TypeBoolSlice, ",")
+		default:
+			return nil, fmt.Errorf("cannot convert %T to []bool", value)
+		}
+
+	case TypeCustom:
+		// String defaults are parsed by the definition's ParseFunc beforehand
+		return value, nil
+
+	case TypeTimeZone:
+		switch v := value.(type) {
+		case *time.Location:
+			return v, nil
+		case string:
+			return parseValue(v, TypeTimeZone, "")
+		default:
+			return nil, fmt.Errorf("cannot convert %T to time zone", value)
+		}
+
+	case TypeHostPort:
+		switch v := value.(type) {
+		case HostPort:
+			return v, nil
+		case string:
+			return parseValue(v, TypeHostPort, "")
+		default:
+			return nil, fmt.Errorf("cannot convert %T to host:port", value)
+		}
+
+	case TypeLocale:
+		switch v := value.(type) {
+		case language.Tag:
+			return v, nil
+		case string:
+			return parseValue(v, TypeLocale, "")
+		default:
+			return nil, fmt.Errorf("cannot convert %T to locale", value)
+		}
+
+	case TypeRegexp:
+		switch v := value.(type) {
+		case *regexp.Regexp:
+			return v, nil
+		case string:
+			return parseValue(v, TypeRegexp, "")
+		default:
+			return nil, fmt.Errorf("cannot convert %T to regexp", value)
+		}
+
+	case TypePort:
+		converted, err := convertDefaultValue(value, TypeInt)
+		if err != nil {
+			return nil, err
+		}
+		if port := converted.(int); port < 1 || port > 65535 {
+			return nil, fmt.Errorf("invalid port: %d (expected 1-65535)", port)
+		}
+		return converted, nil
+
+	case TypeEmail:
+		if text, ok := value.(string); ok {
+			return parseValue(text, TypeEmail, "")
+		}
+		return nil, fmt.Errorf("cannot convert %T to email", value)
+
+	case TypeURLSlice:
+		switch v := value.(type) {
+		case string:
+			return parseValue(v, TypeURLSlice, ",")
+		case []string:
+			return parseValue(joinList(v, ","), TypeURLSlice, ",")
+		default:
+			return nil, fmt.Errorf("cannot convert %T to []string", value)
+		}
+
+	case TypeStringMap:
+		if text, ok := value.(string); ok {
+			return parseStringMap(text, ",")
+		}
+		return stringMapFromAny(value)
+
 	// Add more type conversions as needed for other ValueType constants
 	default:
 		return value, nil // No conversion needed for unsupported types
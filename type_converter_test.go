@@ -88,9 +88,15 @@ func TestTypeConverterConsistency(t *testing.T) {
 			expected:  "x,1,true",
 			delimiter: ",",
 		},
+		{
+			name:      "string map",
+			value:     map[string]string{"b": "2", "a": "1"},
+			expected:  "a=1,b=2",
+			delimiter: ",",
+		},
 		{
 			name:      "unsupported type",
-			value:     map[string]string{"key": "value"},
+			value:     map[string]int{"key": 1},
 			expected:  "",
 			delimiter: ",",
 			shouldErr: true,
@@ -137,8 +143,8 @@ func TestTypeConverterDisplayString(t *testing.T) {
 		},
 		{
 			name:      "unsupported type",
-			value:     map[string]string{"key": "value"},
-			expected:  "map[key:value]",
+			value:     map[string]int{"key": 1},
+			expected:  "map[key:1]",
 			delimiter: ",",
 		},
 	}
@@ -176,7 +182,7 @@ func TestTypeConverterIsSupportedType(t *testing.T) {
 	}
 
 	unsupportedTypes := []any{
-		map[string]string{"key": "value"},
+		map[string]int{"key": 1},
 		complex(1, 2),
 		chan int(nil),
 		func() {},
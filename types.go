@@ -3,16 +3,21 @@ package commandkit
 
 import (
 	"fmt"
+	"math"
+	"math/big"
 	"net"
+	"net/mail"
 	"net/url"
 	"os"
 	"os/user"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"golang.org/x/text/language"
 )
 
 // ValueType represents the expected type of a configuration value
@@ -42,6 +47,18 @@ const (
 	TypeIP
 	TypeUUID
 	TypePath
+	TypeComplex // Structured file value (tables and lists), read with UnmarshalKey
+	TypeInt32
+	TypeStringMap
+	TypeDurationSlice
+	TypeURLSlice
+	TypeEmail
+	TypePort
+	TypeRegexp
+	TypeTimeZone
+	TypeLocale
+	TypeHostPort
+	TypeCustom // Parsed by a ParseFunc, see ParseWith and RegisterType
 )
 
 func (t ValueType) String() string {
@@ -92,6 +109,30 @@ func (t ValueType) String() string {
 		return "uuid"
 	case TypePath:
 		return "path"
+	case TypeComplex:
+		return "complex"
+	case TypeInt32:
+		return "int32"
+	case TypeStringMap:
+		return "map[string]string"
+	case TypeDurationSlice:
+		return "[]duration"
+	case TypeURLSlice:
+		return "[]url"
+	case TypeEmail:
+		return "email"
+	case TypePort:
+		return "port"
+	case TypeRegexp:
+		return "regexp"
+	case TypeTimeZone:
+		return "timezone"
+	case TypeLocale:
+		return "locale"
+	case TypeHostPort:
+		return "host:port"
+	case TypeCustom:
+		return "custom"
 	default:
 		return "unknown"
 	}
@@ -105,6 +146,7 @@ const (
 	SourceFlag
 	SourceEnv
 	SourceFile
+	SourceRemote
 )
 
 func (s SourceType) String() string {
@@ -117,6 +159,8 @@ func (s SourceType) String() string {
 		return "environment" // Changed from "env" to "environment" to match test expectations
 	case SourceFile:
 		return "file"
+	case SourceRemote:
+		return "remote"
 	default:
 		return "unknown"
 	}
@@ -210,19 +254,26 @@ func parseValue(raw string, valueType ValueType, delimiter string) (any, error)
 		return raw, nil
 
 	case TypeInt64:
-		v, err := strconv.ParseInt(raw, 10, 64)
+		v, err := parseInt(raw, 64)
 		if err != nil {
 			return nil, fmt.Errorf("invalid int64: %s", raw)
 		}
 		return v, nil
 
 	case TypeInt:
-		v, err := strconv.ParseInt(raw, 10, 64) // Parse as int64, store as int
+		v, err := parseInt(raw, 64) // Parse as int64, store as int
 		if err != nil {
 			return nil, fmt.Errorf("invalid int: %s", raw)
 		}
 		return int(v), nil // Convert to int
 
+	case TypeInt32:
+		v, err := parseInt(raw, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid int32: %s", raw)
+		}
+		return int32(v), nil
+
 	case TypeFloat64:
 		v, err := strconv.ParseFloat(raw, 64)
 		if err != nil {
@@ -231,46 +282,87 @@ func parseValue(raw string, valueType ValueType, delimiter string) (any, error)
 		return v, nil
 
 	case TypeBool:
-		v, err := strconv.ParseBool(raw)
+		v, err := parseBool(raw)
 		if err != nil {
-			return nil, fmt.Errorf("invalid bool: %s (use true/false, 1/0, yes/no)", raw)
+			return nil, fmt.Errorf("invalid bool: %s (use true/false, 1/0, yes/no, on/off, enabled/disabled)", raw)
 		}
 		return v, nil
 
 	case TypeDuration:
-		// Handle day format (e.g., "7d", "1d")
-		if strings.HasSuffix(raw, "d") {
-			daysStr := strings.TrimSuffix(raw, "d")
-			if days, err := strconv.ParseFloat(daysStr, 64); err == nil {
-				hours := days * 24
-				v, err := time.ParseDuration(fmt.Sprintf("%.0fh", hours))
-				if err != nil {
-					return nil, fmt.Errorf("invalid duration: %s", raw)
-				}
-				return v, nil
-			}
-		}
-		v, err := time.ParseDuration(raw)
+		v, err := parseDuration(raw)
 		if err != nil {
-			return nil, fmt.Errorf("invalid duration: %s (use format like 15m, 1h, 7d)", raw)
+			return nil, err
 		}
 		return v, nil
 
 	case TypeURL:
-		v, err := url.Parse(raw)
+		if err := checkURL(raw); err != nil {
+			return nil, err
+		}
+		return raw, nil // Store as string, validated
+
+	case TypePort:
+		v, err := parseInt(raw, 64)
+		if err != nil || v < 1 || v > 65535 {
+			return nil, fmt.Errorf("invalid port: %s (expected 1-65535)", raw)
+		}
+		return int(v), nil
+
+	case TypeTimeZone:
+		// IANA names only: "Local" would depend on the machine
+		loc, err := time.LoadLocation(raw)
+		if err != nil || raw == "Local" {
+			return nil, fmt.Errorf("invalid time zone: %s (use an IANA name such as Europe/Madrid)", raw)
+		}
+		return loc, nil
+
+	case TypeHostPort:
+		hp, err := parseHostPort(raw)
 		if err != nil {
-			return nil, fmt.Errorf("invalid URL: %s", raw)
+			return nil, err
 		}
-		if v.Scheme == "" || v.Host == "" {
-			return nil, fmt.Errorf("invalid URL (missing scheme or host): %s", raw)
+		return hp, nil
+
+	case TypeLocale:
+		tag, err := language.Parse(raw)
+		if err != nil || tag == language.Und {
+			return nil, fmt.Errorf("invalid locale: %s (expected a BCP 47 tag such as en-US)", raw)
 		}
-		return raw, nil // Store as string, validated
+		return tag, nil // Store as language.Tag, in canonical form
+
+	case TypeRegexp:
+		re, err := regexp.Compile(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regexp: %w", err)
+		}
+		return re, nil
+
+	case TypeEmail:
+		if _, err := mail.ParseAddress(raw); err != nil {
+			return nil, fmt.Errorf("invalid email address: %s", raw)
+		}
+		return strings.TrimSpace(raw), nil // Store as string, validated
+
+	case TypeURLSlice:
+		parts := splitList(raw, delimiter)
+		result := make([]string, 0, len(parts))
+		for _, p := range parts {
+			trimmed := strings.TrimSpace(p)
+			if trimmed == "" {
+				continue
+			}
+			if err := checkURL(trimmed); err != nil {
+				return nil, err
+			}
+			result = append(result, trimmed)
+		}
+		return result, nil
 
 	case TypeStringSlice:
 		if raw == "" {
 			return []string{}, nil
 		}
-		parts := strings.Split(raw, delimiter)
+		parts := splitList(raw, delimiter)
 		result := make([]string, 0, len(parts))
 		for _, p := range parts {
 			trimmed := strings.TrimSpace(p)
@@ -284,14 +376,14 @@ func parseValue(raw string, valueType ValueType, delimiter string) (any, error)
 		if raw == "" {
 			return []int64{}, nil
 		}
-		parts := strings.Split(raw, delimiter)
+		parts := splitList(raw, delimiter)
 		result := make([]int64, 0, len(parts))
 		for _, p := range parts {
 			trimmed := strings.TrimSpace(p)
 			if trimmed == "" {
 				continue
 			}
-			v, err := strconv.ParseInt(trimmed, 10, 64)
+			v, err := parseInt(trimmed, 64)
 			if err != nil {
 				return nil, fmt.Errorf("invalid int64 in array: %s", trimmed)
 			}
@@ -303,14 +395,14 @@ func parseValue(raw string, valueType ValueType, delimiter string) (any, error)
 		if raw == "" {
 			return []int{}, nil
 		}
-		parts := strings.Split(raw, delimiter)
+		parts := splitList(raw, delimiter)
 		result := make([]int, 0, len(parts))
 		for _, p := range parts {
 			trimmed := strings.TrimSpace(p)
 			if trimmed == "" {
 				continue
 			}
-			v, err := strconv.ParseInt(trimmed, 10, 64)
+			v, err := parseInt(trimmed, 64)
 			if err != nil {
 				return nil, fmt.Errorf("invalid int in array: %s", trimmed)
 			}
@@ -320,35 +412,35 @@ func parseValue(raw string, valueType ValueType, delimiter string) (any, error)
 
 	// Unsigned integer types
 	case TypeUint:
-		v, err := strconv.ParseUint(raw, 10, 64)
+		v, err := parseUint(raw, 64)
 		if err != nil {
 			return nil, fmt.Errorf("invalid uint: %s", raw)
 		}
 		return uint(v), nil
 
 	case TypeUint8:
-		v, err := strconv.ParseUint(raw, 10, 8)
+		v, err := parseUint(raw, 8)
 		if err != nil {
 			return nil, fmt.Errorf("invalid uint8: %s", raw)
 		}
 		return uint8(v), nil
 
 	case TypeUint16:
-		v, err := strconv.ParseUint(raw, 10, 16)
+		v, err := parseUint(raw, 16)
 		if err != nil {
 			return nil, fmt.Errorf("invalid uint16: %s", raw)
 		}
 		return uint16(v), nil
 
 	case TypeUint32:
-		v, err := strconv.ParseUint(raw, 10, 32)
+		v, err := parseUint(raw, 32)
 		if err != nil {
 			return nil, fmt.Errorf("invalid uint32: %s", raw)
 		}
 		return uint32(v), nil
 
 	case TypeUint64:
-		v, err := strconv.ParseUint(raw, 10, 64)
+		v, err := parseUint(raw, 64)
 		if err != nil {
 			return nil, fmt.Errorf("invalid uint64: %s", raw)
 		}
@@ -361,38 +453,37 @@ func parseValue(raw string, valueType ValueType, delimiter string) (any, error)
 		}
 		return float32(v), nil
 
+	case TypeStringMap:
+		return parseStringMap(raw, delimiter)
+
 	case TypeTime:
-		// Try multiple time formats
-		timeFormats := []string{
-			time.RFC3339,
-			time.RFC3339Nano,
-			"2006-01-02T15:04:05Z07:00",
-			"2006-01-02 15:04:05",
-			"2006-01-02T15:04:05",
-			"2006-01-02",
-			time.UnixDate,
-			time.RubyDate,
-		}
-
-		// Try Unix timestamp first
-		if timestamp, err := strconv.ParseInt(raw, 10, 64); err == nil {
-			return time.Unix(timestamp, 0), nil
+		v, err := parseTime(raw, nil, nil)
+		if err != nil {
+			return nil, err
 		}
+		return v, nil
 
-		// Try each format
-		for _, format := range timeFormats {
-			if t, err := time.Parse(format, raw); err == nil {
-				return t, nil
+	case TypeDurationSlice:
+		parts := splitList(raw, delimiter)
+		result := make([]time.Duration, 0, len(parts))
+		for _, p := range parts {
+			trimmed := strings.TrimSpace(p)
+			if trimmed == "" {
+				continue
+			}
+			v, err := parseDuration(trimmed)
+			if err != nil {
+				return nil, fmt.Errorf("invalid duration in array: %s", trimmed)
 			}
+			result = append(result, v)
 		}
-
-		return nil, fmt.Errorf("invalid time format: %s (try RFC3339, Unix timestamp, or YYYY-MM-DD HH:MM:SS)", raw)
+		return result, nil
 
 	case TypeFloat64Slice:
 		if raw == "" {
 			return []float64{}, nil
 		}
-		parts := strings.Split(raw, delimiter)
+		parts := splitList(raw, delimiter)
 		result := make([]float64, 0, len(parts))
 		for _, p := range parts {
 			trimmed := strings.TrimSpace(p)
@@ -411,14 +502,14 @@ func parseValue(raw string, valueType ValueType, delimiter string) (any, error)
 		if raw == "" {
 			return []bool{}, nil
 		}
-		parts := strings.Split(raw, delimiter)
+		parts := splitList(raw, delimiter)
 		result := make([]bool, 0, len(parts))
 		for _, p := range parts {
 			trimmed := strings.TrimSpace(p)
 			if trimmed == "" {
 				continue
 			}
-			v, err := strconv.ParseBool(trimmed)
+			v, err := parseBool(trimmed)
 			if err != nil {
 				return nil, fmt.Errorf("invalid bool in array: %s", trimmed)
 			}
@@ -463,10 +554,10 @@ func parseValue(raw string, valueType ValueType, delimiter string) (any, error)
 		if raw == "" {
 			return nil, fmt.Errorf("UUID cannot be empty")
 		}
-		// Use UUID library for proper parsing and validation
+		// Only the canonical 8-4-4-4-12 form, not the {...}, urn:uuid: or undashed ones
 		parsed, err := uuid.Parse(raw)
-		if err != nil {
-			return nil, fmt.Errorf("invalid UUID: %s", raw)
+		if err != nil || len(raw) != 36 {
+			return nil, fmt.Errorf("invalid UUID: %s (expected xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx)", raw)
 		}
 		return parsed, nil // Store as uuid.UUID
 
@@ -483,3 +574,222 @@ func parseValue(raw string, valueType ValueType, delimiter string) (any, error)
 		return nil, fmt.Errorf("unknown type: %v", valueType)
 	}
 }
+
+// parseBool parses the forms strconv.ParseBool accepts plus yes/no, on/off and
+// enabled/disabled, in any case
+func parseBool(raw string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "yes", "on", "enabled":
+		return true, nil
+	case "no", "off", "disabled":
+		return false, nil
+	}
+	return strconv.ParseBool(strings.TrimSpace(raw))
+}
+
+// parseInt parses a signed integer written in decimal, with "_" digit separators, as
+// hex, octal or binary with a 0x, 0o or 0b prefix, or in scientific notation with an
+// integral result such as 1.5e3
+func parseInt(raw string, bitSize int) (int64, error) {
+	return strconv.ParseInt(decimalInt(raw), 10, bitSize)
+}
+
+// parseUint is parseInt for unsigned integers
+func parseUint(raw string, bitSize int) (uint64, error) {
+	return strconv.ParseUint(decimalInt(raw), 10, bitSize)
+}
+
+// decimalInt rewrites the integer forms parseInt accepts as plain decimal. Text it
+// can't rewrite is returned unchanged for strconv to reject. A leading zero never
+// means octal: 010 is ten, and 0_10 is rejected.
+func decimalInt(raw string) string {
+	if isDecimalInt(raw) {
+		return raw
+	}
+	digits := strings.TrimLeft(raw, "+-")
+	prefixed := len(digits) > 1 && digits[0] == '0' && strings.ContainsRune("xXoObB", rune(digits[1]))
+	switch {
+	case !prefixed && strings.ContainsAny(digits, "eE"):
+		// Bound the exponent before computing the exact value
+		if f, err := strconv.ParseFloat(raw, 64); err != nil || math.Abs(f) >= 1<<64 {
+			return raw
+		}
+		if r, ok := new(big.Rat).SetString(raw); ok && r.IsInt() {
+			return r.Num().String()
+		}
+	case !prefixed && strings.HasPrefix(digits, "0"):
+		return raw
+	default:
+		if n, ok := new(big.Int).SetString(raw, 0); ok {
+			return n.String()
+		}
+	}
+	return raw
+}
+
+// isDecimalInt reports whether raw is an optionally signed run of decimal digits
+func isDecimalInt(raw string) bool {
+	digits := strings.TrimLeft(raw, "+-")
+	if digits == "" || len(raw)-len(digits) > 1 {
+		return false
+	}
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// checkStrictInt rejects the integer forms parseInt accepts beyond plain decimal (1_000,
+// 0x1F, 1e6) in a raw value of a StrictInt definition: text split on delimiter, indexed
+// environment items or the items of a file array
+func checkStrictInt(value any, delimiter string) error {
+	var items []string
+	switch v := value.(type) {
+	case string:
+		items = splitList(v, delimiter)
+	case indexedEnv:
+		items = v
+	case []any:
+		for _, item := range v {
+			if text, ok := item.(string); ok {
+				items = append(items, text)
+			}
+		}
+	}
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if !isDecimalInt(item) && item != "" {
+			return fmt.Errorf("invalid integer: %s (use plain decimal digits)", item)
+		}
+	}
+	return nil
+}
+
+// checkStrictBool rejects bool words strconv.ParseBool does not accept (yes, on,
+// enabled...) in a raw value of a StrictBool definition: text split on delimiter,
+// indexed environment items or the items of a file array
+func checkStrictBool(value any, delimiter string) error {
+	var items []string
+	switch v := value.(type) {
+	case string:
+		items = splitList(v, delimiter)
+	case indexedEnv:
+		items = v
+	case []any:
+		for _, item := range v {
+			if text, ok := item.(string); ok {
+				items = append(items, text)
+			}
+		}
+	}
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if _, err := strconv.ParseBool(item); err != nil && item != "" {
+			return fmt.Errorf("invalid bool: %s (use true/false or 1/0)", item)
+		}
+	}
+	return nil
+}
+
+// checkURL requires raw to be a URL with a scheme and a host
+func checkURL(raw string) error {
+	v, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %s", redactRawURL(raw))
+	}
+	if v.Scheme == "" || v.Host == "" {
+		return fmt.Errorf("invalid URL (missing scheme or host): %s", v.Redacted())
+	}
+	return nil
+}
+
+// defaultTimeLayouts are the layouts Time() values accept when no TimeLayout is set
+var defaultTimeLayouts = []string{
+	time.RFC3339,
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+	time.UnixDate,
+	time.RubyDate,
+}
+
+// parseTime parses a time in one of layouts or, when there are none, in one of the
+// default layouts or as a Unix timestamp
+func parseTime(raw string, layouts []string, loc *time.Location) (time.Time, error) {
+	if len(layouts) == 0 {
+		if timestamp, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			if loc != nil {
+				return time.Unix(timestamp, 0).In(loc), nil
+			}
+			return time.Unix(timestamp, 0), nil
+		}
+		for _, layout := range defaultTimeLayouts {
+			if t, err := parseTimeIn(layout, raw, loc); err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("invalid time format: %s (try RFC3339, Unix timestamp, or YYYY-MM-DD HH:MM:SS)", raw)
+	}
+
+	for _, layout := range layouts {
+		if t, err := parseTimeIn(layout, raw, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time: %s (expected layout %s)", raw, strings.Join(layouts, " or "))
+}
+
+// parseTimeIn parses raw with layout, reading times without a zone in loc, or in UTC
+// when loc is nil
+func parseTimeIn(layout, raw string, loc *time.Location) (time.Time, error) {
+	if loc == nil {
+		return time.Parse(layout, raw)
+	}
+	return time.ParseInLocation(layout, raw, loc)
+}
+
+// parseStringMap parses key=value entries separated by delimiter, quoted as for slices
+// when an entry contains it; values may contain "="
+func parseStringMap(raw, delimiter string) (map[string]string, error) {
+	result := make(map[string]string)
+	for _, entry := range splitList(raw, delimiter) {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		key, value, found := strings.Cut(entry, "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			return nil, fmt.Errorf("invalid map entry: %s (use key=value)", entry)
+		}
+		result[key] = strings.TrimSpace(value)
+	}
+	return result, nil
+}
+
+// stringMapFromAny converts a map decoded from a file to map[string]string; values
+// must be scalars
+func stringMapFromAny(value any) (map[string]string, error) {
+	switch v := value.(type) {
+	case map[string]string:
+		return v, nil
+	case map[string]any:
+		result := make(map[string]string, len(v))
+		for key, item := range v {
+			switch item.(type) {
+			case map[string]any, []any:
+				return nil, fmt.Errorf("invalid map value for %s: nested values are not supported", key)
+			case nil:
+				result[key] = ""
+			default:
+				result[key] = fmt.Sprint(item)
+			}
+		}
+		return result, nil
+	default:
+		return nil, fmt.Errorf("cannot convert %T to map[string]string", value)
+	}
+}
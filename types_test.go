@@ -1,6 +1,7 @@
 package commandkit
 
 import (
+	"reflect"
 	"testing"
 	"time"
 )
@@ -122,7 +123,13 @@ func TestParseValueBool(t *testing.T) {
 		{"TRUE", true, false},
 		{"FALSE", false, false},
 		{"invalid", false, true},
-		{"yes", false, true}, // Go's ParseBool doesn't support yes/no
+		{"yes", true, false},
+		{"No", false, false},
+		{"ON", true, false},
+		{"off", false, false},
+		{"Enabled", true, false},
+		{"disabled", false, false},
+		{"yesno", false, true},
 	}
 
 	for _, tt := range tests {
@@ -142,6 +149,102 @@ func TestParseValueBool(t *testing.T) {
 	}
 }
 
+func TestStrictBool(t *testing.T) {
+	cfg := New()
+	cfg.WithEnviron([]string{"DEBUG=yes", "FEATURES=on,off", "STRICT_OK=1"})
+	cfg.Define("DEBUG").Bool().Env("DEBUG")
+	cfg.Define("FEATURES").BoolSlice().Env("FEATURES")
+	cfg.Define("STRICT_OK").Bool().Env("STRICT_OK").StrictBool()
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if debug, _ := cfg.lookupValue("DEBUG"); debug != true {
+		t.Errorf("DEBUG = %v, want true", debug)
+	}
+	if features, _ := cfg.lookupValue("FEATURES"); !reflect.DeepEqual(features, []bool{true, false}) {
+		t.Errorf("FEATURES = %v", features)
+	}
+
+	for _, env := range []string{"DEBUG=yes", "DEBUG=true,enabled"} {
+		strict := New()
+		strict.WithEnviron([]string{env})
+		strict.Define("DEBUG").BoolSlice().Env("DEBUG").StrictBool()
+		if err := strict.Process([]string{"app"}); err == nil {
+			t.Errorf("%s: expected StrictBool to reject it", env)
+		}
+	}
+}
+
+func TestParseIntForms(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+		hasError bool
+	}{
+		{"1_000_000", 1000000, false},
+		{"0x1F", 31, false},
+		{"-0x1f", -31, false},
+		{"0b101", 5, false},
+		{"0o17", 15, false},
+		{"010", 10, false}, // never octal
+		{"1e6", 1000000, false},
+		{"1.5E3", 1500, false},
+		{"+42", 42, false},
+		{"1.5", 0, true},
+		{"1e-3", 0, true},
+		{"1e30", 0, true},
+		{"0_10", 0, true},
+		{"1__000", 0, true},
+		{"_1", 0, true},
+		{"Inf", 0, true},
+	}
+
+	for _, tt := range tests {
+		result, err := parseValue(tt.input, TypeInt64, "")
+		if tt.hasError {
+			if err == nil {
+				t.Errorf("%q: expected error, got %v", tt.input, result)
+			}
+			continue
+		}
+		if err != nil || result != tt.expected {
+			t.Errorf("%q = %v, %v; want %d", tt.input, result, err, tt.expected)
+		}
+	}
+
+	if v, err := parseValue("0xFF", TypeUint8, ""); err != nil || v != uint8(255) {
+		t.Errorf("uint8 0xFF = %v, %v", v, err)
+	}
+	if _, err := parseValue("0x100", TypeUint8, ""); err == nil {
+		t.Error("expected 0x100 to overflow uint8")
+	}
+}
+
+func TestStrictInt(t *testing.T) {
+	cfg := New()
+	cfg.WithEnviron([]string{"MAX_BYTES=1_048_576", "LIMITS=1e3,0x10"})
+	cfg.Define("MAX_BYTES").Int64().Env("MAX_BYTES")
+	cfg.Define("LIMITS").IntSlice().Env("LIMITS")
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if maxBytes, _ := cfg.lookupValue("MAX_BYTES"); maxBytes != int64(1048576) {
+		t.Errorf("MAX_BYTES = %v", maxBytes)
+	}
+	if limits, _ := cfg.lookupValue("LIMITS"); !reflect.DeepEqual(limits, []int{1000, 16}) {
+		t.Errorf("LIMITS = %v", limits)
+	}
+
+	for _, env := range []string{"LIMITS=1_000", "LIMITS=10,0x10"} {
+		strict := New()
+		strict.WithEnviron([]string{env})
+		strict.Define("LIMITS").IntSlice().Env("LIMITS").StrictInt()
+		if err := strict.Process([]string{"app"}); err == nil {
+			t.Errorf("%s: expected StrictInt to reject it", env)
+		}
+	}
+}
+
 func TestParseValueDuration(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -216,8 +319,12 @@ func TestParseValueStringSlice(t *testing.T) {
 		{"a, b, c", ",", []string{"a", "b", "c"}, false}, // with spaces
 		{"a|b|c", "|", []string{"a", "b", "c"}, false},
 		{"single", ",", []string{"single"}, false},
-		{"", ",", nil, true},                     // empty string returns nil
-		{"a,,b", ",", []string{"a", "b"}, false}, // empty elements removed
+		{"", ",", nil, true},                                       // empty string returns nil
+		{"a,,b", ",", []string{"a", "b"}, false},                   // empty elements removed
+		{`a,"b,c",d`, ",", []string{"a", "b,c", "d"}, false},       // quoted delimiter
+		{`a, "say ""hi"""`, ",", []string{"a", `say "hi"`}, false}, // escaped quote
+		{`{"k":1};x`, ";", []string{`{"k":1}`, "x"}, false},        // bare quotes kept
+		{`"a|b"||c`, "||", []string{`"a|b"`, "c"}, false},          // multi-character delimiter
 	}
 
 	for _, tt := range tests {
@@ -307,3 +414,18 @@ func TestParseValueUnknownType(t *testing.T) {
 		t.Error("parseValue with unknown type should return error")
 	}
 }
+
+func TestJoinListRoundTrip(t *testing.T) {
+	items := []string{"a", "b,c", `say "hi"`, `"quoted"`}
+	joined := joinList(items, ",")
+	if want := `a,"b,c",say "hi","""quoted"""`; joined != want {
+		t.Errorf("joinList = %s, want %s", joined, want)
+	}
+	result, err := parseValue(joined, TypeStringSlice, ",")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(result, items) {
+		t.Errorf("round trip = %q, want %q", result, items)
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !unix
+
+// commandkit/umask_other.go
+package commandkit
+
+import "os"
+
+// umaskSupported reports whether the process file mode creation mask can be set
+const umaskSupported = false
+
+// setUmask is not available without Unix permissions
+func setUmask(mask os.FileMode) os.FileMode {
+	return 0
+}
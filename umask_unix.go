@@ -0,0 +1,17 @@
+//go:build unix
+
+// commandkit/umask_unix.go
+package commandkit
+
+import (
+	"os"
+	"syscall"
+)
+
+// umaskSupported reports whether the process file mode creation mask can be set
+const umaskSupported = true
+
+// setUmask sets the file mode creation mask and returns the previous one
+func setUmask(mask os.FileMode) os.FileMode {
+	return os.FileMode(syscall.Umask(int(mask.Perm())))
+}
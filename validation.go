@@ -2,12 +2,22 @@
 package commandkit
 
 import (
+	"context"
 	"fmt"
 	"net"
+	"net/mail"
+	"net/url"
 	"os"
+	"path/filepath"
+	"reflect"
 	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 // ValidationCache stores pre-compiled regex patterns for performance
@@ -43,8 +53,33 @@ func (vc *ValidationCache) getRegexp(pattern string) *regexp.Regexp {
 
 // Validation represents a validation rule
 type Validation struct {
-	Name  string
-	Check func(value any) error
+	Name     string
+	Check    func(value any) error
+	relation *keyRelation // Set for rules comparing against another key's value
+	allowed  []string     // Set for rules accepting a fixed set of values
+}
+
+// allowedValues returns the fixed set of values accepted by a oneOf validation
+func (v Validation) allowedValues() []string {
+	if v.allowed != nil {
+		return v.allowed
+	}
+	if !strings.HasPrefix(v.Name, "oneOf(") {
+		return nil
+	}
+	return oneOfValues(v.Name)
+}
+
+// keyRelation describes an ordering constraint between two configuration keys
+type keyRelation struct {
+	otherKey string
+	want     int    // Expected sign of compare(value, other): -1 less than, 1 greater than
+	symbol   string // Operator used in error messages
+}
+
+// noCheck is used by rules that are only evaluated once every key has been resolved
+func noCheck(value any) error {
+	return nil
 }
 
 // Built-in validation constructors
@@ -147,7 +182,8 @@ func validateRegexp(pattern string) Validation {
 
 func validateOneOf(allowed []string) Validation {
 	return Validation{
-		Name: fmt.Sprintf("oneOf(%v)", allowed),
+		Name:    fmt.Sprintf("oneOf(%v)", allowed),
+		allowed: append([]string(nil), allowed...),
 		Check: func(value any) error {
 			if s, ok := value.(string); ok {
 				for _, a := range allowed {
@@ -164,11 +200,11 @@ func validateOneOf(allowed []string) Validation {
 
 func validateMinDuration(min time.Duration) Validation {
 	return Validation{
-		Name: fmt.Sprintf("minDuration(%s)", min),
+		Name: fmt.Sprintf("minDuration(%s)", FormatDuration(min)),
 		Check: func(value any) error {
 			if d, ok := value.(time.Duration); ok {
 				if d < min {
-					return fmt.Errorf("duration %s is less than minimum %s", d, min)
+					return fmt.Errorf("duration %s is less than minimum %s", FormatDuration(d), FormatDuration(min))
 				}
 			}
 			return nil
@@ -178,11 +214,11 @@ func validateMinDuration(min time.Duration) Validation {
 
 func validateMaxDuration(max time.Duration) Validation {
 	return Validation{
-		Name: fmt.Sprintf("maxDuration(%s)", max),
+		Name: fmt.Sprintf("maxDuration(%s)", FormatDuration(max)),
 		Check: func(value any) error {
 			if d, ok := value.(time.Duration); ok {
 				if d > max {
-					return fmt.Errorf("duration %s is greater than maximum %s", d, max)
+					return fmt.Errorf("duration %s is greater than maximum %s", FormatDuration(d), FormatDuration(max))
 				}
 			}
 			return nil
@@ -194,15 +230,8 @@ func validateMinItems(min int) Validation {
 	return Validation{
 		Name: fmt.Sprintf("minItems(%d)", min),
 		Check: func(value any) error {
-			switch v := value.(type) {
-			case []string:
-				if len(v) < min {
-					return fmt.Errorf("array has %d items, minimum is %d", len(v), min)
-				}
-			case []int64:
-				if len(v) < min {
-					return fmt.Errorf("array has %d items, minimum is %d", len(v), min)
-				}
+			if count, noun, ok := itemCount(value); ok && count < min {
+				return fmt.Errorf("%s has %d %s, minimum is %d", noun, count, itemsWord(noun), min)
 			}
 			return nil
 		},
@@ -213,14 +242,49 @@ func validateMaxItems(max int) Validation {
 	return Validation{
 		Name: fmt.Sprintf("maxItems(%d)", max),
 		Check: func(value any) error {
-			switch v := value.(type) {
-			case []string:
-				if len(v) > max {
-					return fmt.Errorf("array has %d items, maximum is %d", len(v), max)
+			if count, noun, ok := itemCount(value); ok && count > max {
+				return fmt.Errorf("%s has %d %s, maximum is %d", noun, count, itemsWord(noun), max)
+			}
+			return nil
+		},
+	}
+}
+
+// itemCount returns the number of items of a slice or entries of a map, with "array"
+// or "map" to describe it
+func itemCount(value any) (int, string, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		return v.Len(), "array", true
+	case reflect.Map:
+		return v.Len(), "map", true
+	default:
+		return 0, "", false
+	}
+}
+
+// itemsWord names the elements of an array or map in messages
+func itemsWord(noun string) string {
+	if noun == "map" {
+		return "entries"
+	}
+	return "items"
+}
+
+func validateRequiredKeys(keys []string) Validation {
+	return Validation{
+		Name: fmt.Sprintf("requiredKeys(%s)", strings.Join(keys, ", ")),
+		Check: func(value any) error {
+			if m, ok := value.(map[string]string); ok {
+				var missing []string
+				for _, key := range keys {
+					if _, exists := m[key]; !exists {
+						missing = append(missing, key)
+					}
 				}
-			case []int64:
-				if len(v) > max {
-					return fmt.Errorf("array has %d items, maximum is %d", len(v), max)
+				if len(missing) > 0 {
+					return fmt.Errorf("map is missing required keys: %s", strings.Join(missing, ", "))
 				}
 			}
 			return nil
@@ -228,6 +292,29 @@ func validateMaxItems(max int) Validation {
 	}
 }
 
+func validateEmailDomains(domains []string) Validation {
+	return Validation{
+		Name: fmt.Sprintf("emailDomains(%s)", strings.Join(domains, ", ")),
+		Check: func(value any) error {
+			text, ok := value.(string)
+			if !ok {
+				return nil
+			}
+			address, err := mail.ParseAddress(text)
+			if err != nil {
+				return fmt.Errorf("invalid email address: %s", text)
+			}
+			domain := address.Address[strings.LastIndex(address.Address, "@")+1:]
+			for _, allowed := range domains {
+				if strings.EqualFold(domain, allowed) {
+					return nil
+				}
+			}
+			return fmt.Errorf("email domain %s is not one of: %s", domain, strings.Join(domains, ", "))
+		},
+	}
+}
+
 // FileMode validation methods
 func validateValidFilePermission() Validation {
 	return Validation{
@@ -339,11 +426,9 @@ func validatePathWritable() Validation {
 		Name: "pathWritable",
 		Check: func(value any) error {
 			if path, ok := value.(string); ok {
-				file, err := os.OpenFile(path, os.O_WRONLY, 0)
-				if err != nil {
+				if err := checkWritable(path); err != nil {
 					return fmt.Errorf("path '%s' is not writable: %w", path, err)
 				}
-				file.Close()
 				return nil
 			}
 			return fmt.Errorf("value must be string, got %T", value)
@@ -351,6 +436,196 @@ func validatePathWritable() Validation {
 	}
 }
 
+// checkWritable checks that a file can be written, a directory can hold new files,
+// or, for a path that does not exist yet, that its directory can hold it
+func checkWritable(path string) error {
+	info, err := os.Stat(path)
+	switch {
+	case os.IsNotExist(err):
+		parent, err := os.Stat(filepath.Dir(path))
+		if err != nil {
+			return err
+		}
+		if !parent.IsDir() {
+			return fmt.Errorf("%s is not a directory", filepath.Dir(path))
+		}
+		return checkWritable(filepath.Dir(path))
+	case err != nil:
+		return err
+	case info.IsDir():
+		file, err := os.CreateTemp(path, ".write-check-*")
+		if err != nil {
+			return err
+		}
+		file.Close()
+		return os.Remove(file.Name())
+	default:
+		file, err := os.OpenFile(path, os.O_WRONLY, 0)
+		if err != nil {
+			return err
+		}
+		return file.Close()
+	}
+}
+
+// URL validation methods
+func validateURLSchemes(schemes []string) Validation {
+	return Validation{
+		Name: fmt.Sprintf("schemes(%s)", strings.Join(schemes, ", ")),
+		Check: func(value any) error {
+			return checkURLs(value, func(u *url.URL) error {
+				for _, scheme := range schemes {
+					if strings.EqualFold(u.Scheme, scheme) {
+						return nil
+					}
+				}
+				return fmt.Errorf("URL '%s' must use scheme %s", u.Redacted(), strings.Join(schemes, " or "))
+			})
+		},
+	}
+}
+
+func validateURLPort() Validation {
+	return Validation{
+		Name: "requirePort",
+		Check: func(value any) error {
+			return checkURLs(value, func(u *url.URL) error {
+				if u.Port() == "" {
+					return fmt.Errorf("URL '%s' must include a port", u.Redacted())
+				}
+				return nil
+			})
+		},
+	}
+}
+
+// checkURLs parses a URL or URL list value and applies check to each URL
+func checkURLs(value any, check func(*url.URL) error) error {
+	var raws []string
+	switch v := value.(type) {
+	case string:
+		raws = []string{v}
+	case []string:
+		raws = v
+	default:
+		return fmt.Errorf("value must be a URL, got %T", value)
+	}
+	for _, raw := range raws {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return fmt.Errorf("invalid URL: %s", redactRawURL(raw))
+		}
+		if err := check(u); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// redactRawURL hides the password of a URL like url.URL.Redacted, also for URLs that
+// do not parse, so error messages never show credentials
+func redactRawURL(raw string) string {
+	if u, err := url.Parse(raw); err == nil {
+		return u.Redacted()
+	}
+	scheme, rest, found := strings.Cut(raw, "://")
+	if !found {
+		return raw
+	}
+	authority := rest
+	if end := strings.IndexAny(rest, "/?#"); end >= 0 {
+		authority = rest[:end]
+	}
+	at := strings.LastIndex(authority, "@")
+	if at < 0 {
+		return raw
+	}
+	user, _, hasPassword := strings.Cut(authority[:at], ":")
+	if !hasPassword {
+		return raw
+	}
+	return scheme + "://" + user + ":xxxxx" + rest[at:]
+}
+
+// Host:port validation methods
+func validateHostResolves() Validation {
+	return Validation{
+		Name: "resolvable",
+		Check: func(value any) error {
+			hp, ok := value.(HostPort)
+			if !ok {
+				return fmt.Errorf("value must be HostPort, got %T", value)
+			}
+			if hp.Host == "" || net.ParseIP(hp.Host) != nil {
+				return nil
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if _, err := net.DefaultResolver.LookupHost(ctx, hp.Host); err != nil {
+				return fmt.Errorf("host %s does not resolve: %w", hp.Host, err)
+			}
+			return nil
+		},
+	}
+}
+
+// keepsLivePort reports whether a reload keeps the port the live config already holds,
+// which the process itself may be listening on by now
+func (c *Config) keepsLivePort(key string, v Validation, value any) bool {
+	if v.Name != "portFree" || c.liveValues == nil {
+		return false
+	}
+	live, ok := c.liveValues[key]
+	return ok && reflect.DeepEqual(live, value)
+}
+
+// Port validation methods
+func validatePortFree() Validation {
+	return Validation{
+		Name: "portFree",
+		Check: func(value any) error {
+			port, ok := value.(int)
+			if !ok {
+				return fmt.Errorf("value must be int, got %T", value)
+			}
+			listener, err := net.Listen("tcp", net.JoinHostPort("", strconv.Itoa(port)))
+			if err != nil {
+				return fmt.Errorf("port %d is not available: %w", port, err)
+			}
+			listener.Close()
+			return nil
+		},
+	}
+}
+
+// UUID validation methods
+func validateUUIDVersion(versions []int) Validation {
+	names := make([]string, len(versions))
+	for i, version := range versions {
+		names[i] = strconv.Itoa(version)
+	}
+	return Validation{
+		Name: fmt.Sprintf("uuidVersion(%s)", strings.Join(names, ", ")),
+		Check: func(value any) error {
+			id, ok := value.(uuid.UUID)
+			if text, isString := value.(string); isString {
+				parsed, err := uuid.Parse(text)
+				if err != nil {
+					return fmt.Errorf("invalid UUID: %s", text)
+				}
+				id, ok = parsed, true
+			}
+			if !ok {
+				return fmt.Errorf("value must be uuid.UUID, got %T", value)
+			}
+			if !slices.Contains(versions, int(id.Version())) {
+				return fmt.Errorf("UUID '%s' is version %d, expected %s", id, id.Version(), strings.Join(names, " or "))
+			}
+			return nil
+		},
+	}
+}
+
 // IP validation methods
 func validateIPVersion(version int) Validation {
 	return Validation{
@@ -445,3 +720,155 @@ func validateTimeRange(min, max time.Time) Validation {
 		},
 	}
 }
+
+// Key relation validation methods
+func validateLessThanKey(otherKey string) Validation {
+	return Validation{
+		Name:     fmt.Sprintf("lessThanKey(%s)", otherKey),
+		Check:    noCheck,
+		relation: &keyRelation{otherKey: otherKey, want: -1, symbol: "<"},
+	}
+}
+
+func validateGreaterThanKey(otherKey string) Validation {
+	return Validation{
+		Name:     fmt.Sprintf("greaterThanKey(%s)", otherKey),
+		Check:    noCheck,
+		relation: &keyRelation{otherKey: otherKey, want: 1, symbol: ">"},
+	}
+}
+
+// compareValues orders two resolved values of compatible types (durations, times or numbers)
+func compareValues(a, b any) (int, error) {
+	switch av := a.(type) {
+	case time.Duration:
+		bv, ok := b.(time.Duration)
+		if !ok {
+			return 0, fmt.Errorf("cannot compare duration with %T", b)
+		}
+		return compareOrdered(av, bv), nil
+	case time.Time:
+		bv, ok := b.(time.Time)
+		if !ok {
+			return 0, fmt.Errorf("cannot compare time with %T", b)
+		}
+		return av.Compare(bv), nil
+	}
+
+	av, aok := numericValue(a)
+	bv, bok := numericValue(b)
+	if !aok || !bok {
+		return 0, fmt.Errorf("cannot compare %T with %T", a, b)
+	}
+	return compareNumbers(av, bv), nil
+}
+
+// numericValue returns any integer or float kind as a reflect.Value (durations excluded)
+func numericValue(value any) (reflect.Value, bool) {
+	if _, isDuration := value.(time.Duration); isDuration {
+		return reflect.Value{}, false
+	}
+	v := reflect.ValueOf(value)
+	if v.CanInt() || v.CanUint() || v.CanFloat() {
+		return v, true
+	}
+	return reflect.Value{}, false
+}
+
+// compareNumbers orders two numeric values, comparing integers exactly so values
+// beyond float64 precision stay distinct; floats on either side compare as float64
+func compareNumbers(a, b reflect.Value) int {
+	switch {
+	case a.CanInt() && b.CanInt():
+		return compareOrdered(a.Int(), b.Int())
+	case a.CanUint() && b.CanUint():
+		return compareOrdered(a.Uint(), b.Uint())
+	case a.CanInt() && b.CanUint():
+		if a.Int() < 0 {
+			return -1
+		}
+		return compareOrdered(uint64(a.Int()), b.Uint())
+	case a.CanUint() && b.CanInt():
+		return -compareNumbers(b, a)
+	}
+	return compareOrdered(floatValue(a), floatValue(b))
+}
+
+// floatValue converts an integer or float kind to float64
+func floatValue(v reflect.Value) float64 {
+	switch {
+	case v.CanInt():
+		return float64(v.Int())
+	case v.CanUint():
+		return float64(v.Uint())
+	default:
+		return v.Float()
+	}
+}
+
+func compareOrdered[T int64 | uint64 | float64 | time.Duration](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// checkKeyRelations enforces relational rules once every definition has a resolved value
+// Rules are skipped when either side has no value; a missing value is reported by Required
+func (c *Config) checkKeyRelations(sources map[string]SourceType) []ConfigError {
+	var errs []ConfigError
+
+	for key, def := range c.definitions {
+		for _, validation := range def.validations {
+			rel := validation.relation
+			if rel == nil {
+				continue
+			}
+
+			fail := func(message string) {
+				errs = append(errs, ConfigError{
+					Key:              key,
+					Source:           sources[key].String(),
+					Value:            c.maskValueIfNeeded(key, fmt.Sprintf("%v", c.values[key])),
+					Display:          buildErrorDisplay(def),
+					ErrorDescription: message,
+				})
+			}
+
+			// The compared key may live in the global config a command config layers over
+			otherLayer := c.layerFor(rel.otherKey)
+			otherDef, exists := otherLayer.definitions[rel.otherKey]
+			if !exists {
+				fail(fmt.Sprintf("compared key '%s' is not defined", rel.otherKey))
+				continue
+			}
+			if def.secret || otherDef.secret {
+				fail("secret values cannot be compared with other keys")
+				continue
+			}
+
+			value, other := c.values[key], c.values[rel.otherKey]
+			if otherLayer != c {
+				other, _ = otherLayer.lookupValue(rel.otherKey)
+			}
+			if value == nil || other == nil {
+				continue
+			}
+
+			order, err := compareValues(value, other)
+			if err != nil {
+				fail(fmt.Sprintf("cannot compare with '%s': %v", rel.otherKey, err))
+				continue
+			}
+			if order != rel.want {
+				fail(fmt.Sprintf("value %v must be %s %s (%v)", value, rel.symbol, rel.otherKey, other))
+			}
+		}
+	}
+
+	return errs
+}
@@ -1,6 +1,8 @@
 package commandkit
 
 import (
+	"math"
+	"strings"
 	"testing"
 	"time"
 )
@@ -265,3 +267,103 @@ func TestValidationName(t *testing.T) {
 		}
 	}
 }
+
+func TestKeyRelationsDurations(t *testing.T) {
+	cfg := New()
+	cfg.Define("ACCESS_TOKEN_TTL").Duration().Default(15 * time.Minute).LessThanKey("REFRESH_TOKEN_TTL")
+	cfg.Define("REFRESH_TOKEN_TTL").Duration().Default(24 * time.Hour)
+
+	if errs := cfg.processDefinitions(); len(errs) != 0 {
+		t.Fatalf("Expected relation to hold, got %v", errs)
+	}
+
+	cfg = New()
+	cfg.Define("ACCESS_TOKEN_TTL").Duration().Default(48 * time.Hour).LessThanKey("REFRESH_TOKEN_TTL")
+	cfg.Define("REFRESH_TOKEN_TTL").Duration().Default(24 * time.Hour)
+
+	errs := cfg.processDefinitions()
+	if len(errs) != 1 {
+		t.Fatalf("Expected 1 relation error, got %d: %v", len(errs), errs)
+	}
+	if errs[0].Key != "ACCESS_TOKEN_TTL" {
+		t.Errorf("Expected error on ACCESS_TOKEN_TTL, got %s", errs[0].Key)
+	}
+}
+
+func TestKeyRelationsNumeric(t *testing.T) {
+	cfg := New()
+	cfg.Define("MAX_CONNS").Int64().Default(10).GreaterThanKey("MIN_CONNS")
+	cfg.Define("MIN_CONNS").Int().Default(10)
+
+	if errs := cfg.processDefinitions(); len(errs) != 1 {
+		t.Fatalf("Expected equal values to fail GreaterThanKey, got %v", errs)
+	}
+}
+
+func TestCompareValuesLargeIntegers(t *testing.T) {
+	tests := []struct {
+		a, b any
+		want int
+	}{
+		{int64(1<<53 + 1), int64(1 << 53), 1},
+		{uint64(math.MaxUint64 - 1), uint64(math.MaxUint64), -1},
+		{int64(-1), uint64(math.MaxUint64), -1},
+		{uint64(math.MaxInt64 + 1), int64(math.MaxInt64), 1},
+		{int64(3), 2.5, 1},
+	}
+	for _, tt := range tests {
+		got, err := compareValues(tt.a, tt.b)
+		if err != nil || got != tt.want {
+			t.Errorf("compareValues(%v, %v) = %d, %v; want %d", tt.a, tt.b, got, err, tt.want)
+		}
+	}
+}
+
+func TestKeyRelationsAcrossLayers(t *testing.T) {
+	global := New()
+	global.Define("MIN_CONNS").Int64().Default(10)
+	if errs := global.processDefinitions(); len(errs) != 0 {
+		t.Fatalf("Unexpected errors: %v", errs)
+	}
+
+	command := global.deriveChild()
+	command.definitions = make(map[string]*Definition)
+	command.values = make(map[string]any)
+	command.secrets = newSecretStore()
+	command.parent = global
+	command.Define("MAX_CONNS").Int64().Default(5).GreaterThanKey("MIN_CONNS")
+
+	errs := command.processDefinitions()
+	if len(errs) != 1 || !strings.Contains(errs[0].ErrorDescription, "must be > MIN_CONNS (10)") {
+		t.Errorf("Expected the command key to be compared with the global one, got %v", errs)
+	}
+}
+
+func TestKeyRelationsInvalidTargets(t *testing.T) {
+	cfg := New()
+	cfg.Define("TIMEOUT").Duration().Default(time.Second).LessThanKey("MISSING")
+	if errs := cfg.processDefinitions(); len(errs) != 1 {
+		t.Errorf("Expected error for undefined compared key, got %v", errs)
+	}
+
+	cfg = New()
+	cfg.Define("TIMEOUT").Duration().Default(time.Second).LessThanKey("RETRIES")
+	cfg.Define("RETRIES").Int64().Default(3)
+	if errs := cfg.processDefinitions(); len(errs) != 1 {
+		t.Errorf("Expected error comparing duration with number, got %v", errs)
+	}
+
+	cfg = New()
+	cfg.Define("TIMEOUT").Duration().LessThanKey("DEADLINE")
+	cfg.Define("DEADLINE").Duration().Default(time.Second)
+	if errs := cfg.processDefinitions(); len(errs) != 0 {
+		t.Errorf("Expected unset values to skip relation checks, got %v", errs)
+	}
+}
+
+func TestKeyRelationsHelpDisplay(t *testing.T) {
+	got := formatValidation([]Validation{validateLessThanKey("B"), validateGreaterThanKey("C")})
+	if len(got) != 2 || got[0] != "< B" || got[1] != "> C" {
+		t.Errorf("Unexpected relation display: %v", got)
+	}
+}
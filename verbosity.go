@@ -0,0 +1,64 @@
+// commandkit/verbosity.go
+package commandkit
+
+import "log"
+
+// Verbosity is the amount of output requested with the flags defined by VerbosityFlags
+type Verbosity int
+
+const (
+	VerbosityQuiet   Verbosity = -1 // Errors only
+	VerbosityNormal  Verbosity = 0  // Default output
+	VerbosityVerbose Verbosity = 1  // Extra details; each repetition of the verbose flag adds a level
+)
+
+// Configuration keys defined by VerbosityFlags
+const (
+	quietKey   = "QUIET"
+	verboseKey = "VERBOSE"
+)
+
+// VerbosityFlags defines persistent flags, such as --quiet and --verbose, selecting how
+// much the framework prints: built-in middleware logging and override warnings follow
+// the selected level, and commands read it with ctx.Verbosity(). The verbose flag can be
+// repeated (-v -v) for more detail. Either flag name may be empty to omit it.
+func (c *Config) VerbosityFlags(quietFlag, verboseFlag string) *Config {
+	if quietFlag != "" {
+		c.Define(quietKey).CountFlag(quietFlag).Persistent().Description("Only print errors")
+	}
+	if verboseFlag != "" {
+		c.Define(verboseKey).CountFlag(verboseFlag).Persistent().Description("Print more details (repeatable)")
+	}
+	return c
+}
+
+// verbosity returns the level selected with the VerbosityFlags flags; quiet wins over verbose
+func (c *Config) verbosity() Verbosity {
+	if c == nil {
+		return VerbosityNormal
+	}
+	if quiet, ok := c.lookupValue(quietKey); ok {
+		if count, isInt := quiet.(int64); isInt && count > 0 {
+			return VerbosityQuiet
+		}
+	}
+	if verbose, ok := c.lookupValue(verboseKey); ok {
+		if count, isInt := verbose.(int64); isInt && count > 0 {
+			return Verbosity(count)
+		}
+	}
+	return VerbosityNormal
+}
+
+// Verbosity returns the output level selected with the VerbosityFlags flags, or
+// VerbosityNormal when they were not given
+func (ctx *CommandContext) Verbosity() Verbosity {
+	return ctx.GlobalConfig.verbosity()
+}
+
+// logf logs a built-in message when the selected verbosity reaches level
+func (ctx *CommandContext) logf(level Verbosity, format string, args ...any) {
+	if ctx.Verbosity() >= level {
+		log.Printf(format, args...)
+	}
+}
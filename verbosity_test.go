@@ -0,0 +1,64 @@
+package commandkit
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestVerbosityFlags(t *testing.T) {
+	tests := []struct {
+		args []string
+		want Verbosity
+	}{
+		{[]string{"app", "run"}, VerbosityNormal},
+		{[]string{"app", "--quiet", "run"}, VerbosityQuiet},
+		{[]string{"app", "run", "--verbose"}, VerbosityVerbose},
+		{[]string{"app", "--verbose", "run", "--verbose"}, 2},
+		{[]string{"app", "run", "--quiet", "--verbose"}, VerbosityQuiet},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args[1:], " "), func(t *testing.T) {
+			var got Verbosity
+			cfg := New()
+			cfg.VerbosityFlags("quiet", "verbose")
+			cfg.Command("run").Func(func(ctx *CommandContext) error {
+				got = ctx.Verbosity()
+				return nil
+			})
+
+			if err := cfg.Execute(tt.args); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Verbosity() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerbosityAdjustsMiddlewareLogging(t *testing.T) {
+	run := func(args ...string) string {
+		cfg := New()
+		cfg.VerbosityFlags("quiet", "verbose")
+		cfg.UseMiddleware(DefaultErrorHandlingMiddleware())
+		cfg.UseMiddleware(TimingMiddleware())
+		cfg.UseMiddleware(AuthMiddleware(func(*CommandContext) error { return nil }))
+		cfg.Command("fail").Func(func(ctx *CommandContext) error { return errors.New("boom") })
+		return captureLogs(t, func() {
+			cfg.Execute(append([]string{"app", "fail"}, args...))
+		})
+	}
+
+	// The default output is unchanged by VerbosityFlags
+	normal := run()
+	if !strings.Contains(normal, "Command fail took") || !strings.Contains(normal, "Authentication successful") {
+		t.Errorf("normal mode should log as without the flags, got:\n%s", normal)
+	}
+
+	quiet := run("--quiet")
+	if strings.Contains(quiet, "took") || strings.Contains(quiet, "Authentication") || !strings.Contains(quiet, "Error in command fail: boom") {
+		t.Errorf("quiet mode should only log errors, got:\n%s", quiet)
+	}
+}
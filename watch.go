@@ -0,0 +1,108 @@
+// commandkit/watch.go
+package commandkit
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce groups the bursts of events editors produce when saving a file
+const watchDebounce = 100 * time.Millisecond
+
+// WatchFile watches a loaded configuration file and reloads the configuration whenever
+// it changes. Every loaded file is read again and all definitions are processed; when
+// that fails the previous values stay active. callback (which may be nil) receives nil
+// after each successful reload, or the error that prevented it.
+//
+// The directory holding the file is watched so that editors replacing the file on
+// save are handled. Watching stops with StopWatching or Destroy.
+func (c *Config) WatchFile(filename string, callback func(error)) error {
+	if c.fileConfig == nil || !slices.Contains(c.fileConfig.files, filename) {
+		return fmt.Errorf("file %s has not been loaded", filename)
+	}
+
+	target, err := filepath.Abs(filename)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", filename, err)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create file watcher: %w", err)
+	}
+	if err := watcher.Add(filepath.Dir(target)); err != nil {
+		watcher.Close()
+		return fmt.Errorf("failed to watch %s: %w", filename, err)
+	}
+
+	if callback == nil {
+		callback = func(error) {}
+	}
+
+	c.mu.Lock()
+	c.watchers = append(c.watchers, watcher)
+	c.mu.Unlock()
+
+	go c.watchLoop(watcher, target, callback)
+	return nil
+}
+
+// StopWatching stops every watcher started with WatchFile
+func (c *Config) StopWatching() {
+	c.mu.Lock()
+	watchers := c.watchers
+	c.watchers = nil
+	c.mu.Unlock()
+
+	for _, watcher := range watchers {
+		watcher.Close()
+	}
+}
+
+// watchLoop reloads the configuration once events for target settle
+func (c *Config) watchLoop(watcher *fsnotify.Watcher, target string, callback func(error)) {
+	var pending <-chan time.Time
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Name != target || !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) {
+				continue
+			}
+			pending = time.After(watchDebounce)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			callback(fmt.Errorf("file watcher error: %w", err))
+		case <-pending:
+			pending = nil
+			callback(c.Reload())
+		}
+	}
+}
+
+// Reload re-reads the loaded files and registered sources, refreshing those that cache
+// their values (Refresher), into a candidate set of values and runs every validation on
+// it. The candidate is swapped in atomically only
+// when everything passes; otherwise the current values stay untouched and the errors
+// are returned (and passed to the OnReloadError callbacks). OnChange callbacks fire for
+// the keys that changed, then the OnReloadSuccess callbacks.
+func (c *Config) Reload() error {
+	c.refreshSources()
+	candidate, errs := c.stageSnapshot()
+	if len(errs) > 0 {
+		c.reloadRejected(errs)
+		return ConfigErrors(errs)
+	}
+	c.commitSnapshot(&candidate).DestroyAll()
+	c.reloadCommitted()
+	return nil
+}
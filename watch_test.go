@@ -0,0 +1,287 @@
+// commandkit/watch_test.go
+package commandkit
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatchFileReloadsChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfigFile(t, path, "workers: 2\n")
+
+	cfg := New()
+	cfg.Define("WORKERS").Int64().File("workers").Range(1, 10)
+	cfg.SetDefaultPriority(PriorityFileEnvFlagDefault)
+	if err := cfg.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if errs := cfg.processDefinitions(); len(errs) > 0 {
+		t.Fatalf("Unexpected errors: %v", errs)
+	}
+
+	results := make(chan error, 4)
+	if err := cfg.WatchFile(path, func(err error) { results <- err }); err != nil {
+		t.Fatalf("WatchFile failed: %v", err)
+	}
+	defer cfg.Destroy()
+
+	waitForReload := func() error {
+		t.Helper()
+		select {
+		case err := <-results:
+			return err
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for reload")
+			return nil
+		}
+	}
+
+	writeConfigFile(t, path, "workers: 5\n")
+	if err := waitForReload(); err != nil {
+		t.Fatalf("Expected successful reload, got %v", err)
+	}
+	if value, _ := cfg.lookupValue("WORKERS"); value != int64(5) {
+		t.Errorf("Expected reloaded value 5, got %v", value)
+	}
+
+	writeConfigFile(t, path, "workers: 50\n")
+	if err := waitForReload(); err == nil {
+		t.Fatal("Expected invalid reload to report an error")
+	}
+	if value, _ := cfg.lookupValue("WORKERS"); value != int64(5) {
+		t.Errorf("Expected last good value 5 to remain active, got %v", value)
+	}
+}
+
+func TestWatchFileRequiresLoadedFile(t *testing.T) {
+	cfg := New()
+	if err := cfg.WatchFile(filepath.Join(t.TempDir(), "missing.json"), nil); err == nil {
+		t.Error("Expected error when watching a file that was not loaded")
+	}
+}
+
+func TestOnChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfigFile(t, path, "log_level: info\nworkers: 2\n")
+
+	cfg := New()
+	cfg.Define("LOG_LEVEL").String().File("log_level")
+	cfg.Define("WORKERS").Int64().File("workers")
+	cfg.SetDefaultPriority(PriorityFileEnvFlagDefault)
+	if err := cfg.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+
+	var changes []string
+	cfg.OnChange("LOG_LEVEL", func(old, new any) {
+		current, _ := cfg.lookupValue("LOG_LEVEL")
+		changes = append(changes, fmt.Sprintf("%v -> %v (live: %v)", old, new, current))
+	})
+	cfg.OnChange("WORKERS", func(old, new any) {
+		changes = append(changes, fmt.Sprintf("workers %v -> %v", old, new))
+	})
+
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := cfg.Reload(); err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Fatalf("unchanged values must not fire callbacks, got %v", changes)
+	}
+
+	writeConfigFile(t, path, "log_level: debug\nworkers: 2\n")
+	if err := cfg.Reload(); err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	if want := []string{"info -> debug (live: debug)"}; !slices.Equal(changes, want) {
+		t.Errorf("changes = %v, want %v", changes, want)
+	}
+}
+
+func TestOnChangeMasksSecrets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfigFile(t, path, "db_password: hunter2\n")
+
+	cfg := New()
+	cfg.Define("DB_PASSWORD").String().File("db_password").Secret()
+	cfg.SetDefaultPriority(PriorityFileEnvFlagDefault)
+	if err := cfg.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+
+	var changes []string
+	cfg.OnChange("DB_PASSWORD", func(old, new any) {
+		changes = append(changes, fmt.Sprintf("%v -> %v (live: %s)", old, new, cfg.GetSecret("DB_PASSWORD").String()))
+	})
+	passwords := Watch[string](cfg, "DB_PASSWORD")
+
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := cfg.Reload(); err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Fatalf("an unchanged secret must not fire callbacks, got %v", changes)
+	}
+
+	writeConfigFile(t, path, "db_password: rotated-pw\n")
+	if err := cfg.Reload(); err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	if want := []string{"hu***r2 -> ro******pw (live: rotated-pw)"}; !slices.Equal(changes, want) {
+		t.Errorf("changes = %v, want %v", changes, want)
+	}
+	select {
+	case masked := <-passwords:
+		if masked != "ro******pw" {
+			t.Errorf("Watch sent %q, want the masked value", masked)
+		}
+	default:
+		t.Error("Watch did not signal the secret change")
+	}
+}
+
+func TestReloadIsAtomic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfigFile(t, path, "workers: 2\nname: api\n")
+
+	cfg := New()
+	cfg.SetDefaultPriority(PriorityFileEnvFlagDefault)
+	cfg.Define("WORKERS").Int64().File("workers").Range(1, 10)
+	cfg.Define("NAME").String().File("name")
+	if err := cfg.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// A valid key next to an invalid one must not be applied on its own
+	writeConfigFile(t, path, "workers: 50\nname: worker\n")
+	if err := cfg.Reload(); err == nil || !strings.Contains(err.Error(), "WORKERS") {
+		t.Errorf("expected a validation error for WORKERS, got %v", err)
+	}
+	writeConfigFile(t, path, "workers: [\n")
+	if err := cfg.Reload(); err == nil {
+		t.Error("expected a parse error")
+	}
+	if name, _ := cfg.lookupValue("NAME"); name != "api" {
+		t.Errorf("NAME = %v, a rejected reload must keep every previous value", name)
+	}
+
+	writeConfigFile(t, path, "workers: 5\nname: worker\n")
+	if err := cfg.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if workers, _ := cfg.lookupValue("WORKERS"); workers != int64(5) {
+		t.Errorf("WORKERS = %v, want 5", workers)
+	}
+}
+
+func TestProcessAgainKeepsSecretsOnFailure(t *testing.T) {
+	cfg := New()
+	cfg.Define("API_KEY").String().Flag("api-key").Secret()
+	cfg.Define("WORKERS").Int64().Flag("workers").Range(1, 10)
+	defer cfg.Destroy()
+
+	if err := cfg.Process([]string{"app", "--api-key", "first", "--workers", "2"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := cfg.Process([]string{"app", "--api-key", "second", "--workers", "50"}); err == nil {
+		t.Fatal("expected a validation error for WORKERS")
+	}
+	if key := cfg.GetSecret("API_KEY").String(); key != "first" {
+		t.Errorf("API_KEY = %q, a failed Process must keep the previous secrets", key)
+	}
+	if workers, _ := cfg.lookupValue("WORKERS"); workers != int64(2) {
+		t.Errorf("WORKERS = %v, want 2", workers)
+	}
+
+	if err := cfg.Process([]string{"app", "--api-key", "second", "--workers", "5"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if key := cfg.GetSecret("API_KEY").String(); key != "second" {
+		t.Errorf("API_KEY = %q, want second", key)
+	}
+}
+
+func TestImmutableKeysRejectReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfigFile(t, path, "port: 8080\nlog_level: info\n")
+
+	cfg := New()
+	cfg.SetDefaultPriority(PriorityFileEnvFlagDefault)
+	cfg.Define("PORT").Int64().File("port").Immutable()
+	cfg.Define("LOG_LEVEL").String().File("log_level")
+	if err := cfg.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	writeConfigFile(t, path, "port: 9090\nlog_level: debug\n")
+	err := cfg.Reload()
+	if err == nil || !strings.Contains(err.Error(), "immutable") || !strings.Contains(err.Error(), "PORT") {
+		t.Fatalf("expected an immutable error for PORT, got %v", err)
+	}
+	if level, _ := cfg.lookupValue("LOG_LEVEL"); level != "info" {
+		t.Errorf("LOG_LEVEL = %v, a rejected reload must not apply other keys", level)
+	}
+
+	// Other keys may change as long as immutable ones keep their value
+	writeConfigFile(t, path, "port: 8080\nlog_level: debug\n")
+	if err := cfg.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if level, _ := cfg.lookupValue("LOG_LEVEL"); level != "debug" {
+		t.Errorf("LOG_LEVEL = %v, want debug", level)
+	}
+}
+
+func TestReloadHooks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfigFile(t, path, "workers: 2\n")
+
+	cfg := New()
+	cfg.SetDefaultPriority(PriorityFileEnvFlagDefault)
+	cfg.Define("WORKERS").Int64().File("workers").Range(1, 10)
+	if err := cfg.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if err := cfg.Process([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var rejected [][]ConfigError
+	var applied int
+	cfg.OnReloadError(func(errs []ConfigError) { rejected = append(rejected, errs) })
+	cfg.OnReloadSuccess(func() { applied++ })
+
+	writeConfigFile(t, path, "workers: 50\n")
+	if err := cfg.Reload(); err == nil {
+		t.Fatal("expected a validation error")
+	}
+	if len(rejected) != 1 || len(rejected[0]) != 1 || rejected[0][0].Key != "WORKERS" {
+		t.Errorf("OnReloadError received %v, want one WORKERS error", rejected)
+	}
+	if applied != 0 {
+		t.Errorf("OnReloadSuccess called %d times for a rejected reload", applied)
+	}
+
+	writeConfigFile(t, path, "workers: 5\n")
+	if err := cfg.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if applied != 1 || len(rejected) != 1 {
+		t.Errorf("got %d successes and %d failures, want 1 and 1", applied, len(rejected))
+	}
+}